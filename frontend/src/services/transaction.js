@@ -4,6 +4,12 @@ export const transactionService = {
   // Get all transactions for current user
   async getTransactions(limit = 50) {
     const response = await apiClient.get(`/transactions?limit=${limit}`)
+    return response.data.transactions
+  },
+
+  // Search transactions; returns { transactions, next_cursor, summary }
+  async searchTransactions(filters = {}) {
+    const response = await apiClient.get('/transactions', { params: filters })
     return response.data
   },
 
@@ -125,9 +125,12 @@ func isSchemaCompatible() bool {
 
 // autoMigrate runs database migrations
 func autoMigrate() error {
-	// Import models here to avoid circular imports
-	// This will be implemented when we create the models
-	return DB.AutoMigrate(
+	return DB.AutoMigrate(Models()...)
+}
+
+// Models lists every model the schema is migrated from
+func Models() []interface{} {
+	return []interface{}{
 		&models.User{},
 		&models.Wallet{},
 		&models.Transaction{},
@@ -139,7 +142,52 @@ func autoMigrate() error {
 		&models.BlogComment{},
 		&models.BlogCategory{},
 		&models.BlogTag{},
-	)
+		&models.LedgerAccount{},
+		&models.JournalEntry{},
+		&models.Posting{},
+		&models.ExchangeRate{},
+		&models.ExchangeQuote{},
+		&models.ExternalAccount{},
+		&models.Payout{},
+		&models.TransferLimit{},
+		&models.LimitUsage{},
+		&models.SettingsVersion{},
+		&models.ScheduledTransfer{},
+		&models.ScheduledTransferRun{},
+		&models.PaymentRequest{},
+		&models.Reversal{},
+		&models.Dispute{},
+		&models.Statement{},
+		&models.Category{},
+		&models.CategoryRule{},
+		&models.TransactionAnnotation{},
+		&models.TransactionTag{},
+		&models.TransactionAttachment{},
+		&models.InsightRollup{},
+		&models.Pocket{},
+		&models.WalletMember{},
+		&models.WalletInvite{},
+		&models.Organization{},
+		&models.OrganizationMember{},
+		&models.ApprovalRule{},
+		&models.PaymentInstruction{},
+		&models.InstructionDecision{},
+		&models.BulkPayout{},
+		&models.BulkPayoutLine{},
+		&models.Hold{},
+		&models.Merchant{},
+		&models.MerchantAPIKey{},
+		&models.CheckoutSession{},
+		&models.CheckoutRefund{},
+		&models.WebhookDelivery{},
+		&models.FeeRule{},
+		&models.FeeCharge{},
+		&models.InterestRate{},
+		&models.InterestAccrual{},
+		&models.InterestPosting{},
+		&models.CreditLine{},
+		&models.CreditCharge{},
+	}
 }
 
 // GetDB returns the database instance
@@ -0,0 +1,198 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"regexp"
+	"time"
+
+	"securewallet/internal/config"
+	"securewallet/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Idempotency settings
+const (
+	IdempotencyHeader     = "Idempotency-Key"
+	idempotencyKeyPrefix  = "idempotency:"
+	idempotencyTTL        = 24 * time.Hour
+	idempotencyLockTTL    = 2 * time.Minute
+	idempotencyStatusBusy = "processing"
+	idempotencyStatusDone = "completed"
+)
+
+// idempotencyKeyPattern restricts keys to a safe character set (UUIDs, ULIDs, etc.)
+var idempotencyKeyPattern = regexp.MustCompile(`^[A-Za-z0-9_\-:.]{8,255}$`)
+
+// idempotencyRecord is what gets stored in Redis for each key
+type idempotencyRecord struct {
+	Fingerprint string `json:"fingerprint"`
+	Status      string `json:"status"`
+	StatusCode  int    `json:"status_code,omitempty"`
+	ContentType string `json:"content_type,omitempty"`
+	Body        []byte `json:"body,omitempty"`
+}
+
+// responseRecorder captures the response body so it can be replayed later
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *responseRecorder) Write(data []byte) (int, error) {
+	r.body.Write(data)
+	return r.ResponseWriter.Write(data)
+}
+
+func (r *responseRecorder) WriteString(s string) (int, error) {
+	r.body.WriteString(s)
+	return r.ResponseWriter.WriteString(s)
+}
+
+// IdempotencyMiddleware makes money-moving requests safe to retry.
+// When an Idempotency-Key header is present the first response is stored and
+// replayed for later requests with the same key; reusing a key with a
+// different request body is rejected with 422. Must run after AuthMiddleware.
+func IdempotencyMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyHeader)
+		if key == "" {
+			c.Next()
+			return
+		}
+
+		if !idempotencyKeyPattern.MatchString(key) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Idempotency-Key header"})
+			c.Abort()
+			return
+		}
+
+		user, exists := c.Get("user")
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+			c.Abort()
+			return
+		}
+		currentUser := user.(*models.User)
+
+		redisClient := config.GetRedis()
+		if redisClient == nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Idempotency store unavailable"})
+			c.Abort()
+			return
+		}
+
+		// Read the body so it can be fingerprinted, then restore it for the handler
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		// Keys are scoped per user so one user cannot replay another's response
+		redisKey := idempotencyKeyPrefix + currentUser.ID.String() + ":" + key
+		// The actual path, not the route pattern, so that a key reused for another
+		// resource (e.g. another wallet ID) is a mismatch rather than a replay
+		fingerprint := requestFingerprint(c.Request.Method, c.Request.URL.Path, body)
+		ctx := context.Background()
+
+		lock, _ := json.Marshal(idempotencyRecord{Fingerprint: fingerprint, Status: idempotencyStatusBusy})
+		acquired, err := redisClient.SetNX(ctx, redisKey, lock, idempotencyLockTTL).Result()
+		if err != nil {
+			log.Printf("Idempotency store error: %v", err)
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Idempotency store unavailable"})
+			c.Abort()
+			return
+		}
+
+		if !acquired {
+			replayIdempotentResponse(c, redisKey, fingerprint)
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+
+		// Server errors are not stored so that the client can retry
+		if recorder.Status() >= http.StatusInternalServerError {
+			redisClient.Del(ctx, redisKey)
+			return
+		}
+
+		record, _ := json.Marshal(idempotencyRecord{
+			Fingerprint: fingerprint,
+			Status:      idempotencyStatusDone,
+			StatusCode:  recorder.Status(),
+			ContentType: recorder.Header().Get("Content-Type"),
+			Body:        recorder.body.Bytes(),
+		})
+		if err := redisClient.Set(ctx, redisKey, record, idempotencyTTL).Err(); err != nil {
+			log.Printf("Failed to store idempotent response for key %s: %v", key, err)
+		}
+	}
+}
+
+// replayIdempotentResponse answers a request whose key has been seen before
+func replayIdempotentResponse(c *gin.Context, redisKey, fingerprint string) {
+	raw, err := config.GetRedis().Get(context.Background(), redisKey).Bytes()
+	if err != nil {
+		// The key expired between SETNX and GET; ask the client to retry
+		c.JSON(http.StatusConflict, gin.H{"error": "Request with this Idempotency-Key is being processed, please retry"})
+		c.Abort()
+		return
+	}
+
+	var record idempotencyRecord
+	if err := json.Unmarshal(raw, &record); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Corrupt idempotency record"})
+		c.Abort()
+		return
+	}
+
+	if record.Fingerprint != fingerprint {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error": "Idempotency-Key was already used with a different request",
+		})
+		c.Abort()
+		return
+	}
+
+	if record.Status != idempotencyStatusDone {
+		c.JSON(http.StatusConflict, gin.H{"error": "Request with this Idempotency-Key is still being processed"})
+		c.Abort()
+		return
+	}
+
+	c.Header("Idempotent-Replayed", "true")
+	c.Data(record.StatusCode, record.ContentType, record.Body)
+	c.Abort()
+}
+
+// requestFingerprint hashes the method, path and body. JSON bodies are re-encoded
+// first so that whitespace and key order do not change the fingerprint.
+func requestFingerprint(method, path string, body []byte) string {
+	canonical := body
+	var decoded interface{}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber() // Keep amounts exactly as sent
+	if err := decoder.Decode(&decoded); err == nil {
+		if encoded, err := json.Marshal(decoded); err == nil {
+			canonical = encoded
+		}
+	}
+
+	hash := sha256.New()
+	hash.Write([]byte(method + " " + path + "\n"))
+	hash.Write(canonical)
+	return hex.EncodeToString(hash.Sum(nil))
+}
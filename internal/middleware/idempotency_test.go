@@ -0,0 +1,189 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"securewallet/internal/config"
+	"securewallet/internal/models"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+)
+
+// idempotencyTestServer serves POST /transfers behind IdempotencyMiddleware with
+// an in-memory Redis. The handler counts how often it really runs and answers
+// with the status in the "fail" query parameter, 201 by default.
+func idempotencyTestServer(t *testing.T) (*gin.Engine, *miniredis.Miniredis, *int) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	server := miniredis.RunT(t)
+	previous := config.RedisClient
+	config.RedisClient = redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() {
+		config.RedisClient.Close()
+		config.RedisClient = previous
+	})
+
+	calls := 0
+	authenticate := func(c *gin.Context) {
+		c.Set("user", &models.User{ID: uuid.MustParse(c.GetHeader("X-Test-User"))})
+	}
+	handler := func(c *gin.Context) {
+		calls++
+		if c.Query("fail") != "" {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Transfer failed"})
+			return
+		}
+		c.JSON(http.StatusCreated, gin.H{"transfer": calls, "wallet": c.Param("id")})
+	}
+	router := gin.New()
+	router.POST("/transfers", authenticate, IdempotencyMiddleware(), handler)
+	router.POST("/wallets/:id/transfers", authenticate, IdempotencyMiddleware(), handler)
+	return router, server, &calls
+}
+
+func sendIdempotent(router *gin.Engine, userID uuid.UUID, key, path, body string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("X-Test-User", userID.String())
+	if key != "" {
+		request.Header.Set(IdempotencyHeader, key)
+	}
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func TestIdempotencyReplaysResponse(t *testing.T) {
+	router, _, calls := idempotencyTestServer(t)
+	user := uuid.New()
+	key := "transfer-" + uuid.NewString()
+
+	first := sendIdempotent(router, user, key, "/transfers", `{"amount": "10.00", "to": "bob"}`)
+	if first.Code != http.StatusCreated {
+		t.Fatalf("first request: status %d, want 201", first.Code)
+	}
+
+	// Key order and whitespace do not change the request
+	second := sendIdempotent(router, user, key, "/transfers", `{"to":"bob","amount":"10.00"}`)
+	if second.Code != http.StatusCreated || second.Body.String() != first.Body.String() {
+		t.Errorf("replay = %d %s, want %d %s", second.Code, second.Body, first.Code, first.Body)
+	}
+	if second.Header().Get("Idempotent-Replayed") != "true" {
+		t.Error("replayed response is not marked as replayed")
+	}
+	if second.Header().Get("Content-Type") != first.Header().Get("Content-Type") {
+		t.Errorf("replay content type %q, want %q", second.Header().Get("Content-Type"), first.Header().Get("Content-Type"))
+	}
+	if *calls != 1 {
+		t.Errorf("handler ran %d times, want 1", *calls)
+	}
+
+	// The same key with another amount is a client bug, not a retry
+	changed := sendIdempotent(router, user, key, "/transfers", `{"amount": "11.00", "to": "bob"}`)
+	if changed.Code != http.StatusUnprocessableEntity {
+		t.Errorf("reused key with another body: status %d, want 422", changed.Code)
+	}
+
+	// Keys belong to the user who sent them
+	other := sendIdempotent(router, uuid.New(), key, "/transfers", `{"amount": "10.00", "to": "bob"}`)
+	if other.Code != http.StatusCreated || other.Header().Get("Idempotent-Replayed") != "" {
+		t.Errorf("another user's request = %d replayed %q, want a new 201", other.Code, other.Header().Get("Idempotent-Replayed"))
+	}
+	if *calls != 2 {
+		t.Errorf("handler ran %d times, want 2", *calls)
+	}
+}
+
+func TestIdempotencyKeyIsBoundToThePath(t *testing.T) {
+	router, _, calls := idempotencyTestServer(t)
+	user := uuid.New()
+	key := "transfer-" + uuid.NewString()
+	body := `{"amount": "10.00"}`
+
+	first := sendIdempotent(router, user, key, "/wallets/"+uuid.NewString()+"/transfers", body)
+	if first.Code != http.StatusCreated {
+		t.Fatalf("first request: status %d, want 201", first.Code)
+	}
+
+	// The same key and body for another wallet must not replay the first wallet's response
+	other := sendIdempotent(router, user, key, "/wallets/"+uuid.NewString()+"/transfers", body)
+	if other.Code != http.StatusUnprocessableEntity {
+		t.Errorf("key reused for another wallet: status %d %s, want 422", other.Code, other.Body)
+	}
+	if *calls != 1 {
+		t.Errorf("handler ran %d times, want 1", *calls)
+	}
+}
+
+func TestIdempotencyDoesNotStoreServerErrors(t *testing.T) {
+	router, server, calls := idempotencyTestServer(t)
+	user := uuid.New()
+	key := "transfer-" + uuid.NewString()
+
+	failed := sendIdempotent(router, user, key, "/transfers?fail=1", `{"amount": "10.00"}`)
+	if failed.Code != http.StatusInternalServerError {
+		t.Fatalf("failing request: status %d, want 500", failed.Code)
+	}
+	if server.Exists(idempotencyKeyPrefix + user.String() + ":" + key) {
+		t.Error("a server error was stored")
+	}
+
+	retried := sendIdempotent(router, user, key, "/transfers?fail=1", `{"amount": "10.00"}`)
+	if retried.Header().Get("Idempotent-Replayed") != "" || *calls != 2 {
+		t.Errorf("retry after a server error was replayed (handler ran %d times)", *calls)
+	}
+}
+
+func TestIdempotencyRejectsRequestInFlight(t *testing.T) {
+	router, server, calls := idempotencyTestServer(t)
+	user := uuid.New()
+	key := "transfer-" + uuid.NewString()
+	body := `{"amount": "10.00"}`
+
+	// Another request with this key holds the lock
+	busy, _ := json.Marshal(idempotencyRecord{
+		Fingerprint: requestFingerprint(http.MethodPost, "/transfers", []byte(body)),
+		Status:      idempotencyStatusBusy,
+	})
+	server.Set(idempotencyKeyPrefix+user.String()+":"+key, string(busy))
+
+	response := sendIdempotent(router, user, key, "/transfers", body)
+	if response.Code != http.StatusConflict {
+		t.Errorf("request in flight: status %d, want 409", response.Code)
+	}
+	if *calls != 0 {
+		t.Errorf("handler ran %d times while the key was locked", *calls)
+	}
+}
+
+func TestIdempotencyKeyValidation(t *testing.T) {
+	router, server, calls := idempotencyTestServer(t)
+	user := uuid.New()
+
+	for _, key := range []string{"short", "has spaces in it", strings.Repeat("k", 256)} {
+		if response := sendIdempotent(router, user, key, "/transfers", `{}`); response.Code != http.StatusBadRequest {
+			t.Errorf("key %q: status %d, want 400", key, response.Code)
+		}
+	}
+
+	// Requests without a key are passed through and not stored
+	for i := 0; i < 2; i++ {
+		if response := sendIdempotent(router, user, "", "/transfers", `{}`); response.Code != http.StatusCreated {
+			t.Errorf("request without key: status %d, want 201", response.Code)
+		}
+	}
+	if *calls != 2 {
+		t.Errorf("handler ran %d times, want 2", *calls)
+	}
+	if keys := server.Keys(); len(keys) != 0 {
+		t.Errorf("stored keys %v for requests without a key", keys)
+	}
+}
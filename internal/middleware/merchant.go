@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"securewallet/internal/config"
+	"securewallet/internal/models"
+	"securewallet/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MerchantAuthMiddleware authenticates checkout API calls made with a merchant
+// API key ("Authorization: Bearer sk_..."). The merchant is set in the context,
+// and its owner as the user so that per-user middleware such as idempotency works.
+func MerchantAuthMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authHeader := c.GetHeader("Authorization")
+		if authHeader == "" {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header required"})
+			c.Abort()
+			return
+		}
+
+		// Extract key from "Bearer <key>"
+		tokenParts := strings.Split(authHeader, " ")
+		if len(tokenParts) != 2 || tokenParts[0] != "Bearer" {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid authorization header format"})
+			c.Abort()
+			return
+		}
+
+		merchant, err := services.NewMerchantService().Authenticate(tokenParts[1])
+		if errors.Is(err, services.ErrMerchantSuspended) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Merchant is suspended"})
+			c.Abort()
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid API key"})
+			c.Abort()
+			return
+		}
+
+		var owner models.User
+		if err := config.GetDB().First(&owner, "id = ?", merchant.UserID).Error; err != nil || !owner.IsActive {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid API key"})
+			c.Abort()
+			return
+		}
+
+		c.Set("merchant", merchant)
+		c.Set("user", &owner)
+		c.Next()
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"sync"
 	"time"
 
+	"securewallet/internal/services"
+
 	"github.com/gin-gonic/gin"
 )
 
@@ -25,23 +27,25 @@ func RateLimitMiddleware() gin.HandlerFunc {
 		}
 
 		ip := c.ClientIP()
+		limits := services.CurrentSettings().RateLimit
+		window := time.Duration(limits.WindowSeconds) * time.Second
 
 		rateLimitMutex.Lock()
 		now := time.Now()
 
-		// Clean old attempts (older than 15 minutes)
+		// Clean old attempts (older than the configured window)
 		if attempts, exists := loginAttempts[ip]; exists {
 			var validAttempts []time.Time
 			for _, attempt := range attempts {
-				if now.Sub(attempt) < 15*time.Minute {
+				if now.Sub(attempt) < window {
 					validAttempts = append(validAttempts, attempt)
 				}
 			}
 			loginAttempts[ip] = validAttempts
 		}
 
-		// Check rate limit (max 5 attempts per 15 minutes)
-		if len(loginAttempts[ip]) >= 5 {
+		// Check rate limit (max attempts per window, from system settings)
+		if len(loginAttempts[ip]) >= limits.MaxAttempts {
 			rateLimitMutex.Unlock()
 			c.JSON(http.StatusTooManyRequests, gin.H{
 				"error": "Too many requests. Please try again later.",
@@ -0,0 +1,121 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Bulk payout statuses
+const (
+	BulkPayoutStatusValidated          = "validated"           // Every line passed validation
+	BulkPayoutStatusInvalid            = "invalid"             // Some lines or the batch as a whole failed validation
+	BulkPayoutStatusQueued             = "queued"              // Waiting for the executor
+	BulkPayoutStatusProcessing         = "processing"          // Claimed by the executor
+	BulkPayoutStatusCompleted          = "completed"           // Every line was paid
+	BulkPayoutStatusPartiallyCompleted = "partially_completed" // Line-by-line run where some lines failed
+	BulkPayoutStatusFailed             = "failed"              // Nothing was paid
+)
+
+// Bulk payout execution modes
+const (
+	BulkPayoutModeAtomic  = "atomic"   // All lines in one transaction: either every line is paid or none
+	BulkPayoutModePerLine = "per_line" // Each line on its own; failed and invalid lines are reported
+)
+
+// Bulk payout line statuses
+const (
+	BulkPayoutLineStatusValid     = "valid"
+	BulkPayoutLineStatusInvalid   = "invalid"
+	BulkPayoutLineStatusSucceeded = "succeeded"
+	BulkPayoutLineStatusFailed    = "failed"
+	BulkPayoutLineStatusSkipped   = "skipped" // Invalid lines of a line-by-line run
+)
+
+// BulkPayout is an uploaded batch of transfers out of one wallet, e.g. a payroll
+// run. Lines are validated when the batch is uploaded and paid by the executor.
+type BulkPayout struct {
+	ID               uuid.UUID  `json:"id" gorm:"type:char(36);primaryKey"`
+	UserID           uuid.UUID  `json:"user_id" gorm:"type:char(36);not null;index"`
+	WalletID         uuid.UUID  `json:"wallet_id" gorm:"type:char(36);not null;index"`
+	FileName         string     `json:"file_name,omitempty" gorm:"size:255"`
+	Currency         string     `json:"currency" gorm:"size:3;not null"`
+	Mode             string     `json:"mode,omitempty" gorm:"size:10"` // atomic, per_line; set when executed
+	Status           string     `json:"status" gorm:"size:20;not null;index"`
+	LineCount        int        `json:"line_count" gorm:"not null"`
+	ValidCount       int        `json:"valid_count" gorm:"not null"`
+	SucceededCount   int        `json:"succeeded_count" gorm:"not null;default:0"`
+	FailedCount      int        `json:"failed_count" gorm:"not null;default:0"`
+	TotalAmount      Money      `json:"total_amount" gorm:"type:decimal(15,2);not null"` // Valid lines only
+	TotalFees        Money      `json:"total_fees" gorm:"type:decimal(15,2);not null"`
+	ValidationErrors string     `json:"validation_errors,omitempty" gorm:"type:text"` // Batch-level problems such as the balance or limits
+	LastError        string     `json:"last_error,omitempty" gorm:"size:255"`
+	QueuedAt         *time.Time `json:"queued_at,omitempty"`
+	CompletedAt      *time.Time `json:"completed_at,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+
+	// Relationships
+	Lines []BulkPayoutLine `json:"lines,omitempty" gorm:"foreignKey:BatchID"`
+}
+
+// BulkPayoutLine is one recipient of a bulk payout and the outcome of paying them
+type BulkPayoutLine struct {
+	ID                uuid.UUID  `json:"id" gorm:"type:char(36);primaryKey"`
+	BatchID           uuid.UUID  `json:"batch_id" gorm:"type:char(36);not null;uniqueIndex:idx_bulk_payout_line"`
+	LineNumber        int        `json:"line_number" gorm:"not null;uniqueIndex:idx_bulk_payout_line"`
+	Recipient         string     `json:"recipient" gorm:"size:100;not null"` // Email or username as uploaded
+	RecipientUserID   *uuid.UUID `json:"recipient_user_id,omitempty" gorm:"type:char(36)"`
+	RecipientWalletID *uuid.UUID `json:"recipient_wallet_id,omitempty" gorm:"type:char(36)"`
+	Amount            Money      `json:"amount" gorm:"type:decimal(15,2);not null"`
+	Fee               Money      `json:"fee" gorm:"type:decimal(15,2);not null;default:0"`
+	Currency          string     `json:"currency" gorm:"size:3;not null"`
+	Reference         string     `json:"reference" gorm:"size:255"`
+	Status            string     `json:"status" gorm:"size:20;not null"`
+	Error             string     `json:"error,omitempty" gorm:"size:255"`
+	JournalEntryID    *uuid.UUID `json:"journal_entry_id,omitempty" gorm:"type:char(36)"`
+	TransactionID     *uuid.UUID `json:"transaction_id,omitempty" gorm:"type:char(36)"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+}
+
+// TableName specifies the table name for BulkPayout
+func (BulkPayout) TableName() string {
+	return "bulk_payouts"
+}
+
+// TableName specifies the table name for BulkPayoutLine
+func (BulkPayoutLine) TableName() string {
+	return "bulk_payout_lines"
+}
+
+// BeforeCreate will set a UUID rather than numeric ID
+func (b *BulkPayout) BeforeCreate(tx *gorm.DB) error {
+	if b.ID == uuid.Nil {
+		b.ID = uuid.New()
+	}
+	return nil
+}
+
+// BeforeCreate will set a UUID rather than numeric ID
+func (l *BulkPayoutLine) BeforeCreate(tx *gorm.DB) error {
+	if l.ID == uuid.Nil {
+		l.ID = uuid.New()
+	}
+	return nil
+}
+
+// AfterFind binds the totals to the batch currency
+func (b *BulkPayout) AfterFind(tx *gorm.DB) error {
+	b.TotalAmount = b.TotalAmount.bind(b.Currency)
+	b.TotalFees = b.TotalFees.bind(b.Currency)
+	return nil
+}
+
+// AfterFind binds the amount and fee to the line currency
+func (l *BulkPayoutLine) AfterFind(tx *gorm.DB) error {
+	l.Amount = l.Amount.bind(l.Currency)
+	l.Fee = l.Fee.bind(l.Currency)
+	return nil
+}
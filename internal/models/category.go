@@ -0,0 +1,149 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Category rule match types
+const (
+	CategoryMatchContains   = "contains"
+	CategoryMatchStartsWith = "starts_with"
+	CategoryMatchEquals     = "equals"
+)
+
+// Category sources record how a transaction got its category
+const (
+	CategorySourceManual = "manual"
+	CategorySourceRule   = "rule"
+)
+
+// Category is a user-defined spending or income category
+type Category struct {
+	ID        uuid.UUID `json:"id" gorm:"type:char(36);primaryKey"`
+	UserID    uuid.UUID `json:"user_id" gorm:"type:char(36);not null;uniqueIndex:idx_category_user_name"`
+	Name      string    `json:"name" gorm:"size:50;not null;uniqueIndex:idx_category_user_name"`
+	Color     string    `json:"color" gorm:"size:7"` // Hex colour, e.g. #4f46e5
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// CategoryRule assigns a category to new transactions whose description matches
+type CategoryRule struct {
+	ID         uuid.UUID `json:"id" gorm:"type:char(36);primaryKey"`
+	UserID     uuid.UUID `json:"user_id" gorm:"type:char(36);not null;index"`
+	CategoryID uuid.UUID `json:"category_id" gorm:"type:char(36);not null;index"`
+	MatchType  string    `json:"match_type" gorm:"size:20;not null"` // contains, starts_with, equals
+	Pattern    string    `json:"pattern" gorm:"size:100;not null"`   // Matched case-insensitively against the description
+	Direction  string    `json:"direction,omitempty" gorm:"size:3"`  // in, out; empty matches both
+	Priority   int       `json:"priority" gorm:"not null;default:0"` // Lower runs first
+	Enabled    bool      `json:"enabled" gorm:"not null;default:true"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// TransactionAnnotation holds one user's private category and note on a
+// transaction. Both sides of a transfer share a description, so annotations are
+// kept apart from the transaction itself.
+type TransactionAnnotation struct {
+	ID             uuid.UUID  `json:"id" gorm:"type:char(36);primaryKey"`
+	UserID         uuid.UUID  `json:"user_id" gorm:"type:char(36);not null;uniqueIndex:idx_annotation_user_transaction"`
+	TransactionID  uuid.UUID  `json:"transaction_id" gorm:"type:char(36);not null;uniqueIndex:idx_annotation_user_transaction"`
+	CategoryID     *uuid.UUID `json:"category_id,omitempty" gorm:"type:char(36);index"`
+	CategorySource string     `json:"category_source,omitempty" gorm:"size:10"` // manual, rule
+	RuleID         *uuid.UUID `json:"rule_id,omitempty" gorm:"type:char(36)"`
+	Note           string     `json:"note" gorm:"type:text"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+
+	// Relationships
+	Category *Category `json:"category,omitempty" gorm:"foreignKey:CategoryID"`
+}
+
+// TransactionTag is a user's free-form label on a transaction
+type TransactionTag struct {
+	ID            uuid.UUID `json:"id" gorm:"type:char(36);primaryKey"`
+	UserID        uuid.UUID `json:"user_id" gorm:"type:char(36);not null;uniqueIndex:idx_tag_user_transaction_tag"`
+	TransactionID uuid.UUID `json:"transaction_id" gorm:"type:char(36);not null;uniqueIndex:idx_tag_user_transaction_tag"`
+	Tag           string    `json:"tag" gorm:"size:30;not null;uniqueIndex:idx_tag_user_transaction_tag;index"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// TransactionAttachment is a file, such as a receipt image, a user attached to a transaction
+type TransactionAttachment struct {
+	ID            uuid.UUID `json:"id" gorm:"type:char(36);primaryKey"`
+	UserID        uuid.UUID `json:"user_id" gorm:"type:char(36);not null;index"`
+	TransactionID uuid.UUID `json:"transaction_id" gorm:"type:char(36);not null;index"`
+	FileName      string    `json:"file_name" gorm:"size:255;not null"`
+	ContentType   string    `json:"content_type" gorm:"size:100;not null"`
+	Size          int64     `json:"size"`
+	StoragePath   string    `json:"-" gorm:"size:500;not null"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// TableName specifies the table name for Category
+func (Category) TableName() string {
+	return "categories"
+}
+
+// TableName specifies the table name for CategoryRule
+func (CategoryRule) TableName() string {
+	return "category_rules"
+}
+
+// TableName specifies the table name for TransactionAnnotation
+func (TransactionAnnotation) TableName() string {
+	return "transaction_annotations"
+}
+
+// TableName specifies the table name for TransactionTag
+func (TransactionTag) TableName() string {
+	return "transaction_tags"
+}
+
+// TableName specifies the table name for TransactionAttachment
+func (TransactionAttachment) TableName() string {
+	return "transaction_attachments"
+}
+
+// BeforeCreate will set a UUID rather than numeric ID
+func (c *Category) BeforeCreate(tx *gorm.DB) error {
+	if c.ID == uuid.Nil {
+		c.ID = uuid.New()
+	}
+	return nil
+}
+
+// BeforeCreate will set a UUID rather than numeric ID
+func (r *CategoryRule) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
+
+// BeforeCreate will set a UUID rather than numeric ID
+func (a *TransactionAnnotation) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	return nil
+}
+
+// BeforeCreate will set a UUID rather than numeric ID
+func (t *TransactionTag) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	return nil
+}
+
+// BeforeCreate will set a UUID rather than numeric ID
+func (a *TransactionAttachment) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	return nil
+}
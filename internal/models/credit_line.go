@@ -0,0 +1,99 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Credit line statuses
+const (
+	CreditLineStatusActive    = "active"    // Transfers may take the wallet below zero, down to the limit
+	CreditLineStatusSuspended = "suspended" // No new borrowing; interest is still charged on what is owed
+)
+
+// CreditLine lets a wallet's balance go negative down to an agreed limit.
+// Interest is charged monthly on each day's negative closing balance, except
+// for the first GraceDays days of every stretch spent below zero.
+type CreditLine struct {
+	ID             uuid.UUID  `json:"id" gorm:"type:char(36);primaryKey"`
+	WalletID       uuid.UUID  `json:"wallet_id" gorm:"type:char(36);not null;uniqueIndex"`
+	Limit          Money      `json:"limit" gorm:"column:credit_limit;type:decimal(15,2);not null"` // How far below zero the wallet may go
+	Currency       string     `json:"currency" gorm:"size:3;not null"`
+	AnnualRateBps  int64      `json:"annual_rate_bps" gorm:"not null"` // Charged on the negative balance, actual/365
+	GraceDays      int        `json:"grace_days" gorm:"not null;default:0"`
+	Status         string     `json:"status" gorm:"size:20;not null;default:'active';index"` // active, suspended
+	OverLimit      bool       `json:"over_limit" gorm:"not null;default:false;index"`        // Set by the monthly job
+	OverLimitSince *time.Time `json:"over_limit_since,omitempty"`
+	UpdatedBy      uuid.UUID  `json:"updated_by" gorm:"type:char(36);not null"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+
+	// Relationships
+	Wallet Wallet `json:"wallet,omitempty" gorm:"foreignKey:WalletID"`
+}
+
+// CreditCharge is the overdraft interest charged to a credit line for a month
+type CreditCharge struct {
+	ID             uuid.UUID  `json:"id" gorm:"type:char(36);primaryKey"`
+	CreditLineID   uuid.UUID  `json:"credit_line_id" gorm:"type:char(36);not null;uniqueIndex:idx_credit_charge_period"`
+	WalletID       uuid.UUID  `json:"wallet_id" gorm:"type:char(36);not null;index"`
+	PeriodStart    time.Time  `json:"period_start" gorm:"type:date;not null;uniqueIndex:idx_credit_charge_period"`
+	DaysCharged    int        `json:"days_charged" gorm:"not null"` // Days below zero past the grace period
+	Amount         Money      `json:"amount" gorm:"type:decimal(15,2);not null"`
+	Currency       string     `json:"currency" gorm:"size:3;not null"`
+	JournalEntryID *uuid.UUID `json:"journal_entry_id,omitempty" gorm:"type:char(36)"` // Nil when nothing was owed
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// TableName specifies the table name for CreditLine
+func (CreditLine) TableName() string {
+	return "credit_lines"
+}
+
+// TableName specifies the table name for CreditCharge
+func (CreditCharge) TableName() string {
+	return "credit_charges"
+}
+
+// BeforeCreate will set a UUID rather than numeric ID
+func (l *CreditLine) BeforeCreate(tx *gorm.DB) error {
+	if l.ID == uuid.Nil {
+		l.ID = uuid.New()
+	}
+	return nil
+}
+
+// BeforeCreate will set a UUID rather than numeric ID
+func (c *CreditCharge) BeforeCreate(tx *gorm.DB) error {
+	if c.ID == uuid.Nil {
+		c.ID = uuid.New()
+	}
+	return nil
+}
+
+// Available returns how much credit a wallet with the given balance can still
+// draw. A suspended line offers none.
+func (l *CreditLine) Available(balance Money) Money {
+	if l.Status != CreditLineStatusActive {
+		return ZeroMoney(l.Currency)
+	}
+	available := l.Limit
+	if balance.IsNegative() {
+		available.Amount += balance.Amount
+	}
+	return available.Max(ZeroMoney(l.Currency))
+}
+
+// AfterFind binds the limit to the line currency
+func (l *CreditLine) AfterFind(tx *gorm.DB) error {
+	l.Limit = l.Limit.bind(l.Currency)
+	return nil
+}
+
+// AfterFind binds the amount to the charge currency
+func (c *CreditCharge) AfterFind(tx *gorm.DB) error {
+	c.Amount = c.Amount.bind(c.Currency)
+	return nil
+}
@@ -0,0 +1,112 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Reversal kinds
+const (
+	ReversalKindRefund   = "refund"   // Recipient returned part or all of a transfer
+	ReversalKindReversal = "reversal" // Transfer undone, e.g. after a dispute was decided for the sender
+)
+
+// Dispute statuses
+const (
+	DisputeStatusOpen      = "open"
+	DisputeStatusReversed  = "reversed"  // Decided for the sender, the transfer was reversed
+	DisputeStatusRejected  = "rejected"  // Decided for the recipient
+	DisputeStatusWithdrawn = "withdrawn" // Closed by the sender
+)
+
+// Dispute reason codes
+const (
+	DisputeReasonUnauthorized    = "unauthorized"
+	DisputeReasonNotReceived     = "not_received"
+	DisputeReasonDuplicate       = "duplicate"
+	DisputeReasonIncorrectAmount = "incorrect_amount"
+	DisputeReasonFraud           = "fraud"
+	DisputeReasonOther           = "other"
+)
+
+// Reversal records a compensating journal entry that returned money from a
+// transfer. The original entry is never modified.
+type Reversal struct {
+	ID              uuid.UUID  `json:"id" gorm:"type:char(36);primaryKey"`
+	Kind            string     `json:"kind" gorm:"size:20;not null"` // refund, reversal
+	OriginalEntryID uuid.UUID  `json:"original_entry_id" gorm:"type:char(36);not null;index"`
+	EntryID         uuid.UUID  `json:"entry_id" gorm:"type:char(36);not null"`
+	Amount          Money      `json:"amount" gorm:"type:decimal(15,2);not null"`       // Principal returned to the sender
+	FeeRefunded     Money      `json:"fee_refunded" gorm:"type:decimal(15,2);not null"` // Transfer fee returned to the sender
+	Currency        string     `json:"currency" gorm:"size:3;not null"`
+	Reason          string     `json:"reason" gorm:"size:255"`
+	InitiatedBy     uuid.UUID  `json:"initiated_by" gorm:"type:char(36);not null"`
+	DisputeID       *uuid.UUID `json:"dispute_id,omitempty" gorm:"type:char(36)"`
+	CreatedAt       time.Time  `json:"created_at"`
+}
+
+// Dispute is a sender's claim against a transfer, decided by an admin through
+// the linked support ticket
+type Dispute struct {
+	ID              uuid.UUID  `json:"id" gorm:"type:char(36);primaryKey"`
+	TransactionID   uuid.UUID  `json:"transaction_id" gorm:"type:char(36);not null"` // Sender's side of the transfer
+	JournalEntryID  uuid.UUID  `json:"journal_entry_id" gorm:"type:char(36);not null;index"`
+	SenderID        uuid.UUID  `json:"sender_id" gorm:"type:char(36);not null;index"`
+	RecipientID     uuid.UUID  `json:"recipient_id" gorm:"type:char(36);not null"`
+	Amount          Money      `json:"amount" gorm:"type:decimal(15,2);not null"`
+	Currency        string     `json:"currency" gorm:"size:3;not null"`
+	ReasonCode      string     `json:"reason_code" gorm:"size:30;not null"`
+	Description     string     `json:"description" gorm:"type:text"`
+	Status          string     `json:"status" gorm:"size:20;not null;default:'open';index"`
+	SupportTicketID uuid.UUID  `json:"support_ticket_id" gorm:"type:char(36)"`
+	Resolution      string     `json:"resolution,omitempty" gorm:"size:255"` // Admin's note on the decision
+	ResolvedBy      *uuid.UUID `json:"resolved_by,omitempty" gorm:"type:char(36)"`
+	ResolvedAt      *time.Time `json:"resolved_at,omitempty"`
+	ReversalID      *uuid.UUID `json:"reversal_id,omitempty" gorm:"type:char(36)"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+
+	// Relationships
+	SupportTicket SupportTicket `json:"support_ticket,omitempty" gorm:"foreignKey:SupportTicketID"`
+}
+
+// TableName specifies the table name for Reversal
+func (Reversal) TableName() string {
+	return "reversals"
+}
+
+// TableName specifies the table name for Dispute
+func (Dispute) TableName() string {
+	return "disputes"
+}
+
+// BeforeCreate will set a UUID rather than numeric ID
+func (r *Reversal) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
+
+// BeforeCreate will set a UUID rather than numeric ID
+func (d *Dispute) BeforeCreate(tx *gorm.DB) error {
+	if d.ID == uuid.Nil {
+		d.ID = uuid.New()
+	}
+	return nil
+}
+
+// AfterFind binds the amounts to the reversal currency
+func (r *Reversal) AfterFind(tx *gorm.DB) error {
+	r.Amount = r.Amount.bind(r.Currency)
+	r.FeeRefunded = r.FeeRefunded.bind(r.Currency)
+	return nil
+}
+
+// AfterFind binds the amount to the dispute currency
+func (d *Dispute) AfterFind(tx *gorm.DB) error {
+	d.Amount = d.Amount.bind(d.Currency)
+	return nil
+}
@@ -0,0 +1,81 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Exchange quote statuses
+const (
+	ExchangeQuoteStatusPending  = "pending"
+	ExchangeQuoteStatusExecuted = "executed"
+)
+
+// ExchangeRate represents an admin-maintained mid-market rate for a currency pair
+type ExchangeRate struct {
+	ID                uuid.UUID  `json:"id" gorm:"type:char(36);primaryKey"`
+	BaseCurrency      string     `json:"base_currency" gorm:"size:3;not null;uniqueIndex:idx_exchange_rate_pair"`
+	QuoteCurrency     string     `json:"quote_currency" gorm:"size:3;not null;uniqueIndex:idx_exchange_rate_pair"`
+	Rate              string     `json:"rate" gorm:"type:decimal(20,10);not null"` // Units of quote currency per unit of base currency
+	SpreadBasisPoints int64      `json:"spread_basis_points" gorm:"not null;default:0"`
+	UpdatedBy         *uuid.UUID `json:"updated_by,omitempty" gorm:"type:char(36)"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+}
+
+// ExchangeQuote represents a rate locked for a user until it expires or is executed
+type ExchangeQuote struct {
+	ID                uuid.UUID  `json:"id" gorm:"type:char(36);primaryKey"`
+	UserID            uuid.UUID  `json:"user_id" gorm:"type:char(36);not null;index"`
+	FromWalletID      uuid.UUID  `json:"from_wallet_id" gorm:"type:char(36);not null"`
+	ToWalletID        uuid.UUID  `json:"to_wallet_id" gorm:"type:char(36);not null"`
+	FromCurrency      string     `json:"from_currency" gorm:"size:3;not null"`
+	ToCurrency        string     `json:"to_currency" gorm:"size:3;not null"`
+	SellAmount        Money      `json:"sell_amount" gorm:"type:decimal(15,2);not null"` // Debited from the source wallet
+	Fee               Money      `json:"fee" gorm:"type:decimal(15,2);not null"`         // Spread, in the source currency
+	BuyAmount         Money      `json:"buy_amount" gorm:"type:decimal(15,2);not null"`  // Credited to the target wallet
+	Rate              string     `json:"rate" gorm:"type:decimal(20,10);not null"`       // Mid-market rate at quote time
+	SpreadBasisPoints int64      `json:"spread_basis_points"`
+	Status            string     `json:"status" gorm:"size:20;not null;default:'pending'"` // pending, executed
+	ExpiresAt         time.Time  `json:"expires_at"`
+	ExecutedAt        *time.Time `json:"executed_at,omitempty"`
+	JournalEntryID    *uuid.UUID `json:"journal_entry_id,omitempty" gorm:"type:char(36)"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+}
+
+// TableName specifies the table name for ExchangeRate
+func (ExchangeRate) TableName() string {
+	return "exchange_rates"
+}
+
+// TableName specifies the table name for ExchangeQuote
+func (ExchangeQuote) TableName() string {
+	return "exchange_quotes"
+}
+
+// BeforeCreate will set a UUID rather than numeric ID
+func (r *ExchangeRate) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
+
+// BeforeCreate will set a UUID rather than numeric ID
+func (q *ExchangeQuote) BeforeCreate(tx *gorm.DB) error {
+	if q.ID == uuid.Nil {
+		q.ID = uuid.New()
+	}
+	return nil
+}
+
+// AfterFind binds the amounts to the currencies of both sides
+func (q *ExchangeQuote) AfterFind(tx *gorm.DB) error {
+	q.SellAmount = q.SellAmount.bind(q.FromCurrency)
+	q.Fee = q.Fee.bind(q.FromCurrency)
+	q.BuyAmount = q.BuyAmount.bind(q.ToCurrency)
+	return nil
+}
@@ -0,0 +1,113 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Transaction types fee rules are keyed by
+const (
+	FeeTypeTransfer          = "transfer"
+	FeeTypeScheduledTransfer = "scheduled_transfer"
+	FeeTypePaymentRequest    = "payment_request"
+	FeeTypeBulkPayout        = "bulk_payout"
+	FeeTypeCheckout          = "checkout" // Paid by the merchant out of the amount received
+)
+
+// FeeRule prices one kind of transaction: a percentage plus a flat amount,
+// clamped to optional minimum and maximum fees. Of the active rules matching a
+// transaction's type, currency, payer tier and amount, the one with the highest
+// priority applies. A promotional waiver is a rule with no fee, a start and end
+// date and a higher priority than the regular rule.
+type FeeRule struct {
+	ID               uuid.UUID  `json:"id" gorm:"type:char(36);primaryKey"`
+	Name             string     `json:"name" gorm:"size:100;not null"`
+	TransactionType  string     `json:"transaction_type" gorm:"size:30;not null;index:idx_fee_rule_match"`
+	Currency         string     `json:"currency" gorm:"size:3;not null;index:idx_fee_rule_match"`
+	Tier             string     `json:"tier,omitempty" gorm:"size:20;not null;default:''"` // Empty for every tier
+	MinAmount        *Money     `json:"min_amount,omitempty" gorm:"type:decimal(15,2)"`    // Band start, inclusive
+	MaxAmount        *Money     `json:"max_amount,omitempty" gorm:"type:decimal(15,2)"`    // Band end, exclusive
+	BasisPoints      int64      `json:"basis_points" gorm:"not null;default:0"`
+	FlatFee          Money      `json:"flat_fee" gorm:"type:decimal(15,2);not null;default:0"`
+	MinFee           *Money     `json:"min_fee,omitempty" gorm:"type:decimal(15,2)"`
+	MaxFee           *Money     `json:"max_fee,omitempty" gorm:"type:decimal(15,2)"`
+	FreeMonthlyQuota int        `json:"free_monthly_quota" gorm:"not null;default:0"` // Transactions per payer and calendar month charged nothing
+	Priority         int        `json:"priority" gorm:"not null;default:0"`
+	StartsAt         *time.Time `json:"starts_at,omitempty"`
+	EndsAt           *time.Time `json:"ends_at,omitempty"`
+	Active           bool       `json:"active" gorm:"not null"`
+	UpdatedBy        *uuid.UUID `json:"updated_by,omitempty" gorm:"type:char(36)"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+}
+
+// FeeCharge records a transaction priced by a fee rule, so that free monthly
+// quotas can be counted and rule usage reported
+type FeeCharge struct {
+	ID        uuid.UUID `json:"id" gorm:"type:char(36);primaryKey"`
+	RuleID    uuid.UUID `json:"rule_id" gorm:"type:char(36);not null;index:idx_fee_charge_quota,priority:1"`
+	UserID    uuid.UUID `json:"user_id" gorm:"type:char(36);not null;index:idx_fee_charge_quota,priority:2"` // Who paid the fee
+	EntryID   uuid.UUID `json:"entry_id" gorm:"type:char(36);not null"`
+	Amount    Money     `json:"amount" gorm:"type:decimal(15,2);not null"` // Zero when the free quota covered it
+	Currency  string    `json:"currency" gorm:"size:3;not null"`
+	CreatedAt time.Time `json:"created_at" gorm:"index:idx_fee_charge_quota,priority:3"`
+}
+
+// TableName specifies the table name for FeeRule
+func (FeeRule) TableName() string {
+	return "fee_rules"
+}
+
+// TableName specifies the table name for FeeCharge
+func (FeeCharge) TableName() string {
+	return "fee_charges"
+}
+
+// BeforeCreate will set a UUID rather than numeric ID
+func (r *FeeRule) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
+
+// BeforeCreate will set a UUID rather than numeric ID
+func (c *FeeCharge) BeforeCreate(tx *gorm.DB) error {
+	if c.ID == uuid.Nil {
+		c.ID = uuid.New()
+	}
+	return nil
+}
+
+// Price applies the rule's percentage and flat fee to an amount in the rule
+// currency, clamped to the rule's minimum and maximum fee
+func (r *FeeRule) Price(amount Money) Money {
+	fee := amount.Mul(r.BasisPoints, 10000, RoundHalfUp)
+	fee, _ = fee.Add(r.FlatFee)
+	if r.MinFee != nil {
+		fee = fee.Max(*r.MinFee)
+	}
+	if r.MaxFee != nil {
+		fee = fee.Min(*r.MaxFee)
+	}
+	return fee
+}
+
+// AfterFind binds the configured amounts to the rule currency
+func (r *FeeRule) AfterFind(tx *gorm.DB) error {
+	r.FlatFee = r.FlatFee.bind(r.Currency)
+	for _, amount := range []*Money{r.MinAmount, r.MaxAmount, r.MinFee, r.MaxFee} {
+		if amount != nil {
+			*amount = amount.bind(r.Currency)
+		}
+	}
+	return nil
+}
+
+// AfterFind binds the amount to the charge currency
+func (c *FeeCharge) AfterFind(tx *gorm.DB) error {
+	c.Amount = c.Amount.bind(c.Currency)
+	return nil
+}
@@ -0,0 +1,66 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Hold statuses
+const (
+	HoldStatusActive   = "active"
+	HoldStatusCaptured = "captured"
+	HoldStatusReleased = "released"
+	HoldStatusExpired  = "expired"
+)
+
+// Hold kinds
+const (
+	HoldKindWithdrawal    = "withdrawal"    // Funds of a payout until it is submitted to the rail
+	HoldKindInstruction   = "instruction"   // Funds of an organisation payment awaiting approval
+	HoldKindAuthorization = "authorization" // Merchant-style authorisation, captured later
+	HoldKindAdmin         = "admin"         // Placed by an administrator, e.g. during an investigation
+)
+
+// Hold reserves part of a wallet's balance. Active holds reduce the available
+// balance but not the ledger balance; money only moves when a hold is captured.
+// A capture may take less than the held amount, the rest is released.
+type Hold struct {
+	ID             uuid.UUID  `json:"id" gorm:"type:char(36);primaryKey"`
+	WalletID       uuid.UUID  `json:"wallet_id" gorm:"type:char(36);not null;index"`
+	Kind           string     `json:"kind" gorm:"size:20;not null"`
+	Reference      string     `json:"reference,omitempty" gorm:"size:100;index"` // ID of the payout, instruction, ... the hold belongs to
+	Description    string     `json:"description" gorm:"size:255"`
+	Amount         Money      `json:"amount" gorm:"type:decimal(15,2);not null"`
+	CapturedAmount Money      `json:"captured_amount" gorm:"type:decimal(15,2);not null;default:0"`
+	Currency       string     `json:"currency" gorm:"size:3;not null"`
+	Status         string     `json:"status" gorm:"size:20;not null;default:'active';index"` // active, captured, released, expired
+	PlacedBy       uuid.UUID  `json:"placed_by" gorm:"type:char(36);not null"`
+	JournalEntryID *uuid.UUID `json:"journal_entry_id,omitempty" gorm:"type:char(36)"` // Set once captured
+	ExpiresAt      *time.Time `json:"expires_at,omitempty" gorm:"index"`               // Holds without expiry stay until captured or released
+	CapturedAt     *time.Time `json:"captured_at,omitempty"`
+	ReleasedAt     *time.Time `json:"released_at,omitempty"` // Also set when the hold expires
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// TableName specifies the table name for Hold
+func (Hold) TableName() string {
+	return "holds"
+}
+
+// BeforeCreate will set a UUID rather than numeric ID
+func (h *Hold) BeforeCreate(tx *gorm.DB) error {
+	if h.ID == uuid.Nil {
+		h.ID = uuid.New()
+	}
+	return nil
+}
+
+// AfterFind binds the amounts to the hold currency
+func (h *Hold) AfterFind(tx *gorm.DB) error {
+	h.Amount = h.Amount.bind(h.Currency)
+	h.CapturedAmount = h.CapturedAmount.bind(h.Currency)
+	return nil
+}
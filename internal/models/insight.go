@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// InsightRollup is one day of a user's ledger transactions summed per
+// currency, direction, type and counterparty. The insights cron job rebuilds
+// completed days so that the dashboard does not scan raw transactions.
+type InsightRollup struct {
+	ID             uuid.UUID  `json:"id" gorm:"type:char(36);primaryKey"`
+	UserID         uuid.UUID  `json:"user_id" gorm:"type:char(36);not null;index:idx_insight_rollups_user_day"`
+	Day            time.Time  `json:"day" gorm:"type:date;not null;index:idx_insight_rollups_user_day;index"`
+	Currency       string     `json:"currency" gorm:"size:3;not null"`
+	Direction      string     `json:"direction" gorm:"size:3;not null"` // in, out
+	Type           string     `json:"type" gorm:"size:20;not null"`
+	CounterpartyID *uuid.UUID `json:"counterparty_id,omitempty" gorm:"type:char(36)"` // Other user of a transfer
+	Total          Money      `json:"total" gorm:"type:decimal(15,2);not null"`
+	Count          int64      `json:"count" gorm:"not null"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// TableName specifies the table name for InsightRollup
+func (InsightRollup) TableName() string {
+	return "insight_daily_rollups"
+}
+
+// BeforeCreate will set a UUID rather than numeric ID
+func (r *InsightRollup) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
+
+// AfterFind binds the total to the rollup currency
+func (r *InsightRollup) AfterFind(tx *gorm.DB) error {
+	r.Total = r.Total.bind(r.Currency)
+	return nil
+}
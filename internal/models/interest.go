@@ -0,0 +1,116 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Day-count conventions used to turn an annual rate into daily interest
+const (
+	DayCountActual365    = "actual/365"    // Every day is 1/365 of a year
+	DayCountActual360    = "actual/360"    // Every day is 1/360 of a year
+	DayCountActualActual = "actual/actual" // Every day is 1/365 or 1/366 of its year
+	DayCount30360        = "30/360"        // Every month counts as 30 days of a 360-day year
+)
+
+// Interest posting kinds
+const (
+	InterestPostingCapitalisation = "capitalisation" // Interest accrued over a month, credited after it ends
+	InterestPostingCorrection     = "correction"     // Difference found when a month is recomputed
+)
+
+// InterestRate is the annual rate paid on a wallet type and currency from a
+// date on. Rates are never edited: a new row takes over from its effective date,
+// and a backdated row corrects the past once interest is recomputed.
+type InterestRate struct {
+	ID            uuid.UUID  `json:"id" gorm:"type:char(36);primaryKey"`
+	WalletType    string     `json:"wallet_type" gorm:"size:20;not null;index:idx_interest_rate_lookup"`
+	Currency      string     `json:"currency" gorm:"size:3;not null;index:idx_interest_rate_lookup"`
+	AnnualRateBps int64      `json:"annual_rate_bps" gorm:"not null"` // 250 = 2.50% a year
+	DayCount      string     `json:"day_count" gorm:"size:20;not null"`
+	EffectiveFrom time.Time  `json:"effective_from" gorm:"type:date;not null;index:idx_interest_rate_lookup"`
+	CreatedBy     *uuid.UUID `json:"created_by,omitempty" gorm:"type:char(36)"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// InterestAccrual is the interest a wallet earned on one day's closing balance.
+// Amounts below a cent are kept in Accrued, in millionths of the currency's
+// minor unit, and only rounded when the month is capitalised.
+type InterestAccrual struct {
+	ID            uuid.UUID `json:"id" gorm:"type:char(36);primaryKey"`
+	WalletID      uuid.UUID `json:"wallet_id" gorm:"type:char(36);not null;uniqueIndex:idx_interest_accrual_day"`
+	Date          time.Time `json:"date" gorm:"type:date;not null;uniqueIndex:idx_interest_accrual_day;index"`
+	Currency      string    `json:"currency" gorm:"size:3;not null"`
+	Balance       Money     `json:"balance" gorm:"type:decimal(15,2);not null"` // End-of-day ledger balance
+	RateID        uuid.UUID `json:"rate_id" gorm:"type:char(36);not null"`
+	AnnualRateBps int64     `json:"annual_rate_bps" gorm:"not null"`
+	DayCount      string    `json:"day_count" gorm:"size:20;not null"`
+	Accrued       int64     `json:"accrued" gorm:"not null"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// InterestPosting is interest credited to (or, for a downward correction,
+// taken back from) a wallet for a month
+type InterestPosting struct {
+	ID             uuid.UUID `json:"id" gorm:"type:char(36);primaryKey"`
+	WalletID       uuid.UUID `json:"wallet_id" gorm:"type:char(36);not null;index:idx_interest_posting_period"`
+	PeriodStart    time.Time `json:"period_start" gorm:"type:date;not null;index:idx_interest_posting_period"`
+	Kind           string    `json:"kind" gorm:"size:20;not null"`
+	Amount         Money     `json:"amount" gorm:"type:decimal(15,2);not null"` // Negative for a downward correction
+	Currency       string    `json:"currency" gorm:"size:3;not null"`
+	JournalEntryID uuid.UUID `json:"journal_entry_id" gorm:"type:char(36);not null"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// TableName specifies the table name for InterestRate
+func (InterestRate) TableName() string {
+	return "interest_rates"
+}
+
+// TableName specifies the table name for InterestAccrual
+func (InterestAccrual) TableName() string {
+	return "interest_accruals"
+}
+
+// TableName specifies the table name for InterestPosting
+func (InterestPosting) TableName() string {
+	return "interest_postings"
+}
+
+// BeforeCreate will set a UUID rather than numeric ID
+func (r *InterestRate) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
+
+// BeforeCreate will set a UUID rather than numeric ID
+func (a *InterestAccrual) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	return nil
+}
+
+// BeforeCreate will set a UUID rather than numeric ID
+func (p *InterestPosting) BeforeCreate(tx *gorm.DB) error {
+	if p.ID == uuid.Nil {
+		p.ID = uuid.New()
+	}
+	return nil
+}
+
+// AfterFind binds the balance to the accrual currency
+func (a *InterestAccrual) AfterFind(tx *gorm.DB) error {
+	a.Balance = a.Balance.bind(a.Currency)
+	return nil
+}
+
+// AfterFind binds the amount to the posting currency
+func (p *InterestPosting) AfterFind(tx *gorm.DB) error {
+	p.Amount = p.Amount.bind(p.Currency)
+	return nil
+}
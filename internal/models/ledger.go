@@ -0,0 +1,103 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Ledger account types
+const (
+	LedgerAccountTypeWallet = "wallet"
+	LedgerAccountTypeSystem = "system"
+	LedgerAccountTypePocket = "pocket"
+)
+
+// LedgerAccount represents an account in the double-entry ledger.
+// Every wallet and every pocket has exactly one ledger account; system accounts
+// hold the other side of movements that leave the platform (external cash, fees).
+type LedgerAccount struct {
+	ID        uuid.UUID  `json:"id" gorm:"type:char(36);primaryKey"`
+	Code      string     `json:"code" gorm:"size:100;uniqueIndex;not null"` // wallet:<id>, pocket:<id>, system:<kind>:<currency>
+	Type      string     `json:"type" gorm:"size:20;not null"`              // wallet, pocket, system
+	WalletID  *uuid.UUID `json:"wallet_id,omitempty" gorm:"type:char(36);uniqueIndex"`
+	PocketID  *uuid.UUID `json:"pocket_id,omitempty" gorm:"type:char(36);uniqueIndex"`
+	Currency  string     `json:"currency" gorm:"size:3;not null"`
+	Name      string     `json:"name" gorm:"size:100"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// JournalEntry represents a balanced set of postings recorded as one movement
+type JournalEntry struct {
+	ID          uuid.UUID `json:"id" gorm:"type:char(36);primaryKey"`
+	Type        string    `json:"type" gorm:"size:20;not null;index"` // DEPOSIT, TRANSFER, EXCHANGE, OPENING_BALANCE
+	Description string    `json:"description" gorm:"size:255"`
+	Reference   string    `json:"reference" gorm:"size:100;index"`
+	CreatedAt   time.Time `json:"created_at"`
+
+	// Relationships
+	Postings     []Posting     `json:"postings,omitempty" gorm:"foreignKey:EntryID"`
+	Transactions []Transaction `json:"-" gorm:"foreignKey:JournalEntryID"`
+}
+
+// Posting represents a single signed movement on a ledger account.
+// A positive amount increases the account balance, a negative one decreases it.
+type Posting struct {
+	ID        uuid.UUID `json:"id" gorm:"type:char(36);primaryKey"`
+	EntryID   uuid.UUID `json:"entry_id" gorm:"type:char(36);not null;index"`
+	AccountID uuid.UUID `json:"account_id" gorm:"type:char(36);not null;index"`
+	Amount    Money     `json:"amount" gorm:"type:decimal(15,2);not null"`
+	Currency  string    `json:"currency" gorm:"size:3;not null"`
+	Memo      string    `json:"memo" gorm:"size:255"`
+	CreatedAt time.Time `json:"created_at"`
+
+	// Relationships
+	Account LedgerAccount `json:"account,omitempty" gorm:"foreignKey:AccountID"`
+}
+
+// TableName specifies the table name for LedgerAccount
+func (LedgerAccount) TableName() string {
+	return "ledger_accounts"
+}
+
+// TableName specifies the table name for JournalEntry
+func (JournalEntry) TableName() string {
+	return "journal_entries"
+}
+
+// TableName specifies the table name for Posting
+func (Posting) TableName() string {
+	return "ledger_postings"
+}
+
+// BeforeCreate will set a UUID rather than numeric ID
+func (a *LedgerAccount) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	return nil
+}
+
+// BeforeCreate will set a UUID rather than numeric ID
+func (e *JournalEntry) BeforeCreate(tx *gorm.DB) error {
+	if e.ID == uuid.Nil {
+		e.ID = uuid.New()
+	}
+	return nil
+}
+
+// BeforeCreate will set a UUID rather than numeric ID
+func (p *Posting) BeforeCreate(tx *gorm.DB) error {
+	if p.ID == uuid.Nil {
+		p.ID = uuid.New()
+	}
+	return nil
+}
+
+// AfterFind binds the amount to the posting currency
+func (p *Posting) AfterFind(tx *gorm.DB) error {
+	p.Amount = p.Amount.bind(p.Currency)
+	return nil
+}
@@ -0,0 +1,92 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// User tiers
+const (
+	UserTierStandard = "standard"
+	UserTierVerified = "verified"
+	UserTierPremium  = "premium"
+)
+
+// Transfer limit scopes, from least to most specific
+const (
+	LimitScopeGlobal = "global"
+	LimitScopeTier   = "tier"
+	LimitScopeUser   = "user"
+)
+
+// TransferLimit configures transfer caps for a currency. Unset fields are
+// inherited from the less specific scope (user -> tier -> global).
+type TransferLimit struct {
+	ID                uuid.UUID  `json:"id" gorm:"type:char(36);primaryKey"`
+	Scope             string     `json:"scope" gorm:"size:20;not null;uniqueIndex:idx_transfer_limit_scope"`   // global, tier, user
+	Subject           string     `json:"subject" gorm:"size:36;not null;uniqueIndex:idx_transfer_limit_scope"` // Tier name or user ID, empty for global
+	Currency          string     `json:"currency" gorm:"size:3;not null;uniqueIndex:idx_transfer_limit_scope"`
+	MinPerTransaction *Money     `json:"min_per_transaction" gorm:"type:decimal(15,2)"`
+	MaxPerTransaction *Money     `json:"max_per_transaction" gorm:"type:decimal(15,2)"`
+	DailyAmount       *Money     `json:"daily_amount" gorm:"type:decimal(15,2)"`
+	MonthlyAmount     *Money     `json:"monthly_amount" gorm:"type:decimal(15,2)"`
+	DailyCount        *int       `json:"daily_count"`
+	MonthlyCount      *int       `json:"monthly_count"`
+	UpdatedBy         *uuid.UUID `json:"updated_by,omitempty" gorm:"type:char(36)"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+}
+
+// LimitUsage records an outgoing movement that counts towards a user's limits
+type LimitUsage struct {
+	ID        uuid.UUID `json:"id" gorm:"type:char(36);primaryKey"`
+	UserID    uuid.UUID `json:"user_id" gorm:"type:char(36);not null;index:idx_limit_usage_window,priority:1"`
+	Currency  string    `json:"currency" gorm:"size:3;not null;index:idx_limit_usage_window,priority:2"`
+	Amount    Money     `json:"amount" gorm:"type:decimal(15,2);not null"`
+	EntryID   uuid.UUID `json:"entry_id" gorm:"type:char(36);not null"`
+	CreatedAt time.Time `json:"created_at" gorm:"index:idx_limit_usage_window,priority:3"`
+}
+
+// TableName specifies the table name for TransferLimit
+func (TransferLimit) TableName() string {
+	return "transfer_limits"
+}
+
+// TableName specifies the table name for LimitUsage
+func (LimitUsage) TableName() string {
+	return "limit_usages"
+}
+
+// BeforeCreate will set a UUID rather than numeric ID
+func (l *TransferLimit) BeforeCreate(tx *gorm.DB) error {
+	if l.ID == uuid.Nil {
+		l.ID = uuid.New()
+	}
+	return nil
+}
+
+// BeforeCreate will set a UUID rather than numeric ID
+func (u *LimitUsage) BeforeCreate(tx *gorm.DB) error {
+	if u.ID == uuid.Nil {
+		u.ID = uuid.New()
+	}
+	return nil
+}
+
+// AfterFind binds the configured amounts to the limit currency
+func (l *TransferLimit) AfterFind(tx *gorm.DB) error {
+	for _, amount := range []*Money{l.MinPerTransaction, l.MaxPerTransaction, l.DailyAmount, l.MonthlyAmount} {
+		if amount != nil {
+			*amount = amount.bind(l.Currency)
+		}
+	}
+	return nil
+}
+
+// AfterFind binds the amount to the usage currency
+func (u *LimitUsage) AfterFind(tx *gorm.DB) error {
+	u.Amount = u.Amount.bind(u.Currency)
+	return nil
+}
@@ -0,0 +1,200 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Merchant statuses
+const (
+	MerchantStatusActive    = "active"
+	MerchantStatusSuspended = "suspended"
+)
+
+// Checkout session statuses
+const (
+	CheckoutStatusOpen       = "open"       // Waiting for the customer to confirm
+	CheckoutStatusAuthorized = "authorized" // Confirmed with manual capture; the amount is on hold in the customer's wallet
+	CheckoutStatusCompleted  = "completed"  // Paid
+	CheckoutStatusCancelled  = "cancelled"
+	CheckoutStatusExpired    = "expired"
+)
+
+// Checkout capture methods
+const (
+	CaptureMethodAutomatic = "automatic" // The payment is booked when the customer confirms
+	CaptureMethodManual    = "manual"    // Confirming places a hold that the merchant captures later
+)
+
+// Webhook delivery statuses
+const (
+	WebhookStatusPending   = "pending"
+	WebhookStatusDelivered = "delivered"
+	WebhookStatusFailed    = "failed" // Gave up after the last attempt
+)
+
+// Merchant is a user's profile for accepting checkout payments into one of their wallets
+type Merchant struct {
+	ID            uuid.UUID `json:"id" gorm:"type:char(36);primaryKey"`
+	UserID        uuid.UUID `json:"user_id" gorm:"type:char(36);not null;index"`
+	WalletID      uuid.UUID `json:"wallet_id" gorm:"type:char(36);not null"` // Receives checkout payments
+	Name          string    `json:"name" gorm:"size:100;not null"`
+	Website       string    `json:"website,omitempty" gorm:"size:255"`
+	WebhookURL    string    `json:"webhook_url,omitempty" gorm:"size:255"`
+	WebhookSecret string    `json:"-" gorm:"size:100;not null"` // Signs webhook payloads
+	Status        string    `json:"status" gorm:"size:20;not null;default:'active'"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// MerchantAPIKey is a secret a merchant's server uses to call the checkout API.
+// Only a hash of the key is stored; the key itself is shown once on creation.
+type MerchantAPIKey struct {
+	ID         uuid.UUID  `json:"id" gorm:"type:char(36);primaryKey"`
+	MerchantID uuid.UUID  `json:"merchant_id" gorm:"type:char(36);not null;index"`
+	Name       string     `json:"name" gorm:"size:100"`
+	Prefix     string     `json:"prefix" gorm:"size:16;not null"` // Start of the key, to tell keys apart
+	KeyHash    string     `json:"-" gorm:"size:64;not null;uniqueIndex"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// CheckoutSession is a payment a merchant asks a wallet user to make
+type CheckoutSession struct {
+	ID                     uuid.UUID  `json:"id" gorm:"type:char(36);primaryKey"`
+	MerchantID             uuid.UUID  `json:"merchant_id" gorm:"type:char(36);not null;index"`
+	WalletID               uuid.UUID  `json:"wallet_id" gorm:"type:char(36);not null"` // Merchant wallet the payment goes to
+	Amount                 Money      `json:"amount" gorm:"type:decimal(15,2);not null"`
+	Currency               string     `json:"currency" gorm:"size:3;not null"`
+	Description            string     `json:"description" gorm:"size:255"`
+	Reference              string     `json:"reference,omitempty" gorm:"size:100;index"` // The merchant's order ID
+	ReturnURL              string     `json:"return_url" gorm:"size:500;not null"`
+	CaptureMethod          string     `json:"capture_method" gorm:"size:10;not null"` // automatic, manual
+	Status                 string     `json:"status" gorm:"size:20;not null;index"`
+	PayerID                *uuid.UUID `json:"payer_id,omitempty" gorm:"type:char(36);index"`
+	PayerWalletID          *uuid.UUID `json:"payer_wallet_id,omitempty" gorm:"type:char(36)"`
+	HoldID                 *uuid.UUID `json:"hold_id,omitempty" gorm:"type:char(36)"` // Manual capture only
+	CapturedAmount         Money      `json:"captured_amount" gorm:"type:decimal(15,2);not null;default:0"`
+	Fee                    Money      `json:"fee" gorm:"type:decimal(15,2);not null;default:0"` // Merchant fee, taken from the captured amount
+	RefundedAmount         Money      `json:"refunded_amount" gorm:"type:decimal(15,2);not null;default:0"`
+	JournalEntryID         *uuid.UUID `json:"journal_entry_id,omitempty" gorm:"type:char(36)"`
+	ExpiresAt              time.Time  `json:"expires_at" gorm:"not null"`
+	AuthorizationExpiresAt *time.Time `json:"authorization_expires_at,omitempty"`
+	CompletedAt            *time.Time `json:"completed_at,omitempty"`
+	CancelledAt            *time.Time `json:"cancelled_at,omitempty"`
+	CreatedAt              time.Time  `json:"created_at"`
+	UpdatedAt              time.Time  `json:"updated_at"`
+
+	// Relationships
+	Refunds []CheckoutRefund `json:"refunds,omitempty" gorm:"foreignKey:SessionID"`
+}
+
+// CheckoutRefund is money a merchant returned to the customer of a completed checkout
+type CheckoutRefund struct {
+	ID             uuid.UUID `json:"id" gorm:"type:char(36);primaryKey"`
+	SessionID      uuid.UUID `json:"session_id" gorm:"type:char(36);not null;index"`
+	Amount         Money     `json:"amount" gorm:"type:decimal(15,2);not null"`
+	Currency       string    `json:"currency" gorm:"size:3;not null"`
+	Reason         string    `json:"reason,omitempty" gorm:"size:255"`
+	JournalEntryID uuid.UUID `json:"journal_entry_id" gorm:"type:char(36);not null"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// WebhookDelivery is an event queued for a merchant's webhook endpoint
+type WebhookDelivery struct {
+	ID            uuid.UUID  `json:"id" gorm:"type:char(36);primaryKey"`
+	MerchantID    uuid.UUID  `json:"merchant_id" gorm:"type:char(36);not null;index"`
+	EventType     string     `json:"event_type" gorm:"size:50;not null"`
+	Payload       string     `json:"payload" gorm:"type:text;not null"`
+	Status        string     `json:"status" gorm:"size:20;not null;index:idx_webhook_due"`
+	Attempts      int        `json:"attempts" gorm:"not null;default:0"`
+	ResponseCode  int        `json:"response_code,omitempty"`
+	LastError     string     `json:"last_error,omitempty" gorm:"size:255"`
+	NextAttemptAt time.Time  `json:"next_attempt_at" gorm:"index:idx_webhook_due"`
+	DeliveredAt   *time.Time `json:"delivered_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// TableName specifies the table name for Merchant
+func (Merchant) TableName() string {
+	return "merchants"
+}
+
+// TableName specifies the table name for MerchantAPIKey
+func (MerchantAPIKey) TableName() string {
+	return "merchant_api_keys"
+}
+
+// TableName specifies the table name for CheckoutSession
+func (CheckoutSession) TableName() string {
+	return "checkout_sessions"
+}
+
+// TableName specifies the table name for CheckoutRefund
+func (CheckoutRefund) TableName() string {
+	return "checkout_refunds"
+}
+
+// TableName specifies the table name for WebhookDelivery
+func (WebhookDelivery) TableName() string {
+	return "webhook_deliveries"
+}
+
+// BeforeCreate will set a UUID rather than numeric ID
+func (m *Merchant) BeforeCreate(tx *gorm.DB) error {
+	if m.ID == uuid.Nil {
+		m.ID = uuid.New()
+	}
+	return nil
+}
+
+// BeforeCreate will set a UUID rather than numeric ID
+func (k *MerchantAPIKey) BeforeCreate(tx *gorm.DB) error {
+	if k.ID == uuid.Nil {
+		k.ID = uuid.New()
+	}
+	return nil
+}
+
+// BeforeCreate will set a UUID rather than numeric ID
+func (s *CheckoutSession) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	return nil
+}
+
+// BeforeCreate will set a UUID rather than numeric ID
+func (r *CheckoutRefund) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
+
+// BeforeCreate will set a UUID rather than numeric ID
+func (d *WebhookDelivery) BeforeCreate(tx *gorm.DB) error {
+	if d.ID == uuid.Nil {
+		d.ID = uuid.New()
+	}
+	return nil
+}
+
+// AfterFind binds the amounts to the session currency
+func (s *CheckoutSession) AfterFind(tx *gorm.DB) error {
+	s.Amount = s.Amount.bind(s.Currency)
+	s.CapturedAmount = s.CapturedAmount.bind(s.Currency)
+	s.Fee = s.Fee.bind(s.Currency)
+	s.RefundedAmount = s.RefundedAmount.bind(s.Currency)
+	return nil
+}
+
+// AfterFind binds the amount to the refund currency
+func (r *CheckoutRefund) AfterFind(tx *gorm.DB) error {
+	r.Amount = r.Amount.bind(r.Currency)
+	return nil
+}
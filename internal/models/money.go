@@ -0,0 +1,437 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// RoundingMode controls how amounts are rounded to a currency's minor unit
+type RoundingMode int
+
+const (
+	RoundHalfUp   RoundingMode = iota // Round half away from zero (commercial rounding)
+	RoundHalfEven                     // Round half to even (banker's rounding)
+	RoundDown                         // Truncate toward zero
+	RoundUp                           // Round away from zero
+)
+
+// storageScale is the number of decimals of the decimal(15,2) money columns.
+// Amounts without a currency are kept at this scale.
+const storageScale = 2
+
+// currencyExponents lists the supported ISO 4217 currencies and their minor unit exponent
+var currencyExponents = map[string]int{
+	"USD": 2,
+	"EUR": 2,
+	"GBP": 2,
+	"JPY": 0,
+	"CAD": 2,
+	"AUD": 2,
+	"CHF": 2,
+	"CNY": 2,
+}
+
+// Money is an exact monetary amount in integer minor units of an ISO 4217 currency.
+// Values read from the database or decoded from JSON carry no currency until
+// they are bound with In; unbound values are held at the column scale.
+type Money struct {
+	Amount   int64  // Minor units (e.g. cents)
+	Currency string // ISO 4217 code, empty while unbound
+}
+
+// IsSupportedCurrency reports whether a currency code can be used for money
+func IsSupportedCurrency(currency string) bool {
+	_, ok := currencyExponents[currency]
+	return ok
+}
+
+// currencyExponent returns the minor unit exponent of a currency
+func currencyExponent(currency string) int {
+	if exp, ok := currencyExponents[currency]; ok {
+		return exp
+	}
+	return storageScale
+}
+
+// NewMoney creates an amount from minor units
+func NewMoney(amount int64, currency string) Money {
+	return Money{Amount: amount, Currency: currency}
+}
+
+// ZeroMoney returns a zero amount in the given currency
+func ZeroMoney(currency string) Money {
+	return Money{Currency: currency}
+}
+
+// ParseMoney parses a decimal string such as "12.34" exactly.
+// Digits beyond the currency's minor unit are rejected unless they are zero.
+func ParseMoney(value, currency string) (Money, error) {
+	if currency != "" && !IsSupportedCurrency(currency) {
+		return Money{}, fmt.Errorf("unsupported currency %q", currency)
+	}
+	amount, err := parseMinorUnits(value, currencyExponent(currency))
+	if err != nil {
+		return Money{}, err
+	}
+	return Money{Amount: amount, Currency: currency}, nil
+}
+
+// MustParseMoney is like ParseMoney but panics on error; intended for constants
+func MustParseMoney(value, currency string) Money {
+	m, err := ParseMoney(value, currency)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// parseMinorUnits converts a plain decimal string into integer units at the given exponent
+func parseMinorUnits(value string, exp int) (int64, error) {
+	s := strings.TrimSpace(value)
+	negative := false
+	if strings.HasPrefix(s, "-") {
+		negative = true
+		s = s[1:]
+	} else if strings.HasPrefix(s, "+") {
+		s = s[1:]
+	}
+
+	intPart, fracPart, _ := strings.Cut(s, ".")
+	if intPart == "" && fracPart == "" {
+		return 0, fmt.Errorf("invalid amount %q", value)
+	}
+	for _, r := range intPart + fracPart {
+		if r < '0' || r > '9' {
+			return 0, fmt.Errorf("invalid amount %q", value)
+		}
+	}
+
+	if len(fracPart) > exp {
+		if strings.Trim(fracPart[exp:], "0") != "" {
+			return 0, fmt.Errorf("amount %q has more than %d decimal places", value, exp)
+		}
+		fracPart = fracPart[:exp]
+	}
+	fracPart += strings.Repeat("0", exp-len(fracPart))
+
+	digits := strings.TrimLeft(intPart+fracPart, "0")
+	if digits == "" {
+		return 0, nil
+	}
+	amount, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("amount %q is out of range", value)
+	}
+	if negative {
+		amount = -amount
+	}
+	return amount, nil
+}
+
+// In binds an amount to a currency, rescaling unbound values to its minor unit.
+// It fails if the amount cannot be represented exactly in that currency.
+func (m Money) In(currency string) (Money, error) {
+	if !IsSupportedCurrency(currency) {
+		return Money{}, fmt.Errorf("unsupported currency %q", currency)
+	}
+	if m.Currency == currency {
+		return m, nil
+	}
+	if m.Currency != "" {
+		return Money{}, fmt.Errorf("cannot convert %s to %s without an exchange rate", m.Currency, currency)
+	}
+
+	from, to := storageScale, currencyExponent(currency)
+	rounded := rescale(m.Amount, from, to, RoundDown)
+	if rescale(rounded, to, from, RoundDown) != m.Amount {
+		return Money{}, fmt.Errorf("amount %s has more than %d decimal places for %s", m.Format(), to, currency)
+	}
+	return Money{Amount: rounded, Currency: currency}, nil
+}
+
+// RoundTo binds an amount to a currency like In, but rounds to the currency's
+// minor unit instead of failing when the value has too many decimals
+func (m Money) RoundTo(currency string, mode RoundingMode) (Money, error) {
+	if !IsSupportedCurrency(currency) {
+		return Money{}, fmt.Errorf("unsupported currency %q", currency)
+	}
+	if m.Currency == currency {
+		return m, nil
+	}
+	if m.Currency != "" {
+		return Money{}, fmt.Errorf("cannot convert %s to %s without an exchange rate", m.Currency, currency)
+	}
+	return Money{
+		Amount:   rescale(m.Amount, storageScale, currencyExponent(currency), mode),
+		Currency: currency,
+	}, nil
+}
+
+// bind attaches a currency to a value read from storage, rounding if required
+func (m Money) bind(currency string) Money {
+	if m.Currency != "" || !IsSupportedCurrency(currency) {
+		return m
+	}
+	return Money{
+		Amount:   rescale(m.Amount, storageScale, currencyExponent(currency), RoundHalfEven),
+		Currency: currency,
+	}
+}
+
+// rescale converts units between two decimal exponents
+func rescale(amount int64, from, to int, mode RoundingMode) int64 {
+	if to >= from {
+		for i := from; i < to; i++ {
+			amount *= 10
+		}
+		return amount
+	}
+	divisor := int64(1)
+	for i := to; i < from; i++ {
+		divisor *= 10
+	}
+	return divRound(big.NewInt(amount), big.NewInt(divisor), mode)
+}
+
+// divRound divides n by a positive d using the given rounding mode
+func divRound(n, d *big.Int, mode RoundingMode) int64 {
+	q, r := new(big.Int).QuoRem(n, d, new(big.Int))
+	if r.Sign() == 0 {
+		return q.Int64()
+	}
+
+	away := new(big.Int).Set(q)
+	if n.Sign() < 0 {
+		away.Sub(away, big.NewInt(1))
+	} else {
+		away.Add(away, big.NewInt(1))
+	}
+
+	switch mode {
+	case RoundDown:
+		return q.Int64()
+	case RoundUp:
+		return away.Int64()
+	}
+
+	// Compare twice the remainder with the divisor to find the nearest value
+	twice := new(big.Int).Abs(r)
+	twice.Mul(twice, big.NewInt(2))
+	switch twice.Cmp(d) {
+	case -1:
+		return q.Int64()
+	case 1:
+		return away.Int64()
+	}
+	if mode == RoundHalfEven && q.Bit(0) == 0 {
+		return q.Int64()
+	}
+	return away.Int64()
+}
+
+// sameCurrency fails when two amounts are in different currencies
+func (m Money) sameCurrency(o Money) error {
+	if m.Currency != o.Currency {
+		return fmt.Errorf("currency mismatch: %s vs %s", m.Currency, o.Currency)
+	}
+	return nil
+}
+
+// Add returns m + o
+func (m Money) Add(o Money) (Money, error) {
+	if err := m.sameCurrency(o); err != nil {
+		return Money{}, err
+	}
+	return Money{Amount: m.Amount + o.Amount, Currency: m.Currency}, nil
+}
+
+// Sub returns m - o
+func (m Money) Sub(o Money) (Money, error) {
+	if err := m.sameCurrency(o); err != nil {
+		return Money{}, err
+	}
+	return Money{Amount: m.Amount - o.Amount, Currency: m.Currency}, nil
+}
+
+// Cmp compares two amounts and returns -1, 0 or +1
+func (m Money) Cmp(o Money) (int, error) {
+	if err := m.sameCurrency(o); err != nil {
+		return 0, err
+	}
+	switch {
+	case m.Amount < o.Amount:
+		return -1, nil
+	case m.Amount > o.Amount:
+		return 1, nil
+	}
+	return 0, nil
+}
+
+// Mul returns m multiplied by num/den, rounded to the minor unit
+func (m Money) Mul(num, den int64, mode RoundingMode) Money {
+	n := new(big.Int).Mul(big.NewInt(m.Amount), big.NewInt(num))
+	d := big.NewInt(den)
+	if d.Sign() < 0 {
+		n.Neg(n)
+		d.Neg(d)
+	}
+	return Money{Amount: divRound(n, d, mode), Currency: m.Currency}
+}
+
+// Convert returns m in another currency at the given rate (units of the target
+// currency per unit of m's currency), rounded to the target minor unit
+func (m Money) Convert(rate *big.Rat, currency string, mode RoundingMode) Money {
+	n := new(big.Int).Mul(big.NewInt(m.Amount), rate.Num())
+	d := new(big.Int).Set(rate.Denom())
+
+	// Shift between the minor units of both currencies
+	from, to := currencyExponent(m.Currency), currencyExponent(currency)
+	for i := from; i < to; i++ {
+		n.Mul(n, big.NewInt(10))
+	}
+	for i := to; i < from; i++ {
+		d.Mul(d, big.NewInt(10))
+	}
+	return Money{Amount: divRound(n, d, mode), Currency: currency}
+}
+
+// Neg returns -m
+func (m Money) Neg() Money {
+	return Money{Amount: -m.Amount, Currency: m.Currency}
+}
+
+// Abs returns the absolute value of m
+func (m Money) Abs() Money {
+	if m.Amount < 0 {
+		return m.Neg()
+	}
+	return m
+}
+
+// Min returns the smaller of two amounts in the same currency. Comparing
+// different currencies is a programming error and panics.
+func (m Money) Min(o Money) Money {
+	if err := m.sameCurrency(o); err != nil {
+		panic(err)
+	}
+	if o.Amount < m.Amount {
+		return o
+	}
+	return m
+}
+
+// Max returns the larger of two amounts in the same currency. Comparing
+// different currencies is a programming error and panics.
+func (m Money) Max(o Money) Money {
+	if err := m.sameCurrency(o); err != nil {
+		panic(err)
+	}
+	if o.Amount > m.Amount {
+		return o
+	}
+	return m
+}
+
+// IsZero reports whether the amount is zero
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+// IsPositive reports whether the amount is greater than zero
+func (m Money) IsPositive() bool {
+	return m.Amount > 0
+}
+
+// IsNegative reports whether the amount is less than zero
+func (m Money) IsNegative() bool {
+	return m.Amount < 0
+}
+
+// Format returns the amount as a plain decimal string, e.g. "-12.34"
+func (m Money) Format() string {
+	exp := currencyExponent(m.Currency)
+	digits := strconv.FormatInt(m.Amount, 10)
+	sign := ""
+	if strings.HasPrefix(digits, "-") {
+		sign, digits = "-", digits[1:]
+	}
+	if exp == 0 {
+		return sign + digits
+	}
+	if len(digits) <= exp {
+		digits = strings.Repeat("0", exp-len(digits)+1) + digits
+	}
+	return sign + digits[:len(digits)-exp] + "." + digits[len(digits)-exp:]
+}
+
+// String returns the amount with its currency, e.g. "12.34 USD"
+func (m Money) String() string {
+	if m.Currency == "" {
+		return m.Format()
+	}
+	return m.Format() + " " + m.Currency
+}
+
+// Value implements driver.Valuer by writing the exact decimal
+func (m Money) Value() (driver.Value, error) {
+	return m.Format(), nil
+}
+
+// Scan implements sql.Scanner for decimal columns
+func (m *Money) Scan(src interface{}) error {
+	var text string
+	switch v := src.(type) {
+	case nil:
+		text = "0"
+	case []byte:
+		text = string(v)
+	case string:
+		text = v
+	case int64:
+		text = strconv.FormatInt(v, 10)
+	case float64:
+		text = strconv.FormatFloat(v, 'f', storageScale, 64)
+	default:
+		return fmt.Errorf("cannot scan %T into Money", src)
+	}
+
+	// Columns hold two decimals; keep the value at that scale until bound
+	amount, err := parseMinorUnits(text, storageScale)
+	if err != nil {
+		return err
+	}
+	currency := m.Currency
+	*m = Money{Amount: amount}
+	if currency != "" {
+		*m = m.bind(currency)
+	}
+	return nil
+}
+
+// MarshalJSON encodes the amount as an exact JSON number
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.Format()), nil
+}
+
+// UnmarshalJSON accepts a JSON number or string; the result is unbound
+func (m *Money) UnmarshalJSON(data []byte) error {
+	text := string(data)
+	if text == "null" {
+		return nil
+	}
+	if strings.HasPrefix(text, `"`) {
+		if err := json.Unmarshal(data, &text); err != nil {
+			return err
+		}
+	}
+	amount, err := parseMinorUnits(text, storageScale)
+	if err != nil {
+		return err
+	}
+	*m = Money{Amount: amount}
+	return nil
+}
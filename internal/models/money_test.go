@@ -0,0 +1,225 @@
+package models
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+)
+
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		value    string
+		currency string
+		want     int64
+		wantErr  bool
+	}{
+		{"12.34", "USD", 1234, false},
+		{"12", "USD", 1200, false},
+		{"0.5", "USD", 50, false},
+		{".5", "USD", 50, false},
+		{"+7.00", "EUR", 700, false},
+		{"-3.21", "USD", -321, false},
+		{" 1.10 ", "USD", 110, false},
+		{"1.230", "USD", 123, false}, // Trailing zeros beyond the minor unit are exact
+		{"1500", "JPY", 1500, false},
+		{"1500.0", "JPY", 1500, false},
+		{"0", "USD", 0, false},
+		{"1.234", "USD", 0, true}, // More decimals than the currency has
+		{"1.5", "JPY", 0, true},
+		{"1e3", "USD", 0, true},
+		{"12,34", "USD", 0, true},
+		{"", "USD", 0, true},
+		{"-", "USD", 0, true},
+		{"1.2.3", "USD", 0, true},
+		{"99999999999999999999", "USD", 0, true},
+		{"1.00", "XYZ", 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseMoney(tt.value, tt.currency)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseMoney(%q, %q) = %v, want error", tt.value, tt.currency, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseMoney(%q, %q) failed: %v", tt.value, tt.currency, err)
+			continue
+		}
+		if got.Amount != tt.want || got.Currency != tt.currency {
+			t.Errorf("ParseMoney(%q, %q) = %d %s, want %d %s", tt.value, tt.currency, got.Amount, got.Currency, tt.want, tt.currency)
+		}
+	}
+}
+
+func TestMoneyFormat(t *testing.T) {
+	tests := []struct {
+		money Money
+		want  string
+	}{
+		{NewMoney(1234, "USD"), "12.34"},
+		{NewMoney(5, "USD"), "0.05"},
+		{NewMoney(-5, "EUR"), "-0.05"},
+		{NewMoney(-1200, "USD"), "-12.00"},
+		{NewMoney(1500, "JPY"), "1500"},
+		{ZeroMoney("USD"), "0.00"},
+		{Money{Amount: 1234}, "12.34"}, // Unbound values keep the column scale
+	}
+
+	for _, tt := range tests {
+		if got := tt.money.Format(); got != tt.want {
+			t.Errorf("Format(%d %s) = %q, want %q", tt.money.Amount, tt.money.Currency, got, tt.want)
+		}
+	}
+}
+
+func TestMoneyIn(t *testing.T) {
+	// Unbound values are rescaled exactly or rejected
+	got, err := Money{Amount: 150000}.In("JPY")
+	if err != nil || got.Amount != 1500 || got.Currency != "JPY" {
+		t.Errorf("In(JPY) = %v, %v; want 1500 JPY", got, err)
+	}
+	if _, err := (Money{Amount: 150050}).In("JPY"); err == nil {
+		t.Error("In(JPY) accepted an amount with cents")
+	}
+	if _, err := NewMoney(100, "USD").In("EUR"); err == nil {
+		t.Error("In(EUR) converted USD without an exchange rate")
+	}
+	if _, err := (Money{Amount: 100}).In("XYZ"); err == nil {
+		t.Error("In accepted an unsupported currency")
+	}
+}
+
+func TestMoneyRoundTo(t *testing.T) {
+	tests := []struct {
+		amount int64 // Unbound, at the column scale
+		mode   RoundingMode
+		want   int64 // JPY
+	}{
+		{150, RoundHalfUp, 2},
+		{250, RoundHalfUp, 3},
+		{-150, RoundHalfUp, -2},
+		{150, RoundHalfEven, 2},
+		{250, RoundHalfEven, 2},
+		{-250, RoundHalfEven, -2},
+		{251, RoundHalfEven, 3},
+		{199, RoundDown, 1},
+		{-199, RoundDown, -1},
+		{101, RoundUp, 2},
+		{-101, RoundUp, -2},
+		{100, RoundUp, 1},
+	}
+
+	for _, tt := range tests {
+		got, err := Money{Amount: tt.amount}.RoundTo("JPY", tt.mode)
+		if err != nil {
+			t.Errorf("RoundTo(%d, %d) failed: %v", tt.amount, tt.mode, err)
+			continue
+		}
+		if got.Amount != tt.want {
+			t.Errorf("RoundTo(%d, %d) = %d, want %d", tt.amount, tt.mode, got.Amount, tt.want)
+		}
+	}
+}
+
+func TestMoneyMulAndConvert(t *testing.T) {
+	// 1.5% of 10.05 USD is 0.15075
+	fee := NewMoney(1005, "USD").Mul(150, 10000, RoundHalfUp)
+	if fee.Amount != 15 {
+		t.Errorf("Mul half up = %d, want 15", fee.Amount)
+	}
+	if fee := NewMoney(1005, "USD").Mul(150, 10000, RoundUp); fee.Amount != 16 {
+		t.Errorf("Mul up = %d, want 16", fee.Amount)
+	}
+
+	rate, _ := new(big.Rat).SetString("151.235")
+	yen := NewMoney(1000, "USD").Convert(rate, "JPY", RoundHalfEven)
+	if yen.Amount != 1512 || yen.Currency != "JPY" {
+		t.Errorf("Convert(10 USD) = %v, want 1512 JPY", yen)
+	}
+	rate, _ = new(big.Rat).SetString("0.0066")
+	dollars := NewMoney(1500, "JPY").Convert(rate, "USD", RoundHalfEven)
+	if dollars.Amount != 990 {
+		t.Errorf("Convert(1500 JPY) = %v, want 9.90 USD", dollars)
+	}
+}
+
+func TestMoneyArithmeticChecksCurrency(t *testing.T) {
+	usd, eur := NewMoney(100, "USD"), NewMoney(100, "EUR")
+	if _, err := usd.Add(eur); err == nil {
+		t.Error("Add mixed currencies")
+	}
+	if _, err := usd.Sub(eur); err == nil {
+		t.Error("Sub mixed currencies")
+	}
+	if _, err := usd.Cmp(eur); err == nil {
+		t.Error("Cmp mixed currencies")
+	}
+
+	for name, compare := range map[string]func(){
+		"Min": func() { usd.Min(eur) },
+		"Max": func() { usd.Max(eur) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s mixed currencies without panicking", name)
+				}
+			}()
+			compare()
+		}()
+	}
+	if got := usd.Min(NewMoney(50, "USD")); got.Amount != 50 {
+		t.Errorf("Min = %v, want 0.50 USD", got)
+	}
+	if got := usd.Max(NewMoney(50, "USD")); got.Amount != 100 {
+		t.Errorf("Max = %v, want 1.00 USD", got)
+	}
+
+	sum, err := usd.Add(NewMoney(-250, "USD"))
+	if err != nil || sum.Amount != -150 {
+		t.Errorf("Add = %v, %v; want -1.50 USD", sum, err)
+	}
+}
+
+func TestMoneyScanKeepsCurrency(t *testing.T) {
+	tests := []interface{}{[]byte("15.00"), "15.00", float64(15), int64(15)}
+	for _, src := range tests {
+		money := ZeroMoney("JPY")
+		if err := money.Scan(src); err != nil {
+			t.Errorf("Scan(%#v) failed: %v", src, err)
+			continue
+		}
+		if money.Amount != 15 || money.Currency != "JPY" {
+			t.Errorf("Scan(%#v) = %d %s, want 15 JPY", src, money.Amount, money.Currency)
+		}
+	}
+
+	var unbound Money
+	if err := unbound.Scan(nil); err != nil || unbound.Amount != 0 {
+		t.Errorf("Scan(nil) = %v, %v", unbound, err)
+	}
+}
+
+func TestMoneyJSON(t *testing.T) {
+	data, err := json.Marshal(NewMoney(-1234, "USD"))
+	if err != nil || string(data) != "-12.34" {
+		t.Errorf("Marshal = %s, %v; want -12.34", data, err)
+	}
+
+	for _, input := range []string{`12.34`, `"12.34"`} {
+		var money Money
+		if err := json.Unmarshal([]byte(input), &money); err != nil {
+			t.Errorf("Unmarshal(%s) failed: %v", input, err)
+			continue
+		}
+		if money.Amount != 1234 || money.Currency != "" {
+			t.Errorf("Unmarshal(%s) = %d %q, want unbound 1234", input, money.Amount, money.Currency)
+		}
+	}
+	var money Money
+	if err := json.Unmarshal([]byte(`12.345`), &money); err == nil {
+		t.Error("Unmarshal accepted three decimals")
+	}
+}
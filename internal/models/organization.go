@@ -0,0 +1,189 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Organisation member roles
+const (
+	OrgRoleAdmin    = "admin"    // Manages members, policy and wallets; can initiate and approve payments
+	OrgRoleApprover = "approver" // Approves or rejects payments initiated by others
+	OrgRoleMaker    = "maker"    // Initiates payments from the organisation's wallets
+	OrgRoleViewer   = "viewer"   // Sees the wallets and their payments only
+)
+
+// Payment instruction statuses
+const (
+	InstructionStatusPending  = "pending"  // Waiting for approvals
+	InstructionStatusExecuted = "executed" // Approved and booked in the ledger
+	InstructionStatusRejected = "rejected" // Rejected by an approver or withdrawn by its maker
+	InstructionStatusFailed   = "failed"   // Approved, but the transfer could not be booked
+)
+
+// Instruction decisions
+const (
+	InstructionDecisionApprove = "approve"
+	InstructionDecisionReject  = "reject"
+)
+
+// Organization is a company account whose wallets are shared by its members.
+// Payments out of its wallets need approvals according to its policy.
+type Organization struct {
+	ID               uuid.UUID `json:"id" gorm:"type:char(36);primaryKey"`
+	Name             string    `json:"name" gorm:"size:100;not null"`
+	DefaultApprovals int       `json:"default_approvals" gorm:"not null;default:1"` // Approvals needed when no rule matches
+	CreatedBy        uuid.UUID `json:"created_by" gorm:"type:char(36);not null"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+
+	// Relationships
+	Members       []OrganizationMember `json:"members,omitempty" gorm:"foreignKey:OrganizationID"`
+	ApprovalRules []ApprovalRule       `json:"approval_rules,omitempty" gorm:"foreignKey:OrganizationID"`
+}
+
+// OrganizationMember gives a user a role in an organisation
+type OrganizationMember struct {
+	ID             uuid.UUID  `json:"id" gorm:"type:char(36);primaryKey"`
+	OrganizationID uuid.UUID  `json:"organization_id" gorm:"type:char(36);not null;uniqueIndex:idx_org_member"`
+	UserID         uuid.UUID  `json:"user_id" gorm:"type:char(36);not null;uniqueIndex:idx_org_member;index"`
+	Role           string     `json:"role" gorm:"size:10;not null"` // admin, approver, maker, viewer
+	AddedBy        *uuid.UUID `json:"added_by,omitempty" gorm:"type:char(36)"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+
+	// Relationships
+	User User `json:"user,omitempty" gorm:"foreignKey:UserID"`
+}
+
+// ApprovalRule requires a number of approvals for payments above a threshold,
+// e.g. two approvers for anything over 500.00 USD. The rule with the highest
+// threshold below the amount applies.
+type ApprovalRule struct {
+	ID                uuid.UUID `json:"id" gorm:"type:char(36);primaryKey"`
+	OrganizationID    uuid.UUID `json:"organization_id" gorm:"type:char(36);not null;index"`
+	Currency          string    `json:"currency" gorm:"size:3;not null"`
+	Threshold         Money     `json:"threshold" gorm:"type:decimal(15,2);not null"`
+	RequiredApprovals int       `json:"required_approvals" gorm:"not null"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+// PaymentInstruction is a transfer out of an organisation wallet that waits for
+// approval. It becomes a ledger movement only once enough approvers agree.
+type PaymentInstruction struct {
+	ID                uuid.UUID  `json:"id" gorm:"type:char(36);primaryKey"`
+	OrganizationID    uuid.UUID  `json:"organization_id" gorm:"type:char(36);not null;index:idx_instruction_org_status"`
+	WalletID          uuid.UUID  `json:"wallet_id" gorm:"type:char(36);not null;index"`
+	RecipientUserID   uuid.UUID  `json:"recipient_user_id" gorm:"type:char(36);not null"`
+	RecipientWalletID uuid.UUID  `json:"recipient_wallet_id" gorm:"type:char(36);not null"`
+	Amount            Money      `json:"amount" gorm:"type:decimal(15,2);not null"`
+	Currency          string     `json:"currency" gorm:"size:3;not null"`
+	Description       string     `json:"description" gorm:"size:255"`
+	Status            string     `json:"status" gorm:"size:20;not null;default:'pending';index:idx_instruction_org_status"`
+	RequiredApprovals int        `json:"required_approvals" gorm:"not null"`
+	Approvals         int        `json:"approvals" gorm:"not null;default:0"`
+	CreatedBy         uuid.UUID  `json:"created_by" gorm:"type:char(36);not null"` // The maker; never one of the approvers
+	HoldID            *uuid.UUID `json:"hold_id,omitempty" gorm:"type:char(36)"`   // Reserves the amount and fee while the instruction is pending
+	JournalEntryID    *uuid.UUID `json:"journal_entry_id,omitempty" gorm:"type:char(36)"`
+	LastError         string     `json:"last_error,omitempty" gorm:"size:255"`
+	DecidedAt         *time.Time `json:"decided_at,omitempty"`
+	ExecutedAt        *time.Time `json:"executed_at,omitempty"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+
+	// Relationships
+	Decisions []InstructionDecision `json:"decisions,omitempty" gorm:"foreignKey:InstructionID"`
+}
+
+// InstructionDecision records one member approving or rejecting an instruction
+type InstructionDecision struct {
+	ID            uuid.UUID `json:"id" gorm:"type:char(36);primaryKey"`
+	InstructionID uuid.UUID `json:"instruction_id" gorm:"type:char(36);not null;uniqueIndex:idx_instruction_decision"`
+	UserID        uuid.UUID `json:"user_id" gorm:"type:char(36);not null;uniqueIndex:idx_instruction_decision"`
+	Decision      string    `json:"decision" gorm:"size:10;not null"` // approve, reject
+	Comment       string    `json:"comment,omitempty" gorm:"size:255"`
+	CreatedAt     time.Time `json:"created_at"`
+
+	// Relationships
+	User User `json:"user,omitempty" gorm:"foreignKey:UserID"`
+}
+
+// TableName specifies the table name for Organization
+func (Organization) TableName() string {
+	return "organizations"
+}
+
+// TableName specifies the table name for OrganizationMember
+func (OrganizationMember) TableName() string {
+	return "organization_members"
+}
+
+// TableName specifies the table name for ApprovalRule
+func (ApprovalRule) TableName() string {
+	return "approval_rules"
+}
+
+// TableName specifies the table name for PaymentInstruction
+func (PaymentInstruction) TableName() string {
+	return "payment_instructions"
+}
+
+// TableName specifies the table name for InstructionDecision
+func (InstructionDecision) TableName() string {
+	return "instruction_decisions"
+}
+
+// BeforeCreate will set a UUID rather than numeric ID
+func (o *Organization) BeforeCreate(tx *gorm.DB) error {
+	if o.ID == uuid.Nil {
+		o.ID = uuid.New()
+	}
+	return nil
+}
+
+// BeforeCreate will set a UUID rather than numeric ID
+func (m *OrganizationMember) BeforeCreate(tx *gorm.DB) error {
+	if m.ID == uuid.Nil {
+		m.ID = uuid.New()
+	}
+	return nil
+}
+
+// BeforeCreate will set a UUID rather than numeric ID
+func (r *ApprovalRule) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
+
+// BeforeCreate will set a UUID rather than numeric ID
+func (p *PaymentInstruction) BeforeCreate(tx *gorm.DB) error {
+	if p.ID == uuid.Nil {
+		p.ID = uuid.New()
+	}
+	return nil
+}
+
+// BeforeCreate will set a UUID rather than numeric ID
+func (d *InstructionDecision) BeforeCreate(tx *gorm.DB) error {
+	if d.ID == uuid.Nil {
+		d.ID = uuid.New()
+	}
+	return nil
+}
+
+// AfterFind binds the threshold to the rule currency
+func (r *ApprovalRule) AfterFind(tx *gorm.DB) error {
+	r.Threshold = r.Threshold.bind(r.Currency)
+	return nil
+}
+
+// AfterFind binds the amount to the instruction currency
+func (p *PaymentInstruction) AfterFind(tx *gorm.DB) error {
+	p.Amount = p.Amount.bind(p.Currency)
+	return nil
+}
@@ -0,0 +1,67 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Payment request kinds
+const (
+	PaymentRequestKindRequest = "request" // Addressed to one payer
+	PaymentRequestKindLink    = "link"    // Shareable signed link anyone logged in can pay
+)
+
+// Payment request statuses
+const (
+	PaymentRequestStatusPending   = "pending"
+	PaymentRequestStatusPaid      = "paid"
+	PaymentRequestStatusDeclined  = "declined"
+	PaymentRequestStatusCancelled = "cancelled"
+	PaymentRequestStatusExpired   = "expired"
+)
+
+// PaymentRequest asks for money to be paid into the requester's wallet, either
+// by a specific user or by whoever opens a payment link
+type PaymentRequest struct {
+	ID                uuid.UUID  `json:"id" gorm:"type:char(36);primaryKey"`
+	Kind              string     `json:"kind" gorm:"size:20;not null"` // request, link
+	RequesterID       uuid.UUID  `json:"requester_id" gorm:"type:char(36);not null;index"`
+	RequesterWalletID uuid.UUID  `json:"requester_wallet_id" gorm:"type:char(36);not null"`
+	PayerID           *uuid.UUID `json:"payer_id,omitempty" gorm:"type:char(36);index"` // Set for requests, and for links once paid
+	Amount            Money      `json:"amount" gorm:"type:decimal(15,2);not null"`
+	Currency          string     `json:"currency" gorm:"size:3;not null"`
+	Memo              string     `json:"memo" gorm:"size:255"`
+	Status            string     `json:"status" gorm:"size:20;not null;default:'pending';index"`
+	ExpiresAt         time.Time  `json:"expires_at"`
+	PaidAt            *time.Time `json:"paid_at,omitempty"`
+	DeclinedAt        *time.Time `json:"declined_at,omitempty"`
+	CancelledAt       *time.Time `json:"cancelled_at,omitempty"`
+	JournalEntryID    *uuid.UUID `json:"journal_entry_id,omitempty" gorm:"type:char(36)"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+
+	// Filled in from the users table so that parties see usernames rather than IDs
+	RequesterUsername string `json:"requester_username" gorm:"-"`
+	PayerUsername     string `json:"payer_username,omitempty" gorm:"-"`
+}
+
+// TableName specifies the table name for PaymentRequest
+func (PaymentRequest) TableName() string {
+	return "payment_requests"
+}
+
+// BeforeCreate will set a UUID rather than numeric ID
+func (p *PaymentRequest) BeforeCreate(tx *gorm.DB) error {
+	if p.ID == uuid.Nil {
+		p.ID = uuid.New()
+	}
+	return nil
+}
+
+// AfterFind binds the amount to the request currency
+func (p *PaymentRequest) AfterFind(tx *gorm.DB) error {
+	p.Amount = p.Amount.bind(p.Currency)
+	return nil
+}
@@ -0,0 +1,96 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// External account types
+const (
+	ExternalAccountTypeIBAN  = "iban"   // International Bank Account Number
+	ExternalAccountTypeUSACH = "us_ach" // US routing number + account number
+)
+
+// Payout statuses
+const (
+	PayoutStatusPending    = "pending"
+	PayoutStatusProcessing = "processing"
+	PayoutStatusSettled    = "settled"
+	PayoutStatusFailed     = "failed"
+	PayoutStatusReturned   = "returned"
+)
+
+// ExternalAccount represents a bank account a user can withdraw to
+type ExternalAccount struct {
+	ID            uuid.UUID      `json:"id" gorm:"type:char(36);primaryKey"`
+	UserID        uuid.UUID      `json:"user_id" gorm:"type:char(36);not null;index"`
+	Type          string         `json:"type" gorm:"size:20;not null"` // iban, us_ach
+	HolderName    string         `json:"holder_name" gorm:"size:100;not null"`
+	BankName      string         `json:"bank_name" gorm:"size:100"`
+	AccountNumber string         `json:"-" gorm:"size:34;not null"` // IBAN or account number, never returned
+	RoutingNumber string         `json:"routing_number,omitempty" gorm:"size:9"`
+	Last4         string         `json:"last4" gorm:"size:4"`
+	Currency      string         `json:"currency" gorm:"size:3;not null"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	DeletedAt     gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// Payout represents a withdrawal from a wallet to an external account
+type Payout struct {
+	ID                uuid.UUID  `json:"id" gorm:"type:char(36);primaryKey"`
+	UserID            uuid.UUID  `json:"user_id" gorm:"type:char(36);not null;index"`
+	WalletID          uuid.UUID  `json:"wallet_id" gorm:"type:char(36);not null;index"`
+	ExternalAccountID uuid.UUID  `json:"external_account_id" gorm:"type:char(36);not null"`
+	Amount            Money      `json:"amount" gorm:"type:decimal(15,2);not null"`
+	Currency          string     `json:"currency" gorm:"size:3;not null"`
+	Status            string     `json:"status" gorm:"size:20;not null;default:'pending';index"` // pending, processing, settled, failed, returned
+	Rail              string     `json:"rail" gorm:"size:50"`
+	RailReference     string     `json:"rail_reference,omitempty" gorm:"size:100"`
+	FailureReason     string     `json:"failure_reason,omitempty" gorm:"size:255"`
+	HoldID            *uuid.UUID `json:"hold_id,omitempty" gorm:"type:char(36)"`       // Reserves the amount until the payout is submitted
+	HoldEntryID       *uuid.UUID `json:"hold_entry_id,omitempty" gorm:"type:char(36)"` // Moved the amount to payouts in transit
+	SubmittedAt       *time.Time `json:"submitted_at,omitempty"`
+	SettledAt         *time.Time `json:"settled_at,omitempty"`
+	FailedAt          *time.Time `json:"failed_at,omitempty"`
+	ReturnedAt        *time.Time `json:"returned_at,omitempty"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+
+	// Relationships
+	ExternalAccount ExternalAccount `json:"external_account,omitempty" gorm:"foreignKey:ExternalAccountID"`
+}
+
+// TableName specifies the table name for ExternalAccount
+func (ExternalAccount) TableName() string {
+	return "external_accounts"
+}
+
+// TableName specifies the table name for Payout
+func (Payout) TableName() string {
+	return "payouts"
+}
+
+// BeforeCreate will set a UUID rather than numeric ID
+func (a *ExternalAccount) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	return nil
+}
+
+// BeforeCreate will set a UUID rather than numeric ID
+func (p *Payout) BeforeCreate(tx *gorm.DB) error {
+	if p.ID == uuid.Nil {
+		p.ID = uuid.New()
+	}
+	return nil
+}
+
+// AfterFind binds the amount to the payout currency
+func (p *Payout) AfterFind(tx *gorm.DB) error {
+	p.Amount = p.Amount.bind(p.Currency)
+	return nil
+}
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Pocket ring-fences part of a wallet's money, e.g. towards a savings goal.
+// Money in a pocket sits on the pocket's own ledger account, so it is no
+// longer part of the wallet's spendable balance.
+type Pocket struct {
+	ID           uuid.UUID      `json:"id" gorm:"type:char(36);primaryKey"`
+	WalletID     uuid.UUID      `json:"wallet_id" gorm:"type:char(36);not null;index"`
+	Name         string         `json:"name" gorm:"size:50;not null"`
+	Balance      Money          `json:"balance" gorm:"type:decimal(15,2);not null;default:0"`
+	Currency     string         `json:"currency" gorm:"size:3;not null"`
+	TargetAmount Money          `json:"target_amount" gorm:"type:decimal(15,2);not null;default:0"` // Zero means no goal
+	TargetDate   *time.Time     `json:"target_date,omitempty" gorm:"type:date"`
+	RoundUpTo    Money          `json:"round_up_to" gorm:"type:decimal(15,2);not null;default:0"` // Outgoing transfers are rounded up to a multiple of this; zero disables round-ups
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+	DeletedAt    gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// TableName specifies the table name for Pocket
+func (Pocket) TableName() string {
+	return "pockets"
+}
+
+// BeforeCreate will set a UUID rather than numeric ID
+func (p *Pocket) BeforeCreate(tx *gorm.DB) error {
+	if p.ID == uuid.Nil {
+		p.ID = uuid.New()
+	}
+	return nil
+}
+
+// AfterFind binds the amounts to the pocket currency
+func (p *Pocket) AfterFind(tx *gorm.DB) error {
+	p.Balance = p.Balance.bind(p.Currency)
+	p.TargetAmount = p.TargetAmount.bind(p.Currency)
+	p.RoundUpTo = p.RoundUpTo.bind(p.Currency)
+	return nil
+}
@@ -0,0 +1,110 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Scheduled transfer frequencies
+const (
+	ScheduleFrequencyOnce                = "once"
+	ScheduleFrequencyWeekly              = "weekly"
+	ScheduleFrequencyMonthly             = "monthly"
+	ScheduleFrequencyMonthlyLastBusiness = "monthly_last_business_day"
+)
+
+// Scheduled transfer statuses
+const (
+	ScheduledTransferStatusActive     = "active"
+	ScheduledTransferStatusProcessing = "processing" // Claimed by the executor
+	ScheduledTransferStatusPaused     = "paused"
+	ScheduledTransferStatusCompleted  = "completed"
+	ScheduledTransferStatusFailed     = "failed"
+	ScheduledTransferStatusCancelled  = "cancelled"
+)
+
+// Scheduled transfer run statuses
+const (
+	ScheduledRunStatusSucceeded = "succeeded"
+	ScheduledRunStatusFailed    = "failed"
+	ScheduledRunStatusSkipped   = "skipped" // Retries exhausted, moved on to the next occurrence
+)
+
+// ScheduledTransfer is a one-off or recurring transfer (standing order) that
+// the executor runs through the normal transfer path
+type ScheduledTransfer struct {
+	ID              uuid.UUID  `json:"id" gorm:"type:char(36);primaryKey"`
+	UserID          uuid.UUID  `json:"user_id" gorm:"type:char(36);not null;index"`
+	WalletID        uuid.UUID  `json:"wallet_id" gorm:"type:char(36);not null;index"`
+	RecipientUserID uuid.UUID  `json:"recipient_user_id" gorm:"type:char(36);not null"`
+	RecipientEmail  string     `json:"recipient_email" gorm:"size:100;not null"`
+	Amount          Money      `json:"amount" gorm:"type:decimal(15,2);not null"`
+	Currency        string     `json:"currency" gorm:"size:3;not null"`
+	Description     string     `json:"description" gorm:"size:255"`
+	Frequency       string     `json:"frequency" gorm:"size:30;not null"` // once, weekly, monthly, monthly_last_business_day
+	DayOfMonth      int        `json:"day_of_month,omitempty"`            // Requested day for monthly transfers, clamped to short months
+	StartDate       time.Time  `json:"start_date" gorm:"not null"`
+	EndDate         *time.Time `json:"end_date,omitempty"`        // Last date an occurrence may fall on
+	MaxOccurrences  *int       `json:"max_occurrences,omitempty"` // Stop after this many successful transfers
+	Occurrences     int        `json:"occurrences" gorm:"not null;default:0"`
+	NextRunAt       *time.Time `json:"next_run_at,omitempty" gorm:"index"` // Date of the next occurrence
+	RetryAt         *time.Time `json:"retry_at,omitempty"`                 // Set while a failed occurrence waits for a retry
+	Attempts        int        `json:"attempts" gorm:"not null;default:0"` // Failed attempts of the current occurrence
+	Status          string     `json:"status" gorm:"size:20;not null;default:'active';index"`
+	LastRunAt       *time.Time `json:"last_run_at,omitempty"`
+	LastError       string     `json:"last_error,omitempty" gorm:"size:255"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+
+	// Relationships
+	Runs []ScheduledTransferRun `json:"runs,omitempty" gorm:"foreignKey:ScheduledTransferID"`
+}
+
+// ScheduledTransferRun records one execution attempt of a scheduled transfer.
+// SucceededFor repeats ScheduledFor on successful runs only, so that the unique
+// index rejects paying the same occurrence twice while failed attempts may repeat.
+type ScheduledTransferRun struct {
+	ID                  uuid.UUID  `json:"id" gorm:"type:char(36);primaryKey"`
+	ScheduledTransferID uuid.UUID  `json:"scheduled_transfer_id" gorm:"type:char(36);not null;index;uniqueIndex:idx_scheduled_run_success,priority:1"`
+	ScheduledFor        time.Time  `json:"scheduled_for"`
+	SucceededFor        *time.Time `json:"-" gorm:"uniqueIndex:idx_scheduled_run_success,priority:2"`
+	Attempt             int        `json:"attempt"`
+	Status              string     `json:"status" gorm:"size:20;not null"` // succeeded, failed, skipped
+	Error               string     `json:"error,omitempty" gorm:"size:255"`
+	JournalEntryID      *uuid.UUID `json:"journal_entry_id,omitempty" gorm:"type:char(36)"`
+	CreatedAt           time.Time  `json:"created_at"`
+}
+
+// TableName specifies the table name for ScheduledTransfer
+func (ScheduledTransfer) TableName() string {
+	return "scheduled_transfers"
+}
+
+// TableName specifies the table name for ScheduledTransferRun
+func (ScheduledTransferRun) TableName() string {
+	return "scheduled_transfer_runs"
+}
+
+// BeforeCreate will set a UUID rather than numeric ID
+func (s *ScheduledTransfer) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	return nil
+}
+
+// BeforeCreate will set a UUID rather than numeric ID
+func (r *ScheduledTransferRun) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
+
+// AfterFind binds the amount to the transfer currency
+func (s *ScheduledTransfer) AfterFind(tx *gorm.DB) error {
+	s.Amount = s.Amount.bind(s.Currency)
+	return nil
+}
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// SettingsVersion is one saved revision of the system settings.
+// The row with the highest version is the current configuration.
+type SettingsVersion struct {
+	ID        uuid.UUID       `json:"id" gorm:"type:char(36);primaryKey"`
+	Version   int             `json:"version" gorm:"not null;uniqueIndex"`
+	Data      string          `json:"-" gorm:"type:text;not null"`     // Full settings document as JSON
+	Changes   json.RawMessage `json:"changes" gorm:"type:text"`        // JSON list of changed keys with old and new values
+	Note      string          `json:"note" gorm:"size:255"`            // Optional reason given by the admin
+	ChangedBy uuid.UUID       `json:"changed_by" gorm:"type:char(36)"` // Admin who saved this version
+	CreatedAt time.Time       `json:"created_at"`
+
+	// Relationships
+	User User `json:"user,omitempty" gorm:"foreignKey:ChangedBy"`
+}
+
+// TableName specifies the table name for SettingsVersion
+func (SettingsVersion) TableName() string {
+	return "settings_versions"
+}
+
+// BeforeCreate will set a UUID rather than numeric ID
+func (s *SettingsVersion) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Statement records a monthly wallet statement that was pre-generated and
+// stored as CSV, OFX and PDF files
+type Statement struct {
+	ID               uuid.UUID `json:"id" gorm:"type:char(36);primaryKey"`
+	WalletID         uuid.UUID `json:"wallet_id" gorm:"type:char(36);not null;uniqueIndex:idx_statement_period"`
+	UserID           uuid.UUID `json:"user_id" gorm:"type:char(36);not null;index"`
+	PeriodStart      time.Time `json:"period_start" gorm:"not null;uniqueIndex:idx_statement_period"`
+	PeriodEnd        time.Time `json:"period_end" gorm:"not null"` // Exclusive
+	Currency         string    `json:"currency" gorm:"size:3;not null"`
+	OpeningBalance   Money     `json:"opening_balance" gorm:"type:decimal(15,2);not null"`
+	ClosingBalance   Money     `json:"closing_balance" gorm:"type:decimal(15,2);not null"`
+	TotalIn          Money     `json:"total_in" gorm:"type:decimal(15,2);not null"`
+	TotalOut         Money     `json:"total_out" gorm:"type:decimal(15,2);not null"`
+	TransactionCount int       `json:"transaction_count" gorm:"not null;default:0"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// TableName specifies the table name for Statement
+func (Statement) TableName() string {
+	return "statements"
+}
+
+// BeforeCreate will set a UUID rather than numeric ID
+func (s *Statement) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	return nil
+}
+
+// AfterFind binds the balances and totals to the statement currency
+func (s *Statement) AfterFind(tx *gorm.DB) error {
+	s.OpeningBalance = s.OpeningBalance.bind(s.Currency)
+	s.ClosingBalance = s.ClosingBalance.bind(s.Currency)
+	s.TotalIn = s.TotalIn.bind(s.Currency)
+	s.TotalOut = s.TotalOut.bind(s.Currency)
+	return nil
+}
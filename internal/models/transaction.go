@@ -7,18 +7,24 @@ import (
 	"gorm.io/gorm"
 )
 
-// Transaction represents a financial transaction
+// Transaction represents a financial transaction as seen by a wallet owner.
+// Rows created by the ledger are a per-wallet view over a JournalEntry and
+// must not be modified directly.
 type Transaction struct {
-	ID          uuid.UUID      `json:"id" gorm:"type:char(36);primaryKey"`
-	WalletID    uuid.UUID      `json:"wallet_id" gorm:"type:char(36);not null"`
-	Type        string         `json:"type" gorm:"size:20;not null"` // deposit, withdrawal, transfer
-	Amount      float64        `json:"amount" gorm:"type:decimal(15,2);not null"`
-	Currency    string         `json:"currency" gorm:"size:3;default:'USD'"`
-	Description string         `json:"description" gorm:"size:255"`
-	Status      string         `json:"status" gorm:"size:20;default:'pending'"`
-	CreatedAt   time.Time      `json:"created_at"`
-	UpdatedAt   time.Time      `json:"updated_at"`
-	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
+	ID             uuid.UUID      `json:"id" gorm:"type:char(36);primaryKey"`
+	WalletID       uuid.UUID      `json:"wallet_id" gorm:"type:char(36);not null;index:idx_transactions_wallet_created"`
+	JournalEntryID *uuid.UUID     `json:"journal_entry_id,omitempty" gorm:"type:char(36);index"`
+	Type           string         `json:"type" gorm:"size:20;not null"` // deposit, withdrawal, transfer
+	Direction      string         `json:"direction" gorm:"size:3"`      // in, out
+	Amount         Money          `json:"amount" gorm:"type:decimal(15,2);not null"`
+	Currency       string         `json:"currency" gorm:"size:3;default:'USD'"`
+	Description    string         `json:"description" gorm:"size:255"`
+	Status         string         `json:"status" gorm:"size:20;default:'pending'"`
+	InitiatedBy    *uuid.UUID     `json:"initiated_by,omitempty" gorm:"type:char(36);index"` // User who made the payment; empty for system entries
+	FeeRuleID      *uuid.UUID     `json:"fee_rule_id,omitempty" gorm:"type:char(36)"`        // Fee rule that priced the payment; empty when the default fee applied
+	CreatedAt      time.Time      `json:"created_at" gorm:"index:idx_transactions_wallet_created"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+	DeletedAt      gorm.DeletedAt `json:"-" gorm:"index"`
 
 	// Relationships
 	Wallet Wallet `json:"wallet,omitempty" gorm:"foreignKey:WalletID"`
@@ -36,3 +42,9 @@ func (t *Transaction) BeforeCreate(tx *gorm.DB) error {
 	}
 	return nil
 }
+
+// AfterFind binds the amount to the transaction currency
+func (t *Transaction) AfterFind(tx *gorm.DB) error {
+	t.Amount = t.Amount.bind(t.Currency)
+	return nil
+}
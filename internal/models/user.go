@@ -21,6 +21,7 @@ type User struct {
 	TwoFactorEnabled bool           `json:"two_factor_enabled" gorm:"default:false"`
 	IsActive         bool           `json:"is_active" gorm:"default:true"`
 	IsAdmin          bool           `json:"is_admin" gorm:"default:false"`
+	Tier             string         `json:"tier" gorm:"size:20;default:'standard'"` // standard, verified, premium
 	CreatedAt        time.Time      `json:"created_at"`
 	UpdatedAt        time.Time      `json:"updated_at"`
 	DeletedAt        gorm.DeletedAt `json:"-" gorm:"index"`
@@ -7,15 +7,38 @@ import (
 	"gorm.io/gorm"
 )
 
-// Wallet represents a user's wallet
+// Wallet statuses
+const (
+	WalletStatusActive = "active"
+	WalletStatusClosed = "closed"
+)
+
+// Wallet types, which decide the interest rate a wallet earns
+const (
+	WalletTypeCurrent = "current"
+	WalletTypeSavings = "savings"
+)
+
+// Wallet represents a user's wallet. A user can hold several wallets in
+// different currencies; one of them is the default for incoming transfers.
+// UserID is the account holder; other users get access through WalletMember.
+// Balance is the ledger balance; the part reserved by active holds is Held.
+// Organisation wallets are shared through the organisation's members instead.
 type Wallet struct {
-	ID        uuid.UUID      `json:"id" gorm:"type:char(36);primaryKey"`
-	UserID    uuid.UUID      `json:"user_id" gorm:"type:char(36);not null"`
-	Balance   float64        `json:"balance" gorm:"type:decimal(15,2);default:0"`
-	Currency  string         `json:"currency" gorm:"size:3;default:'USD'"`
-	CreatedAt time.Time      `json:"created_at"`
-	UpdatedAt time.Time      `json:"updated_at"`
-	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+	ID             uuid.UUID      `json:"id" gorm:"type:char(36);primaryKey"`
+	UserID         uuid.UUID      `json:"user_id" gorm:"type:char(36);not null;index"`
+	OrganizationID *uuid.UUID     `json:"organization_id,omitempty" gorm:"type:char(36);index"` // Set for company wallets
+	Name           string         `json:"name" gorm:"size:100"`
+	Type           string         `json:"type" gorm:"size:20;not null;default:'current'"` // current, savings
+	Balance        Money          `json:"balance" gorm:"type:decimal(15,2);default:0"`
+	Held           Money          `json:"held" gorm:"type:decimal(15,2);not null;default:0"` // Sum of active holds
+	Currency       string         `json:"currency" gorm:"size:3;default:'USD'"`
+	IsDefault      bool           `json:"is_default" gorm:"default:false"`
+	Status         string         `json:"status" gorm:"size:20;default:'active'"` // active, closed
+	ClosedAt       *time.Time     `json:"closed_at,omitempty"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+	DeletedAt      gorm.DeletedAt `json:"-" gorm:"index"`
 
 	// Relationships
 	User         User          `json:"user,omitempty" gorm:"foreignKey:UserID"`
@@ -34,3 +57,15 @@ func (w *Wallet) BeforeCreate(tx *gorm.DB) error {
 	}
 	return nil
 }
+
+// AfterFind binds the balance to the wallet currency
+func (w *Wallet) AfterFind(tx *gorm.DB) error {
+	w.Balance = w.Balance.bind(w.Currency)
+	w.Held = w.Held.bind(w.Currency)
+	return nil
+}
+
+// Available returns the part of the balance that is not reserved by holds
+func (w *Wallet) Available() Money {
+	return Money{Amount: w.Balance.Amount - w.Held.Amount, Currency: w.Balance.Currency}
+}
@@ -0,0 +1,97 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Wallet member roles. The wallet's own UserID is always an owner and needs no member row.
+const (
+	WalletRoleOwner   = "owner"   // Spends, manages members and settings
+	WalletRoleSpender = "spender" // Spends within their limit and sees the wallet
+	WalletRoleViewer  = "viewer"  // Sees the balance and history only
+)
+
+// Wallet invite statuses
+const (
+	WalletInviteStatusPending  = "pending"
+	WalletInviteStatusAccepted = "accepted"
+	WalletInviteStatusDeclined = "declined"
+	WalletInviteStatusRevoked  = "revoked"
+)
+
+// WalletMember gives another user access to a joint or shared wallet
+type WalletMember struct {
+	ID         uuid.UUID  `json:"id" gorm:"type:char(36);primaryKey"`
+	WalletID   uuid.UUID  `json:"wallet_id" gorm:"type:char(36);not null;uniqueIndex:idx_wallet_member"`
+	UserID     uuid.UUID  `json:"user_id" gorm:"type:char(36);not null;uniqueIndex:idx_wallet_member;index"`
+	Role       string     `json:"role" gorm:"size:10;not null"`                             // owner, spender, viewer
+	SpendLimit Money      `json:"spend_limit" gorm:"type:decimal(15,2);not null;default:0"` // Per calendar month; zero means no limit
+	Currency   string     `json:"currency" gorm:"size:3;not null"`
+	InvitedBy  *uuid.UUID `json:"invited_by,omitempty" gorm:"type:char(36)"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+
+	// Relationships
+	User User `json:"user,omitempty" gorm:"foreignKey:UserID"`
+}
+
+// WalletInvite asks someone, by email, to join a wallet. It can be accepted by
+// the user registered with that email, now or after they sign up.
+type WalletInvite struct {
+	ID          uuid.UUID  `json:"id" gorm:"type:char(36);primaryKey"`
+	WalletID    uuid.UUID  `json:"wallet_id" gorm:"type:char(36);not null;index"`
+	Email       string     `json:"email" gorm:"size:100;not null;index"`
+	Role        string     `json:"role" gorm:"size:10;not null"`
+	SpendLimit  Money      `json:"spend_limit" gorm:"type:decimal(15,2);not null;default:0"`
+	Currency    string     `json:"currency" gorm:"size:3;not null"`
+	InvitedBy   uuid.UUID  `json:"invited_by" gorm:"type:char(36);not null"`
+	Status      string     `json:"status" gorm:"size:20;not null;default:'pending'"`
+	ExpiresAt   time.Time  `json:"expires_at" gorm:"not null"`
+	RespondedAt *time.Time `json:"responded_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+
+	// Relationships
+	Wallet Wallet `json:"wallet,omitempty" gorm:"foreignKey:WalletID"`
+}
+
+// TableName specifies the table name for WalletMember
+func (WalletMember) TableName() string {
+	return "wallet_members"
+}
+
+// TableName specifies the table name for WalletInvite
+func (WalletInvite) TableName() string {
+	return "wallet_invites"
+}
+
+// BeforeCreate will set a UUID rather than numeric ID
+func (m *WalletMember) BeforeCreate(tx *gorm.DB) error {
+	if m.ID == uuid.Nil {
+		m.ID = uuid.New()
+	}
+	return nil
+}
+
+// BeforeCreate will set a UUID rather than numeric ID
+func (i *WalletInvite) BeforeCreate(tx *gorm.DB) error {
+	if i.ID == uuid.Nil {
+		i.ID = uuid.New()
+	}
+	return nil
+}
+
+// AfterFind binds the spend limit to the wallet currency
+func (m *WalletMember) AfterFind(tx *gorm.DB) error {
+	m.SpendLimit = m.SpendLimit.bind(m.Currency)
+	return nil
+}
+
+// AfterFind binds the spend limit to the wallet currency
+func (i *WalletInvite) AfterFind(tx *gorm.DB) error {
+	i.SpendLimit = i.SpendLimit.bind(i.Currency)
+	return nil
+}
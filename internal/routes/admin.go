@@ -1,11 +1,16 @@
 package routes
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"securewallet/internal/config"
 	"securewallet/internal/middleware"
 	"securewallet/internal/models"
+	"securewallet/internal/services"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -27,6 +32,31 @@ func SetupAdminRoutes(router *gin.RouterGroup) {
 		admin.POST("/users/:id/enable", enableUser)
 		admin.GET("/settings", getSystemSettings)
 		admin.POST("/settings", saveSystemSettings)
+		admin.GET("/settings/history", getSettingsHistory)
+		admin.GET("/settings/versions/:version", getSettingsVersion)
+		admin.POST("/settings/versions/:version/restore", restoreSettingsVersion)
+		admin.GET("/ledger/reconcile", reconcileLedger)
+		admin.GET("/limits", getTransferLimits)
+		admin.PUT("/limits", setTransferLimit)
+		admin.DELETE("/limits/:id", deleteTransferLimit)
+		admin.PUT("/users/:id/tier", setUserTier)
+		admin.GET("/fee-rules", getFeeRules)
+		admin.POST("/fee-rules", createFeeRule)
+		admin.PUT("/fee-rules/:id", updateFeeRule)
+		admin.DELETE("/fee-rules/:id", deleteFeeRule)
+		admin.GET("/interest-rates", getInterestRates)
+		admin.POST("/interest-rates", setInterestRate)
+		admin.POST("/interest/recompute", recomputeInterest)
+		admin.GET("/credit-lines", getCreditLines)
+		admin.PUT("/credit-lines", setCreditLine)
+		admin.GET("/exchange-rates", getAdminExchangeRates)
+		admin.PUT("/exchange-rates", setExchangeRate)
+		admin.GET("/disputes", getAdminDisputes)
+		admin.POST("/disputes/:id/resolve", resolveDispute)
+		admin.POST("/transactions/:id/reverse", reverseTransaction)
+		admin.GET("/holds", getAdminHolds)
+		admin.POST("/holds", placeAdminHold)
+		admin.POST("/holds/:id/release", releaseAdminHold)
 		// Support management routes
 		admin.GET("/support/tickets", getAdminSupportTickets)
 		admin.POST("/support/tickets/:id/reply", replyToTicket)
@@ -69,15 +99,15 @@ func getAdminTransactions(c *gin.Context) {
 
 	// Transform transactions to include transaction_type field for frontend compatibility
 	type TransactionResponse struct {
-		ID              string    `json:"id"`
-		WalletID        string    `json:"wallet_id"`
-		TransactionType string    `json:"transaction_type"`
-		Amount          float64   `json:"amount"`
-		Currency        string    `json:"currency"`
-		Description     string    `json:"description"`
-		Status          string    `json:"status"`
-		CreatedAt       time.Time `json:"created_at"`
-		UpdatedAt       time.Time `json:"updated_at"`
+		ID              string       `json:"id"`
+		WalletID        string       `json:"wallet_id"`
+		TransactionType string       `json:"transaction_type"`
+		Amount          models.Money `json:"amount"`
+		Currency        string       `json:"currency"`
+		Description     string       `json:"description"`
+		Status          string       `json:"status"`
+		CreatedAt       time.Time    `json:"created_at"`
+		UpdatedAt       time.Time    `json:"updated_at"`
 		Wallet          struct {
 			User struct {
 				Email string `json:"email"`
@@ -128,44 +158,899 @@ func enableUser(c *gin.Context) {
 
 // getSystemSettings gets current system settings
 func getSystemSettings(c *gin.Context) {
-	// TODO: Implement getting settings from database
-	settings := gin.H{
-		"security": gin.H{
-			"twoFactorEnabled": true,
-			"sessionTimeout":   30,
-			"passwordPolicy": gin.H{
-				"minLength":           8,
-				"requireUppercase":    true,
-				"requireLowercase":    true,
-				"requireNumbers":      true,
-				"requireSpecialChars": true,
-			},
-		},
-		"transactionLimits": gin.H{
-			"dailyTransferLimit":   10000,
-			"monthlyTransferLimit": 50000,
-			"minTransferAmount":    1,
-		},
+	settings, version, err := services.NewSettingsService().Current()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load settings"})
+		return
+	}
+
+	c.JSON(http.StatusOK, settingsResponse(settings, version))
+}
+
+// settingsResponse adds the version and the read-only transfer limits to the settings document
+func settingsResponse(settings *services.SystemSettings, version int) gin.H {
+	response := gin.H{
+		"version":   version,
+		"security":  settings.Security,
+		"transfers": settings.Transfers,
+		"rateLimit": settings.RateLimit,
+		"comments":  settings.Comments,
+		"backups":   settings.Backups,
+	}
+
+	// Transfer limits come from the limits engine (global USD limits) and are managed under /admin/limits
+	if limits, err := services.NewLimitService().GlobalLimits("USD"); err == nil {
+		response["transactionLimits"] = gin.H{
+			"dailyTransferLimit":   limits.DailyAmount,
+			"monthlyTransferLimit": limits.MonthlyAmount,
+			"minTransferAmount":    limits.MinPerTransaction,
+			"maxTransferAmount":    limits.MaxPerTransaction,
+			"dailyTransferCount":   limits.DailyCount,
+			"monthlyTransferCount": limits.MonthlyCount,
+		}
 	}
-	c.JSON(http.StatusOK, settings)
+	return response
 }
 
-// saveSystemSettings saves system settings
+// saveSystemSettings validates a (partial) settings document and saves it as a new version.
+// An If-Match header with the version the admin edited rejects concurrent changes.
 func saveSystemSettings(c *gin.Context) {
-	var settings map[string]interface{}
-	if err := c.ShouldBindJSON(&settings); err != nil {
+	var document map[string]json.RawMessage
+	if err := c.ShouldBindJSON(&document); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid settings data"})
 		return
 	}
 
-	// TODO: Implement saving settings to database
-	// For now, just return success
+	// Drop the read-only fields that getSystemSettings adds
+	delete(document, "version")
+	delete(document, "transactionLimits")
+
+	note := ""
+	if raw, ok := document["note"]; ok {
+		json.Unmarshal(raw, &note)
+		delete(document, "note")
+	}
+
+	expectedVersion := -1
+	if ifMatch := strings.Trim(c.GetHeader("If-Match"), `"`); ifMatch != "" {
+		parsed, err := strconv.Atoi(ifMatch)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid If-Match header"})
+			return
+		}
+		expectedVersion = parsed
+	}
+
+	patch, _ := json.Marshal(document)
+	user, _ := c.Get("user")
+	adminUser := user.(*models.User)
+
+	settingsService := services.NewSettingsService()
+	saved, err := settingsService.Update(patch, adminUser.ID, note, expectedVersion)
+	if err != nil {
+		var invalid *services.SettingsValidationError
+		switch {
+		case errors.As(err, &invalid):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid settings", "details": invalid.Problems})
+		case errors.Is(err, services.ErrSettingsConflict):
+			c.JSON(http.StatusConflict, gin.H{"error": "Settings were changed by someone else, reload and try again"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save settings"})
+		}
+		return
+	}
+
+	settings, version, err := settingsService.Current()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load settings"})
+		return
+	}
+
+	message := "Settings unchanged"
+	if saved != nil {
+		message = "Settings saved successfully"
+		config.GetDB().Create(&models.AuditLog{
+			UserID:    adminUser.ID,
+			Action:    "SETTINGS_UPDATE",
+			Resource:  "settings",
+			Details:   fmt.Sprintf("Saved settings version %d: %s", saved.Version, saved.Changes),
+			IPAddress: c.ClientIP(),
+			UserAgent: c.Request.UserAgent(),
+		})
+	}
+
 	c.JSON(http.StatusOK, gin.H{
-		"message":  "Settings saved successfully",
+		"message":  message,
+		"settings": settingsResponse(settings, version),
+	})
+}
+
+// getSettingsHistory lists saved settings versions with who changed what
+func getSettingsHistory(c *gin.Context) {
+	limit := 50 // default limit
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if parsedLimit, err := strconv.Atoi(limitStr); err == nil && parsedLimit > 0 {
+			limit = parsedLimit
+		}
+	}
+
+	versions, err := services.NewSettingsService().History(limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch settings history"})
+		return
+	}
+
+	c.JSON(http.StatusOK, versions)
+}
+
+// getSettingsVersion returns the full settings of a stored version
+func getSettingsVersion(c *gin.Context) {
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid version"})
+		return
+	}
+
+	stored, settings, err := services.NewSettingsService().Version(version)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Settings version not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"version":  stored,
 		"settings": settings,
 	})
 }
 
+// restoreSettingsVersion saves an older version as the new current settings
+func restoreSettingsVersion(c *gin.Context) {
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid version"})
+		return
+	}
+
+	user, _ := c.Get("user")
+	adminUser := user.(*models.User)
+
+	saved, err := services.NewSettingsService().Restore(version, adminUser.ID)
+	if err != nil {
+		var invalid *services.SettingsValidationError
+		switch {
+		case errors.Is(err, services.ErrSettingsVersionNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Settings version not found"})
+		case errors.As(err, &invalid):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid settings", "details": invalid.Problems})
+		case errors.Is(err, services.ErrSettingsConflict):
+			c.JSON(http.StatusConflict, gin.H{"error": "Settings were changed by someone else, try again"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to restore settings"})
+		}
+		return
+	}
+	if saved == nil {
+		c.JSON(http.StatusOK, gin.H{"message": "Settings already match this version"})
+		return
+	}
+
+	config.GetDB().Create(&models.AuditLog{
+		UserID:    adminUser.ID,
+		Action:    "SETTINGS_RESTORE",
+		Resource:  "settings",
+		Details:   fmt.Sprintf("Restored settings version %d as version %d", version, saved.Version),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Settings restored",
+		"version": saved,
+	})
+}
+
+// reconcileLedger compares stored wallet balances with the ledger postings
+func reconcileLedger(c *gin.Context) {
+	ledger := services.NewLedgerService()
+
+	discrepancies, err := ledger.ReconcileWallets()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reconcile ledger"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"balanced":      len(discrepancies) == 0,
+		"discrepancies": discrepancies,
+	})
+}
+
+// TransferLimitRequest represents an admin update of a limit row.
+// Omitted fields are inherited from the less specific scope.
+type TransferLimitRequest struct {
+	Scope             string        `json:"scope" binding:"required,oneof=global tier user"`
+	Subject           string        `json:"subject"` // Tier name or user ID
+	Currency          string        `json:"currency" binding:"required,len=3"`
+	MinPerTransaction *models.Money `json:"min_per_transaction"`
+	MaxPerTransaction *models.Money `json:"max_per_transaction"`
+	DailyAmount       *models.Money `json:"daily_amount"`
+	MonthlyAmount     *models.Money `json:"monthly_amount"`
+	DailyCount        *int          `json:"daily_count"`
+	MonthlyCount      *int          `json:"monthly_count"`
+}
+
+// UserTierRequest represents a tier assignment
+type UserTierRequest struct {
+	Tier string `json:"tier" binding:"required,oneof=standard verified premium"`
+}
+
+// getTransferLimits lists the configured transfer limits
+func getTransferLimits(c *gin.Context) {
+	limits, err := services.NewLimitService().ListLimits()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch transfer limits"})
+		return
+	}
+
+	c.JSON(http.StatusOK, limits)
+}
+
+// setTransferLimit creates or replaces a global, tier or user limit
+func setTransferLimit(c *gin.Context) {
+	var limitReq TransferLimitRequest
+	if err := c.ShouldBindJSON(&limitReq); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, _ := c.Get("user")
+	adminUser := user.(*models.User)
+
+	limit, err := services.NewLimitService().SetLimit(services.TransferLimitInput{
+		Scope:             limitReq.Scope,
+		Subject:           limitReq.Subject,
+		Currency:          limitReq.Currency,
+		MinPerTransaction: limitReq.MinPerTransaction,
+		MaxPerTransaction: limitReq.MaxPerTransaction,
+		DailyAmount:       limitReq.DailyAmount,
+		MonthlyAmount:     limitReq.MonthlyAmount,
+		DailyCount:        limitReq.DailyCount,
+		MonthlyCount:      limitReq.MonthlyCount,
+		UpdatedBy:         adminUser.ID,
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	config.GetDB().Create(&models.AuditLog{
+		UserID:    adminUser.ID,
+		Action:    "TRANSFER_LIMIT_UPDATE",
+		Resource:  "transfer_limit",
+		Details:   fmt.Sprintf("Set %s limit %q for %s", limit.Scope, limit.Subject, limit.Currency),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+
+	c.JSON(http.StatusOK, limit)
+}
+
+// deleteTransferLimit removes a limit row
+func deleteTransferLimit(c *gin.Context) {
+	id := c.Param("id")
+
+	if err := services.NewLimitService().DeleteLimit(id); err != nil {
+		if errors.Is(err, services.ErrLimitNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Transfer limit not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete transfer limit"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Transfer limit deleted"})
+}
+
+// setUserTier assigns a user to a limit tier
+func setUserTier(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var tierReq UserTierRequest
+	if err := c.ShouldBindJSON(&tierReq); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := services.NewLimitService().SetUserTier(userID, tierReq.Tier); err != nil {
+		if errors.Is(err, services.ErrUserNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "User tier updated", "tier": tierReq.Tier})
+}
+
+// FeeRuleRequest represents a fee rule an admin creates or replaces
+type FeeRuleRequest struct {
+	Name             string        `json:"name" binding:"required,max=100"`
+	TransactionType  string        `json:"transaction_type" binding:"required,oneof=transfer scheduled_transfer payment_request bulk_payout checkout"`
+	Currency         string        `json:"currency" binding:"required,len=3"`
+	Tier             string        `json:"tier" binding:"omitempty,oneof=standard verified premium"` // Empty for every tier
+	MinAmount        *models.Money `json:"min_amount"`
+	MaxAmount        *models.Money `json:"max_amount"`
+	BasisPoints      int64         `json:"basis_points" binding:"min=0,max=1000"`
+	FlatFee          *models.Money `json:"flat_fee"`
+	MinFee           *models.Money `json:"min_fee"`
+	MaxFee           *models.Money `json:"max_fee"`
+	FreeMonthlyQuota int           `json:"free_monthly_quota" binding:"min=0"`
+	Priority         int           `json:"priority"`
+	StartsAt         *time.Time    `json:"starts_at"`
+	EndsAt           *time.Time    `json:"ends_at"`
+	Active           *bool         `json:"active"` // Defaults to true
+}
+
+// input converts the request into fee service input
+func (r FeeRuleRequest) input(adminID uuid.UUID) services.FeeRuleInput {
+	active := r.Active == nil || *r.Active
+	return services.FeeRuleInput{
+		Name:             r.Name,
+		TransactionType:  r.TransactionType,
+		Currency:         r.Currency,
+		Tier:             r.Tier,
+		MinAmount:        r.MinAmount,
+		MaxAmount:        r.MaxAmount,
+		BasisPoints:      r.BasisPoints,
+		FlatFee:          r.FlatFee,
+		MinFee:           r.MinFee,
+		MaxFee:           r.MaxFee,
+		FreeMonthlyQuota: r.FreeMonthlyQuota,
+		Priority:         r.Priority,
+		StartsAt:         r.StartsAt,
+		EndsAt:           r.EndsAt,
+		Active:           active,
+		UpdatedBy:        adminID,
+	}
+}
+
+// getFeeRules lists the fee rules
+func getFeeRules(c *gin.Context) {
+	rules, err := services.NewFeeService().ListRules()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch fee rules"})
+		return
+	}
+
+	c.JSON(http.StatusOK, rules)
+}
+
+// createFeeRule adds a fee rule
+func createFeeRule(c *gin.Context) {
+	var ruleReq FeeRuleRequest
+	if err := c.ShouldBindJSON(&ruleReq); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, _ := c.Get("user")
+	adminUser := user.(*models.User)
+
+	rule, err := services.NewFeeService().CreateRule(ruleReq.input(adminUser.ID))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	config.GetDB().Create(&models.AuditLog{
+		UserID:    adminUser.ID,
+		Action:    "FEE_RULE_CREATE",
+		Resource:  "fee_rule",
+		Details:   fmt.Sprintf("Created fee rule %s (%s) for %s %s", rule.ID, rule.Name, rule.TransactionType, rule.Currency),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+
+	c.JSON(http.StatusCreated, rule)
+}
+
+// updateFeeRule replaces a fee rule
+func updateFeeRule(c *gin.Context) {
+	var ruleReq FeeRuleRequest
+	if err := c.ShouldBindJSON(&ruleReq); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, _ := c.Get("user")
+	adminUser := user.(*models.User)
+
+	rule, err := services.NewFeeService().UpdateRule(c.Param("id"), ruleReq.input(adminUser.ID))
+	if errors.Is(err, services.ErrFeeRuleNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Fee rule not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	config.GetDB().Create(&models.AuditLog{
+		UserID:    adminUser.ID,
+		Action:    "FEE_RULE_UPDATE",
+		Resource:  "fee_rule",
+		Details:   fmt.Sprintf("Updated fee rule %s (%s) for %s %s, active %t", rule.ID, rule.Name, rule.TransactionType, rule.Currency, rule.Active),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+
+	c.JSON(http.StatusOK, rule)
+}
+
+// deleteFeeRule removes a fee rule
+func deleteFeeRule(c *gin.Context) {
+	id := c.Param("id")
+
+	if err := services.NewFeeService().DeleteRule(id); err != nil {
+		if errors.Is(err, services.ErrFeeRuleNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Fee rule not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete fee rule"})
+		return
+	}
+
+	user, _ := c.Get("user")
+	adminUser := user.(*models.User)
+	config.GetDB().Create(&models.AuditLog{
+		UserID:    adminUser.ID,
+		Action:    "FEE_RULE_DELETE",
+		Resource:  "fee_rule",
+		Details:   fmt.Sprintf("Deleted fee rule %s", id),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+
+	c.JSON(http.StatusOK, gin.H{"message": "Fee rule deleted"})
+}
+
+// InterestRateRequest represents an interest rate an admin sets for a wallet type
+type InterestRateRequest struct {
+	WalletType    string `json:"wallet_type" binding:"required,oneof=current savings"`
+	Currency      string `json:"currency" binding:"required,len=3"`
+	AnnualRateBps int64  `json:"annual_rate_bps" binding:"min=0,max=10000"`
+	DayCount      string `json:"day_count" binding:"omitempty,oneof=actual/365 actual/360 actual/actual 30/360"` // Defaults to actual/365
+	EffectiveFrom string `json:"effective_from"`                                                                 // YYYY-MM-DD, defaults to today
+}
+
+// InterestRecomputeRequest represents a date range to accrue and capitalise again
+type InterestRecomputeRequest struct {
+	From string `json:"from" binding:"required"` // YYYY-MM-DD
+	To   string `json:"to" binding:"required"`   // YYYY-MM-DD, inclusive
+}
+
+// getInterestRates lists the interest rates on file
+func getInterestRates(c *gin.Context) {
+	rates, err := services.NewInterestService().ListRates()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch interest rates"})
+		return
+	}
+
+	c.JSON(http.StatusOK, rates)
+}
+
+// setInterestRate records a rate for a wallet type and currency. A backdated
+// rate only applies to the past once that period is recomputed.
+func setInterestRate(c *gin.Context) {
+	var rateReq InterestRateRequest
+	if err := c.ShouldBindJSON(&rateReq); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	effectiveFrom := time.Now()
+	if rateReq.EffectiveFrom != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", rateReq.EffectiveFrom, time.Local)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "effective_from must be a YYYY-MM-DD date"})
+			return
+		}
+		effectiveFrom = parsed
+	}
+
+	user, _ := c.Get("user")
+	adminUser := user.(*models.User)
+
+	rate, err := services.NewInterestService().SetRate(services.InterestRateInput{
+		WalletType:    rateReq.WalletType,
+		Currency:      rateReq.Currency,
+		AnnualRateBps: rateReq.AnnualRateBps,
+		DayCount:      rateReq.DayCount,
+		EffectiveFrom: effectiveFrom,
+		CreatedBy:     adminUser.ID,
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	config.GetDB().Create(&models.AuditLog{
+		UserID:    adminUser.ID,
+		Action:    "INTEREST_RATE_SET",
+		Resource:  "interest_rate",
+		Details:   fmt.Sprintf("Set %s %s interest to %d bps (%s) from %s", rate.WalletType, rate.Currency, rate.AnnualRateBps, rate.DayCount, rate.EffectiveFrom.Format("2006-01-02")),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+
+	c.JSON(http.StatusCreated, rate)
+}
+
+// recomputeInterest accrues a date range again with the rates now on file and
+// posts corrections for months already capitalised
+func recomputeInterest(c *gin.Context) {
+	var recomputeReq InterestRecomputeRequest
+	if err := c.ShouldBindJSON(&recomputeReq); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	from, err := time.ParseInLocation("2006-01-02", recomputeReq.From, time.Local)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from must be a YYYY-MM-DD date"})
+		return
+	}
+	to, err := time.ParseInLocation("2006-01-02", recomputeReq.To, time.Local)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "to must be a YYYY-MM-DD date"})
+		return
+	}
+
+	user, _ := c.Get("user")
+	adminUser := user.(*models.User)
+
+	result, err := services.NewInterestService().Recompute(from, to)
+	if errors.Is(err, services.ErrInvalidInterestRange) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to recompute interest", "result": result})
+		return
+	}
+
+	config.GetDB().Create(&models.AuditLog{
+		UserID:    adminUser.ID,
+		Action:    "INTEREST_RECOMPUTE",
+		Resource:  "interest",
+		Details:   fmt.Sprintf("Recomputed interest from %s to %s: %d accruals, %d postings", recomputeReq.From, recomputeReq.To, result.Accruals, result.Postings),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+
+	c.JSON(http.StatusOK, result)
+}
+
+// CreditLineRequest represents a credit line an admin grants to a wallet or changes
+type CreditLineRequest struct {
+	WalletID      string       `json:"wallet_id" binding:"required"`
+	Limit         models.Money `json:"limit"`
+	AnnualRateBps int64        `json:"annual_rate_bps" binding:"min=0,max=10000"`
+	GraceDays     int          `json:"grace_days" binding:"min=0,max=90"`
+	Status        string       `json:"status" binding:"omitempty,oneof=active suspended"` // Defaults to active
+}
+
+// getCreditLines lists credit lines (?over_limit=true for those over their limit)
+func getCreditLines(c *gin.Context) {
+	lines, err := services.NewCreditLineService().List(c.Query("over_limit") == "true")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch credit lines"})
+		return
+	}
+
+	c.JSON(http.StatusOK, lines)
+}
+
+// setCreditLine grants a credit line to a wallet or changes its limit, rate,
+// grace period or status
+func setCreditLine(c *gin.Context) {
+	var lineReq CreditLineRequest
+	if err := c.ShouldBindJSON(&lineReq); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	walletID, err := uuid.Parse(lineReq.WalletID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid wallet ID"})
+		return
+	}
+
+	user, _ := c.Get("user")
+	adminUser := user.(*models.User)
+
+	line, err := services.NewCreditLineService().Set(services.CreditLineInput{
+		WalletID:      walletID,
+		Limit:         lineReq.Limit,
+		AnnualRateBps: lineReq.AnnualRateBps,
+		GraceDays:     lineReq.GraceDays,
+		Status:        lineReq.Status,
+		UpdatedBy:     adminUser.ID,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrWalletNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Wallet not found"})
+		case errors.Is(err, services.ErrWalletClosed):
+			c.JSON(http.StatusConflict, gin.H{"error": "Wallet is closed"})
+		case errors.Is(err, services.ErrCurrencyMismatch):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Limit must be in the wallet currency"})
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	config.GetDB().Create(&models.AuditLog{
+		UserID:    adminUser.ID,
+		Action:    "CREDIT_LINE_SET",
+		Resource:  "credit_line",
+		Details:   fmt.Sprintf("Set credit line of wallet %s to %s at %d bps, %d grace days, %s", line.WalletID, line.Limit, line.AnnualRateBps, line.GraceDays, line.Status),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+
+	c.JSON(http.StatusOK, line)
+}
+
+// ExchangeRateRequest represents an admin update of a currency pair rate
+type ExchangeRateRequest struct {
+	BaseCurrency      string `json:"base_currency" binding:"required,len=3"`
+	QuoteCurrency     string `json:"quote_currency" binding:"required,len=3"`
+	Rate              string `json:"rate" binding:"required"` // Decimal string, e.g. "1.0850"
+	SpreadBasisPoints int64  `json:"spread_basis_points"`
+}
+
+// getAdminExchangeRates lists the stored exchange rates
+func getAdminExchangeRates(c *gin.Context) {
+	rates, err := services.NewDBRateProvider().ListRates()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch exchange rates"})
+		return
+	}
+
+	c.JSON(http.StatusOK, rates)
+}
+
+// setExchangeRate creates or updates the rate of a currency pair
+func setExchangeRate(c *gin.Context) {
+	var rateReq ExchangeRateRequest
+	if err := c.ShouldBindJSON(&rateReq); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, _ := c.Get("user")
+	adminUser := user.(*models.User)
+
+	rate, err := services.NewDBRateProvider().SetRate(
+		rateReq.BaseCurrency,
+		rateReq.QuoteCurrency,
+		rateReq.Rate,
+		rateReq.SpreadBasisPoints,
+		adminUser.ID,
+	)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Record who changed the rate
+	config.GetDB().Create(&models.AuditLog{
+		UserID:    adminUser.ID,
+		Action:    "EXCHANGE_RATE_UPDATE",
+		Resource:  "exchange_rate",
+		Details:   fmt.Sprintf("%s/%s set to %s (spread %d bps)", rate.BaseCurrency, rate.QuoteCurrency, rate.Rate, rate.SpreadBasisPoints),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+
+	c.JSON(http.StatusOK, rate)
+}
+
+// DisputeDecisionRequest represents an admin decision on a dispute
+type DisputeDecisionRequest struct {
+	Decision string `json:"decision" binding:"required,oneof=reverse reject"`
+	Note     string `json:"note" binding:"max=200"`
+}
+
+// ReverseTransactionRequest represents an admin reversal of a transfer
+type ReverseTransactionRequest struct {
+	Reason string `json:"reason" binding:"required,max=200"`
+}
+
+// AdminHoldRequest represents funds an admin reserves in a wallet, e.g. during an investigation
+type AdminHoldRequest struct {
+	WalletID  string       `json:"wallet_id" binding:"required"`
+	Amount    models.Money `json:"amount" binding:"required"`
+	Reason    string       `json:"reason" binding:"required,max=200"`
+	ExpiresAt *time.Time   `json:"expires_at"` // Optional; without it the hold stays until released
+}
+
+// getAdminDisputes lists disputes for review, oldest first (?status=open)
+func getAdminDisputes(c *gin.Context) {
+	limit := 100 // default limit for admin
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if parsedLimit, err := strconv.Atoi(limitStr); err == nil && parsedLimit > 0 {
+			limit = parsedLimit
+		}
+	}
+
+	disputes, err := services.NewDisputeService().AdminList(c.Query("status"), limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch disputes"})
+		return
+	}
+
+	c.JSON(http.StatusOK, disputes)
+}
+
+// resolveDispute decides a dispute, reversing the transfer when decided for the sender
+func resolveDispute(c *gin.Context) {
+	var decisionReq DisputeDecisionRequest
+	if err := c.ShouldBindJSON(&decisionReq); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, _ := c.Get("user")
+	adminUser := user.(*models.User)
+
+	dispute, err := services.NewDisputeService().Resolve(adminUser.ID, c.Param("id"), decisionReq.Decision, decisionReq.Note)
+	if err != nil {
+		respondReversalError(c, err)
+		return
+	}
+
+	// Record who decided the dispute
+	config.GetDB().Create(&models.AuditLog{
+		UserID:    adminUser.ID,
+		Action:    "DISPUTE_RESOLVE",
+		Resource:  "dispute",
+		Details:   fmt.Sprintf("Dispute %s decided %s: %s", dispute.ID, dispute.Status, decisionReq.Note),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+
+	c.JSON(http.StatusOK, dispute)
+}
+
+// reverseTransaction reverses the transfer behind a transaction with a compensating entry
+func reverseTransaction(c *gin.Context) {
+	var reverseReq ReverseTransactionRequest
+	if err := c.ShouldBindJSON(&reverseReq); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, _ := c.Get("user")
+	adminUser := user.(*models.User)
+
+	reversal, err := services.NewReversalService().ReverseTransaction(c.Param("id"), adminUser.ID, reverseReq.Reason)
+	if err != nil {
+		respondReversalError(c, err)
+		return
+	}
+
+	config.GetDB().Create(&models.AuditLog{
+		UserID:    adminUser.ID,
+		Action:    "TRANSACTION_REVERSE",
+		Resource:  "transaction",
+		Details:   fmt.Sprintf("Reversed entry %s (%s + %s fee): %s", reversal.OriginalEntryID, reversal.Amount, reversal.FeeRefunded, reverseReq.Reason),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Transfer reversed",
+		"reversal": reversal,
+	})
+}
+
+// getAdminHolds lists holds across all wallets, newest first (?status=active)
+func getAdminHolds(c *gin.Context) {
+	limit := 100 // default limit for admin
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if parsedLimit, err := strconv.Atoi(limitStr); err == nil && parsedLimit > 0 {
+			limit = parsedLimit
+		}
+	}
+
+	holds, err := services.NewHoldService().AdminList(c.Query("status"), limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch holds"})
+		return
+	}
+
+	c.JSON(http.StatusOK, holds)
+}
+
+// placeAdminHold reserves funds in any wallet; they cannot be spent until the hold is released or expires
+func placeAdminHold(c *gin.Context) {
+	var holdReq AdminHoldRequest
+	if err := c.ShouldBindJSON(&holdReq); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	walletID, err := uuid.Parse(holdReq.WalletID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Wallet not found"})
+		return
+	}
+
+	user, _ := c.Get("user")
+	adminUser := user.(*models.User)
+
+	hold, err := services.NewHoldService().Authorize(services.HoldInput{
+		WalletID:    walletID,
+		Amount:      holdReq.Amount,
+		Kind:        models.HoldKindAdmin,
+		Description: holdReq.Reason,
+		PlacedBy:    adminUser.ID,
+		ExpiresAt:   holdReq.ExpiresAt,
+	})
+	if err != nil {
+		respondHoldError(c, err)
+		return
+	}
+
+	config.GetDB().Create(&models.AuditLog{
+		UserID:    adminUser.ID,
+		Action:    "HOLD_PLACE",
+		Resource:  "hold",
+		Details:   fmt.Sprintf("Held %s in wallet %s: %s", hold.Amount, hold.WalletID, holdReq.Reason),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Hold placed",
+		"hold":    hold,
+	})
+}
+
+// releaseAdminHold releases a hold placed by an admin
+func releaseAdminHold(c *gin.Context) {
+	user, _ := c.Get("user")
+	adminUser := user.(*models.User)
+
+	hold, err := services.NewHoldService().AdminRelease(c.Param("id"))
+	if err != nil {
+		respondHoldError(c, err)
+		return
+	}
+
+	config.GetDB().Create(&models.AuditLog{
+		UserID:    adminUser.ID,
+		Action:    "HOLD_RELEASE",
+		Resource:  "hold",
+		Details:   fmt.Sprintf("Released hold %s of %s in wallet %s", hold.ID, hold.Amount, hold.WalletID),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Hold released",
+		"hold":    hold,
+	})
+}
+
 // getAdminSupportTickets gets all support tickets for admin
 func getAdminSupportTickets(c *gin.Context) {
 	db := config.GetDB()
@@ -0,0 +1,62 @@
+package routes
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestLoginTokenLifetime(t *testing.T) {
+	db := setupTestDB(t)
+	t.Setenv("JWT_SECRET_KEY", "test-secret")
+	t.Setenv("ACCESS_TOKEN_EXPIRE_MINUTES", "")
+	user, _ := createTestUser(t, db, "USD")
+	hash, err := bcrypt.GenerateFromPassword([]byte("Correct-Horse-42"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("failed to hash password: %v", err)
+	}
+	db.Model(user).Update("password_hash", string(hash))
+
+	body := fmt.Sprintf(`{"username": %q, "password": "Correct-Horse-42"}`, user.Username)
+	response := serveAs(nil, http.MethodPost, "/auth/login", login, body)
+	if response.Code != http.StatusOK {
+		t.Fatalf("login: status %d %s, want 200", response.Code, response.Body)
+	}
+	var reply Token
+	json.Unmarshal(response.Body.Bytes(), &reply)
+
+	claims := jwt.MapClaims{}
+	if _, err := jwt.ParseWithClaims(reply.AccessToken, claims, func(*jwt.Token) (interface{}, error) {
+		return []byte("test-secret"), nil
+	}); err != nil {
+		t.Fatalf("failed to parse token: %v", err)
+	}
+	expires, _ := claims.GetExpirationTime()
+	issued, _ := claims.GetIssuedAt()
+	if lifetime := expires.Sub(issued.Time); lifetime.Minutes() != 120 {
+		t.Errorf("token lifetime = %v, want 2h", lifetime)
+	}
+}
+
+func TestIsStrongPassword(t *testing.T) {
+	tests := []struct {
+		password string
+		want     bool
+	}{
+		{"Correct-Horse-42", true},
+		{"Short-Pw-1", false},
+		{"correct-horse-42", false},
+		{"CORRECT-HORSE-42", false},
+		{"Correct-Horse-Go", false},
+		{"CorrectHorse4242", false},
+	}
+	for _, tt := range tests {
+		if got := isStrongPassword(tt.password); got != tt.want {
+			t.Errorf("isStrongPassword(%q) = %v, want %v", tt.password, got, tt.want)
+		}
+	}
+}
@@ -39,7 +39,7 @@ func listBackups(c *gin.Context) {
 
 // getBackupConfig returns backup configuration
 func getBackupConfig(c *gin.Context) {
-	config := services.DefaultBackupConfig
+	config := services.CurrentBackupConfig()
 	
 	c.JSON(http.StatusOK, gin.H{
 		"config": gin.H{
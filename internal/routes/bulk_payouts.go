@@ -0,0 +1,232 @@
+package routes
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"securewallet/internal/middleware"
+	"securewallet/internal/models"
+	"securewallet/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupBulkPayoutRoutes sets up routes for bulk payouts, batches of transfers uploaded as CSV or JSON
+func SetupBulkPayoutRoutes(router *gin.RouterGroup) {
+	batches := router.Group("/bulk-payouts")
+	batches.Use(middleware.AuthMiddleware())
+	{
+		batches.GET("/", getBulkPayouts)
+		batches.POST("/", createBulkPayout)
+		batches.GET("/:id", getBulkPayout)
+		batches.GET("/:id/results", getBulkPayoutResults)
+		batches.POST("/:id/execute", middleware.IdempotencyMiddleware(), executeBulkPayout)
+	}
+}
+
+// BulkPayoutRequest represents a batch sent as JSON instead of a CSV upload
+type BulkPayoutRequest struct {
+	WalletID string                  `json:"wallet_id"` // Defaults to the user's default wallet
+	Lines    []BulkPayoutLineRequest `json:"lines" binding:"required,min=1,max=1000"`
+}
+
+// BulkPayoutLineRequest represents one recipient of a batch
+type BulkPayoutLineRequest struct {
+	Recipient string       `json:"recipient"` // Email or username
+	Amount    models.Money `json:"amount"`
+	Currency  string       `json:"currency"` // Defaults to the wallet currency
+	Reference string       `json:"reference"`
+}
+
+// ExecuteBulkPayoutRequest represents how a batch should be paid
+type ExecuteBulkPayoutRequest struct {
+	Mode string `json:"mode" binding:"required,oneof=atomic per_line"`
+}
+
+// respondBulkPayoutError maps bulk payout service errors to HTTP responses
+func respondBulkPayoutError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrBulkPayoutNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Bulk payout not found"})
+	case errors.Is(err, services.ErrInvalidBulkPayout),
+		errors.Is(err, services.ErrInvalidBulkPayoutFile),
+		errors.Is(err, services.ErrInvalidBulkPayoutMode):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrBulkPayoutState):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		respondWalletError(c, err)
+	}
+}
+
+// getBulkPayouts lists the current user's bulk payouts
+func getBulkPayouts(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	currentUser := user.(*models.User)
+
+	batches, err := services.NewBulkPayoutService().List(currentUser.ID)
+	if err != nil {
+		respondBulkPayoutError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, batches)
+}
+
+// createBulkPayout validates an uploaded batch and returns the per-line report.
+// The batch is either a CSV file in the 'file' form field, with the wallet in
+// the 'wallet_id' form field, or a JSON body.
+func createBulkPayout(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	currentUser := user.(*models.User)
+	input := services.BulkPayoutInput{UserID: currentUser.ID}
+
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		fileHeader, err := c.FormFile("file")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "A CSV file is required in the 'file' form field"})
+			return
+		}
+		if fileHeader.Size > services.MaxBulkPayoutFileSize {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "File is larger than 1 MB"})
+			return
+		}
+		file, err := fileHeader.Open()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read file"})
+			return
+		}
+		defer file.Close()
+
+		// Read one byte past the limit so that a wrong size header cannot sneak a larger file through
+		data, err := io.ReadAll(io.LimitReader(file, services.MaxBulkPayoutFileSize+1))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read file"})
+			return
+		}
+		if len(data) > services.MaxBulkPayoutFileSize {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "File is larger than 1 MB"})
+			return
+		}
+
+		lines, err := services.ParseBulkPayoutCSV(data)
+		if err != nil {
+			respondBulkPayoutError(c, err)
+			return
+		}
+		input.WalletID = c.PostForm("wallet_id")
+		input.FileName = fileHeader.Filename
+		input.Lines = lines
+	} else {
+		var batchReq BulkPayoutRequest
+		if err := c.ShouldBindJSON(&batchReq); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		input.WalletID = batchReq.WalletID
+		for _, line := range batchReq.Lines {
+			input.Lines = append(input.Lines, services.BulkPayoutLineInput{
+				Recipient: line.Recipient,
+				Amount:    line.Amount,
+				Currency:  line.Currency,
+				Reference: line.Reference,
+			})
+		}
+	}
+
+	batch, err := services.NewBulkPayoutService().Create(input)
+	if err != nil {
+		respondBulkPayoutError(c, err)
+		return
+	}
+
+	message := "Bulk payout validated"
+	if batch.Status == models.BulkPayoutStatusInvalid {
+		message = "Bulk payout has validation errors"
+	}
+	c.JSON(http.StatusCreated, gin.H{
+		"message":     message,
+		"bulk_payout": batch,
+	})
+}
+
+// getBulkPayout returns a bulk payout with the status of every line
+func getBulkPayout(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	currentUser := user.(*models.User)
+
+	batch, err := services.NewBulkPayoutService().Get(currentUser.ID, c.Param("id"))
+	if err != nil {
+		respondBulkPayoutError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, batch)
+}
+
+// getBulkPayoutResults downloads the per-line results of a bulk payout as CSV
+func getBulkPayoutResults(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	currentUser := user.(*models.User)
+
+	batch, data, err := services.NewBulkPayoutService().Results(currentUser.ID, c.Param("id"))
+	if err != nil {
+		respondBulkPayoutError(c, err)
+		return
+	}
+
+	name := fmt.Sprintf("bulk_payout_%s_results.csv", strings.SplitN(batch.ID.String(), "-", 2)[0])
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name))
+	c.Data(http.StatusOK, "text/csv; charset=utf-8", data)
+}
+
+// executeBulkPayout queues a bulk payout; poll the bulk payout for its progress
+func executeBulkPayout(c *gin.Context) {
+	var executeReq ExecuteBulkPayoutRequest
+	if err := c.ShouldBindJSON(&executeReq); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	currentUser := user.(*models.User)
+
+	batch, err := services.NewBulkPayoutService().Execute(currentUser.ID, c.Param("id"), executeReq.Mode)
+	if err != nil {
+		respondBulkPayoutError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message":     "Bulk payout queued",
+		"bulk_payout": batch,
+	})
+}
@@ -0,0 +1,300 @@
+package routes
+
+import (
+	"errors"
+	"net/http"
+
+	"securewallet/internal/middleware"
+	"securewallet/internal/models"
+	"securewallet/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupCategoryRoutes sets up transaction category and auto-categorisation rule routes
+func SetupCategoryRoutes(router *gin.RouterGroup) {
+	categories := router.Group("/categories")
+	categories.Use(middleware.AuthMiddleware())
+	{
+		categories.GET("/", getCategories)
+		categories.POST("/", createCategory)
+		categories.PUT("/:id", updateCategory)
+		categories.DELETE("/:id", deleteCategory)
+	}
+
+	rules := router.Group("/category-rules")
+	rules.Use(middleware.AuthMiddleware())
+	{
+		rules.GET("/", getCategoryRules)
+		rules.POST("/", createCategoryRule)
+		rules.POST("/apply", applyCategoryRules)
+		rules.PUT("/:id", updateCategoryRule)
+		rules.DELETE("/:id", deleteCategoryRule)
+	}
+}
+
+// CategoryRequest represents a category to create or update
+type CategoryRequest struct {
+	Name  string `json:"name" binding:"required,max=50"`
+	Color string `json:"color"` // #rrggbb
+}
+
+// CategoryRuleRequest represents an auto-categorisation rule to create or replace
+type CategoryRuleRequest struct {
+	CategoryID string `json:"category_id" binding:"required"`
+	MatchType  string `json:"match_type"` // contains (default), starts_with, equals
+	Pattern    string `json:"pattern" binding:"required,max=100"`
+	Direction  string `json:"direction"` // in, out; empty matches both
+	Priority   int    `json:"priority"`
+	Enabled    *bool  `json:"enabled"` // Defaults to true
+}
+
+// input converts the request to the service input
+func (r CategoryRuleRequest) input() services.CategoryRuleInput {
+	enabled := true
+	if r.Enabled != nil {
+		enabled = *r.Enabled
+	}
+	return services.CategoryRuleInput{
+		CategoryID: r.CategoryID,
+		MatchType:  r.MatchType,
+		Pattern:    r.Pattern,
+		Direction:  r.Direction,
+		Priority:   r.Priority,
+		Enabled:    enabled,
+	}
+}
+
+// respondCategoryError maps category, rule and annotation errors to HTTP responses
+func respondCategoryError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrCategoryNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Category not found"})
+	case errors.Is(err, services.ErrRuleNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Category rule not found"})
+	case errors.Is(err, services.ErrAttachmentNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Attachment not found"})
+	case errors.Is(err, services.ErrTransactionNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Transaction not found"})
+	case errors.Is(err, services.ErrCategoryExists):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrAttachmentTooLarge):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidCategory),
+		errors.Is(err, services.ErrInvalidRule),
+		errors.Is(err, services.ErrInvalidTag),
+		errors.Is(err, services.ErrUnsupportedAttachment),
+		errors.Is(err, services.ErrTooManyAttachments):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		respondWalletError(c, err)
+	}
+}
+
+// getCategories lists the current user's categories
+func getCategories(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	currentUser := user.(*models.User)
+
+	categories, err := services.NewCategoryService().List(currentUser.ID)
+	if err != nil {
+		respondCategoryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, categories)
+}
+
+// createCategory adds a category
+func createCategory(c *gin.Context) {
+	var categoryReq CategoryRequest
+	if err := c.ShouldBindJSON(&categoryReq); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	currentUser := user.(*models.User)
+
+	category, err := services.NewCategoryService().Create(currentUser.ID, categoryReq.Name, categoryReq.Color)
+	if err != nil {
+		respondCategoryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":  "Category created",
+		"category": category,
+	})
+}
+
+// updateCategory renames or recolours a category
+func updateCategory(c *gin.Context) {
+	var categoryReq CategoryRequest
+	if err := c.ShouldBindJSON(&categoryReq); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	currentUser := user.(*models.User)
+
+	category, err := services.NewCategoryService().Update(currentUser.ID, c.Param("id"), categoryReq.Name, categoryReq.Color)
+	if err != nil {
+		respondCategoryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Category updated",
+		"category": category,
+	})
+}
+
+// deleteCategory removes a category and its rules
+func deleteCategory(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	currentUser := user.(*models.User)
+
+	if err := services.NewCategoryService().Delete(currentUser.ID, c.Param("id")); err != nil {
+		respondCategoryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Category deleted"})
+}
+
+// getCategoryRules lists the current user's rules in the order they are applied
+func getCategoryRules(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	currentUser := user.(*models.User)
+
+	rules, err := services.NewCategoryService().Rules(currentUser.ID)
+	if err != nil {
+		respondCategoryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, rules)
+}
+
+// createCategoryRule adds an auto-categorisation rule
+func createCategoryRule(c *gin.Context) {
+	var ruleReq CategoryRuleRequest
+	if err := c.ShouldBindJSON(&ruleReq); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	currentUser := user.(*models.User)
+
+	rule, err := services.NewCategoryService().CreateRule(currentUser.ID, ruleReq.input())
+	if err != nil {
+		respondCategoryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Rule created",
+		"rule":    rule,
+	})
+}
+
+// updateCategoryRule replaces an auto-categorisation rule
+func updateCategoryRule(c *gin.Context) {
+	var ruleReq CategoryRuleRequest
+	if err := c.ShouldBindJSON(&ruleReq); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	currentUser := user.(*models.User)
+
+	rule, err := services.NewCategoryService().UpdateRule(currentUser.ID, c.Param("id"), ruleReq.input())
+	if err != nil {
+		respondCategoryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Rule updated",
+		"rule":    rule,
+	})
+}
+
+// deleteCategoryRule removes an auto-categorisation rule
+func deleteCategoryRule(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	currentUser := user.(*models.User)
+
+	if err := services.NewCategoryService().DeleteRule(currentUser.ID, c.Param("id")); err != nil {
+		respondCategoryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Rule deleted"})
+}
+
+// applyCategoryRules runs the rules over existing transactions that have no category yet
+func applyCategoryRules(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	currentUser := user.(*models.User)
+
+	categorised, err := services.NewCategoryService().ApplyRules(currentUser.ID)
+	if err != nil {
+		respondCategoryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "Rules applied",
+		"categorised": categorised,
+	})
+}
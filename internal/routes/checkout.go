@@ -0,0 +1,280 @@
+package routes
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"securewallet/internal/middleware"
+	"securewallet/internal/models"
+	"securewallet/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupCheckoutRoutes sets up the checkout API merchants call with their API
+// keys, and the routes customers use to review and pay a checkout session
+func SetupCheckoutRoutes(router *gin.RouterGroup) {
+	sessions := router.Group("/merchant-api/checkout-sessions")
+	sessions.Use(middleware.MerchantAuthMiddleware())
+	{
+		sessions.GET("/", getCheckoutSessions)
+		sessions.POST("/", middleware.IdempotencyMiddleware(), createCheckoutSession)
+		sessions.GET("/:id", getCheckoutSession)
+		sessions.POST("/:id/capture", middleware.IdempotencyMiddleware(), captureCheckoutSession)
+		sessions.POST("/:id/cancel", cancelCheckoutSession)
+		sessions.POST("/:id/refunds", middleware.IdempotencyMiddleware(), refundCheckoutSession)
+	}
+
+	checkout := router.Group("/checkout")
+	checkout.Use(middleware.AuthMiddleware())
+	{
+		checkout.GET("/:id", viewCheckoutSession)
+		checkout.POST("/:id/confirm", middleware.IdempotencyMiddleware(), confirmCheckoutSession)
+	}
+}
+
+// CheckoutSessionRequest represents a checkout session a merchant creates
+type CheckoutSessionRequest struct {
+	Amount           models.Money `json:"amount" binding:"required"`
+	Description      string       `json:"description" binding:"max=255"`
+	Reference        string       `json:"reference" binding:"max=100"` // The merchant's order ID
+	ReturnURL        string       `json:"return_url" binding:"required,max=500"`
+	CaptureMethod    string       `json:"capture_method" binding:"omitempty,oneof=automatic manual"`
+	ExpiresInMinutes int          `json:"expires_in_minutes" binding:"omitempty,min=1"` // Defaults to 30 minutes
+}
+
+// CheckoutCaptureRequest represents how much of an authorized session to capture
+type CheckoutCaptureRequest struct {
+	Amount *models.Money `json:"amount"` // Defaults to the full amount
+}
+
+// CheckoutRefundRequest represents a refund of a completed session
+type CheckoutRefundRequest struct {
+	Amount *models.Money `json:"amount"` // Defaults to what has not been refunded yet
+	Reason string        `json:"reason" binding:"max=255"`
+}
+
+// CheckoutConfirmRequest represents the wallet a customer pays a session from
+type CheckoutConfirmRequest struct {
+	WalletID string `json:"wallet_id"` // Defaults to the customer's wallet in the session currency
+}
+
+// respondCheckoutError maps checkout service errors to HTTP responses
+func respondCheckoutError(c *gin.Context, err error) {
+	var insufficient *services.InsufficientFundsError
+	var limitErr *services.LimitExceededError
+	switch {
+	case errors.As(err, &insufficient):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Insufficient available balance",
+			"details": gin.H{
+				"required_amount":   insufficient.Required,
+				"available_balance": insufficient.Balance,
+			},
+		})
+	case errors.As(err, &limitErr):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": limitErr.Error(),
+			"limit": limitErr.Limit,
+		})
+	case errors.Is(err, services.ErrCheckoutNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Checkout session not found"})
+	case errors.Is(err, services.ErrInvalidAmount),
+		errors.Is(err, services.ErrInvalidCapture),
+		errors.Is(err, services.ErrInvalidReturnURL),
+		errors.Is(err, services.ErrInvalidCheckoutExpiry),
+		errors.Is(err, services.ErrInvalidCaptureMethod),
+		errors.Is(err, services.ErrSelfCheckout),
+		errors.Is(err, services.ErrRefundTooLarge):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrCurrencyMismatch):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Wallet currency does not match the checkout currency"})
+	case errors.Is(err, services.ErrCheckoutClosed),
+		errors.Is(err, services.ErrCheckoutExpired),
+		errors.Is(err, services.ErrCheckoutNotAuthorized),
+		errors.Is(err, services.ErrCheckoutNotCompleted),
+		errors.Is(err, services.ErrCheckoutNotCancellable),
+		errors.Is(err, services.ErrNothingToReverse):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrNoRecipientWallet):
+		c.JSON(http.StatusConflict, gin.H{"error": "Customer has no open wallet in this currency to refund to"})
+	default:
+		respondMerchantError(c, err)
+	}
+}
+
+// getCheckoutSessions lists the merchant's checkout sessions (?status=, ?limit=)
+func getCheckoutSessions(c *gin.Context) {
+	merchant := c.MustGet("merchant").(*models.Merchant)
+
+	limit := 50 // default limit
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if parsedLimit, err := strconv.Atoi(limitStr); err == nil && parsedLimit > 0 && parsedLimit <= 200 {
+			limit = parsedLimit
+		}
+	}
+
+	sessions, err := services.NewCheckoutService().List(merchant, c.Query("status"), limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch checkout sessions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, sessions)
+}
+
+// createCheckoutSession opens a checkout session and returns the URL to send the customer to
+func createCheckoutSession(c *gin.Context) {
+	var sessionReq CheckoutSessionRequest
+	if err := c.ShouldBindJSON(&sessionReq); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	merchant := c.MustGet("merchant").(*models.Merchant)
+	checkoutService := services.NewCheckoutService()
+
+	session, err := checkoutService.Create(merchant, services.CheckoutInput{
+		Amount:        sessionReq.Amount,
+		Description:   sessionReq.Description,
+		Reference:     sessionReq.Reference,
+		ReturnURL:     sessionReq.ReturnURL,
+		CaptureMethod: sessionReq.CaptureMethod,
+		ExpiresIn:     time.Duration(sessionReq.ExpiresInMinutes) * time.Minute,
+	})
+	if err != nil {
+		respondCheckoutError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"checkout_session": session,
+		"url":              checkoutService.CheckoutURL(session),
+	})
+}
+
+// getCheckoutSession returns one of the merchant's checkout sessions with its refunds
+func getCheckoutSession(c *gin.Context) {
+	merchant := c.MustGet("merchant").(*models.Merchant)
+
+	session, err := services.NewCheckoutService().Get(merchant, c.Param("id"))
+	if err != nil {
+		respondCheckoutError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, session)
+}
+
+// captureCheckoutSession books an authorized session, in full or in part
+func captureCheckoutSession(c *gin.Context) {
+	var captureReq CheckoutCaptureRequest
+	if err := c.ShouldBindJSON(&captureReq); err != nil && c.Request.ContentLength > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	merchant := c.MustGet("merchant").(*models.Merchant)
+
+	session, err := services.NewCheckoutService().Capture(merchant, c.Param("id"), captureReq.Amount)
+	if err != nil {
+		respondCheckoutError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":          "Checkout session captured",
+		"checkout_session": session,
+	})
+}
+
+// cancelCheckoutSession cancels an open session or releases an authorized one
+func cancelCheckoutSession(c *gin.Context) {
+	merchant := c.MustGet("merchant").(*models.Merchant)
+
+	session, err := services.NewCheckoutService().Cancel(merchant, c.Param("id"))
+	if err != nil {
+		respondCheckoutError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":          "Checkout session cancelled",
+		"checkout_session": session,
+	})
+}
+
+// refundCheckoutSession returns part or all of a completed session to the customer
+func refundCheckoutSession(c *gin.Context) {
+	var refundReq CheckoutRefundRequest
+	if err := c.ShouldBindJSON(&refundReq); err != nil && c.Request.ContentLength > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	merchant := c.MustGet("merchant").(*models.Merchant)
+
+	refund, err := services.NewCheckoutService().Refund(merchant, c.Param("id"), refundReq.Amount, refundReq.Reason)
+	if err != nil {
+		respondCheckoutError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Refund issued",
+		"refund":  refund,
+	})
+}
+
+// viewCheckoutSession shows the customer what a checkout session asks for before they pay it
+func viewCheckoutSession(c *gin.Context) {
+	view, err := services.NewCheckoutService().View(c.Param("id"))
+	if err != nil {
+		respondCheckoutError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, view)
+}
+
+// confirmCheckoutSession pays a checkout session from the current user's wallet
+func confirmCheckoutSession(c *gin.Context) {
+	var confirmReq CheckoutConfirmRequest
+	if err := c.ShouldBindJSON(&confirmReq); err != nil && c.Request.ContentLength > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	currentUser := user.(*models.User)
+
+	result, err := services.NewCheckoutService().Confirm(currentUser.ID, c.Param("id"), confirmReq.WalletID)
+	if err != nil {
+		respondCheckoutError(c, err)
+		return
+	}
+
+	response := gin.H{
+		"message":      "Payment successful",
+		"status":       result.Session.Status,
+		"redirect_url": result.RedirectURL,
+	}
+	if result.Transfer != nil {
+		response["sender_wallet"] = gin.H{
+			"balance":  result.Transfer.SenderWallet.Balance,
+			"currency": result.Transfer.SenderWallet.Currency,
+		}
+		response["transaction"] = result.Transfer.SenderTransaction
+	} else {
+		response["message"] = "Payment authorized"
+	}
+
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,86 @@
+package routes
+
+import (
+	"net/http"
+
+	"securewallet/internal/middleware"
+	"securewallet/internal/models"
+	"securewallet/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupDisputeRoutes sets up routes for the current user's transfer disputes
+func SetupDisputeRoutes(router *gin.RouterGroup) {
+	disputes := router.Group("/disputes")
+	disputes.Use(middleware.AuthMiddleware())
+	{
+		disputes.GET("/", getDisputes)
+		disputes.GET("/:id", getDispute)
+		disputes.POST("/:id/withdraw", withdrawDispute)
+	}
+}
+
+// getDisputes lists the disputes the current user opened
+func getDisputes(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	currentUser := user.(*models.User)
+
+	disputes, err := services.NewDisputeService().List(currentUser.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch disputes"})
+		return
+	}
+
+	c.JSON(http.StatusOK, disputes)
+}
+
+// getDispute gets one of the current user's disputes with its support ticket
+func getDispute(c *gin.Context) {
+	id := c.Param("id")
+
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	currentUser := user.(*models.User)
+
+	dispute, err := services.NewDisputeService().Get(currentUser.ID, id)
+	if err != nil {
+		respondReversalError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dispute)
+}
+
+// withdrawDispute closes one of the current user's open disputes
+func withdrawDispute(c *gin.Context) {
+	id := c.Param("id")
+
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	currentUser := user.(*models.User)
+
+	dispute, err := services.NewDisputeService().Withdraw(currentUser.ID, id)
+	if err != nil {
+		respondReversalError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Dispute withdrawn",
+		"dispute": dispute,
+	})
+}
@@ -0,0 +1,145 @@
+package routes
+
+import (
+	"errors"
+	"net/http"
+
+	"securewallet/internal/models"
+	"securewallet/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ExchangeQuoteRequest represents a request to price a currency exchange
+type ExchangeQuoteRequest struct {
+	FromWalletID string       `json:"from_wallet_id" binding:"required"`
+	ToWalletID   string       `json:"to_wallet_id" binding:"required"`
+	Amount       models.Money `json:"amount" binding:"required"` // Amount to sell, in the source currency
+}
+
+// ExchangeRequest executes a locked quote, or quotes and executes in one step
+type ExchangeRequest struct {
+	QuoteID      string       `json:"quote_id"`
+	FromWalletID string       `json:"from_wallet_id"`
+	ToWalletID   string       `json:"to_wallet_id"`
+	Amount       models.Money `json:"amount"`
+}
+
+// respondExchangeError maps exchange service errors to HTTP responses
+func respondExchangeError(c *gin.Context, err error) {
+	var insufficient *services.InsufficientFundsError
+	switch {
+	case errors.As(err, &insufficient):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Insufficient balance",
+			"details": gin.H{
+				"required_amount": insufficient.Required,
+				"current_balance": insufficient.Balance,
+			},
+		})
+	case errors.Is(err, services.ErrInvalidAmount):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrSameWallet), errors.Is(err, services.ErrSameCurrency):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Choose two wallets in different currencies"})
+	case errors.Is(err, services.ErrCurrencyMismatch):
+		c.JSON(http.StatusConflict, gin.H{"error": "Wallet currencies changed since the quote was made"})
+	case errors.Is(err, services.ErrRateUnavailable):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "No exchange rate available for this currency pair"})
+	case errors.Is(err, services.ErrQuoteNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Quote not found"})
+	case errors.Is(err, services.ErrQuoteExpired):
+		c.JSON(http.StatusGone, gin.H{"error": "Quote has expired, request a new one"})
+	case errors.Is(err, services.ErrQuoteNotPending):
+		c.JSON(http.StatusConflict, gin.H{"error": "Quote has already been used"})
+	default:
+		respondWalletError(c, err)
+	}
+}
+
+// getExchangeRates lists the current exchange rates
+func getExchangeRates(c *gin.Context) {
+	rates, err := services.NewDBRateProvider().ListRates()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch exchange rates"})
+		return
+	}
+
+	c.JSON(http.StatusOK, rates)
+}
+
+// createExchangeQuote prices an exchange and locks the rate for a short time
+func createExchangeQuote(c *gin.Context) {
+	var quoteReq ExchangeQuoteRequest
+	if err := c.ShouldBindJSON(&quoteReq); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	currentUser := user.(*models.User)
+
+	quote, err := services.NewExchangeService().Quote(services.ExchangeQuoteInput{
+		UserID:       currentUser.ID,
+		FromWalletID: quoteReq.FromWalletID,
+		ToWalletID:   quoteReq.ToWalletID,
+		Amount:       quoteReq.Amount,
+	})
+	if err != nil {
+		respondExchangeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, quote)
+}
+
+// exchange converts funds between two of the current user's wallets
+func exchange(c *gin.Context) {
+	var exchangeReq ExchangeRequest
+	if err := c.ShouldBindJSON(&exchangeReq); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	currentUser := user.(*models.User)
+	exchangeService := services.NewExchangeService()
+
+	var result *services.ExchangeResult
+	var err error
+	if exchangeReq.QuoteID != "" {
+		result, err = exchangeService.Execute(currentUser.ID, exchangeReq.QuoteID)
+	} else {
+		if exchangeReq.FromWalletID == "" || exchangeReq.ToWalletID == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Provide a quote_id or from_wallet_id, to_wallet_id and amount"})
+			return
+		}
+		result, err = exchangeService.Exchange(services.ExchangeQuoteInput{
+			UserID:       currentUser.ID,
+			FromWalletID: exchangeReq.FromWalletID,
+			ToWalletID:   exchangeReq.ToWalletID,
+			Amount:       exchangeReq.Amount,
+		})
+	}
+	if err != nil {
+		respondExchangeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "Exchange successful",
+		"quote":       result.Quote,
+		"from_wallet": result.FromWallet,
+		"to_wallet":   result.ToWallet,
+		"entry_id":    result.Entry.ID,
+	})
+}
@@ -0,0 +1,87 @@
+package routes
+
+import (
+	"errors"
+	"net/http"
+
+	"securewallet/internal/middleware"
+	"securewallet/internal/models"
+	"securewallet/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupHoldRoutes sets up routes for holds, the funds reserved in a wallet by
+// withdrawals, pending approvals and authorisations
+func SetupHoldRoutes(router *gin.RouterGroup) {
+	holds := router.Group("/wallets/:id/holds")
+	holds.Use(middleware.AuthMiddleware())
+	{
+		holds.GET("/", getHolds)
+		holds.GET("/:holdId", getHold)
+	}
+}
+
+// respondHoldError maps hold service errors to HTTP responses
+func respondHoldError(c *gin.Context, err error) {
+	var insufficient *services.InsufficientFundsError
+	switch {
+	case errors.As(err, &insufficient):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Insufficient available balance",
+			"details": gin.H{
+				"required_amount":   insufficient.Required,
+				"available_balance": insufficient.Balance,
+			},
+		})
+	case errors.Is(err, services.ErrHoldNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Hold not found"})
+	case errors.Is(err, services.ErrInvalidAmount),
+		errors.Is(err, services.ErrInvalidHoldExpiry),
+		errors.Is(err, services.ErrInvalidHoldStatus):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrHoldNotActive),
+		errors.Is(err, services.ErrHoldNotReleasable):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		respondWalletError(c, err)
+	}
+}
+
+// getHolds lists the holds on one of the current user's wallets (?status=active by default, or all)
+func getHolds(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	currentUser := user.(*models.User)
+
+	holds, err := services.NewHoldService().List(currentUser.ID, c.Param("id"), c.DefaultQuery("status", models.HoldStatusActive))
+	if err != nil {
+		respondHoldError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, holds)
+}
+
+// getHold returns one of the holds on one of the current user's wallets
+func getHold(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	currentUser := user.(*models.User)
+
+	hold, err := services.NewHoldService().Get(currentUser.ID, c.Param("id"), c.Param("holdId"))
+	if err != nil {
+		respondHoldError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, hold)
+}
@@ -0,0 +1,46 @@
+package routes
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"securewallet/internal/middleware"
+	"securewallet/internal/models"
+	"securewallet/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupInsightRoutes sets up spending insight routes
+func SetupInsightRoutes(router *gin.RouterGroup) {
+	insights := router.Group("/insights")
+	insights.Use(middleware.AuthMiddleware())
+	{
+		insights.GET("", getInsights)
+	}
+}
+
+// getInsights returns the current user's income and spending insights for a month
+func getInsights(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	currentUser := user.(*models.User)
+
+	insights, err := services.NewInsightService().Insights(currentUser.ID, c.Query("month"), strings.ToUpper(c.Query("currency")), time.Now())
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidInsightMonth) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		respondWalletError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, insights)
+}
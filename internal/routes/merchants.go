@@ -0,0 +1,310 @@
+package routes
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"securewallet/internal/config"
+	"securewallet/internal/middleware"
+	"securewallet/internal/models"
+	"securewallet/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// SetupMerchantRoutes sets up routes for managing merchant profiles, their API
+// keys and webhook settings
+func SetupMerchantRoutes(router *gin.RouterGroup) {
+	merchants := router.Group("/merchants")
+	merchants.Use(middleware.AuthMiddleware())
+	{
+		merchants.GET("/", getMerchants)
+		merchants.POST("/", createMerchant)
+		merchants.GET("/:id", getMerchant)
+		merchants.PUT("/:id", updateMerchant)
+		merchants.POST("/:id/webhook-secret", rotateWebhookSecret)
+		merchants.GET("/:id/webhooks", getWebhookDeliveries)
+		merchants.GET("/:id/keys", getMerchantAPIKeys)
+		merchants.POST("/:id/keys", createMerchantAPIKey)
+		merchants.DELETE("/:id/keys/:keyId", revokeMerchantAPIKey)
+	}
+}
+
+// MerchantRequest represents a merchant profile to create or update
+type MerchantRequest struct {
+	Name       string `json:"name" binding:"required,max=100"`
+	Website    string `json:"website" binding:"max=255"`
+	WalletID   string `json:"wallet_id"` // Receives checkout payments, defaults to the user's default wallet
+	WebhookURL string `json:"webhook_url" binding:"max=255"`
+}
+
+// MerchantAPIKeyRequest represents an API key to create
+type MerchantAPIKeyRequest struct {
+	Name string `json:"name" binding:"max=100"`
+}
+
+// respondMerchantError maps merchant service errors to HTTP responses
+func respondMerchantError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrMerchantNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Merchant not found"})
+	case errors.Is(err, services.ErrAPIKeyNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "API key not found"})
+	case errors.Is(err, services.ErrInvalidMerchant),
+		errors.Is(err, services.ErrInvalidWebhookURL):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrTooManyMerchants),
+		errors.Is(err, services.ErrTooManyAPIKeys):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrMerchantSuspended):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Merchant is suspended"})
+	default:
+		respondWalletError(c, err)
+	}
+}
+
+// recordMerchantAudit writes a merchant profile or API key change to the audit log
+func recordMerchantAudit(c *gin.Context, userID uuid.UUID, action, details string) {
+	config.GetDB().Create(&models.AuditLog{
+		UserID:    userID,
+		Action:    action,
+		Resource:  "merchant",
+		Details:   details,
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
+
+// getMerchants lists the current user's merchant profiles
+func getMerchants(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	currentUser := user.(*models.User)
+
+	merchants, err := services.NewMerchantService().List(currentUser.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch merchants"})
+		return
+	}
+
+	c.JSON(http.StatusOK, merchants)
+}
+
+// createMerchant creates a merchant profile for the current user. The webhook
+// signing secret is only returned here and when it is rotated.
+func createMerchant(c *gin.Context) {
+	var merchantReq MerchantRequest
+	if err := c.ShouldBindJSON(&merchantReq); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	currentUser := user.(*models.User)
+
+	merchant, secret, err := services.NewMerchantService().Create(currentUser.ID, services.MerchantInput{
+		Name:       merchantReq.Name,
+		Website:    merchantReq.Website,
+		WalletID:   merchantReq.WalletID,
+		WebhookURL: merchantReq.WebhookURL,
+	})
+	if err != nil {
+		respondMerchantError(c, err)
+		return
+	}
+
+	recordMerchantAudit(c, currentUser.ID, "MERCHANT_CREATE",
+		fmt.Sprintf("Created merchant %s (%s) receiving into wallet %s", merchant.ID, merchant.Name, merchant.WalletID))
+
+	c.JSON(http.StatusCreated, gin.H{
+		"merchant":       merchant,
+		"webhook_secret": secret,
+	})
+}
+
+// getMerchant returns one of the current user's merchant profiles
+func getMerchant(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	currentUser := user.(*models.User)
+
+	merchant, err := services.NewMerchantService().Get(currentUser.ID, c.Param("id"))
+	if err != nil {
+		respondMerchantError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, merchant)
+}
+
+// updateMerchant changes one of the current user's merchant profiles
+func updateMerchant(c *gin.Context) {
+	var merchantReq MerchantRequest
+	if err := c.ShouldBindJSON(&merchantReq); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	currentUser := user.(*models.User)
+
+	merchant, err := services.NewMerchantService().Update(currentUser.ID, c.Param("id"), services.MerchantInput{
+		Name:       merchantReq.Name,
+		Website:    merchantReq.Website,
+		WalletID:   merchantReq.WalletID,
+		WebhookURL: merchantReq.WebhookURL,
+	})
+	if err != nil {
+		respondMerchantError(c, err)
+		return
+	}
+
+	recordMerchantAudit(c, currentUser.ID, "MERCHANT_UPDATE",
+		fmt.Sprintf("Updated merchant %s (%s), wallet %s, webhook URL %q", merchant.ID, merchant.Name, merchant.WalletID, merchant.WebhookURL))
+
+	c.JSON(http.StatusOK, merchant)
+}
+
+// rotateWebhookSecret replaces a merchant's webhook signing secret
+func rotateWebhookSecret(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	currentUser := user.(*models.User)
+
+	merchant, secret, err := services.NewMerchantService().RotateWebhookSecret(currentUser.ID, c.Param("id"))
+	if err != nil {
+		respondMerchantError(c, err)
+		return
+	}
+
+	recordMerchantAudit(c, currentUser.ID, "MERCHANT_WEBHOOK_SECRET_ROTATE",
+		fmt.Sprintf("Rotated the webhook secret of merchant %s", merchant.ID))
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":        "Webhook secret rotated",
+		"webhook_secret": secret,
+	})
+}
+
+// getWebhookDeliveries lists a merchant's most recent webhook deliveries
+func getWebhookDeliveries(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	currentUser := user.(*models.User)
+
+	limit := 50 // default limit
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if parsedLimit, err := strconv.Atoi(limitStr); err == nil && parsedLimit > 0 && parsedLimit <= 200 {
+			limit = parsedLimit
+		}
+	}
+
+	deliveries, err := services.NewMerchantService().ListWebhookDeliveries(currentUser.ID, c.Param("id"), limit)
+	if err != nil {
+		respondMerchantError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, deliveries)
+}
+
+// getMerchantAPIKeys lists a merchant's API keys
+func getMerchantAPIKeys(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	currentUser := user.(*models.User)
+
+	keys, err := services.NewMerchantService().ListAPIKeys(currentUser.ID, c.Param("id"))
+	if err != nil {
+		respondMerchantError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, keys)
+}
+
+// createMerchantAPIKey issues an API key for the checkout API. The key is only
+// shown in this response.
+func createMerchantAPIKey(c *gin.Context) {
+	var keyReq MerchantAPIKeyRequest
+	if err := c.ShouldBindJSON(&keyReq); err != nil && c.Request.ContentLength > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	currentUser := user.(*models.User)
+
+	apiKey, key, err := services.NewMerchantService().CreateAPIKey(currentUser.ID, c.Param("id"), keyReq.Name)
+	if err != nil {
+		respondMerchantError(c, err)
+		return
+	}
+
+	recordMerchantAudit(c, currentUser.ID, "MERCHANT_API_KEY_CREATE",
+		fmt.Sprintf("Created API key %s (%s) for merchant %s", apiKey.ID, apiKey.Prefix, apiKey.MerchantID))
+
+	c.JSON(http.StatusCreated, gin.H{
+		"api_key": apiKey,
+		"key":     key,
+	})
+}
+
+// revokeMerchantAPIKey revokes one of a merchant's API keys
+func revokeMerchantAPIKey(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	currentUser := user.(*models.User)
+
+	if err := services.NewMerchantService().RevokeAPIKey(currentUser.ID, c.Param("id"), c.Param("keyId")); err != nil {
+		respondMerchantError(c, err)
+		return
+	}
+
+	recordMerchantAudit(c, currentUser.ID, "MERCHANT_API_KEY_REVOKE",
+		fmt.Sprintf("Revoked API key %s of merchant %s", c.Param("keyId"), c.Param("id")))
+
+	c.JSON(http.StatusOK, gin.H{"message": "API key revoked"})
+}
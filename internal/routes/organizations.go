@@ -0,0 +1,473 @@
+package routes
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"securewallet/internal/config"
+	"securewallet/internal/middleware"
+	"securewallet/internal/models"
+	"securewallet/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// SetupOrganizationRoutes sets up routes for organisations, their approval policy
+// and the payment instructions waiting for approval
+func SetupOrganizationRoutes(router *gin.RouterGroup) {
+	orgs := router.Group("/organizations")
+	orgs.Use(middleware.AuthMiddleware())
+	{
+		orgs.GET("/", getOrganizations)
+		orgs.POST("/", createOrganization)
+		orgs.GET("/:id", getOrganization)
+		orgs.POST("/:id/members", addOrganizationMember)
+		orgs.PUT("/:id/members/:memberId", updateOrganizationMember)
+		orgs.DELETE("/:id/members/:memberId", removeOrganizationMember)
+		orgs.PUT("/:id/policy", updateApprovalPolicy)
+		orgs.POST("/:id/wallets", createOrganizationWallet)
+		orgs.GET("/:id/instructions", getPaymentInstructions)
+		orgs.GET("/:id/instructions/:instructionId", getPaymentInstruction)
+		orgs.POST("/:id/instructions/:instructionId/approve", approvePaymentInstruction)
+		orgs.POST("/:id/instructions/:instructionId/reject", rejectPaymentInstruction)
+	}
+}
+
+// OrganizationRequest represents an organisation to create
+type OrganizationRequest struct {
+	Name string `json:"name" binding:"required,max=100"`
+}
+
+// OrganizationMemberRequest represents a user to add to an organisation
+type OrganizationMemberRequest struct {
+	User string `json:"user" binding:"required,max=100"` // Email or username
+	Role string `json:"role" binding:"required,oneof=admin approver maker viewer"`
+}
+
+// OrganizationRoleRequest represents a change to a member's role
+type OrganizationRoleRequest struct {
+	Role string `json:"role" binding:"required,oneof=admin approver maker viewer"`
+}
+
+// ApprovalPolicyRequest represents an organisation's approval policy
+type ApprovalPolicyRequest struct {
+	DefaultApprovals int                   `json:"default_approvals" binding:"required,min=1,max=5"`
+	Rules            []ApprovalRuleRequest `json:"rules" binding:"max=10,dive"`
+}
+
+// ApprovalRuleRequest requires more approvals for payments over a threshold
+type ApprovalRuleRequest struct {
+	Currency          string       `json:"currency" binding:"required,len=3"`
+	Threshold         models.Money `json:"threshold"` // Payments over this amount need the rule's approvals
+	RequiredApprovals int          `json:"required_approvals" binding:"required,min=1,max=5"`
+}
+
+// OrganizationWalletRequest represents an organisation wallet to open
+type OrganizationWalletRequest struct {
+	Name     string `json:"name" binding:"max=100"`
+	Currency string `json:"currency" binding:"required,len=3"`
+}
+
+// InstructionDecisionRequest represents an optional comment on an approval or rejection
+type InstructionDecisionRequest struct {
+	Comment string `json:"comment" binding:"max=255"`
+}
+
+// respondOrganizationError maps organisation and payment instruction errors to HTTP responses
+func respondOrganizationError(c *gin.Context, err error) {
+	var insufficient *services.InsufficientFundsError
+	switch {
+	case errors.As(err, &insufficient):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Insufficient available balance",
+			"details": gin.H{
+				"required_amount":   insufficient.Required,
+				"available_balance": insufficient.Balance,
+			},
+		})
+	case errors.Is(err, services.ErrOrganizationNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Organisation not found"})
+	case errors.Is(err, services.ErrOrgMemberNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Member not found"})
+	case errors.Is(err, services.ErrInstructionNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Payment instruction not found"})
+	case errors.Is(err, services.ErrRecipientNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+	case errors.Is(err, services.ErrOrganizationPermission),
+		errors.Is(err, services.ErrSelfApproval):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidOrganizationName),
+		errors.Is(err, services.ErrInvalidOrgRole),
+		errors.Is(err, services.ErrInvalidApprovalPolicy),
+		errors.Is(err, services.ErrInvalidInstructionStatus),
+		errors.Is(err, services.ErrInvalidAmount),
+		errors.Is(err, services.ErrSameWallet),
+		errors.Is(err, services.ErrCurrencyMismatch):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrAlreadyOrgMember),
+		errors.Is(err, services.ErrTooManyOrgMembers),
+		errors.Is(err, services.ErrLastOrgAdmin),
+		errors.Is(err, services.ErrInstructionNotPending),
+		errors.Is(err, services.ErrInstructionDecided):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		respondWalletError(c, err)
+	}
+}
+
+// recordOrganizationAudit writes an organisation or payment instruction change to the audit log
+func recordOrganizationAudit(c *gin.Context, userID uuid.UUID, action, resource, details string) {
+	config.GetDB().Create(&models.AuditLog{
+		UserID:    userID,
+		Action:    action,
+		Resource:  resource,
+		Details:   details,
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
+
+// getOrganizations lists the organisations the current user belongs to
+func getOrganizations(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	currentUser := user.(*models.User)
+
+	orgs, err := services.NewOrganizationService().List(currentUser.ID)
+	if err != nil {
+		respondOrganizationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, orgs)
+}
+
+// createOrganization sets up an organisation with the current user as admin
+func createOrganization(c *gin.Context) {
+	var orgReq OrganizationRequest
+	if err := c.ShouldBindJSON(&orgReq); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	currentUser := user.(*models.User)
+
+	org, err := services.NewOrganizationService().Create(currentUser.ID, orgReq.Name)
+	if err != nil {
+		respondOrganizationError(c, err)
+		return
+	}
+
+	recordOrganizationAudit(c, currentUser.ID, "ORG_CREATE", "organization",
+		fmt.Sprintf("Created organisation %s (%s)", org.ID, org.Name))
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":      "Organisation created",
+		"organization": org,
+	})
+}
+
+// getOrganization returns an organisation with its members, policy and wallets
+func getOrganization(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	currentUser := user.(*models.User)
+
+	org, err := services.NewOrganizationService().Get(currentUser.ID, c.Param("id"))
+	if err != nil {
+		respondOrganizationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, org)
+}
+
+// addOrganizationMember gives a registered user a role in the organisation
+func addOrganizationMember(c *gin.Context) {
+	var memberReq OrganizationMemberRequest
+	if err := c.ShouldBindJSON(&memberReq); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	currentUser := user.(*models.User)
+
+	member, err := services.NewOrganizationService().AddMember(currentUser.ID, c.Param("id"), memberReq.User, memberReq.Role)
+	if err != nil {
+		respondOrganizationError(c, err)
+		return
+	}
+
+	recordOrganizationAudit(c, currentUser.ID, "ORG_MEMBER_ADD", "organization",
+		fmt.Sprintf("Added user %s to organisation %s as %s", member.UserID, member.OrganizationID, member.Role))
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Member added",
+		"member":  member,
+	})
+}
+
+// updateOrganizationMember changes a member's role
+func updateOrganizationMember(c *gin.Context) {
+	var roleReq OrganizationRoleRequest
+	if err := c.ShouldBindJSON(&roleReq); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	currentUser := user.(*models.User)
+
+	member, err := services.NewOrganizationService().UpdateMember(currentUser.ID, c.Param("id"), c.Param("memberId"), roleReq.Role)
+	if err != nil {
+		respondOrganizationError(c, err)
+		return
+	}
+
+	recordOrganizationAudit(c, currentUser.ID, "ORG_MEMBER_UPDATE", "organization",
+		fmt.Sprintf("Changed role of user %s in organisation %s to %s", member.UserID, member.OrganizationID, member.Role))
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Member updated",
+		"member":  member,
+	})
+}
+
+// removeOrganizationMember removes a member from the organisation, or lets a member leave
+func removeOrganizationMember(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	currentUser := user.(*models.User)
+
+	if err := services.NewOrganizationService().RemoveMember(currentUser.ID, c.Param("id"), c.Param("memberId")); err != nil {
+		respondOrganizationError(c, err)
+		return
+	}
+
+	recordOrganizationAudit(c, currentUser.ID, "ORG_MEMBER_REMOVE", "organization",
+		fmt.Sprintf("Removed member %s from organisation %s", c.Param("memberId"), c.Param("id")))
+
+	c.JSON(http.StatusOK, gin.H{"message": "Member removed"})
+}
+
+// updateApprovalPolicy replaces the organisation's approval policy
+func updateApprovalPolicy(c *gin.Context) {
+	var policyReq ApprovalPolicyRequest
+	if err := c.ShouldBindJSON(&policyReq); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	currentUser := user.(*models.User)
+
+	rules := make([]services.ApprovalRuleInput, 0, len(policyReq.Rules))
+	for _, rule := range policyReq.Rules {
+		rules = append(rules, services.ApprovalRuleInput{
+			Currency:          rule.Currency,
+			Threshold:         rule.Threshold,
+			RequiredApprovals: rule.RequiredApprovals,
+		})
+	}
+
+	org, err := services.NewOrganizationService().SetPolicy(currentUser.ID, c.Param("id"), policyReq.DefaultApprovals, rules)
+	if err != nil {
+		respondOrganizationError(c, err)
+		return
+	}
+
+	details := fmt.Sprintf("Set approval policy of organisation %s: %d by default", org.ID, org.DefaultApprovals)
+	for _, rule := range org.ApprovalRules {
+		details += fmt.Sprintf(", %d over %s", rule.RequiredApprovals, rule.Threshold)
+	}
+	recordOrganizationAudit(c, currentUser.ID, "ORG_POLICY_UPDATE", "organization", details)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":      "Approval policy updated",
+		"organization": org,
+	})
+}
+
+// createOrganizationWallet opens a wallet owned by the organisation
+func createOrganizationWallet(c *gin.Context) {
+	var walletReq OrganizationWalletRequest
+	if err := c.ShouldBindJSON(&walletReq); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	currentUser := user.(*models.User)
+
+	wallet, err := services.NewOrganizationService().CreateWallet(currentUser.ID, c.Param("id"), walletReq.Name, walletReq.Currency)
+	if err != nil {
+		respondOrganizationError(c, err)
+		return
+	}
+
+	recordOrganizationAudit(c, currentUser.ID, "ORG_WALLET_CREATE", "wallet",
+		fmt.Sprintf("Opened %s wallet %s for organisation %s", wallet.Currency, wallet.ID, c.Param("id")))
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Wallet created",
+		"wallet":  wallet,
+	})
+}
+
+// getPaymentInstructions lists an organisation's payment instructions, pending ones by default
+func getPaymentInstructions(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	currentUser := user.(*models.User)
+
+	status := c.DefaultQuery("status", models.InstructionStatusPending)
+	instructions, err := services.NewPaymentInstructionService().List(currentUser.ID, c.Param("id"), status)
+	if err != nil {
+		respondOrganizationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, instructions)
+}
+
+// getPaymentInstruction returns one payment instruction with its approvals
+func getPaymentInstruction(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	currentUser := user.(*models.User)
+
+	instruction, err := services.NewPaymentInstructionService().Get(currentUser.ID, c.Param("id"), c.Param("instructionId"))
+	if err != nil {
+		respondOrganizationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, instruction)
+}
+
+// approvePaymentInstruction approves an instruction, executing it once enough approvers agree
+func approvePaymentInstruction(c *gin.Context) {
+	decidePaymentInstruction(c, true)
+}
+
+// rejectPaymentInstruction rejects an instruction, or lets its maker withdraw it
+func rejectPaymentInstruction(c *gin.Context) {
+	decidePaymentInstruction(c, false)
+}
+
+// decidePaymentInstruction handles both approvals and rejections and audits the outcome
+func decidePaymentInstruction(c *gin.Context, approve bool) {
+	var decisionReq InstructionDecisionRequest
+	if err := c.ShouldBindJSON(&decisionReq); err != nil && c.Request.ContentLength > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	currentUser := user.(*models.User)
+	instructionService := services.NewPaymentInstructionService()
+
+	decide, action, message := instructionService.Approve, "INSTRUCTION_APPROVE", "Payment instruction approved"
+	if !approve {
+		decide, action, message = instructionService.Reject, "INSTRUCTION_REJECT", "Payment instruction rejected"
+	}
+	instruction, err := decide(currentUser.ID, c.Param("id"), c.Param("instructionId"), decisionReq.Comment)
+	if err != nil {
+		respondOrganizationError(c, err)
+		return
+	}
+
+	recordOrganizationAudit(c, currentUser.ID, action, "payment_instruction",
+		fmt.Sprintf("Instruction %s for %s from wallet %s: %d of %d approvals", instruction.ID, instruction.Amount,
+			instruction.WalletID, instruction.Approvals, instruction.RequiredApprovals))
+
+	switch instruction.Status {
+	case models.InstructionStatusExecuted:
+		message = "Payment instruction approved and executed"
+		recordOrganizationAudit(c, currentUser.ID, "INSTRUCTION_EXECUTE", "payment_instruction",
+			fmt.Sprintf("Instruction %s executed: %s from wallet %s to wallet %s, journal entry %s", instruction.ID,
+				instruction.Amount, instruction.WalletID, instruction.RecipientWalletID, instruction.JournalEntryID))
+	case models.InstructionStatusFailed:
+		message = "Payment instruction approved but the transfer failed"
+		recordOrganizationAudit(c, currentUser.ID, "INSTRUCTION_FAIL", "payment_instruction",
+			fmt.Sprintf("Instruction %s failed: %s", instruction.ID, instruction.LastError))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     message,
+		"instruction": instruction,
+	})
+}
+
+// createPaymentInstruction records a transfer out of an organisation wallet as an
+// instruction that waits for approval instead of moving money straight away
+func createPaymentInstruction(c *gin.Context, currentUser *models.User, input services.InstructionInput) {
+	instruction, err := services.NewPaymentInstructionService().Create(input)
+	if err != nil {
+		respondOrganizationError(c, err)
+		return
+	}
+
+	recordOrganizationAudit(c, currentUser.ID, "INSTRUCTION_CREATE", "payment_instruction",
+		fmt.Sprintf("Instruction %s created: %s from wallet %s to %s, %d approvals required", instruction.ID,
+			instruction.Amount, instruction.WalletID, input.Recipient.Username, instruction.RequiredApprovals))
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message":     "Transfer is waiting for approval",
+		"instruction": instruction,
+	})
+}
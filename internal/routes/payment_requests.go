@@ -0,0 +1,364 @@
+package routes
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"securewallet/internal/middleware"
+	"securewallet/internal/models"
+	"securewallet/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupPaymentRequestRoutes sets up payment request and payment link routes
+func SetupPaymentRequestRoutes(router *gin.RouterGroup) {
+	requests := router.Group("/payment-requests")
+	requests.Use(middleware.AuthMiddleware())
+	{
+		requests.GET("/", getPaymentRequests)
+		requests.POST("/", createPaymentRequest)
+		requests.POST("/links", createPaymentLink)
+		requests.GET("/links/:token", getPaymentLink)
+		requests.POST("/links/:token/pay", middleware.IdempotencyMiddleware(), payPaymentLink)
+		requests.GET("/:id", getPaymentRequest)
+		requests.POST("/:id/pay", middleware.IdempotencyMiddleware(), payPaymentRequest)
+		requests.POST("/:id/decline", declinePaymentRequest)
+		requests.POST("/:id/cancel", cancelPaymentRequest)
+	}
+}
+
+// PaymentRequestRequest represents a request for money from another user
+type PaymentRequestRequest struct {
+	WalletID         string       `json:"wallet_id"`                        // Receiving wallet, defaults to the user's default wallet
+	Payer            string       `json:"payer" binding:"required,max=100"` // Email or username
+	Amount           models.Money `json:"amount" binding:"required"`
+	Memo             string       `json:"memo" binding:"max=255"`
+	ExpiresInMinutes int          `json:"expires_in_minutes" binding:"omitempty,min=1"` // Defaults to 7 days
+}
+
+// PaymentLinkRequest represents a shareable payment link to create
+type PaymentLinkRequest struct {
+	WalletID         string       `json:"wallet_id"` // Receiving wallet, defaults to the user's default wallet
+	Amount           models.Money `json:"amount" binding:"required"`
+	Memo             string       `json:"memo" binding:"max=255"`
+	ExpiresInMinutes int          `json:"expires_in_minutes" binding:"omitempty,min=1"` // Defaults to 7 days
+}
+
+// PayRequest represents the wallet to pay a request or link from
+type PayRequest struct {
+	WalletID string `json:"wallet_id"` // Defaults to the payer's wallet in the requested currency
+}
+
+// respondPaymentRequestError maps payment request service errors to HTTP responses
+func respondPaymentRequestError(c *gin.Context, err error) {
+	var insufficient *services.InsufficientFundsError
+	var limitErr *services.LimitExceededError
+	switch {
+	case errors.As(err, &insufficient):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Insufficient balance",
+			"details": gin.H{
+				"total_amount":    insufficient.Required,
+				"current_balance": insufficient.Balance,
+			},
+		})
+	case errors.As(err, &limitErr):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": limitErr.Error(),
+			"limit": limitErr.Limit,
+		})
+	case errors.Is(err, services.ErrInvalidAmount),
+		errors.Is(err, services.ErrInvalidExpiry),
+		errors.Is(err, services.ErrSelfPaymentRequest):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrCurrencyMismatch):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Wallet currency does not match the requested currency"})
+	case errors.Is(err, services.ErrSameWallet):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot pay yourself"})
+	case errors.Is(err, services.ErrRecipientNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Payer not found"})
+	case errors.Is(err, services.ErrNoRecipientWallet):
+		c.JSON(http.StatusConflict, gin.H{"error": "Requester has no open wallet in this currency"})
+	case errors.Is(err, services.ErrPaymentRequestNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Payment request not found"})
+	case errors.Is(err, services.ErrInvalidPaymentLink):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Invalid payment link"})
+	case errors.Is(err, services.ErrPaymentRequestClosed),
+		errors.Is(err, services.ErrPaymentRequestExpired):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		respondWalletError(c, err)
+	}
+}
+
+// getPaymentRequests lists the current user's payment requests (?role=incoming|outgoing, ?status=)
+func getPaymentRequests(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	currentUser := user.(*models.User)
+
+	limit := 50 // default limit
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if parsedLimit, err := strconv.Atoi(limitStr); err == nil && parsedLimit > 0 {
+			limit = parsedLimit
+		}
+	}
+
+	requests, err := services.NewPaymentRequestService().List(currentUser.ID, c.Query("role"), c.Query("status"), limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch payment requests"})
+		return
+	}
+
+	c.JSON(http.StatusOK, requests)
+}
+
+// createPaymentRequest asks another user to pay the current user
+func createPaymentRequest(c *gin.Context) {
+	var requestReq PaymentRequestRequest
+	if err := c.ShouldBindJSON(&requestReq); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	currentUser := user.(*models.User)
+
+	request, err := services.NewPaymentRequestService().Create(services.PaymentRequestInput{
+		RequesterID: currentUser.ID,
+		WalletID:    requestReq.WalletID,
+		Payer:       requestReq.Payer,
+		Amount:      requestReq.Amount,
+		Memo:        requestReq.Memo,
+		ExpiresIn:   time.Duration(requestReq.ExpiresInMinutes) * time.Minute,
+	})
+	if err != nil {
+		respondPaymentRequestError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, request)
+}
+
+// createPaymentLink creates a signed payment link that any logged-in user can pay
+func createPaymentLink(c *gin.Context) {
+	var linkReq PaymentLinkRequest
+	if err := c.ShouldBindJSON(&linkReq); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	currentUser := user.(*models.User)
+	requestService := services.NewPaymentRequestService()
+
+	request, err := requestService.Create(services.PaymentRequestInput{
+		RequesterID: currentUser.ID,
+		WalletID:    linkReq.WalletID,
+		Amount:      linkReq.Amount,
+		Memo:        linkReq.Memo,
+		ExpiresIn:   time.Duration(linkReq.ExpiresInMinutes) * time.Minute,
+	})
+	if err != nil {
+		respondPaymentRequestError(c, err)
+		return
+	}
+
+	link, err := requestService.Link(request)
+	if err != nil {
+		log.Printf("Failed to sign payment link %s: %v", request.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create payment link"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"payment_request": request,
+		"link":            link,
+	})
+}
+
+// getPaymentLink shows what a payment link asks for before it is paid
+func getPaymentLink(c *gin.Context) {
+	request, err := services.NewPaymentRequestService().ResolveLink(c.Param("token"))
+	if err != nil {
+		respondPaymentRequestError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"id":                 request.ID,
+		"requester_username": request.RequesterUsername,
+		"amount":             request.Amount,
+		"memo":               request.Memo,
+		"status":             request.Status,
+		"expires_at":         request.ExpiresAt,
+	})
+}
+
+// payPaymentLink pays a payment link from the current user's wallet
+func payPaymentLink(c *gin.Context) {
+	var payReq PayRequest
+	if err := c.ShouldBindJSON(&payReq); err != nil && c.Request.ContentLength > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	currentUser := user.(*models.User)
+
+	result, err := services.NewPaymentRequestService().PayLink(currentUser.ID, c.Param("token"), payReq.WalletID)
+	if err != nil {
+		respondPaymentRequestError(c, err)
+		return
+	}
+
+	respondPayment(c, result)
+}
+
+// getPaymentRequest gets a payment request the current user created or was asked to pay
+func getPaymentRequest(c *gin.Context) {
+	id := c.Param("id")
+
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	currentUser := user.(*models.User)
+	requestService := services.NewPaymentRequestService()
+
+	request, err := requestService.Get(currentUser.ID, id)
+	if err != nil {
+		respondPaymentRequestError(c, err)
+		return
+	}
+
+	// The requester can fetch the shareable form of their links again
+	if request.Kind == models.PaymentRequestKindLink && request.RequesterID == currentUser.ID {
+		link, err := requestService.Link(request)
+		if err != nil {
+			log.Printf("Failed to sign payment link %s: %v", request.ID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load payment link"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"payment_request": request,
+			"link":            link,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"payment_request": request})
+}
+
+// payPaymentRequest pays a request addressed to the current user
+func payPaymentRequest(c *gin.Context) {
+	var payReq PayRequest
+	if err := c.ShouldBindJSON(&payReq); err != nil && c.Request.ContentLength > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	currentUser := user.(*models.User)
+
+	result, err := services.NewPaymentRequestService().Pay(currentUser.ID, c.Param("id"), payReq.WalletID)
+	if err != nil {
+		respondPaymentRequestError(c, err)
+		return
+	}
+
+	respondPayment(c, result)
+}
+
+// respondPayment writes the response for a paid request or link
+func respondPayment(c *gin.Context, result *services.PaymentResult) {
+	c.JSON(http.StatusOK, gin.H{
+		"message":         "Payment successful",
+		"payment_request": result.Request,
+		"sender_wallet": gin.H{
+			"balance":  result.Transfer.SenderWallet.Balance,
+			"currency": result.Transfer.SenderWallet.Currency,
+		},
+		"fee":         result.Transfer.Fee,
+		"total":       result.Transfer.Total,
+		"transaction": result.Transfer.SenderTransaction,
+	})
+}
+
+// declinePaymentRequest declines a request addressed to the current user
+func declinePaymentRequest(c *gin.Context) {
+	id := c.Param("id")
+
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	currentUser := user.(*models.User)
+
+	request, err := services.NewPaymentRequestService().Decline(currentUser.ID, id)
+	if err != nil {
+		respondPaymentRequestError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":         "Payment request declined",
+		"payment_request": request,
+	})
+}
+
+// cancelPaymentRequest cancels a request or link the current user created
+func cancelPaymentRequest(c *gin.Context) {
+	id := c.Param("id")
+
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	currentUser := user.(*models.User)
+
+	request, err := services.NewPaymentRequestService().Cancel(currentUser.ID, id)
+	if err != nil {
+		respondPaymentRequestError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":         "Payment request cancelled",
+		"payment_request": request,
+	})
+}
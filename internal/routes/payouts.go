@@ -0,0 +1,224 @@
+package routes
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"securewallet/internal/middleware"
+	"securewallet/internal/models"
+	"securewallet/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupExternalAccountRoutes sets up linked bank account routes
+func SetupExternalAccountRoutes(router *gin.RouterGroup) {
+	accounts := router.Group("/external-accounts")
+	accounts.Use(middleware.AuthMiddleware())
+	{
+		accounts.GET("/", getExternalAccounts)
+		accounts.POST("/", createExternalAccount)
+		accounts.DELETE("/:id", deleteExternalAccount)
+	}
+}
+
+// ExternalAccountRequest represents a bank account to link
+type ExternalAccountRequest struct {
+	Type          string `json:"type" binding:"required,oneof=iban us_ach"`
+	HolderName    string `json:"holder_name" binding:"required,max=100"`
+	BankName      string `json:"bank_name" binding:"max=100"`
+	AccountNumber string `json:"account_number" binding:"required"` // IBAN for iban accounts
+	RoutingNumber string `json:"routing_number"`
+	Currency      string `json:"currency" binding:"required,len=3"`
+}
+
+// WithdrawRequest represents withdrawal request data
+type WithdrawRequest struct {
+	WalletID          string       `json:"wallet_id"` // Defaults to the user's default wallet
+	ExternalAccountID string       `json:"external_account_id" binding:"required"`
+	Amount            models.Money `json:"amount" binding:"required"`
+}
+
+// respondPayoutError maps payout service errors to HTTP responses
+func respondPayoutError(c *gin.Context, err error) {
+	var insufficient *services.InsufficientFundsError
+	switch {
+	case errors.As(err, &insufficient):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Insufficient balance",
+			"details": gin.H{
+				"required_amount": insufficient.Required,
+				"current_balance": insufficient.Balance,
+			},
+		})
+	case errors.Is(err, services.ErrInvalidAmount),
+		errors.Is(err, services.ErrInvalidIBAN),
+		errors.Is(err, services.ErrInvalidRoutingNumber),
+		errors.Is(err, services.ErrInvalidAccountNumber):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrCurrencyMismatch):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Bank account and wallet use different currencies"})
+	case errors.Is(err, services.ErrExternalAccountNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Bank account not found"})
+	case errors.Is(err, services.ErrExternalAccountInUse):
+		c.JSON(http.StatusConflict, gin.H{"error": "Bank account has withdrawals in progress"})
+	case errors.Is(err, services.ErrPayoutNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Withdrawal not found"})
+	case errors.Is(err, services.ErrUnsupportedCurrency):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		respondWalletError(c, err)
+	}
+}
+
+// getExternalAccounts lists the current user's linked bank accounts
+func getExternalAccounts(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	currentUser := user.(*models.User)
+
+	accounts, err := services.NewPayoutService().ListExternalAccounts(currentUser.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch bank accounts"})
+		return
+	}
+
+	c.JSON(http.StatusOK, accounts)
+}
+
+// createExternalAccount links a bank account after validating its checksum
+func createExternalAccount(c *gin.Context) {
+	var accountReq ExternalAccountRequest
+	if err := c.ShouldBindJSON(&accountReq); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	currentUser := user.(*models.User)
+
+	account, err := services.NewPayoutService().AddExternalAccount(currentUser.ID, services.ExternalAccountInput{
+		Type:          accountReq.Type,
+		HolderName:    accountReq.HolderName,
+		BankName:      accountReq.BankName,
+		AccountNumber: accountReq.AccountNumber,
+		RoutingNumber: accountReq.RoutingNumber,
+		Currency:      accountReq.Currency,
+	})
+	if err != nil {
+		respondPayoutError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, account)
+}
+
+// deleteExternalAccount unlinks a bank account
+func deleteExternalAccount(c *gin.Context) {
+	id := c.Param("id")
+
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	currentUser := user.(*models.User)
+
+	if err := services.NewPayoutService().RemoveExternalAccount(currentUser.ID, id); err != nil {
+		respondPayoutError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Bank account removed"})
+}
+
+// withdraw holds funds in a wallet and queues a payout to a linked bank account
+func withdraw(c *gin.Context) {
+	var withdrawReq WithdrawRequest
+	if err := c.ShouldBindJSON(&withdrawReq); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	currentUser := user.(*models.User)
+
+	payout, err := services.NewPayoutService().RequestWithdrawal(services.WithdrawalInput{
+		UserID:            currentUser.ID,
+		WalletID:          withdrawReq.WalletID,
+		ExternalAccountID: withdrawReq.ExternalAccountID,
+		Amount:            withdrawReq.Amount,
+	})
+	if err != nil {
+		respondPayoutError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message": "Withdrawal requested",
+		"payout":  payout,
+	})
+}
+
+// getWithdrawals lists the current user's withdrawals
+func getWithdrawals(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	currentUser := user.(*models.User)
+
+	limit := 50 // default limit
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if parsedLimit, err := strconv.Atoi(limitStr); err == nil && parsedLimit > 0 {
+			limit = parsedLimit
+		}
+	}
+
+	payouts, err := services.NewPayoutService().ListPayouts(currentUser.ID, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch withdrawals"})
+		return
+	}
+
+	c.JSON(http.StatusOK, payouts)
+}
+
+// getWithdrawal gets one of the current user's withdrawals
+func getWithdrawal(c *gin.Context) {
+	id := c.Param("id")
+
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	currentUser := user.(*models.User)
+
+	payout, err := services.NewPayoutService().GetPayout(currentUser.ID, id)
+	if err != nil {
+		respondPayoutError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, payout)
+}
@@ -0,0 +1,219 @@
+package routes
+
+import (
+	"errors"
+	"net/http"
+
+	"securewallet/internal/middleware"
+	"securewallet/internal/models"
+	"securewallet/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupPocketRoutes sets up routes for pockets, the savings goals inside a wallet
+func SetupPocketRoutes(router *gin.RouterGroup) {
+	pockets := router.Group("/wallets/:id/pockets")
+	pockets.Use(middleware.AuthMiddleware())
+	{
+		pockets.GET("/", getPockets)
+		pockets.POST("/", createPocket)
+		pockets.PUT("/:pocketId", updatePocket)
+		pockets.DELETE("/:pocketId", deletePocket)
+		pockets.POST("/:pocketId/add", middleware.IdempotencyMiddleware(), addToPocket)
+		pockets.POST("/:pocketId/withdraw", middleware.IdempotencyMiddleware(), withdrawFromPocket)
+	}
+}
+
+// PocketRequest represents a pocket to create or update
+type PocketRequest struct {
+	Name         string       `json:"name" binding:"required,max=50"`
+	TargetAmount models.Money `json:"target_amount"` // Optional savings goal
+	TargetDate   string       `json:"target_date"`   // Optional, YYYY-MM-DD
+	RoundUpTo    models.Money `json:"round_up_to"`   // e.g. 1.00 rounds each outgoing transfer up to the next whole unit; 0 disables
+}
+
+// input converts the request to the service input
+func (r PocketRequest) input() services.PocketInput {
+	return services.PocketInput{
+		Name:         r.Name,
+		TargetAmount: r.TargetAmount,
+		TargetDate:   r.TargetDate,
+		RoundUpTo:    r.RoundUpTo,
+	}
+}
+
+// PocketMoveRequest represents money moved into or out of a pocket
+type PocketMoveRequest struct {
+	Amount models.Money `json:"amount" binding:"required"`
+}
+
+// respondPocketError maps pocket service errors to HTTP responses
+func respondPocketError(c *gin.Context, err error) {
+	var insufficient *services.InsufficientFundsError
+	switch {
+	case errors.Is(err, services.ErrInvalidPocket),
+		errors.Is(err, services.ErrInvalidAmount):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.As(err, &insufficient):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":     "Insufficient balance",
+			"available": insufficient.Balance,
+			"required":  insufficient.Required,
+		})
+	case errors.Is(err, services.ErrTooManyPockets):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrPocketNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Pocket not found"})
+	default:
+		respondWalletError(c, err)
+	}
+}
+
+// getPockets lists the pockets of one of the current user's wallets
+func getPockets(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	currentUser := user.(*models.User)
+
+	pockets, err := services.NewPocketService().List(currentUser.ID, c.Param("id"))
+	if err != nil {
+		respondPocketError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, pockets)
+}
+
+// createPocket adds a pocket to a wallet
+func createPocket(c *gin.Context) {
+	var pocketReq PocketRequest
+	if err := c.ShouldBindJSON(&pocketReq); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	currentUser := user.(*models.User)
+
+	pocket, err := services.NewPocketService().Create(currentUser.ID, c.Param("id"), pocketReq.input())
+	if err != nil {
+		respondPocketError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Pocket created",
+		"pocket":  pocket,
+	})
+}
+
+// updatePocket changes a pocket's name, goal or round-up setting
+func updatePocket(c *gin.Context) {
+	var pocketReq PocketRequest
+	if err := c.ShouldBindJSON(&pocketReq); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	currentUser := user.(*models.User)
+
+	pocket, err := services.NewPocketService().Update(currentUser.ID, c.Param("id"), c.Param("pocketId"), pocketReq.input())
+	if err != nil {
+		respondPocketError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Pocket updated",
+		"pocket":  pocket,
+	})
+}
+
+// deletePocket moves a pocket's money back to its wallet and removes it
+func deletePocket(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	currentUser := user.(*models.User)
+
+	if err := services.NewPocketService().Delete(currentUser.ID, c.Param("id"), c.Param("pocketId")); err != nil {
+		respondPocketError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Pocket deleted"})
+}
+
+// addToPocket moves money from the wallet's available balance into a pocket
+func addToPocket(c *gin.Context) {
+	movePocketMoney(c, false)
+}
+
+// withdrawFromPocket moves money from a pocket back to the wallet's available balance
+func withdrawFromPocket(c *gin.Context) {
+	movePocketMoney(c, true)
+}
+
+// movePocketMoney handles both directions of a pocket move
+func movePocketMoney(c *gin.Context, withdraw bool) {
+	var moveReq PocketMoveRequest
+	if err := c.ShouldBindJSON(&moveReq); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	currentUser := user.(*models.User)
+	pocketService := services.NewPocketService()
+
+	move, message := pocketService.AddMoney, "Money added to pocket"
+	if withdraw {
+		move, message = pocketService.WithdrawMoney, "Money moved out of pocket"
+	}
+	pocket, err := move(currentUser.ID, c.Param("id"), c.Param("pocketId"), moveReq.Amount)
+	if err != nil {
+		respondPocketError(c, err)
+		return
+	}
+
+	wallet, err := services.NewWalletService().UserWallet(currentUser.ID, c.Param("id"))
+	if err != nil {
+		respondWalletError(c, err)
+		return
+	}
+	balance, err := pocketService.Balance(wallet)
+	if err != nil {
+		respondWalletError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": message,
+		"pocket":  pocket,
+		"balance": balance,
+	})
+}
@@ -0,0 +1,200 @@
+package routes
+
+import (
+	"errors"
+	"net/http"
+
+	"securewallet/internal/middleware"
+	"securewallet/internal/models"
+	"securewallet/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupScheduledTransferRoutes sets up scheduled and recurring transfer routes
+func SetupScheduledTransferRoutes(router *gin.RouterGroup) {
+	scheduled := router.Group("/scheduled-transfers")
+	scheduled.Use(middleware.AuthMiddleware())
+	{
+		scheduled.GET("/", getScheduledTransfers)
+		scheduled.POST("/", createScheduledTransfer)
+		scheduled.GET("/:id", getScheduledTransfer)
+		scheduled.POST("/:id/pause", pauseScheduledTransfer)
+		scheduled.POST("/:id/resume", resumeScheduledTransfer)
+		scheduled.DELETE("/:id", cancelScheduledTransfer)
+	}
+}
+
+// ScheduledTransferRequest represents a one-off or recurring transfer to set up
+type ScheduledTransferRequest struct {
+	WalletID       string       `json:"wallet_id"`                            // Source wallet, defaults to the user's default wallet
+	Recipient      string       `json:"recipient" binding:"required,max=100"` // Email or username
+	Amount         models.Money `json:"amount" binding:"required"`
+	Description    string       `json:"description" binding:"max=255"`
+	Frequency      string       `json:"frequency" binding:"required,oneof=once weekly monthly monthly_last_business_day"`
+	StartDate      string       `json:"start_date" binding:"required"` // YYYY-MM-DD
+	EndDate        string       `json:"end_date"`                      // Optional last date, YYYY-MM-DD
+	MaxOccurrences *int         `json:"max_occurrences" binding:"omitempty,min=1"`
+}
+
+// respondScheduledTransferError maps scheduled transfer service errors to HTTP responses
+func respondScheduledTransferError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidSchedule),
+		errors.Is(err, services.ErrInvalidAmount):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrSameWallet):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot transfer to yourself"})
+	case errors.Is(err, services.ErrRecipientNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Recipient not found"})
+	case errors.Is(err, services.ErrNoRecipientWallet):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Recipient has no wallet in this currency"})
+	case errors.Is(err, services.ErrScheduledTransferNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Scheduled transfer not found"})
+	case errors.Is(err, services.ErrScheduledTransferState):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		respondWalletError(c, err)
+	}
+}
+
+// getScheduledTransfers lists the current user's scheduled transfers
+func getScheduledTransfers(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	currentUser := user.(*models.User)
+
+	scheduled, err := services.NewScheduledTransferService().List(currentUser.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch scheduled transfers"})
+		return
+	}
+
+	c.JSON(http.StatusOK, scheduled)
+}
+
+// createScheduledTransfer sets up a one-off or recurring transfer
+func createScheduledTransfer(c *gin.Context) {
+	var scheduledReq ScheduledTransferRequest
+	if err := c.ShouldBindJSON(&scheduledReq); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	currentUser := user.(*models.User)
+
+	scheduled, err := services.NewScheduledTransferService().Create(services.ScheduledTransferInput{
+		UserID:         currentUser.ID,
+		WalletID:       scheduledReq.WalletID,
+		Recipient:      scheduledReq.Recipient,
+		Amount:         scheduledReq.Amount,
+		Description:    scheduledReq.Description,
+		Frequency:      scheduledReq.Frequency,
+		StartDate:      scheduledReq.StartDate,
+		EndDate:        scheduledReq.EndDate,
+		MaxOccurrences: scheduledReq.MaxOccurrences,
+	})
+	if err != nil {
+		respondScheduledTransferError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, scheduled)
+}
+
+// getScheduledTransfer gets one scheduled transfer with its recent runs
+func getScheduledTransfer(c *gin.Context) {
+	id := c.Param("id")
+
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	currentUser := user.(*models.User)
+
+	scheduled, err := services.NewScheduledTransferService().Get(currentUser.ID, id)
+	if err != nil {
+		respondScheduledTransferError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, scheduled)
+}
+
+// pauseScheduledTransfer pauses an active scheduled transfer
+func pauseScheduledTransfer(c *gin.Context) {
+	id := c.Param("id")
+
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	currentUser := user.(*models.User)
+
+	scheduled, err := services.NewScheduledTransferService().Pause(currentUser.ID, id)
+	if err != nil {
+		respondScheduledTransferError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, scheduled)
+}
+
+// resumeScheduledTransfer resumes a paused or failed scheduled transfer
+func resumeScheduledTransfer(c *gin.Context) {
+	id := c.Param("id")
+
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	currentUser := user.(*models.User)
+
+	scheduled, err := services.NewScheduledTransferService().Resume(currentUser.ID, id)
+	if err != nil {
+		respondScheduledTransferError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, scheduled)
+}
+
+// cancelScheduledTransfer cancels a scheduled transfer
+func cancelScheduledTransfer(c *gin.Context) {
+	id := c.Param("id")
+
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	currentUser := user.(*models.User)
+
+	scheduled, err := services.NewScheduledTransferService().Cancel(currentUser.ID, id)
+	if err != nil {
+		respondScheduledTransferError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":            "Scheduled transfer cancelled",
+		"scheduled_transfer": scheduled,
+	})
+}
@@ -0,0 +1,102 @@
+package routes
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"securewallet/internal/config"
+	"securewallet/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// setupTestDB points config.DB at an empty database for one test: the MySQL
+// database in TEST_MYSQL_DSN when it is set (its tables are dropped first),
+// otherwise a SQLite file whose transactions take the write lock when they begin
+func setupTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	gormConfig := &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)}
+	var db *gorm.DB
+	var err error
+	if dsn := os.Getenv("TEST_MYSQL_DSN"); dsn != "" {
+		db, err = gorm.Open(mysql.Open(dsn), gormConfig)
+		if err == nil {
+			err = db.Migrator().DropTable(config.Models()...)
+		}
+	} else {
+		path := filepath.Join(t.TempDir(), "wallet.db")
+		db, err = gorm.Open(sqlite.Open("file:"+path+"?_txlock=immediate&_busy_timeout=30000&_journal_mode=WAL"), gormConfig)
+	}
+	if err != nil {
+		t.Fatalf("failed to open test database: %v", err)
+	}
+	if err := db.AutoMigrate(config.Models()...); err != nil {
+		t.Fatalf("failed to migrate test database: %v", err)
+	}
+
+	previous := config.DB
+	config.DB = db
+	t.Cleanup(func() {
+		config.DB = previous
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return db
+}
+
+// createTestUser creates a user with a default wallet in the given currency
+func createTestUser(t *testing.T, db *gorm.DB, currency string) (*models.User, *models.Wallet) {
+	t.Helper()
+
+	name := "user" + uuid.NewString()[:8]
+	user := models.User{
+		Username:     name,
+		Name:         name,
+		Email:        name + "@example.com",
+		PasswordHash: "x",
+		IsActive:     true,
+	}
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+	wallet := models.Wallet{
+		UserID:    user.ID,
+		Name:      "Main",
+		Type:      models.WalletTypeCurrent,
+		Balance:   models.ZeroMoney(currency),
+		Held:      models.ZeroMoney(currency),
+		Currency:  currency,
+		IsDefault: true,
+		Status:    models.WalletStatusActive,
+	}
+	if err := db.Create(&wallet).Error; err != nil {
+		t.Fatalf("failed to create wallet: %v", err)
+	}
+	return &user, &wallet
+}
+
+// serveAs calls a handler as the given user, the way AuthMiddleware would
+// leave the request
+func serveAs(user *models.User, method, path string, handler gin.HandlerFunc, body string) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Handle(method, path, func(c *gin.Context) {
+		c.Set("user", user)
+	}, handler)
+
+	request := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+	request.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+	return recorder
+}
@@ -1,14 +1,20 @@
 package routes
 
 import (
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"os"
 	"strconv"
 
 	"securewallet/internal/config"
 	"securewallet/internal/middleware"
 	"securewallet/internal/models"
+	"securewallet/internal/services"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
 // SetupTransactionRoutes sets up transaction routes
@@ -16,14 +22,23 @@ func SetupTransactionRoutes(router *gin.RouterGroup) {
 	transactions := router.Group("/transactions")
 	{
 		transactions.GET("", middleware.AuthMiddleware(), getTransactions)
+		transactions.GET("/tags", middleware.AuthMiddleware(), getTransactionTags)
 		transactions.GET("/:id", middleware.AuthMiddleware(), getTransaction)
 		transactions.POST("", middleware.AuthMiddleware(), createTransaction)
 		transactions.PUT("/:id", middleware.AuthMiddleware(), updateTransaction)
 		transactions.DELETE("/:id", middleware.AuthMiddleware(), deleteTransaction)
+		transactions.GET("/:id/reversals", middleware.AuthMiddleware(), getTransactionReversals)
+		transactions.POST("/:id/refund", middleware.AuthMiddleware(), middleware.IdempotencyMiddleware(), refundTransaction)
+		transactions.POST("/:id/dispute", middleware.AuthMiddleware(), disputeTransaction)
+		transactions.GET("/:id/notes", middleware.AuthMiddleware(), getTransactionNotes)
+		transactions.PUT("/:id/notes", middleware.AuthMiddleware(), updateTransactionNotes)
+		transactions.POST("/:id/attachments", middleware.AuthMiddleware(), uploadTransactionAttachment)
+		transactions.GET("/:id/attachments/:attachmentId", middleware.AuthMiddleware(), downloadTransactionAttachment)
+		transactions.DELETE("/:id/attachments/:attachmentId", middleware.AuthMiddleware(), deleteTransactionAttachment)
 	}
 }
 
-// getTransactions gets all transactions for the current user
+// getTransactions searches the current user's transactions, newest first
 func getTransactions(c *gin.Context) {
 	user, exists := c.Get("user")
 	if !exists {
@@ -32,17 +47,48 @@ func getTransactions(c *gin.Context) {
 	}
 
 	currentUser := user.(*models.User)
-	db := config.GetDB()
 
-	// Get user's wallet
-	var userWallet models.Wallet
-	if err := db.Where("user_id = ?", currentUser.ID).First(&userWallet).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Wallet not found"})
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	page, err := services.NewTransactionService().Search(currentUser.ID, services.TransactionFilter{
+		WalletID:     c.Query("wallet_id"),
+		Currency:     c.Query("currency"),
+		From:         c.Query("from"),
+		To:           c.Query("to"),
+		Type:         c.Query("type"),
+		Status:       c.Query("status"),
+		Direction:    c.Query("direction"),
+		MinAmount:    c.Query("min_amount"),
+		MaxAmount:    c.Query("max_amount"),
+		Counterparty: c.Query("counterparty"),
+		Query:        c.Query("q"),
+		CategoryID:   c.Query("category_id"),
+		Tag:          c.Query("tag"),
+		Cursor:       c.Query("cursor"),
+		Limit:        limit,
+	})
+	if err != nil {
+		respondTransactionSearchError(c, err)
 		return
 	}
 
-	// Get limit parameter
-	limitStr := c.Query("limit")
+	c.JSON(http.StatusOK, page)
+}
+
+// respondTransactionSearchError maps transaction search errors to HTTP responses
+func respondTransactionSearchError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidCursor),
+		errors.Is(err, services.ErrInvalidFilter):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrTransactionNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Transaction not found"})
+	default:
+		respondWalletError(c, err)
+	}
+}
+
+// listWalletTransactions returns the newest transactions of a wallet
+func listWalletTransactions(walletID uuid.UUID, limitStr string) ([]models.Transaction, error) {
 	limit := 50 // default limit
 	if limitStr != "" {
 		if parsedLimit, err := strconv.Atoi(limitStr); err == nil && parsedLimit > 0 {
@@ -50,23 +96,31 @@ func getTransactions(c *gin.Context) {
 		}
 	}
 
-	// Get transactions for the user's wallet
 	var transactions []models.Transaction
-	if err := db.Where("wallet_id = ?", userWallet.ID).
+	err := config.GetDB().Where("wallet_id = ?", walletID).
 		Order("created_at DESC").
 		Limit(limit).
-		Find(&transactions).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch transactions"})
+		Find(&transactions).Error
+	return transactions, err
+}
+
+// getTransaction gets one of the current user's transactions with its counterparty
+func getTransaction(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
 		return
 	}
 
-	c.JSON(http.StatusOK, transactions)
-}
+	currentUser := user.(*models.User)
 
-// getTransaction gets a specific transaction
-func getTransaction(c *gin.Context) {
-	id := c.Param("id")
-	c.JSON(http.StatusOK, gin.H{"message": "Get transaction", "id": id})
+	transaction, err := services.NewTransactionService().Get(currentUser.ID, c.Param("id"))
+	if err != nil {
+		respondTransactionSearchError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, transaction)
 }
 
 // createTransaction creates a new transaction
@@ -74,14 +128,315 @@ func createTransaction(c *gin.Context) {
 	c.JSON(http.StatusCreated, gin.H{"message": "Create transaction"})
 }
 
-// updateTransaction updates a transaction
+// updateTransaction rejects edits: posted transactions are immutable ledger views
 func updateTransaction(c *gin.Context) {
-	id := c.Param("id")
-	c.JSON(http.StatusOK, gin.H{"message": "Update transaction", "id": id})
+	c.JSON(http.StatusMethodNotAllowed, gin.H{
+		"error": "Transactions cannot be modified; use a refund or open a dispute instead",
+	})
 }
 
-// deleteTransaction deletes a transaction
+// deleteTransaction rejects deletes: money is returned with compensating entries instead
 func deleteTransaction(c *gin.Context) {
-	id := c.Param("id")
-	c.JSON(http.StatusOK, gin.H{"message": "Delete transaction", "id": id})
+	c.JSON(http.StatusMethodNotAllowed, gin.H{
+		"error": "Transactions cannot be deleted; use a refund or open a dispute instead",
+	})
+}
+
+// RefundRequest represents a refund of a received transfer
+type RefundRequest struct {
+	Amount *models.Money `json:"amount"` // Defaults to everything not refunded yet
+	Reason string        `json:"reason" binding:"max=200"`
+}
+
+// TransactionNotesRequest represents changes to the user's private annotations
+// on a transaction; omitted fields are left unchanged
+type TransactionNotesRequest struct {
+	CategoryID *string   `json:"category_id"` // Empty string clears the category
+	Note       *string   `json:"note" binding:"omitempty,max=2000"`
+	Tags       *[]string `json:"tags"`
+}
+
+// DisputeRequest represents a dispute of a sent transfer
+type DisputeRequest struct {
+	ReasonCode  string `json:"reason_code" binding:"required,oneof=unauthorized not_received duplicate incorrect_amount fraud other"`
+	Description string `json:"description" binding:"required,max=2000"`
+}
+
+// respondReversalError maps refund, reversal and dispute errors to HTTP responses
+func respondReversalError(c *gin.Context, err error) {
+	var insufficient *services.InsufficientFundsError
+	switch {
+	case errors.As(err, &insufficient):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Insufficient balance",
+			"details": gin.H{
+				"required_amount": insufficient.Required,
+				"current_balance": insufficient.Balance,
+			},
+		})
+	case errors.Is(err, services.ErrTransactionNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Transaction not found"})
+	case errors.Is(err, services.ErrDisputeNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Dispute not found"})
+	case errors.Is(err, services.ErrInvalidAmount),
+		errors.Is(err, services.ErrNotReversible),
+		errors.Is(err, services.ErrNotTransferSide),
+		errors.Is(err, services.ErrRefundTooLarge),
+		errors.Is(err, services.ErrInvalidReasonCode),
+		errors.Is(err, services.ErrInvalidDecision),
+		errors.Is(err, services.ErrDisputeWindowClosed):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrNothingToReverse),
+		errors.Is(err, services.ErrDisputeExists),
+		errors.Is(err, services.ErrDisputeClosed):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		respondWalletError(c, err)
+	}
+}
+
+// getTransactionReversals lists the refunds and reversals of a transfer the user took part in
+func getTransactionReversals(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	currentUser := user.(*models.User)
+	reversalService := services.NewReversalService()
+
+	transaction, err := reversalService.UserTransaction(currentUser.ID, c.Param("id"))
+	if err != nil {
+		respondReversalError(c, err)
+		return
+	}
+	if transaction.JournalEntryID == nil {
+		c.JSON(http.StatusOK, []models.Reversal{})
+		return
+	}
+
+	reversals, err := reversalService.Reversals(*transaction.JournalEntryID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch refunds"})
+		return
+	}
+
+	c.JSON(http.StatusOK, reversals)
+}
+
+// refundTransaction returns part or all of a received transfer to its sender
+func refundTransaction(c *gin.Context) {
+	var refundReq RefundRequest
+	if err := c.ShouldBindJSON(&refundReq); err != nil && c.Request.ContentLength > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	currentUser := user.(*models.User)
+
+	refund, err := services.NewReversalService().Refund(currentUser.ID, c.Param("id"), refundReq.Amount, refundReq.Reason)
+	if err != nil {
+		respondReversalError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Refund issued",
+		"refund":  refund,
+	})
+}
+
+// disputeTransaction opens a dispute on a sent transfer for an admin to decide
+func disputeTransaction(c *gin.Context) {
+	var disputeReq DisputeRequest
+	if err := c.ShouldBindJSON(&disputeReq); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	currentUser := user.(*models.User)
+
+	dispute, err := services.NewDisputeService().Open(currentUser.ID, c.Param("id"), disputeReq.ReasonCode, disputeReq.Description)
+	if err != nil {
+		respondReversalError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Dispute opened; our support team will review it",
+		"dispute": dispute,
+	})
+}
+
+// getTransactionTags lists every tag the current user has used
+func getTransactionTags(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	currentUser := user.(*models.User)
+
+	tags, err := services.NewAnnotationService().Tags(currentUser.ID)
+	if err != nil {
+		respondCategoryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tags)
+}
+
+// getTransactionNotes returns the current user's category, note, tags and attachments on a transaction
+func getTransactionNotes(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	currentUser := user.(*models.User)
+
+	notes, err := services.NewAnnotationService().Get(currentUser.ID, c.Param("id"))
+	if err != nil {
+		respondCategoryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, notes)
+}
+
+// updateTransactionNotes sets the current user's category, note and tags on a transaction
+func updateTransactionNotes(c *gin.Context) {
+	var notesReq TransactionNotesRequest
+	if err := c.ShouldBindJSON(&notesReq); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	currentUser := user.(*models.User)
+
+	notes, err := services.NewAnnotationService().Update(currentUser.ID, c.Param("id"), services.AnnotationInput{
+		CategoryID: notesReq.CategoryID,
+		Note:       notesReq.Note,
+		Tags:       notesReq.Tags,
+	})
+	if err != nil {
+		respondCategoryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Transaction notes updated",
+		"notes":   notes,
+	})
+}
+
+// uploadTransactionAttachment attaches a receipt image or PDF to a transaction
+func uploadTransactionAttachment(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	currentUser := user.(*models.User)
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A file is required in the 'file' form field"})
+		return
+	}
+	if fileHeader.Size > services.MaxAttachmentSize {
+		respondCategoryError(c, services.ErrAttachmentTooLarge)
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read file"})
+		return
+	}
+	defer file.Close()
+
+	// Read one byte past the limit so that a wrong size header cannot sneak a larger file through
+	data, err := io.ReadAll(io.LimitReader(file, services.MaxAttachmentSize+1))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read file"})
+		return
+	}
+
+	attachment, err := services.NewAnnotationService().AddAttachment(currentUser.ID, c.Param("id"), fileHeader.Filename, data)
+	if err != nil {
+		respondCategoryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":    "Attachment uploaded",
+		"attachment": attachment,
+	})
+}
+
+// downloadTransactionAttachment returns an attachment file
+func downloadTransactionAttachment(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	currentUser := user.(*models.User)
+
+	attachment, err := services.NewAnnotationService().Attachment(currentUser.ID, c.Param("id"), c.Param("attachmentId"))
+	if err != nil {
+		respondCategoryError(c, err)
+		return
+	}
+
+	data, err := os.ReadFile(attachment.StoragePath)
+	if err != nil {
+		respondCategoryError(c, services.ErrAttachmentNotFound)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("inline; filename=%q", attachment.FileName))
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Data(http.StatusOK, attachment.ContentType, data)
+}
+
+// deleteTransactionAttachment removes an attachment
+func deleteTransactionAttachment(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	currentUser := user.(*models.User)
+
+	if err := services.NewAnnotationService().DeleteAttachment(currentUser.ID, c.Param("id"), c.Param("attachmentId")); err != nil {
+		respondCategoryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Attachment deleted"})
 }
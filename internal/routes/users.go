@@ -268,7 +268,7 @@ func deleteCurrentUserAccount(c *gin.Context) {
 
 	// Check if user has any active wallets with balance
 	var walletCount int64
-	db.Model(&models.Wallet{}).Where("user_id = ? AND balance > 0", userData.ID).Count(&walletCount)
+	db.Model(&models.Wallet{}).Where("user_id = ? AND organization_id IS NULL AND balance > 0", userData.ID).Count(&walletCount)
 	if walletCount > 0 {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error": "Cannot delete account with active wallet balance. Please transfer or withdraw all funds first.",
@@ -309,18 +309,29 @@ func deleteCurrentUserAccount(c *gin.Context) {
 	}
 
 	// 5. Delete transactions
-	if err := tx.Where("wallet_id IN (SELECT id FROM wallets WHERE user_id = ?)", userData.ID).Delete(&models.Transaction{}).Error; err != nil {
+	if err := tx.Where("wallet_id IN (SELECT id FROM wallets WHERE user_id = ? AND organization_id IS NULL)", userData.ID).Delete(&models.Transaction{}).Error; err != nil {
 		tx.Rollback()
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete transactions"})
 		return
 	}
 
 	// 6. Delete wallets
-	if err := tx.Where("user_id = ?", userData.ID).Delete(&models.Wallet{}).Error; err != nil {
+	// Organisation wallets belong to the organisation and stay open
+	if err := tx.Where("user_id = ? AND organization_id IS NULL", userData.ID).Delete(&models.Wallet{}).Error; err != nil {
 		tx.Rollback()
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete wallets"})
 		return
 	}
+	if err := tx.Where("user_id = ?", userData.ID).Delete(&models.WalletMember{}).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete wallet memberships"})
+		return
+	}
+	if err := tx.Where("user_id = ?", userData.ID).Delete(&models.OrganizationMember{}).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete organisation memberships"})
+		return
+	}
 
 	// 7. Finally, delete the user
 	if err := tx.Delete(&userData).Error; err != nil {
@@ -561,7 +572,7 @@ func deleteUser(c *gin.Context) {
 
 	// Check if user has any active wallets with balance
 	var walletCount int64
-	db.Model(&models.Wallet{}).Where("user_id = ? AND balance > 0", targetUser.ID).Count(&walletCount)
+	db.Model(&models.Wallet{}).Where("user_id = ? AND organization_id IS NULL AND balance > 0", targetUser.ID).Count(&walletCount)
 	if walletCount > 0 {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error": "Cannot delete user with active wallet balance. Please transfer or withdraw all funds first.",
@@ -602,18 +613,29 @@ func deleteUser(c *gin.Context) {
 	}
 
 	// 5. Delete transactions
-	if err := tx.Where("wallet_id IN (SELECT id FROM wallets WHERE user_id = ?)", targetUser.ID).Delete(&models.Transaction{}).Error; err != nil {
+	if err := tx.Where("wallet_id IN (SELECT id FROM wallets WHERE user_id = ? AND organization_id IS NULL)", targetUser.ID).Delete(&models.Transaction{}).Error; err != nil {
 		tx.Rollback()
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete transactions"})
 		return
 	}
 
 	// 6. Delete wallets
-	if err := tx.Where("user_id = ?", targetUser.ID).Delete(&models.Wallet{}).Error; err != nil {
+	// Organisation wallets belong to the organisation and stay open
+	if err := tx.Where("user_id = ? AND organization_id IS NULL", targetUser.ID).Delete(&models.Wallet{}).Error; err != nil {
 		tx.Rollback()
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete wallets"})
 		return
 	}
+	if err := tx.Where("user_id = ?", targetUser.ID).Delete(&models.WalletMember{}).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete wallet memberships"})
+		return
+	}
+	if err := tx.Where("user_id = ?", targetUser.ID).Delete(&models.OrganizationMember{}).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete organisation memberships"})
+		return
+	}
 
 	// 7. Finally, delete the user
 	if err := tx.Delete(&targetUser).Error; err != nil {
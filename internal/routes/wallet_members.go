@@ -0,0 +1,262 @@
+package routes
+
+import (
+	"errors"
+	"net/http"
+
+	"securewallet/internal/middleware"
+	"securewallet/internal/models"
+	"securewallet/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupWalletMemberRoutes sets up routes for joint and shared wallet members and their invites
+func SetupWalletMemberRoutes(router *gin.RouterGroup) {
+	members := router.Group("/wallets/:id/members")
+	members.Use(middleware.AuthMiddleware())
+	{
+		members.GET("/", getWalletMembers)
+		members.PUT("/:memberId", updateWalletMember)
+		members.DELETE("/:memberId", removeWalletMember)
+	}
+
+	invites := router.Group("/wallets/:id/invites")
+	invites.Use(middleware.AuthMiddleware())
+	{
+		invites.GET("/", getWalletInvites)
+		invites.POST("/", createWalletInvite)
+		invites.DELETE("/:inviteId", revokeWalletInvite)
+	}
+
+	received := router.Group("/wallet-invites")
+	received.Use(middleware.AuthMiddleware())
+	{
+		received.GET("/", getReceivedWalletInvites)
+		received.POST("/:id/accept", acceptWalletInvite)
+		received.POST("/:id/decline", declineWalletInvite)
+	}
+}
+
+// WalletInviteRequest represents an invite to join a wallet
+type WalletInviteRequest struct {
+	Email      string       `json:"email" binding:"required,email,max=100"`
+	Role       string       `json:"role" binding:"required,oneof=owner spender viewer"`
+	SpendLimit models.Money `json:"spend_limit"` // Monthly, in the wallet currency; 0 means no limit
+}
+
+// WalletMemberRequest represents a change to a member's role or spend limit
+type WalletMemberRequest struct {
+	Role       string       `json:"role" binding:"required,oneof=owner spender viewer"`
+	SpendLimit models.Money `json:"spend_limit"` // Monthly, in the wallet currency; 0 means no limit
+}
+
+// respondWalletMemberError maps wallet member service errors to HTTP responses
+func respondWalletMemberError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidMemberRole):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrMemberNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Member not found"})
+	case errors.Is(err, services.ErrInviteNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Invite not found"})
+	case errors.Is(err, services.ErrInviteExpired):
+		c.JSON(http.StatusGone, gin.H{"error": "Invite has expired"})
+	case errors.Is(err, services.ErrAlreadyMember),
+		errors.Is(err, services.ErrInviteExists),
+		errors.Is(err, services.ErrTooManyMembers),
+		errors.Is(err, services.ErrWalletHolder):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		respondWalletError(c, err)
+	}
+}
+
+// getWalletMembers lists everyone with access to a wallet
+func getWalletMembers(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	currentUser := user.(*models.User)
+
+	members, err := services.NewWalletMemberService().Members(currentUser.ID, c.Param("id"))
+	if err != nil {
+		respondWalletMemberError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, members)
+}
+
+// updateWalletMember changes a member's role or spend limit
+func updateWalletMember(c *gin.Context) {
+	var memberReq WalletMemberRequest
+	if err := c.ShouldBindJSON(&memberReq); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	currentUser := user.(*models.User)
+
+	member, err := services.NewWalletMemberService().UpdateMember(currentUser.ID, c.Param("id"), c.Param("memberId"), memberReq.Role, memberReq.SpendLimit)
+	if err != nil {
+		respondWalletMemberError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Member updated",
+		"member":  member,
+	})
+}
+
+// removeWalletMember removes a member from a wallet, or lets a member leave
+func removeWalletMember(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	currentUser := user.(*models.User)
+
+	if err := services.NewWalletMemberService().RemoveMember(currentUser.ID, c.Param("id"), c.Param("memberId")); err != nil {
+		respondWalletMemberError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Member removed"})
+}
+
+// getWalletInvites lists a wallet's pending invites
+func getWalletInvites(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	currentUser := user.(*models.User)
+
+	invites, err := services.NewWalletMemberService().Invites(currentUser.ID, c.Param("id"))
+	if err != nil {
+		respondWalletMemberError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, invites)
+}
+
+// createWalletInvite invites someone to a wallet by email
+func createWalletInvite(c *gin.Context) {
+	var inviteReq WalletInviteRequest
+	if err := c.ShouldBindJSON(&inviteReq); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	currentUser := user.(*models.User)
+
+	invite, err := services.NewWalletMemberService().Invite(currentUser.ID, c.Param("id"), inviteReq.Email, inviteReq.Role, inviteReq.SpendLimit)
+	if err != nil {
+		respondWalletMemberError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Invite sent",
+		"invite":  invite,
+	})
+}
+
+// revokeWalletInvite withdraws a pending invite
+func revokeWalletInvite(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	currentUser := user.(*models.User)
+
+	if err := services.NewWalletMemberService().RevokeInvite(currentUser.ID, c.Param("id"), c.Param("inviteId")); err != nil {
+		respondWalletMemberError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Invite revoked"})
+}
+
+// getReceivedWalletInvites lists the open invites sent to the current user's email
+func getReceivedWalletInvites(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	currentUser := user.(*models.User)
+
+	invites, err := services.NewWalletMemberService().PendingInvites(currentUser)
+	if err != nil {
+		respondWalletMemberError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, invites)
+}
+
+// acceptWalletInvite joins the wallet an invite is for
+func acceptWalletInvite(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	currentUser := user.(*models.User)
+
+	member, err := services.NewWalletMemberService().AcceptInvite(currentUser, c.Param("id"))
+	if err != nil {
+		respondWalletMemberError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Invite accepted",
+		"member":  member,
+	})
+}
+
+// declineWalletInvite turns an invite down
+func declineWalletInvite(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	currentUser := user.(*models.User)
+
+	if err := services.NewWalletMemberService().DeclineInvite(currentUser, c.Param("id")); err != nil {
+		respondWalletMemberError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Invite declined"})
+}
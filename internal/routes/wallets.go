@@ -1,6 +1,7 @@
 package routes
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
@@ -20,9 +21,21 @@ func SetupWalletRoutes(router *gin.RouterGroup) {
 	{
 		wallets.GET("/", middleware.AuthMiddleware(), getWallets)
 		wallets.GET("/balance", middleware.AuthMiddleware(), getBalance)
-		wallets.POST("/deposit", middleware.AuthMiddleware(), deposit)
-		wallets.POST("/transfer", middleware.AuthMiddleware(), transfer)
+		wallets.GET("/limits", middleware.AuthMiddleware(), getLimits)
+		wallets.GET("/fees/preview", middleware.AuthMiddleware(), previewFee)
+		wallets.POST("/deposit", middleware.AuthMiddleware(), middleware.IdempotencyMiddleware(), deposit)
+		wallets.POST("/transfer", middleware.AuthMiddleware(), middleware.IdempotencyMiddleware(), transfer)
+		wallets.POST("/withdraw", middleware.AuthMiddleware(), middleware.IdempotencyMiddleware(), withdraw)
+		wallets.GET("/withdrawals", middleware.AuthMiddleware(), getWithdrawals)
+		wallets.GET("/withdrawals/:id", middleware.AuthMiddleware(), getWithdrawal)
+		wallets.GET("/exchange/rates", middleware.AuthMiddleware(), getExchangeRates)
+		wallets.POST("/exchange/quote", middleware.AuthMiddleware(), createExchangeQuote)
+		wallets.POST("/exchange", middleware.AuthMiddleware(), middleware.IdempotencyMiddleware(), exchange)
 		wallets.GET("/:id", middleware.AuthMiddleware(), getWallet)
+		wallets.GET("/:id/transactions", middleware.AuthMiddleware(), getWalletTransactions)
+		wallets.GET("/:id/statements", middleware.AuthMiddleware(), getWalletStatements)
+		wallets.GET("/:id/interest", middleware.AuthMiddleware(), getWalletInterest)
+		wallets.GET("/:id/credit-line", middleware.AuthMiddleware(), getWalletCreditLine)
 		wallets.POST("/", middleware.AuthMiddleware(), createWallet)
 		wallets.PUT("/:id", middleware.AuthMiddleware(), updateWallet)
 		wallets.DELETE("/:id", middleware.AuthMiddleware(), deleteWallet)
@@ -31,25 +44,70 @@ func SetupWalletRoutes(router *gin.RouterGroup) {
 
 // DepositRequest represents deposit request data
 type DepositRequest struct {
-	Amount      float64 `json:"amount" binding:"required,gt=0"`
-	Description string  `json:"description"`
+	WalletID    string       `json:"wallet_id"` // Defaults to the user's default wallet
+	Amount      models.Money `json:"amount" binding:"required"`
+	Description string       `json:"description"`
 }
 
 // TransferRequest represents transfer request data
 type TransferRequest struct {
-	Recipient   string  `json:"recipient" binding:"required"`
-	Amount      float64 `json:"amount" binding:"required,gt=0"`
-	Description string  `json:"description"`
+	WalletID    string       `json:"wallet_id"` // Source wallet, defaults to the user's default wallet
+	Recipient   string       `json:"recipient" binding:"required"`
+	Amount      models.Money `json:"amount" binding:"required"`
+	Description string       `json:"description"`
 }
 
-// Transfer fee constants
-const (
-	TRANSFER_FEE_PERCENTAGE = 0.01   // 1% transfer fee
-	MIN_TRANSFER_FEE        = 1.0    // Minimum $1 fee
-	MAX_TRANSFER_FEE        = 50.0   // Maximum $50 fee
-	MIN_TRANSFER_AMOUNT     = 1.0    // Minimum transfer amount
-	MAX_TRANSFER_AMOUNT     = 1000.0 // Maximum transfer amount
-)
+// CreateWalletRequest represents wallet creation data
+type CreateWalletRequest struct {
+	Name      string `json:"name" binding:"max=100"`
+	Currency  string `json:"currency" binding:"required,len=3"`
+	Type      string `json:"type" binding:"omitempty,oneof=current savings"` // Defaults to current
+	IsDefault bool   `json:"is_default"`
+}
+
+// UpdateWalletRequest represents wallet update data
+type UpdateWalletRequest struct {
+	Name      *string `json:"name" binding:"omitempty,max=100"`
+	IsDefault bool    `json:"is_default"`
+}
+
+// respondWalletError maps wallet service errors to HTTP responses
+func respondWalletError(c *gin.Context, err error) {
+	var spendLimit *services.MemberSpendLimitError
+	switch {
+	case errors.Is(err, services.ErrWalletNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Wallet not found"})
+	case errors.Is(err, services.ErrWalletClosed):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Wallet is closed"})
+	case errors.Is(err, services.ErrWalletNotEmpty):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Wallet balance must be zero before it can be closed"})
+	case errors.Is(err, services.ErrWalletHasPayouts):
+		c.JSON(http.StatusConflict, gin.H{"error": "Wallet has withdrawals in progress or within their return window"})
+	case errors.Is(err, services.ErrWalletHasPockets):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Move the money out of the wallet's pockets before closing it"})
+	case errors.Is(err, services.ErrUnsupportedCurrency):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported currency"})
+	case errors.Is(err, services.ErrInvalidWalletType):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Wallet type must be current or savings"})
+	case errors.Is(err, services.ErrWalletPermission):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Your role on this wallet does not allow this"})
+	case errors.Is(err, services.ErrApprovalRequired),
+		errors.Is(err, services.ErrOrganizationWallet):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.As(err, &spendLimit):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Monthly spend limit on this wallet exceeded",
+			"details": gin.H{
+				"spend_limit":     spendLimit.Limit,
+				"spent":           spendLimit.Spent,
+				"required_amount": spendLimit.Required,
+			},
+		})
+	default:
+		log.Printf("Wallet operation failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Wallet operation failed"})
+	}
+}
 
 // deposit handles wallet deposit
 func deposit(c *gin.Context) {
@@ -66,59 +124,41 @@ func deposit(c *gin.Context) {
 	}
 
 	currentUser := user.(*models.User)
-	db := config.GetDB()
 
-	// Get user's wallet
-	var userWallet models.Wallet
-	if err := db.Where("user_id = ?", currentUser.ID).First(&userWallet).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Wallet not found"})
+	// Get user's wallet (ownership and member role checked by the wallet service)
+	userWallet, err := services.NewWalletService().MemberWallet(currentUser.ID, depositReq.WalletID, models.WalletRoleSpender)
+	if err != nil {
+		respondWalletError(c, err)
 		return
 	}
 
-	// Start transaction
-	tx := db.Begin()
-
-	// Update wallet balance
-	if err := tx.Model(&userWallet).Update("balance", userWallet.Balance+depositReq.Amount).Error; err != nil {
-		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update wallet balance"})
+	amount, err := depositReq.Amount.In(userWallet.Currency)
+	if err != nil || !amount.IsPositive() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid deposit amount"})
 		return
 	}
 
-	// Create transaction record
-	transaction := models.Transaction{
+	transferService := services.NewTransferService()
+	result, err := transferService.Deposit(services.DepositInput{
 		WalletID:    userWallet.ID,
-		Type:        "DEPOSIT",
-		Amount:      depositReq.Amount,
-		Currency:    userWallet.Currency,
+		Amount:      amount,
 		Description: depositReq.Description,
-		Status:      "completed",
-	}
-
-	if err := tx.Create(&transaction).Error; err != nil {
-		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create transaction record"})
-		return
-	}
-
-	// Commit transaction
-	if err := tx.Commit().Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
+		InitiatedBy: currentUser.ID,
+	})
+	if err != nil {
+		log.Printf("Deposit into wallet %s failed: %v", userWallet.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record deposit"})
 		return
 	}
 
-	// Get updated wallet
-	var updatedWallet models.Wallet
-	db.First(&updatedWallet, userWallet.ID)
-
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Deposit successful",
-		"wallet":  updatedWallet,
+		"wallet":  result.Wallet,
 		"transaction": gin.H{
-			"id":          transaction.ID,
-			"amount":      transaction.Amount,
-			"description": transaction.Description,
-			"status":      transaction.Status,
+			"id":          result.Transaction.ID,
+			"amount":      result.Transaction.Amount,
+			"description": result.Transaction.Description,
+			"status":      result.Transaction.Status,
 		},
 	})
 }
@@ -131,14 +171,6 @@ func transfer(c *gin.Context) {
 		return
 	}
 
-	// SECURE: Validate transfer amount
-	if transferReq.Amount < MIN_TRANSFER_AMOUNT || transferReq.Amount > MAX_TRANSFER_AMOUNT {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": fmt.Sprintf("Transfer amount must be between $%.2f and $%.2f", MIN_TRANSFER_AMOUNT, MAX_TRANSFER_AMOUNT),
-		})
-		return
-	}
-
 	// SECURE: Validate recipient email format
 	if !strings.Contains(transferReq.Recipient, "@") || len(transferReq.Recipient) > 100 {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid recipient email format"})
@@ -158,50 +190,32 @@ func transfer(c *gin.Context) {
 	}
 
 	currentUser := user.(*models.User)
-	db := config.GetDB()
+	walletService := services.NewWalletService()
 
-	// Get sender's wallet
-	var senderWallet models.Wallet
-	if err := db.Where("user_id = ?", currentUser.ID).First(&senderWallet).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Sender wallet not found"})
+	// Get sender's wallet (ownership and member role checked by the wallet service)
+	senderWallet, err := walletService.MemberWallet(currentUser.ID, transferReq.WalletID, models.WalletRoleSpender)
+	if err != nil {
+		respondWalletError(c, err)
 		return
 	}
 
-	// Calculate transfer fee
-	transferFee := transferReq.Amount * TRANSFER_FEE_PERCENTAGE
-	if transferFee < MIN_TRANSFER_FEE {
-		transferFee = MIN_TRANSFER_FEE
-	} else if transferFee > MAX_TRANSFER_FEE {
-		transferFee = MAX_TRANSFER_FEE
-	}
-
-	totalAmount := transferReq.Amount + transferFee
-
-	// Check if sender has sufficient balance (including fee)
-	if senderWallet.Balance < totalAmount {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Insufficient balance",
-			"details": gin.H{
-				"transfer_amount": transferReq.Amount,
-				"transfer_fee":    transferFee,
-				"total_amount":    totalAmount,
-				"current_balance": senderWallet.Balance,
-			},
-		})
+	amount, err := transferReq.Amount.In(senderWallet.Currency)
+	if err != nil || !amount.IsPositive() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid transfer amount"})
 		return
 	}
 
 	// Find recipient by email
-	var recipient models.User
-	if err := db.Where("email = ?", transferReq.Recipient).First(&recipient).Error; err != nil {
+	recipient, err := walletService.FindRecipient(transferReq.Recipient)
+	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Recipient not found"})
 		return
 	}
 
-	// Get recipient's wallet
-	var recipientWallet models.Wallet
-	if err := db.Where("user_id = ?", recipient.ID).First(&recipientWallet).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Recipient wallet not found"})
+	// Get recipient's wallet in the transfer currency
+	recipientWallet, err := walletService.RecipientWallet(recipient.ID, senderWallet.Currency)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("Recipient has no %s wallet", senderWallet.Currency)})
 		return
 	}
 
@@ -211,88 +225,90 @@ func transfer(c *gin.Context) {
 		return
 	}
 
-	// Start database transaction
-	tx := db.Begin()
-
-	// Deduct from sender's wallet (amount + fee)
-	if err := tx.Model(&senderWallet).Update("balance", senderWallet.Balance-totalAmount).Error; err != nil {
-		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update sender wallet"})
-		return
-	}
-
-	// Add to recipient's wallet
-	if err := tx.Model(&recipientWallet).Update("balance", recipientWallet.Balance+transferReq.Amount).Error; err != nil {
-		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update recipient wallet"})
-		return
-	}
-
-	// Create outgoing transaction record for sender (including fee)
-	outgoingTransaction := models.Transaction{
-		WalletID:    senderWallet.ID,
-		Type:        "TRANSFER",
-		Amount:      totalAmount,
-		Currency:    senderWallet.Currency,
-		Description: transferReq.Description + " (to " + recipient.Username + ") + $" + fmt.Sprintf("%.2f", transferFee) + " fee",
-		Status:      "completed",
-	}
-
-	if err := tx.Create(&outgoingTransaction).Error; err != nil {
-		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create outgoing transaction"})
-		return
-	}
-
-	// Create incoming transaction record for recipient
-	incomingTransaction := models.Transaction{
-		WalletID:    recipientWallet.ID,
-		Type:        "TRANSFER",
-		Amount:      transferReq.Amount,
-		Currency:    recipientWallet.Currency,
-		Description: transferReq.Description + " (from " + currentUser.Username + ")",
-		Status:      "completed",
-	}
-
-	if err := tx.Create(&incomingTransaction).Error; err != nil {
-		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create incoming transaction"})
+	// Organisation wallets only pay out once the organisation's approvers agree
+	if senderWallet.OrganizationID != nil {
+		createPaymentInstruction(c, currentUser, services.InstructionInput{
+			UserID:          currentUser.ID,
+			Wallet:          senderWallet,
+			Recipient:       recipient,
+			RecipientWallet: recipientWallet,
+			Amount:          amount,
+			Description:     transferReq.Description,
+		})
 		return
 	}
 
-	// Commit transaction
-	if err := tx.Commit().Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transfer"})
+	transferService := services.NewTransferService()
+	result, err := transferService.Transfer(services.TransferInput{
+		SenderWalletID:    senderWallet.ID,
+		RecipientWalletID: recipientWallet.ID,
+		Amount:            amount,
+		Description:       transferReq.Description,
+		SenderMemo:        transferReq.Description + " (to " + recipient.Username + ")",
+		RecipientMemo:     transferReq.Description + " (from " + currentUser.Username + ")",
+		InitiatedBy:       currentUser.ID,
+	})
+	if err != nil {
+		var insufficient *services.InsufficientFundsError
+		var limitErr *services.LimitExceededError
+		var spendLimitErr *services.MemberSpendLimitError
+		switch {
+		case errors.As(err, &spendLimitErr), errors.Is(err, services.ErrWalletPermission):
+			respondWalletError(c, err)
+		case errors.As(err, &limitErr):
+			// SECURE: Amount and velocity limits are enforced inside the transfer transaction
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": limitErr.Error(),
+				"limit": limitErr.Limit,
+			})
+		case errors.As(err, &insufficient):
+			transferFee, _ := insufficient.Required.Sub(amount)
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Insufficient balance",
+				"details": gin.H{
+					"transfer_amount": amount,
+					"transfer_fee":    transferFee,
+					"total_amount":    insufficient.Required,
+					"current_balance": insufficient.Balance,
+				},
+			})
+		case errors.Is(err, services.ErrInvalidAmount):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid transfer amount"})
+		case errors.Is(err, services.ErrCurrencyMismatch):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Recipient wallet uses a different currency"})
+		case errors.Is(err, services.ErrWalletNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Wallet not found"})
+		default:
+			log.Printf("Transfer from wallet %s failed: %v", senderWallet.ID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record transfer"})
+		}
 		return
 	}
 
-	// Get updated wallets
-	var updatedSenderWallet, updatedRecipientWallet models.Wallet
-	db.First(&updatedSenderWallet, senderWallet.ID)
-	db.First(&updatedRecipientWallet, recipientWallet.ID)
-
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Transfer successful",
 		"sender_wallet": gin.H{
-			"balance":  updatedSenderWallet.Balance,
-			"currency": updatedSenderWallet.Currency,
+			"balance":  result.SenderWallet.Balance,
+			"currency": result.SenderWallet.Currency,
 		},
 		"recipient": gin.H{
 			"username": recipient.Username,
 			"email":    recipient.Email,
 		},
 		"transfer": gin.H{
-			"id":           outgoingTransaction.ID,
-			"amount":       transferReq.Amount,
-			"transfer_fee": transferFee,
-			"total_amount": totalAmount,
+			"id":           result.SenderTransaction.ID,
+			"amount":       amount,
+			"transfer_fee": result.Fee,
+			"fee_rule_id":  result.FeeRuleID,
+			"total_amount": result.Total,
+			"round_up":     result.RoundUp,
 			"description":  transferReq.Description,
 			"status":       "completed",
 		},
 	})
 }
 
-// getBalance gets the current user's wallet balance
+// getBalance gets the balance of one of the current user's wallets
 func getBalance(c *gin.Context) {
 	user, exists := c.Get("user")
 	if !exists {
@@ -303,10 +319,10 @@ func getBalance(c *gin.Context) {
 	currentUser := user.(*models.User)
 	db := config.GetDB()
 
-	// Get user's wallet
-	var userWallet models.Wallet
-	if err := db.Where("user_id = ?", currentUser.ID).First(&userWallet).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Wallet not found"})
+	// Get user's wallet (defaults to the default wallet)
+	userWallet, err := services.NewWalletService().UserWallet(currentUser.ID, c.Query("wallet_id"))
+	if err != nil {
+		respondWalletError(c, err)
 		return
 	}
 
@@ -314,13 +330,114 @@ func getBalance(c *gin.Context) {
 	var transactionCount int64
 	db.Model(&models.Transaction{}).Where("wallet_id = ?", userWallet.ID).Count(&transactionCount)
 
+	// balance stays the wallet's own balance; money in pockets only shows in
+	// the pocket and total keys, and money on hold only in available
+	balance, err := services.NewPocketService().Balance(userWallet)
+	if err != nil {
+		respondWalletError(c, err)
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
+		"wallet_id":         userWallet.ID,
 		"balance":           userWallet.Balance,
+		"available":         balance.Available,
+		"held":              balance.Held,
+		"pocket_balance":    balance.InPockets,
+		"total_balance":     balance.Total,
 		"currency":          userWallet.Currency,
 		"transaction_count": transactionCount,
 	})
 }
 
+// getLimits shows the transfer limits and usage for each currency the user holds
+func getLimits(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	currentUser := user.(*models.User)
+
+	currencies := []string{}
+	if currency := strings.ToUpper(c.Query("currency")); currency != "" {
+		currencies = append(currencies, currency)
+	} else {
+		wallets, err := services.NewWalletService().ListWallets(currentUser.ID, false)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch wallets"})
+			return
+		}
+		seen := map[string]bool{}
+		for _, wallet := range wallets {
+			if !seen[wallet.Currency] {
+				seen[wallet.Currency] = true
+				currencies = append(currencies, wallet.Currency)
+			}
+		}
+	}
+
+	limitService := services.NewLimitService()
+	summaries := []*services.LimitUsageSummary{}
+	for _, currency := range currencies {
+		if !models.IsSupportedCurrency(currency) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported currency"})
+			return
+		}
+		summary, err := limitService.Summary(currentUser.ID, currency)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch limits"})
+			return
+		}
+		summaries = append(summaries, summary)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"tier":   currentUser.Tier,
+		"limits": summaries,
+	})
+}
+
+// previewFee returns the fee a payment from one of the current user's wallets
+// would be charged (?amount=, ?wallet_id=, ?type=transfer by default), so that
+// it can be shown before the user confirms
+func previewFee(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	currentUser := user.(*models.User)
+
+	wallet, err := services.NewWalletService().MemberWallet(currentUser.ID, c.Query("wallet_id"), models.WalletRoleSpender)
+	if err != nil {
+		respondWalletError(c, err)
+		return
+	}
+
+	amount, err := models.ParseMoney(c.Query("amount"), wallet.Currency)
+	if err != nil || !amount.IsPositive() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid amount"})
+		return
+	}
+
+	// Fees are charged to the wallet owner, whose tier and free quota apply
+	quote, err := services.NewFeeService().Preview(wallet.UserID, c.DefaultQuery("type", models.FeeTypeTransfer), amount)
+	if errors.Is(err, services.ErrInvalidFeeType) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown transaction type"})
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to preview fee for wallet %s: %v", wallet.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to calculate fee"})
+		return
+	}
+
+	c.JSON(http.StatusOK, quote)
+}
+
 // getWallets gets all wallets for the current user
 func getWallets(c *gin.Context) {
 	user, exists := c.Get("user")
@@ -330,10 +447,9 @@ func getWallets(c *gin.Context) {
 	}
 
 	currentUser := user.(*models.User)
-	db := config.GetDB()
 
-	var wallets []models.Wallet
-	if err := db.Where("user_id = ?", currentUser.ID).Find(&wallets).Error; err != nil {
+	wallets, err := services.NewWalletService().ListWallets(currentUser.ID, c.Query("include_closed") == "true")
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch wallets"})
 		return
 	}
@@ -341,8 +457,7 @@ func getWallets(c *gin.Context) {
 	c.JSON(http.StatusOK, wallets)
 }
 
-// getWallet gets a specific wallet
-// VULNERABLE: IDOR - No ownership check, allows access to any wallet by ID
+// getWallet gets a specific wallet the current user owns or is a member of
 func getWallet(c *gin.Context) {
 	id := c.Param("id")
 
@@ -355,17 +470,20 @@ func getWallet(c *gin.Context) {
 	currentUser := user.(*models.User)
 	db := config.GetDB()
 
-	// VULNERABLE: No ownership validation - any authenticated user can access any wallet
 	var wallet models.Wallet
 	if err := db.Preload("User").Where("id = ?", id).First(&wallet).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Wallet not found"})
 		return
 	}
 
-	// VULNERABLE: Return wallet details without checking if currentUser owns it
-	// This allows any authenticated user to read any wallet's balance and details
+	// The account holder and the wallet's members can read it
+	role, err := services.NewWalletService().WalletRole(currentUser.ID, &wallet)
+	if err != nil {
+		respondWalletError(c, err)
+		return
+	}
 
-	// Log the access for audit purposes (but this doesn't prevent the vulnerability)
+	// Log the access for audit purposes
 	auditLog := models.AuditLog{
 		UserID:    currentUser.ID,
 		Action:    "WALLET_READ",
@@ -377,7 +495,7 @@ func getWallet(c *gin.Context) {
 	db.Create(&auditLog)
 
 	// 🚨 SECURITY DETECTION: Detect IDOR attempts
-	if wallet.UserID != currentUser.ID {
+	if role == "" {
 		securityDetector := services.NewSecurityDetector()
 		alert, err := securityDetector.DetectIDOR(
 			currentUser.ID.String(),
@@ -392,13 +510,19 @@ func getWallet(c *gin.Context) {
 		} else if alert != nil {
 			log.Printf("🚨 IDOR ALERT CREATED: %s", alert.ID)
 		}
+
+		// Reported as missing so that wallet IDs cannot be probed
+		c.JSON(http.StatusNotFound, gin.H{"error": "Wallet not found"})
+		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"wallet": gin.H{
 			"id":       wallet.ID,
+			"name":     wallet.Name,
 			"balance":  wallet.Balance,
 			"currency": wallet.Currency,
+			"role":     role,
 			"owner": gin.H{
 				"id":       wallet.User.ID,
 				"username": wallet.User.Username,
@@ -410,19 +534,210 @@ func getWallet(c *gin.Context) {
 	})
 }
 
-// createWallet creates a new wallet
+// createWallet opens a new wallet for the current user
 func createWallet(c *gin.Context) {
-	c.JSON(http.StatusCreated, gin.H{"message": "Create wallet"})
+	var createReq CreateWalletRequest
+	if err := c.ShouldBindJSON(&createReq); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	currentUser := user.(*models.User)
+
+	wallet, err := services.NewWalletService().CreateWallet(currentUser.ID, createReq.Name, createReq.Currency, createReq.Type, createReq.IsDefault)
+	if err != nil {
+		respondWalletError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, wallet)
 }
 
-// updateWallet updates a wallet
+// updateWallet renames a wallet or makes it the default
 func updateWallet(c *gin.Context) {
 	id := c.Param("id")
-	c.JSON(http.StatusOK, gin.H{"message": "Update wallet", "id": id})
+
+	var updateReq UpdateWalletRequest
+	if err := c.ShouldBindJSON(&updateReq); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	currentUser := user.(*models.User)
+
+	wallet, err := services.NewWalletService().UpdateWallet(currentUser.ID, id, updateReq.Name, updateReq.IsDefault)
+	if err != nil {
+		respondWalletError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, wallet)
 }
 
-// deleteWallet deletes a wallet
+// deleteWallet closes an empty wallet; its history is kept
 func deleteWallet(c *gin.Context) {
 	id := c.Param("id")
-	c.JSON(http.StatusOK, gin.H{"message": "Delete wallet", "id": id})
+
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	currentUser := user.(*models.User)
+
+	wallet, err := services.NewWalletService().CloseWallet(currentUser.ID, id)
+	if err != nil {
+		respondWalletError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Wallet closed",
+		"wallet":  wallet,
+	})
+}
+
+// getWalletTransactions gets the transactions of one of the current user's wallets
+func getWalletTransactions(c *gin.Context) {
+	id := c.Param("id")
+
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	currentUser := user.(*models.User)
+
+	wallet, err := services.NewWalletService().UserWallet(currentUser.ID, id)
+	if err != nil {
+		respondWalletError(c, err)
+		return
+	}
+
+	transactions, err := listWalletTransactions(wallet.ID, c.Query("limit"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch transactions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, transactions)
+}
+
+// getWalletStatements lists the stored monthly statements of a wallet or, when a
+// month or date range is given, returns the statement as JSON, CSV, OFX or PDF
+func getWalletStatements(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	currentUser := user.(*models.User)
+	statementService := services.NewStatementService()
+
+	month, from, to := c.Query("month"), c.Query("from"), c.Query("to")
+	if month == "" && from == "" && to == "" {
+		statements, err := statementService.List(currentUser.ID, c.Param("id"))
+		if err != nil {
+			respondStatementError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"statements": statements})
+		return
+	}
+
+	start, end, err := services.ParseStatementPeriod(month, from, to)
+	if err != nil {
+		respondStatementError(c, err)
+		return
+	}
+
+	format := strings.ToLower(c.DefaultQuery("format", services.StatementFormatJSON))
+	if format == services.StatementFormatJSON {
+		statement, err := statementService.Build(currentUser.ID, c.Param("id"), start, end)
+		if err != nil {
+			respondStatementError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, statement)
+		return
+	}
+
+	file, err := statementService.Export(currentUser.ID, c.Param("id"), start, end, format)
+	if err != nil {
+		respondStatementError(c, err)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", file.Name))
+	c.Data(http.StatusOK, file.ContentType, file.Data)
+}
+
+// getWalletInterest returns the interest rate, this month's accrued interest and
+// past interest postings of a wallet
+func getWalletInterest(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	currentUser := user.(*models.User)
+
+	summary, err := services.NewInterestService().WalletSummary(currentUser.ID, c.Param("id"))
+	if err != nil {
+		respondWalletError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, summary)
+}
+
+// getWalletCreditLine returns a wallet's credit line, what is owed on it and
+// the overdraft interest charged so far
+func getWalletCreditLine(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	currentUser := user.(*models.User)
+
+	credit, err := services.NewCreditLineService().WalletCredit(currentUser.ID, c.Param("id"))
+	if errors.Is(err, services.ErrCreditLineNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Wallet has no credit line"})
+		return
+	}
+	if err != nil {
+		respondWalletError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, credit)
+}
+
+// respondStatementError maps statement errors to HTTP responses
+func respondStatementError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidStatementPeriod),
+		errors.Is(err, services.ErrInvalidStatementFormat):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		respondWalletError(c, err)
+	}
 }
@@ -0,0 +1,61 @@
+package routes
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"securewallet/internal/models"
+	"securewallet/internal/services"
+)
+
+func TestTransferRejectsNonPositiveAmounts(t *testing.T) {
+	db := setupTestDB(t)
+	sender, senderWallet := createTestUser(t, db, "USD")
+	recipient, recipientWallet := createTestUser(t, db, "USD")
+	if _, err := services.NewTransferService().Deposit(services.DepositInput{
+		WalletID:    senderWallet.ID,
+		Amount:      models.MustParseMoney("100.00", "USD"),
+		InitiatedBy: sender.ID,
+	}); err != nil {
+		t.Fatalf("failed to fund sender: %v", err)
+	}
+
+	tests := []struct {
+		amount string
+		want   int
+	}{
+		{`"0"`, http.StatusBadRequest},
+		{`0`, http.StatusBadRequest},
+		{`"-5.00"`, http.StatusBadRequest},
+		{`-5.00`, http.StatusBadRequest},
+		{`"5.00"`, http.StatusOK},
+	}
+	for _, tt := range tests {
+		body := fmt.Sprintf(`{"recipient": %q, "amount": %s}`, recipient.Email, tt.amount)
+		response := serveAs(sender, http.MethodPost, "/wallet/transfer", transfer, body)
+		if response.Code != tt.want {
+			t.Errorf("amount %s: status %d %s, want %d", tt.amount, response.Code, response.Body, tt.want)
+			continue
+		}
+		if tt.want == http.StatusBadRequest {
+			var reply map[string]interface{}
+			json.Unmarshal(response.Body.Bytes(), &reply)
+			if reply["error"] != "Invalid transfer amount" {
+				t.Errorf("amount %s: error %v, want Invalid transfer amount", tt.amount, reply["error"])
+			}
+		}
+	}
+
+	// Only the valid transfer moved money: 5.00 plus the 1.00 minimum fee
+	var senderStored, recipientStored models.Wallet
+	db.First(&senderStored, "id = ?", senderWallet.ID)
+	if senderStored.Balance.Amount != 9400 {
+		t.Errorf("sender balance = %s, want 94.00", senderStored.Balance)
+	}
+	db.First(&recipientStored, "id = ?", recipientWallet.ID)
+	if recipientStored.Balance.Amount != 500 {
+		t.Errorf("recipient balance = %s, want 5.00", recipientStored.Balance)
+	}
+}
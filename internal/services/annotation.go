@@ -0,0 +1,303 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"securewallet/internal/config"
+	"securewallet/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Attachment limits
+const (
+	MaxAttachmentSize            = 5 << 20 // 5 MB
+	maxAttachmentsPerTransaction = 10
+	maxTagsPerTransaction        = 10
+	maxTagLength                 = 30
+)
+
+// attachmentDir holds uploaded transaction attachments
+const attachmentDir = "attachments"
+
+// attachmentExtensions lists the accepted attachment types, detected from the file content
+var attachmentExtensions = map[string]string{
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"image/gif":       ".gif",
+	"image/webp":      ".webp",
+	"application/pdf": ".pdf",
+}
+
+// Annotation errors
+var (
+	ErrAttachmentNotFound    = errors.New("attachment not found")
+	ErrAttachmentTooLarge    = errors.New("attachment is larger than 5 MB")
+	ErrUnsupportedAttachment = errors.New("attachment must be a JPEG, PNG, GIF, WebP image or a PDF")
+	ErrTooManyAttachments    = errors.New("transaction already has the maximum number of attachments")
+	ErrInvalidTag            = errors.New("tags must be 1 to 30 characters and a transaction can have at most 10")
+)
+
+// TransactionNotes is everything a user added to a transaction: category, note, tags and attachments
+type TransactionNotes struct {
+	Category       *models.Category               `json:"category"`
+	CategorySource string                         `json:"category_source,omitempty"`
+	Note           string                         `json:"note"`
+	Tags           []string                       `json:"tags"`
+	Attachments    []models.TransactionAttachment `json:"attachments"`
+}
+
+// AnnotationInput changes a user's annotations on a transaction; nil fields are left as they are
+type AnnotationInput struct {
+	CategoryID *string // Empty string clears the category
+	Note       *string
+	Tags       *[]string
+}
+
+// AnnotationService keeps each user's private categories, notes, tags and
+// attachments on their transactions
+type AnnotationService struct {
+	db           *gorm.DB
+	transactions *TransactionService
+	categories   *CategoryService
+}
+
+// NewAnnotationService creates a new annotation service
+func NewAnnotationService() *AnnotationService {
+	return &AnnotationService{
+		db:           config.GetDB(),
+		transactions: NewTransactionService(),
+		categories:   NewCategoryService(),
+	}
+}
+
+// Get returns the user's annotations on one of their transactions
+func (as *AnnotationService) Get(userID uuid.UUID, transactionID string) (*TransactionNotes, error) {
+	transaction, err := as.transactions.UserTransaction(userID, transactionID)
+	if err != nil {
+		return nil, err
+	}
+	return loadTransactionNotes(as.db, userID, transaction.ID)
+}
+
+// Update sets the category, note and tags the user keeps on a transaction
+func (as *AnnotationService) Update(userID uuid.UUID, transactionID string, input AnnotationInput) (*TransactionNotes, error) {
+	transaction, err := as.transactions.UserTransaction(userID, transactionID)
+	if err != nil {
+		return nil, err
+	}
+
+	var categoryID *uuid.UUID
+	if input.CategoryID != nil && *input.CategoryID != "" {
+		category, err := as.categories.Get(userID, *input.CategoryID)
+		if err != nil {
+			return nil, err
+		}
+		categoryID = &category.ID
+	}
+	var tags []string
+	if input.Tags != nil {
+		if tags, err = normalizeTags(*input.Tags); err != nil {
+			return nil, err
+		}
+	}
+
+	err = as.db.Transaction(func(tx *gorm.DB) error {
+		if input.CategoryID != nil || input.Note != nil {
+			var annotation models.TransactionAnnotation
+			err := tx.Where("user_id = ? AND transaction_id = ?", userID, transaction.ID).First(&annotation).Error
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+			annotation.UserID = userID
+			annotation.TransactionID = transaction.ID
+			if input.CategoryID != nil {
+				// A category chosen by hand replaces whatever a rule picked
+				annotation.CategoryID = categoryID
+				annotation.CategorySource = ""
+				annotation.RuleID = nil
+				if categoryID != nil {
+					annotation.CategorySource = models.CategorySourceManual
+				}
+			}
+			if input.Note != nil {
+				annotation.Note = strings.TrimSpace(*input.Note)
+			}
+			if err := tx.Save(&annotation).Error; err != nil {
+				return fmt.Errorf("failed to save annotation: %w", err)
+			}
+		}
+
+		if input.Tags != nil {
+			if err := tx.Where("user_id = ? AND transaction_id = ?", userID, transaction.ID).
+				Delete(&models.TransactionTag{}).Error; err != nil {
+				return err
+			}
+			for _, tag := range tags {
+				if err := tx.Create(&models.TransactionTag{UserID: userID, TransactionID: transaction.ID, Tag: tag}).Error; err != nil {
+					return fmt.Errorf("failed to save tag: %w", err)
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return loadTransactionNotes(as.db, userID, transaction.ID)
+}
+
+// Tags returns every tag the user has used, for suggestions
+func (as *AnnotationService) Tags(userID uuid.UUID) ([]string, error) {
+	tags := []string{}
+	if err := as.db.Model(&models.TransactionTag{}).Where("user_id = ?", userID).
+		Distinct().Order("tag ASC").Pluck("tag", &tags).Error; err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+// AddAttachment stores a receipt or other file on one of the user's transactions
+func (as *AnnotationService) AddAttachment(userID uuid.UUID, transactionID, fileName string, data []byte) (*models.TransactionAttachment, error) {
+	transaction, err := as.transactions.UserTransaction(userID, transactionID)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > MaxAttachmentSize {
+		return nil, ErrAttachmentTooLarge
+	}
+	contentType := http.DetectContentType(data)
+	extension, ok := attachmentExtensions[contentType]
+	if !ok {
+		return nil, ErrUnsupportedAttachment
+	}
+
+	var count int64
+	if err := as.db.Model(&models.TransactionAttachment{}).
+		Where("user_id = ? AND transaction_id = ?", userID, transaction.ID).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count >= maxAttachmentsPerTransaction {
+		return nil, ErrTooManyAttachments
+	}
+
+	attachment := models.TransactionAttachment{
+		ID:            uuid.New(),
+		UserID:        userID,
+		TransactionID: transaction.ID,
+		FileName:      filepath.Base(strings.TrimSpace(fileName)),
+		ContentType:   contentType,
+		Size:          int64(len(data)),
+	}
+	if attachment.FileName == "." || attachment.FileName == "/" || attachment.FileName == "" {
+		attachment.FileName = "attachment" + extension
+	}
+	// Stored under generated names so that uploaded file names never reach the file system
+	attachment.StoragePath = filepath.Join(attachmentDir, userID.String(), attachment.ID.String()+extension)
+
+	if err := os.MkdirAll(filepath.Dir(attachment.StoragePath), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create attachment directory: %w", err)
+	}
+	if err := os.WriteFile(attachment.StoragePath, data, 0644); err != nil {
+		return nil, fmt.Errorf("failed to store attachment: %w", err)
+	}
+	if err := as.db.Create(&attachment).Error; err != nil {
+		os.Remove(attachment.StoragePath)
+		return nil, fmt.Errorf("failed to record attachment: %w", err)
+	}
+	return &attachment, nil
+}
+
+// Attachment returns one of the user's attachments on a transaction
+func (as *AnnotationService) Attachment(userID uuid.UUID, transactionID, attachmentID string) (*models.TransactionAttachment, error) {
+	transaction, err := as.transactions.UserTransaction(userID, transactionID)
+	if err != nil {
+		return nil, err
+	}
+	id, err := uuid.Parse(attachmentID)
+	if err != nil {
+		return nil, ErrAttachmentNotFound
+	}
+
+	var attachment models.TransactionAttachment
+	if err := as.db.Where("id = ? AND user_id = ? AND transaction_id = ?", id, userID, transaction.ID).
+		First(&attachment).Error; err != nil {
+		return nil, ErrAttachmentNotFound
+	}
+	return &attachment, nil
+}
+
+// DeleteAttachment removes an attachment and its file
+func (as *AnnotationService) DeleteAttachment(userID uuid.UUID, transactionID, attachmentID string) error {
+	attachment, err := as.Attachment(userID, transactionID, attachmentID)
+	if err != nil {
+		return err
+	}
+	if err := as.db.Delete(attachment).Error; err != nil {
+		return err
+	}
+	if err := os.Remove(attachment.StoragePath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove attachment file: %w", err)
+	}
+	return nil
+}
+
+// loadTransactionNotes reads a user's annotations on a transaction
+func loadTransactionNotes(db *gorm.DB, userID, transactionID uuid.UUID) (*TransactionNotes, error) {
+	notes := &TransactionNotes{Tags: []string{}, Attachments: []models.TransactionAttachment{}}
+
+	var annotation models.TransactionAnnotation
+	err := db.Preload("Category").Where("user_id = ? AND transaction_id = ?", userID, transactionID).First(&annotation).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if err == nil {
+		notes.Category = annotation.Category
+		notes.CategorySource = annotation.CategorySource
+		notes.Note = annotation.Note
+	}
+
+	if err := db.Model(&models.TransactionTag{}).Where("user_id = ? AND transaction_id = ?", userID, transactionID).
+		Order("tag ASC").Pluck("tag", &notes.Tags).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Where("user_id = ? AND transaction_id = ?", userID, transactionID).
+		Order("created_at ASC").Find(&notes.Attachments).Error; err != nil {
+		return nil, err
+	}
+	return notes, nil
+}
+
+// normalizeTags lowercases, trims and de-duplicates tags
+func normalizeTags(tags []string) ([]string, error) {
+	seen := make(map[string]bool, len(tags))
+	normalized := []string{}
+	for _, tag := range tags {
+		tag = NormalizeTag(tag)
+		if tag == "" || len([]rune(tag)) > maxTagLength {
+			return nil, ErrInvalidTag
+		}
+		if !seen[tag] {
+			seen[tag] = true
+			normalized = append(normalized, tag)
+		}
+	}
+	if len(normalized) > maxTagsPerTransaction {
+		return nil, ErrInvalidTag
+	}
+	sort.Strings(normalized)
+	return normalized, nil
+}
+
+// NormalizeTag returns the stored form of a tag
+func NormalizeTag(tag string) string {
+	return strings.ToLower(strings.Join(strings.Fields(tag), "-"))
+}
@@ -27,11 +27,14 @@ type BackupConfig struct {
 	Enabled        bool          // Whether automatic backups are enabled
 }
 
-// Default backup configuration
-var DefaultBackupConfig = BackupConfig{
-	MaxBackups:     7,             // Keep max 7 backups
-	BackupInterval: 1 * time.Hour, // Create backup every hour
-	Enabled:        true,          // Enable automatic backups
+// CurrentBackupConfig returns the backup configuration from the system settings
+func CurrentBackupConfig() BackupConfig {
+	settings := CurrentSettings().Backups
+	return BackupConfig{
+		MaxBackups:     settings.MaxBackups,
+		BackupInterval: time.Duration(settings.IntervalMinutes) * time.Minute,
+		Enabled:        settings.Enabled,
+	}
 }
 
 // NewBackupService creates a new backup service
@@ -239,24 +242,20 @@ func (bs *BackupService) GetBackupInfo(backupName string) (map[string]interface{
 	return backupData, nil
 }
 
-// startBackupScheduler starts the automatic backup scheduler
+// startBackupScheduler starts the automatic backup scheduler.
+// The settings are read before every run so that admins can change them at runtime.
 func (bs *BackupService) startBackupScheduler() {
-	if !DefaultBackupConfig.Enabled {
-		log.Println("Automatic backups are disabled")
-		return
-	}
-
+	backupConfig := CurrentBackupConfig()
 	log.Printf("Starting automatic backup scheduler (interval: %v, max backups: %d)",
-		DefaultBackupConfig.BackupInterval, DefaultBackupConfig.MaxBackups)
+		backupConfig.BackupInterval, backupConfig.MaxBackups)
 
-	ticker := time.NewTicker(DefaultBackupConfig.BackupInterval)
-	defer ticker.Stop()
-
-	// Create initial backup
-	bs.createScheduledBackup()
-
-	for range ticker.C {
-		bs.createScheduledBackup()
+	for {
+		// Create a backup right away, then after every interval
+		if backupConfig.Enabled {
+			bs.createScheduledBackup()
+		}
+		time.Sleep(backupConfig.BackupInterval)
+		backupConfig = CurrentBackupConfig()
 	}
 }
 
@@ -281,7 +280,8 @@ func (bs *BackupService) cleanupOldBackups() {
 		return
 	}
 
-	if len(backups) <= DefaultBackupConfig.MaxBackups {
+	maxBackups := CurrentBackupConfig().MaxBackups
+	if len(backups) <= maxBackups {
 		return
 	}
 
@@ -291,7 +291,7 @@ func (bs *BackupService) cleanupOldBackups() {
 	})
 
 	// Remove oldest backups
-	backupsToRemove := len(backups) - DefaultBackupConfig.MaxBackups
+	backupsToRemove := len(backups) - maxBackups
 	for i := 0; i < backupsToRemove; i++ {
 		backupFile := filepath.Join("backups", backups[i])
 		if err := os.Remove(backupFile); err != nil {
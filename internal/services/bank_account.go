@@ -0,0 +1,73 @@
+package services
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+// Bank account errors
+var (
+	ErrInvalidIBAN          = errors.New("invalid IBAN")
+	ErrInvalidRoutingNumber = errors.New("invalid routing number")
+	ErrInvalidAccountNumber = errors.New("invalid account number")
+)
+
+var (
+	ibanPattern          = regexp.MustCompile(`^[A-Z]{2}[0-9]{2}[A-Z0-9]{11,30}$`)
+	routingNumberPattern = regexp.MustCompile(`^[0-9]{9}$`)
+	accountNumberPattern = regexp.MustCompile(`^[0-9]{4,17}$`)
+)
+
+// NormalizeIBAN removes spaces and upper-cases an IBAN
+func NormalizeIBAN(iban string) string {
+	return strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(iban), " ", ""))
+}
+
+// ValidateIBAN checks the format and the ISO 13616 mod-97 checksum of a normalized IBAN
+func ValidateIBAN(iban string) error {
+	if !ibanPattern.MatchString(iban) {
+		return ErrInvalidIBAN
+	}
+
+	// Move the country code and check digits to the end, then read letters as 10..35
+	rearranged := iban[4:] + iban[:4]
+	remainder := 0
+	for _, ch := range rearranged {
+		if ch >= 'A' && ch <= 'Z' {
+			value := int(ch-'A') + 10
+			remainder = (remainder*100 + value) % 97
+		} else {
+			remainder = (remainder*10 + int(ch-'0')) % 97
+		}
+	}
+	if remainder != 1 {
+		return ErrInvalidIBAN
+	}
+	return nil
+}
+
+// ValidateRoutingNumber checks the format and the ABA checksum of a US routing number
+func ValidateRoutingNumber(routing string) error {
+	if !routingNumberPattern.MatchString(routing) {
+		return ErrInvalidRoutingNumber
+	}
+
+	weights := []int{3, 7, 1, 3, 7, 1, 3, 7, 1}
+	sum := 0
+	for i, ch := range routing {
+		sum += int(ch-'0') * weights[i]
+	}
+	if sum%10 != 0 {
+		return ErrInvalidRoutingNumber
+	}
+	return nil
+}
+
+// ValidateAccountNumber checks the format of a US bank account number
+func ValidateAccountNumber(account string) error {
+	if !accountNumberPattern.MatchString(account) {
+		return ErrInvalidAccountNumber
+	}
+	return nil
+}
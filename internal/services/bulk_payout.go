@@ -0,0 +1,551 @@
+package services
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"securewallet/internal/config"
+	"securewallet/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Bulk payout limits
+const (
+	MaxBulkPayoutFileSize  = 1 << 20 // 1 MB
+	maxBulkPayoutLines     = 1000
+	maxBulkPayoutsPerRun   = 20
+	bulkPayoutClaimTimeout = 30 * time.Minute
+)
+
+// Bulk payout errors
+var (
+	ErrBulkPayoutNotFound    = errors.New("bulk payout not found")
+	ErrBulkPayoutState       = errors.New("bulk payout cannot be executed in its current status")
+	ErrInvalidBulkPayout     = errors.New("a bulk payout needs between 1 and 1000 lines")
+	ErrInvalidBulkPayoutFile = errors.New("the CSV file needs a header row with at least recipient and amount columns")
+	ErrInvalidBulkPayoutMode = errors.New("mode must be atomic or per_line")
+)
+
+// BulkPayoutLineInput is one line of an uploaded batch
+type BulkPayoutLineInput struct {
+	Recipient string       // Email or username
+	Amount    models.Money // Unbound; bound to the currency during validation
+	Currency  string       // Defaults to the wallet currency
+	Reference string
+
+	invalid string // Set by the CSV parser when the line cannot be read
+}
+
+// BulkPayoutInput describes an uploaded batch
+type BulkPayoutInput struct {
+	UserID   uuid.UUID
+	WalletID string // Defaults to the user's default wallet
+	FileName string
+	Lines    []BulkPayoutLineInput
+}
+
+// BulkPayoutService validates uploaded batches of transfers and pays them out
+// through the normal transfer path, all at once or line by line
+type BulkPayoutService struct {
+	db        *gorm.DB
+	transfers *TransferService
+	wallets   *WalletService
+	limits    *LimitService
+}
+
+// NewBulkPayoutService creates a new bulk payout service
+func NewBulkPayoutService() *BulkPayoutService {
+	return &BulkPayoutService{
+		db:        config.GetDB(),
+		transfers: NewTransferService(),
+		wallets:   NewWalletService(),
+		limits:    NewLimitService(),
+	}
+}
+
+// ParseBulkPayoutCSV reads a batch from a CSV file with a header row. The
+// recipient and amount columns are required; currency and reference are optional.
+func ParseBulkPayoutCSV(data []byte) ([]BulkPayoutLineInput, error) {
+	r := csv.NewReader(bytes.NewReader(data))
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+
+	header, err := r.Read()
+	if err != nil {
+		return nil, ErrInvalidBulkPayoutFile
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	if _, ok := columns["recipient"]; !ok {
+		return nil, ErrInvalidBulkPayoutFile
+	}
+	if _, ok := columns["amount"]; !ok {
+		return nil, ErrInvalidBulkPayoutFile
+	}
+	field := func(record []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	var lines []BulkPayoutLineInput
+	for {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidBulkPayoutFile, err)
+		}
+		if len(lines) >= maxBulkPayoutLines {
+			return nil, ErrInvalidBulkPayout
+		}
+
+		line := BulkPayoutLineInput{
+			Recipient: field(record, "recipient"),
+			Currency:  field(record, "currency"),
+			Reference: field(record, "reference"),
+		}
+		if line.Amount, err = models.ParseMoney(field(record, "amount"), ""); err != nil {
+			line.invalid = "amount is not a valid number"
+		}
+		lines = append(lines, line)
+	}
+	return lines, nil
+}
+
+// Create validates every line of a batch up front (recipient, currency, amount
+// and per-transfer limits) and then the batch as a whole against the wallet
+// balance and the remaining daily and monthly limits. The batch is saved with
+// its per-line report either way; only a validated batch can run atomically.
+func (bs *BulkPayoutService) Create(input BulkPayoutInput) (*models.BulkPayout, error) {
+	wallet, err := bs.wallets.MemberWallet(input.UserID, input.WalletID, models.WalletRoleSpender)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkOrganizationPayment(wallet); err != nil {
+		return nil, err
+	}
+	if len(input.Lines) == 0 || len(input.Lines) > maxBulkPayoutLines {
+		return nil, ErrInvalidBulkPayout
+	}
+
+	limits, err := bs.limits.Summary(wallet.UserID, wallet.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	batch := models.BulkPayout{
+		UserID:      input.UserID,
+		WalletID:    wallet.ID,
+		FileName:    input.FileName,
+		Currency:    wallet.Currency,
+		LineCount:   len(input.Lines),
+		TotalAmount: models.ZeroMoney(wallet.Currency),
+		TotalFees:   models.ZeroMoney(wallet.Currency),
+	}
+	for i, lineInput := range input.Lines {
+		line := bs.validateLine(wallet, &limits.EffectiveLimits, lineInput)
+		line.LineNumber = i + 1
+		if line.Status == models.BulkPayoutLineStatusValid {
+			batch.ValidCount++
+			batch.TotalAmount, _ = batch.TotalAmount.Add(line.Amount)
+			batch.TotalFees, _ = batch.TotalFees.Add(line.Fee)
+		}
+		batch.Lines = append(batch.Lines, line)
+	}
+
+	// The batch as a whole has to fit the balance and what is left of the limits
+	var problems []string
+	total, _ := batch.TotalAmount.Add(batch.TotalFees)
+	if wallet.Available().Amount < total.Amount {
+		problems = append(problems, fmt.Sprintf("available balance %s does not cover the total of %s including fees", wallet.Available(), total))
+	}
+	if batch.TotalAmount.Amount > limits.DailyRemaining.Amount {
+		problems = append(problems, fmt.Sprintf("total of %s is over the %s left of the daily limit", batch.TotalAmount, limits.DailyRemaining))
+	}
+	if batch.TotalAmount.Amount > limits.MonthlyRemaining.Amount {
+		problems = append(problems, fmt.Sprintf("total of %s is over the %s left of the monthly limit", batch.TotalAmount, limits.MonthlyRemaining))
+	}
+	if batch.ValidCount > limits.TransfersRemaining {
+		problems = append(problems, fmt.Sprintf("%d transfers are over the %d left of the transfer count limits", batch.ValidCount, limits.TransfersRemaining))
+	}
+	batch.ValidationErrors = strings.Join(problems, "; ")
+
+	batch.Status = models.BulkPayoutStatusValidated
+	if batch.ValidCount < batch.LineCount || len(problems) > 0 {
+		batch.Status = models.BulkPayoutStatusInvalid
+	}
+
+	if err := bs.db.Create(&batch).Error; err != nil {
+		return nil, fmt.Errorf("failed to save bulk payout: %w", err)
+	}
+	return &batch, nil
+}
+
+// List returns the user's batches, newest first, without their lines
+func (bs *BulkPayoutService) List(userID uuid.UUID) ([]models.BulkPayout, error) {
+	batches := []models.BulkPayout{}
+	if err := bs.db.Where("user_id = ?", userID).Order("created_at DESC").Limit(100).Find(&batches).Error; err != nil {
+		return nil, err
+	}
+	return batches, nil
+}
+
+// Get returns one of the user's batches with its lines, for status polling
+func (bs *BulkPayoutService) Get(userID uuid.UUID, batchID string) (*models.BulkPayout, error) {
+	id, err := uuid.Parse(batchID)
+	if err != nil {
+		return nil, ErrBulkPayoutNotFound
+	}
+
+	var batch models.BulkPayout
+	if err := bs.db.Preload("Lines", func(db *gorm.DB) *gorm.DB {
+		return db.Order("line_number ASC")
+	}).First(&batch, "id = ? AND user_id = ?", id, userID).Error; err != nil {
+		return nil, ErrBulkPayoutNotFound
+	}
+	return &batch, nil
+}
+
+// Execute queues a batch for the executor. An atomic run needs a fully validated
+// batch; a line-by-line run pays the valid lines and skips the invalid ones.
+func (bs *BulkPayoutService) Execute(userID uuid.UUID, batchID, mode string) (*models.BulkPayout, error) {
+	batch, err := bs.Get(userID, batchID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := bs.wallets.MemberWallet(userID, batch.WalletID.String(), models.WalletRoleSpender); err != nil {
+		return nil, err
+	}
+
+	allowed := []string{models.BulkPayoutStatusValidated}
+	switch mode {
+	case models.BulkPayoutModeAtomic:
+	case models.BulkPayoutModePerLine:
+		if batch.ValidCount == 0 {
+			return nil, ErrBulkPayoutState
+		}
+		allowed = append(allowed, models.BulkPayoutStatusInvalid)
+	default:
+		return nil, ErrInvalidBulkPayoutMode
+	}
+
+	now := time.Now()
+	result := bs.db.Model(&models.BulkPayout{}).
+		Where("id = ? AND status IN ?", batch.ID, allowed).
+		Updates(map[string]interface{}{
+			"status":    models.BulkPayoutStatusQueued,
+			"mode":      mode,
+			"queued_at": now,
+		})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrBulkPayoutState
+	}
+
+	return bs.Get(userID, batchID)
+}
+
+// Results renders the per-line outcome of a batch as a CSV file
+func (bs *BulkPayoutService) Results(userID uuid.UUID, batchID string) (*models.BulkPayout, []byte, error) {
+	batch, err := bs.Get(userID, batchID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	rows := [][]string{{"line", "recipient", "amount", "fee", "currency", "reference", "status", "error", "transaction_id"}}
+	for _, line := range batch.Lines {
+		transactionID := ""
+		if line.TransactionID != nil {
+			transactionID = line.TransactionID.String()
+		}
+		rows = append(rows, []string{
+			strconv.Itoa(line.LineNumber),
+			line.Recipient,
+			line.Amount.Format(),
+			line.Fee.Format(),
+			line.Currency,
+			line.Reference,
+			line.Status,
+			line.Error,
+			transactionID,
+		})
+	}
+	if err := w.WriteAll(rows); err != nil {
+		return nil, nil, err
+	}
+	return batch, buf.Bytes(), nil
+}
+
+// ProcessQueued runs queued batches. It returns the number of batches processed.
+func (bs *BulkPayoutService) ProcessQueued(now time.Time) (int, error) {
+	// Requeue batches claimed by an executor that never finished; paid lines are
+	// recorded with their transfer, so only the rest is paid again
+	if err := bs.db.Model(&models.BulkPayout{}).
+		Where("status = ? AND updated_at < ?", models.BulkPayoutStatusProcessing, now.Add(-bulkPayoutClaimTimeout)).
+		Update("status", models.BulkPayoutStatusQueued).Error; err != nil {
+		return 0, err
+	}
+
+	var queued []models.BulkPayout
+	if err := bs.db.Where("status = ?", models.BulkPayoutStatusQueued).
+		Order("queued_at ASC").
+		Limit(maxBulkPayoutsPerRun).
+		Find(&queued).Error; err != nil {
+		return 0, err
+	}
+
+	processed := 0
+	for i := range queued {
+		batch := &queued[i]
+
+		// Claim the batch so that a concurrent executor cannot pay it twice
+		claim := bs.db.Model(&models.BulkPayout{}).
+			Where("id = ? AND status = ?", batch.ID, models.BulkPayoutStatusQueued).
+			Update("status", models.BulkPayoutStatusProcessing)
+		if claim.Error != nil {
+			log.Printf("Failed to claim bulk payout %s: %v", batch.ID, claim.Error)
+			continue
+		}
+		if claim.RowsAffected == 0 {
+			continue
+		}
+
+		if err := bs.run(batch, now); err != nil {
+			log.Printf("Failed to record run of bulk payout %s: %v", batch.ID, err)
+			continue
+		}
+		processed++
+	}
+
+	return processed, nil
+}
+
+// run pays a claimed batch and records the outcome of every line
+func (bs *BulkPayoutService) run(batch *models.BulkPayout, now time.Time) error {
+	var sender models.User
+	if err := bs.db.First(&sender, "id = ?", batch.UserID).Error; err != nil {
+		return err
+	}
+	var lines []models.BulkPayoutLine
+	if err := bs.db.Where("batch_id = ? AND status = ?", batch.ID, models.BulkPayoutLineStatusValid).
+		Order("line_number ASC").
+		Find(&lines).Error; err != nil {
+		return err
+	}
+
+	updates := map[string]interface{}{"completed_at": now}
+	if batch.Mode == models.BulkPayoutModeAtomic {
+		var failed *models.BulkPayoutLine
+		err := bs.transfers.withRetry(func(tx *gorm.DB) error {
+			failed = nil
+			for i := range lines {
+				if err := bs.pay(tx, batch, &sender, &lines[i]); err != nil {
+					failed = &lines[i]
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			reason := truncateError(err)
+			updates["status"] = models.BulkPayoutStatusFailed
+			if failed != nil {
+				updates["last_error"] = truncateError(fmt.Errorf("line %d: %s", failed.LineNumber, reason))
+				if err := bs.db.Model(failed).Updates(map[string]interface{}{"error": reason}).Error; err != nil {
+					return err
+				}
+			} else {
+				updates["last_error"] = reason
+			}
+			log.Printf("Bulk payout %s failed: %v", batch.ID, err)
+		} else {
+			updates["status"] = models.BulkPayoutStatusCompleted
+			updates["succeeded_count"] = len(lines)
+		}
+		return bs.db.Model(batch).Updates(updates).Error
+	}
+
+	// Line by line: invalid lines are skipped and every valid line is paid on its own
+	if err := bs.db.Model(&models.BulkPayoutLine{}).
+		Where("batch_id = ? AND status = ?", batch.ID, models.BulkPayoutLineStatusInvalid).
+		Update("status", models.BulkPayoutLineStatusSkipped).Error; err != nil {
+		return err
+	}
+	for i := range lines {
+		line := &lines[i]
+		err := bs.transfers.withRetry(func(tx *gorm.DB) error {
+			return bs.pay(tx, batch, &sender, line)
+		})
+		if err != nil {
+			if err := bs.db.Model(line).Updates(map[string]interface{}{
+				"status": models.BulkPayoutLineStatusFailed,
+				"error":  truncateError(err),
+			}).Error; err != nil {
+				return err
+			}
+			log.Printf("Bulk payout %s line %d failed: %v", batch.ID, line.LineNumber, err)
+		}
+	}
+
+	var succeeded, failed int64
+	if err := bs.db.Model(&models.BulkPayoutLine{}).
+		Where("batch_id = ? AND status = ?", batch.ID, models.BulkPayoutLineStatusSucceeded).
+		Count(&succeeded).Error; err != nil {
+		return err
+	}
+	if err := bs.db.Model(&models.BulkPayoutLine{}).
+		Where("batch_id = ? AND status = ?", batch.ID, models.BulkPayoutLineStatusFailed).
+		Count(&failed).Error; err != nil {
+		return err
+	}
+	updates["succeeded_count"] = succeeded
+	updates["failed_count"] = failed
+	switch {
+	case int(succeeded) == batch.LineCount:
+		updates["status"] = models.BulkPayoutStatusCompleted
+	case succeeded == 0:
+		updates["status"] = models.BulkPayoutStatusFailed
+	default:
+		updates["status"] = models.BulkPayoutStatusPartiallyCompleted
+	}
+	return bs.db.Model(batch).Updates(updates).Error
+}
+
+// pay sends one line through the normal transfer path, so fees, limits, spend
+// limits and balance checks apply exactly as for a transfer made by hand, and
+// marks the line paid in the same transaction
+func (bs *BulkPayoutService) pay(tx *gorm.DB, batch *models.BulkPayout, sender *models.User, line *models.BulkPayoutLine) error {
+	if line.RecipientUserID == nil {
+		return ErrRecipientNotFound
+	}
+	var recipient models.User
+	if err := tx.First(&recipient, "id = ?", *line.RecipientUserID).Error; err != nil {
+		return ErrRecipientNotFound
+	}
+
+	// The recipient may have changed wallets since the upload
+	recipientWallet, err := bs.wallets.RecipientWallet(recipient.ID, line.Currency)
+	if err != nil {
+		return err
+	}
+
+	result, err := bs.transfers.transfer(tx, TransferInput{
+		SenderWalletID:    batch.WalletID,
+		RecipientWalletID: recipientWallet.ID,
+		Amount:            line.Amount,
+		Description:       line.Reference,
+		SenderMemo:        line.Reference + " (bulk payout, to " + recipient.Username + ")",
+		RecipientMemo:     line.Reference + " (from " + sender.Username + ")",
+		InitiatedBy:       batch.UserID,
+		feeType:           models.FeeTypeBulkPayout,
+	})
+	if err != nil {
+		return err
+	}
+
+	return tx.Model(line).Updates(map[string]interface{}{
+		"status":              models.BulkPayoutLineStatusSucceeded,
+		"fee":                 result.Fee,
+		"recipient_wallet_id": recipientWallet.ID,
+		"journal_entry_id":    result.Entry.ID,
+		"transaction_id":      result.SenderTransaction.ID,
+		"error":               "",
+	}).Error
+}
+
+// validateLine checks one uploaded line and prices it with the transfer fee
+func (bs *BulkPayoutService) validateLine(wallet *models.Wallet, limits *EffectiveLimits, input BulkPayoutLineInput) models.BulkPayoutLine {
+	line := models.BulkPayoutLine{
+		Recipient: strings.TrimSpace(input.Recipient),
+		Amount:    models.ZeroMoney(wallet.Currency),
+		Fee:       models.ZeroMoney(wallet.Currency),
+		Currency:  wallet.Currency,
+		Reference: strings.TrimSpace(input.Reference),
+		Status:    models.BulkPayoutLineStatusInvalid,
+	}
+	if len(line.Recipient) > 100 {
+		line.Recipient = line.Recipient[:100]
+	}
+	if len(line.Reference) > 255 {
+		line.Error = "reference is longer than 255 characters"
+		line.Reference = line.Reference[:255]
+		return line
+	}
+	if input.invalid != "" {
+		line.Error = input.invalid
+		return line
+	}
+
+	if currency := strings.ToUpper(strings.TrimSpace(input.Currency)); currency != "" && currency != wallet.Currency {
+		line.Error = fmt.Sprintf("currency must be %s, the currency of the wallet", wallet.Currency)
+		return line
+	}
+	amount, err := input.Amount.In(wallet.Currency)
+	if err != nil || !amount.IsPositive() {
+		line.Error = "amount must be a positive amount in the wallet currency"
+		return line
+	}
+	line.Amount = amount
+	if amount.Amount < limits.MinPerTransaction.Amount {
+		line.Error = (&LimitExceededError{Limit: LimitMinPerTransaction, Allowed: limits.MinPerTransaction.String()}).Error()
+		return line
+	}
+	if amount.Amount > limits.MaxPerTransaction.Amount {
+		line.Error = (&LimitExceededError{Limit: LimitMaxPerTransaction, Allowed: limits.MaxPerTransaction.String()}).Error()
+		return line
+	}
+
+	recipient, err := bs.wallets.FindRecipient(line.Recipient)
+	if err != nil {
+		line.Error = err.Error()
+		return line
+	}
+	line.RecipientUserID = &recipient.ID
+	recipientWallet, err := bs.wallets.RecipientWallet(recipient.ID, wallet.Currency)
+	if err != nil {
+		line.Error = err.Error()
+		return line
+	}
+	if recipientWallet.ID == wallet.ID {
+		line.Error = ErrSameWallet.Error()
+		return line
+	}
+	line.RecipientWalletID = &recipientWallet.ID
+
+	// An estimate until the line is paid; free quota may run out part way through a batch
+	quote, err := bs.transfers.fees.Preview(wallet.UserID, models.FeeTypeBulkPayout, amount)
+	if err != nil {
+		line.Error = err.Error()
+		return line
+	}
+	line.Fee = quote.Fee
+	line.Status = models.BulkPayoutLineStatusValid
+	return line
+}
+
+// truncateError shortens an error message to fit a 255 character column
+func truncateError(err error) string {
+	reason := err.Error()
+	if len(reason) > 255 {
+		reason = reason[:255]
+	}
+	return reason
+}
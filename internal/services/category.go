@@ -0,0 +1,364 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+
+	"securewallet/internal/config"
+	"securewallet/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// maxRulesApplied bounds how many uncategorised transactions one ApplyRules call updates
+const maxRulesApplied = 5000
+
+// categoryColorPattern matches a #rrggbb colour
+var categoryColorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// Category errors
+var (
+	ErrCategoryNotFound = errors.New("category not found")
+	ErrCategoryExists   = errors.New("a category with this name already exists")
+	ErrInvalidCategory  = errors.New("category name is required and colour must look like #rrggbb")
+	ErrRuleNotFound     = errors.New("category rule not found")
+	ErrInvalidRule      = errors.New("rule needs a pattern, a match type of contains, starts_with or equals and a direction of in, out or empty")
+)
+
+// CategoryRuleInput describes a rule to create or replace
+type CategoryRuleInput struct {
+	CategoryID string
+	MatchType  string
+	Pattern    string
+	Direction  string
+	Priority   int
+	Enabled    bool
+}
+
+// CategoryService manages a user's categories and auto-categorisation rules
+type CategoryService struct {
+	db *gorm.DB
+}
+
+// NewCategoryService creates a new category service
+func NewCategoryService() *CategoryService {
+	return &CategoryService{db: config.GetDB()}
+}
+
+// List returns the user's categories by name
+func (cs *CategoryService) List(userID uuid.UUID) ([]models.Category, error) {
+	var categories []models.Category
+	if err := cs.db.Where("user_id = ?", userID).Order("name ASC").Find(&categories).Error; err != nil {
+		return nil, err
+	}
+	return categories, nil
+}
+
+// Create adds a category
+func (cs *CategoryService) Create(userID uuid.UUID, name, color string) (*models.Category, error) {
+	name = strings.TrimSpace(name)
+	if err := validateCategory(name, color); err != nil {
+		return nil, err
+	}
+	if err := cs.checkNameFree(userID, name, uuid.Nil); err != nil {
+		return nil, err
+	}
+
+	category := models.Category{UserID: userID, Name: name, Color: color}
+	if err := cs.db.Create(&category).Error; err != nil {
+		return nil, fmt.Errorf("failed to create category: %w", err)
+	}
+	return &category, nil
+}
+
+// Update renames or recolours a category
+func (cs *CategoryService) Update(userID uuid.UUID, categoryID, name, color string) (*models.Category, error) {
+	category, err := cs.Get(userID, categoryID)
+	if err != nil {
+		return nil, err
+	}
+	name = strings.TrimSpace(name)
+	if err := validateCategory(name, color); err != nil {
+		return nil, err
+	}
+	if err := cs.checkNameFree(userID, name, category.ID); err != nil {
+		return nil, err
+	}
+
+	if err := cs.db.Model(category).Updates(map[string]interface{}{"name": name, "color": color}).Error; err != nil {
+		return nil, fmt.Errorf("failed to update category: %w", err)
+	}
+	return category, nil
+}
+
+// Delete removes a category with its rules; transactions filed under it become uncategorised
+func (cs *CategoryService) Delete(userID uuid.UUID, categoryID string) error {
+	category, err := cs.Get(userID, categoryID)
+	if err != nil {
+		return err
+	}
+
+	return cs.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.TransactionAnnotation{}).
+			Where("user_id = ? AND category_id = ?", userID, category.ID).
+			Updates(map[string]interface{}{"category_id": nil, "category_source": "", "rule_id": nil}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ? AND category_id = ?", userID, category.ID).Delete(&models.CategoryRule{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(category).Error
+	})
+}
+
+// Get returns one of the user's categories
+func (cs *CategoryService) Get(userID uuid.UUID, categoryID string) (*models.Category, error) {
+	id, err := uuid.Parse(categoryID)
+	if err != nil {
+		return nil, ErrCategoryNotFound
+	}
+
+	var category models.Category
+	if err := cs.db.Where("id = ? AND user_id = ?", id, userID).First(&category).Error; err != nil {
+		return nil, ErrCategoryNotFound
+	}
+	return &category, nil
+}
+
+// Rules returns the user's rules in the order they are applied
+func (cs *CategoryService) Rules(userID uuid.UUID) ([]models.CategoryRule, error) {
+	return loadCategoryRules(cs.db, userID, false)
+}
+
+// CreateRule adds an auto-categorisation rule
+func (cs *CategoryService) CreateRule(userID uuid.UUID, input CategoryRuleInput) (*models.CategoryRule, error) {
+	rule := models.CategoryRule{UserID: userID}
+	if err := cs.fillRule(userID, &rule, input); err != nil {
+		return nil, err
+	}
+	if err := cs.db.Create(&rule).Error; err != nil {
+		return nil, fmt.Errorf("failed to create rule: %w", err)
+	}
+	return &rule, nil
+}
+
+// UpdateRule replaces a rule. Transactions it already categorised keep their category.
+func (cs *CategoryService) UpdateRule(userID uuid.UUID, ruleID string, input CategoryRuleInput) (*models.CategoryRule, error) {
+	rule, err := cs.rule(userID, ruleID)
+	if err != nil {
+		return nil, err
+	}
+	if err := cs.fillRule(userID, rule, input); err != nil {
+		return nil, err
+	}
+	if err := cs.db.Save(rule).Error; err != nil {
+		return nil, fmt.Errorf("failed to update rule: %w", err)
+	}
+	return rule, nil
+}
+
+// DeleteRule removes a rule
+func (cs *CategoryService) DeleteRule(userID uuid.UUID, ruleID string) error {
+	rule, err := cs.rule(userID, ruleID)
+	if err != nil {
+		return err
+	}
+	return cs.db.Delete(rule).Error
+}
+
+// ApplyRules categorises the user's existing transactions that have no category yet
+func (cs *CategoryService) ApplyRules(userID uuid.UUID) (int, error) {
+	rules, err := loadCategoryRules(cs.db, userID, true)
+	if err != nil {
+		return 0, err
+	}
+	if len(rules) == 0 {
+		return 0, nil
+	}
+
+	var transactions []models.Transaction
+	if err := cs.db.Where("wallet_id IN (?)", cs.db.Unscoped().Model(&models.Wallet{}).Select("id").Where("user_id = ?", userID)).
+		Where("id NOT IN (?)", cs.db.Model(&models.TransactionAnnotation{}).Select("transaction_id").
+			Where("user_id = ? AND category_id IS NOT NULL", userID)).
+		Order("created_at DESC").
+		Limit(maxRulesApplied).
+		Find(&transactions).Error; err != nil {
+		return 0, fmt.Errorf("failed to load transactions: %w", err)
+	}
+
+	categorised := 0
+	for i := range transactions {
+		rule := matchCategoryRule(rules, &transactions[i])
+		if rule == nil {
+			continue
+		}
+		if err := setRuleCategory(cs.db, userID, transactions[i].ID, rule); err != nil {
+			return categorised, err
+		}
+		categorised++
+	}
+	return categorised, nil
+}
+
+// checkNameFree rejects a name already used by another of the user's categories
+func (cs *CategoryService) checkNameFree(userID uuid.UUID, name string, except uuid.UUID) error {
+	var count int64
+	if err := cs.db.Model(&models.Category{}).
+		Where("user_id = ? AND name = ? AND id <> ?", userID, name, except).
+		Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrCategoryExists
+	}
+	return nil
+}
+
+// rule loads one of the user's rules
+func (cs *CategoryService) rule(userID uuid.UUID, ruleID string) (*models.CategoryRule, error) {
+	id, err := uuid.Parse(ruleID)
+	if err != nil {
+		return nil, ErrRuleNotFound
+	}
+
+	var rule models.CategoryRule
+	if err := cs.db.Where("id = ? AND user_id = ?", id, userID).First(&rule).Error; err != nil {
+		return nil, ErrRuleNotFound
+	}
+	return &rule, nil
+}
+
+// fillRule validates a rule input and copies it onto the rule
+func (cs *CategoryService) fillRule(userID uuid.UUID, rule *models.CategoryRule, input CategoryRuleInput) error {
+	category, err := cs.Get(userID, input.CategoryID)
+	if err != nil {
+		return err
+	}
+
+	pattern := strings.TrimSpace(input.Pattern)
+	matchType := strings.ToLower(input.MatchType)
+	if matchType == "" {
+		matchType = models.CategoryMatchContains
+	}
+	direction := strings.ToLower(input.Direction)
+	if pattern == "" ||
+		(matchType != models.CategoryMatchContains && matchType != models.CategoryMatchStartsWith && matchType != models.CategoryMatchEquals) ||
+		(direction != "" && direction != "in" && direction != "out") {
+		return ErrInvalidRule
+	}
+
+	rule.CategoryID = category.ID
+	rule.MatchType = matchType
+	rule.Pattern = pattern
+	rule.Direction = direction
+	rule.Priority = input.Priority
+	rule.Enabled = input.Enabled
+	return nil
+}
+
+// validateCategory checks a category name and optional colour
+func validateCategory(name, color string) error {
+	if name == "" || len([]rune(name)) > 50 {
+		return ErrInvalidCategory
+	}
+	if color != "" && !categoryColorPattern.MatchString(color) {
+		return ErrInvalidCategory
+	}
+	return nil
+}
+
+// loadCategoryRules returns a user's rules by priority, oldest first on ties
+func loadCategoryRules(db *gorm.DB, userID uuid.UUID, enabledOnly bool) ([]models.CategoryRule, error) {
+	query := db.Where("user_id = ?", userID)
+	if enabledOnly {
+		query = query.Where("enabled = ?", true)
+	}
+
+	var rules []models.CategoryRule
+	if err := query.Order("priority ASC, created_at ASC").Find(&rules).Error; err != nil {
+		return nil, fmt.Errorf("failed to load category rules: %w", err)
+	}
+	return rules, nil
+}
+
+// matchCategoryRule returns the first rule that matches a transaction
+func matchCategoryRule(rules []models.CategoryRule, transaction *models.Transaction) *models.CategoryRule {
+	description := strings.ToLower(transaction.Description)
+	for i := range rules {
+		rule := &rules[i]
+		if rule.Direction != "" && rule.Direction != transaction.Direction {
+			continue
+		}
+		pattern := strings.ToLower(rule.Pattern)
+		switch rule.MatchType {
+		case models.CategoryMatchEquals:
+			if description == pattern {
+				return rule
+			}
+		case models.CategoryMatchStartsWith:
+			if strings.HasPrefix(description, pattern) {
+				return rule
+			}
+		default:
+			if strings.Contains(description, pattern) {
+				return rule
+			}
+		}
+	}
+	return nil
+}
+
+// setRuleCategory files a transaction under a rule's category unless the user
+// already chose one by hand
+func setRuleCategory(db *gorm.DB, userID, transactionID uuid.UUID, rule *models.CategoryRule) error {
+	var annotation models.TransactionAnnotation
+	err := db.Where("user_id = ? AND transaction_id = ?", userID, transactionID).First(&annotation).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		annotation = models.TransactionAnnotation{
+			UserID:         userID,
+			TransactionID:  transactionID,
+			CategoryID:     &rule.CategoryID,
+			CategorySource: models.CategorySourceRule,
+			RuleID:         &rule.ID,
+		}
+		return db.Create(&annotation).Error
+	}
+	if err != nil {
+		return err
+	}
+	if annotation.CategoryID != nil {
+		return nil
+	}
+	return db.Model(&annotation).Updates(map[string]interface{}{
+		"category_id":     rule.CategoryID,
+		"category_source": models.CategorySourceRule,
+		"rule_id":         rule.ID,
+	}).Error
+}
+
+// autoCategorize applies the wallet owner's rules to a newly posted transaction.
+// A failure is logged rather than returned so that it never blocks a payment.
+func autoCategorize(tx *gorm.DB, transaction *models.Transaction) {
+	var wallet models.Wallet
+	if err := tx.Select("id", "user_id").First(&wallet, "id = ?", transaction.WalletID).Error; err != nil {
+		log.Printf("Auto-categorisation skipped for transaction %s: %v", transaction.ID, err)
+		return
+	}
+
+	rules, err := loadCategoryRules(tx, wallet.UserID, true)
+	if err != nil {
+		log.Printf("Auto-categorisation skipped for transaction %s: %v", transaction.ID, err)
+		return
+	}
+	rule := matchCategoryRule(rules, transaction)
+	if rule == nil {
+		return
+	}
+	if err := setRuleCategory(tx, wallet.UserID, transaction.ID, rule); err != nil {
+		log.Printf("Auto-categorisation failed for transaction %s: %v", transaction.ID, err)
+	}
+}
@@ -0,0 +1,678 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"securewallet/internal/config"
+	"securewallet/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Checkout session expiry bounds
+const (
+	DefaultCheckoutTTL       = 30 * time.Minute
+	MaxCheckoutTTL           = 24 * time.Hour
+	checkoutAuthorizationTTL = 7 * 24 * time.Hour // How long a manual-capture checkout holds the customer's funds
+)
+
+// Checkout errors
+var (
+	ErrCheckoutNotFound       = errors.New("checkout session not found")
+	ErrCheckoutClosed         = errors.New("checkout session is no longer open")
+	ErrCheckoutExpired        = errors.New("checkout session has expired")
+	ErrCheckoutNotAuthorized  = errors.New("checkout session is not waiting to be captured")
+	ErrCheckoutNotCompleted   = errors.New("only completed checkout sessions can be refunded")
+	ErrCheckoutNotCancellable = errors.New("only open or authorized checkout sessions can be cancelled")
+	ErrSelfCheckout           = errors.New("cannot pay your own checkout session")
+	ErrInvalidReturnURL       = errors.New("return URL must be an absolute http or https URL")
+	ErrInvalidCheckoutExpiry  = errors.New("expiry must be between 1 minute and 24 hours")
+	ErrInvalidCaptureMethod   = errors.New("capture method must be automatic or manual")
+)
+
+// CheckoutInput describes a checkout session a merchant creates
+type CheckoutInput struct {
+	Amount        models.Money
+	Description   string
+	Reference     string // The merchant's order ID
+	ReturnURL     string // Where the customer is sent after confirming
+	CaptureMethod string // automatic (default) or manual
+	ExpiresIn     time.Duration
+}
+
+// CheckoutView is what a customer sees of a checkout session before confirming it
+type CheckoutView struct {
+	ID              uuid.UUID    `json:"id"`
+	MerchantName    string       `json:"merchant_name"`
+	MerchantWebsite string       `json:"merchant_website,omitempty"`
+	Amount          models.Money `json:"amount"`
+	Description     string       `json:"description"`
+	Status          string       `json:"status"`
+	ExpiresAt       time.Time    `json:"expires_at"`
+}
+
+// CheckoutResult is the outcome of a customer confirming a checkout session
+type CheckoutResult struct {
+	Session     *models.CheckoutSession
+	Transfer    *TransferResult // Nil for manual capture, where only a hold is placed
+	RedirectURL string
+}
+
+// CheckoutService runs checkout sessions: a merchant creates one, a wallet user
+// confirms it, and the payment is booked as a transfer with the merchant fee
+type CheckoutService struct {
+	db        *gorm.DB
+	ledger    *LedgerService
+	transfers *TransferService
+	holds     *HoldService
+	wallets   *WalletService
+}
+
+// NewCheckoutService creates a new checkout service
+func NewCheckoutService() *CheckoutService {
+	return &CheckoutService{
+		db:        config.GetDB(),
+		ledger:    NewLedgerService(),
+		transfers: NewTransferService(),
+		holds:     NewHoldService(),
+		wallets:   NewWalletService(),
+	}
+}
+
+// Create opens a checkout session paying into the merchant's wallet
+func (cs *CheckoutService) Create(merchant *models.Merchant, input CheckoutInput) (*models.CheckoutSession, error) {
+	var wallet models.Wallet
+	if err := cs.db.First(&wallet, "id = ?", merchant.WalletID).Error; err != nil {
+		return nil, ErrWalletNotFound
+	}
+	if wallet.Status != models.WalletStatusActive {
+		return nil, ErrWalletClosed
+	}
+
+	amount, err := input.Amount.In(wallet.Currency)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidAmount, err)
+	}
+	if !amount.IsPositive() {
+		return nil, ErrInvalidAmount
+	}
+	returnURL, err := url.Parse(strings.TrimSpace(input.ReturnURL))
+	if err != nil || (returnURL.Scheme != "https" && returnURL.Scheme != "http") || returnURL.Host == "" {
+		return nil, ErrInvalidReturnURL
+	}
+	captureMethod := input.CaptureMethod
+	if captureMethod == "" {
+		captureMethod = models.CaptureMethodAutomatic
+	}
+	if captureMethod != models.CaptureMethodAutomatic && captureMethod != models.CaptureMethodManual {
+		return nil, ErrInvalidCaptureMethod
+	}
+	ttl := input.ExpiresIn
+	if ttl == 0 {
+		ttl = DefaultCheckoutTTL
+	}
+	if ttl < time.Minute || ttl > MaxCheckoutTTL {
+		return nil, ErrInvalidCheckoutExpiry
+	}
+
+	session := models.CheckoutSession{
+		MerchantID:     merchant.ID,
+		WalletID:       wallet.ID,
+		Amount:         amount,
+		Currency:       amount.Currency,
+		Description:    strings.TrimSpace(input.Description),
+		Reference:      strings.TrimSpace(input.Reference),
+		ReturnURL:      returnURL.String(),
+		CaptureMethod:  captureMethod,
+		Status:         models.CheckoutStatusOpen,
+		CapturedAmount: models.ZeroMoney(amount.Currency),
+		Fee:            models.ZeroMoney(amount.Currency),
+		RefundedAmount: models.ZeroMoney(amount.Currency),
+		ExpiresAt:      time.Now().Add(ttl).Truncate(time.Second),
+	}
+	if err := cs.db.Create(&session).Error; err != nil {
+		return nil, fmt.Errorf("failed to create checkout session: %w", err)
+	}
+	return &session, nil
+}
+
+// CheckoutURL is the page where the customer reviews and confirms a session
+func (cs *CheckoutService) CheckoutURL(session *models.CheckoutSession) string {
+	return publicAppURL() + "/checkout/" + session.ID.String()
+}
+
+// List returns the merchant's checkout sessions, newest first, optionally filtered by status
+func (cs *CheckoutService) List(merchant *models.Merchant, status string, limit int) ([]models.CheckoutSession, error) {
+	query := cs.db.Where("merchant_id = ?", merchant.ID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	sessions := []models.CheckoutSession{}
+	if err := query.Order("created_at DESC").Limit(limit).Find(&sessions).Error; err != nil {
+		return nil, err
+	}
+	for i := range sessions {
+		cs.expire(&sessions[i])
+	}
+	return sessions, nil
+}
+
+// Get returns one of the merchant's checkout sessions with its refunds
+func (cs *CheckoutService) Get(merchant *models.Merchant, sessionID string) (*models.CheckoutSession, error) {
+	id, err := uuid.Parse(sessionID)
+	if err != nil {
+		return nil, ErrCheckoutNotFound
+	}
+
+	var session models.CheckoutSession
+	if err := cs.db.Preload("Refunds").First(&session, "id = ? AND merchant_id = ?", id, merchant.ID).Error; err != nil {
+		return nil, ErrCheckoutNotFound
+	}
+	cs.expire(&session)
+	return &session, nil
+}
+
+// View returns what a customer needs to see to confirm a checkout session
+func (cs *CheckoutService) View(sessionID string) (*CheckoutView, error) {
+	id, err := uuid.Parse(sessionID)
+	if err != nil {
+		return nil, ErrCheckoutNotFound
+	}
+
+	var session models.CheckoutSession
+	if err := cs.db.First(&session, "id = ?", id).Error; err != nil {
+		return nil, ErrCheckoutNotFound
+	}
+	var merchant models.Merchant
+	if err := cs.db.First(&merchant, "id = ?", session.MerchantID).Error; err != nil {
+		return nil, ErrCheckoutNotFound
+	}
+	cs.expire(&session)
+
+	return &CheckoutView{
+		ID:              session.ID,
+		MerchantName:    merchant.Name,
+		MerchantWebsite: merchant.Website,
+		Amount:          session.Amount,
+		Description:     session.Description,
+		Status:          session.Status,
+		ExpiresAt:       session.ExpiresAt,
+	}, nil
+}
+
+// Confirm pays an open checkout session from one of the customer's wallets,
+// defaulting to their wallet in the session currency. Automatic sessions are
+// booked at once; manual ones place a hold for the merchant to capture.
+func (cs *CheckoutService) Confirm(payerID uuid.UUID, sessionID, walletID string) (*CheckoutResult, error) {
+	id, err := uuid.Parse(sessionID)
+	if err != nil {
+		return nil, ErrCheckoutNotFound
+	}
+	var session models.CheckoutSession
+	if err := cs.db.First(&session, "id = ?", id).Error; err != nil {
+		return nil, ErrCheckoutNotFound
+	}
+	var merchant models.Merchant
+	if err := cs.db.First(&merchant, "id = ?", session.MerchantID).Error; err != nil {
+		return nil, ErrCheckoutNotFound
+	}
+	if merchant.Status != models.MerchantStatusActive {
+		return nil, ErrMerchantSuspended
+	}
+	if merchant.UserID == payerID {
+		return nil, ErrSelfCheckout
+	}
+
+	var payerWallet *models.Wallet
+	if walletID == "" {
+		payerWallet, err = cs.wallets.RecipientWallet(payerID, session.Currency)
+		if errors.Is(err, ErrNoRecipientWallet) {
+			err = fmt.Errorf("%w: no %s wallet to pay from", ErrCurrencyMismatch, session.Currency)
+		}
+	} else {
+		payerWallet, err = cs.wallets.MemberWallet(payerID, walletID, models.WalletRoleSpender)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := checkOrganizationPayment(payerWallet); err != nil {
+		return nil, err
+	}
+
+	var payer models.User
+	if err := cs.db.First(&payer, "id = ?", payerID).Error; err != nil {
+		return nil, err
+	}
+	description := session.Description
+	if description == "" {
+		description = "Checkout payment"
+	}
+
+	var result CheckoutResult
+	err = cs.transfers.withRetry(func(tx *gorm.DB) error {
+		locked, err := lockCheckout(tx, session.ID, session.MerchantID)
+		if err != nil {
+			return err
+		}
+		if locked.Status != models.CheckoutStatusOpen {
+			return ErrCheckoutClosed
+		}
+		if !time.Now().Before(locked.ExpiresAt) {
+			return ErrCheckoutExpired
+		}
+
+		now := time.Now()
+		updates := map[string]interface{}{
+			"payer_id":        payerID,
+			"payer_wallet_id": payerWallet.ID,
+		}
+		event := WebhookEventCheckoutCompleted
+
+		if locked.CaptureMethod == models.CaptureMethodManual {
+			wallets, err := cs.transfers.lockWallets(tx, payerWallet.ID)
+			if err != nil {
+				return err
+			}
+			lockedWallet := wallets[payerWallet.ID]
+			if err := checkMemberSpend(tx, &lockedWallet, payerID, locked.Amount); err != nil {
+				return err
+			}
+			if err := cs.transfers.limits.Check(tx, lockedWallet.UserID, locked.Amount); err != nil {
+				return err
+			}
+
+			expiresAt := now.Add(checkoutAuthorizationTTL)
+			hold, err := cs.holds.place(tx, &lockedWallet, HoldInput{
+				WalletID:    lockedWallet.ID,
+				Amount:      locked.Amount,
+				Kind:        models.HoldKindAuthorization,
+				Reference:   locked.ID.String(),
+				Description: description + " (" + merchant.Name + ")",
+				PlacedBy:    payerID,
+				ExpiresAt:   &expiresAt,
+			})
+			if err != nil {
+				return err
+			}
+			updates["status"] = models.CheckoutStatusAuthorized
+			updates["hold_id"] = hold.ID
+			updates["authorization_expires_at"] = &expiresAt
+			event = WebhookEventCheckoutAuthorized
+		} else {
+			transfer, err := cs.transfers.transfer(tx, TransferInput{
+				SenderWalletID:    payerWallet.ID,
+				RecipientWalletID: locked.WalletID,
+				Amount:            locked.Amount,
+				Description:       description,
+				SenderMemo:        description + " (" + merchant.Name + ")",
+				RecipientMemo:     description + " (paid by " + payer.Username + ")",
+				InitiatedBy:       payerID,
+				feeType:           models.FeeTypeCheckout,
+			})
+			if err != nil {
+				return err
+			}
+			updates["status"] = models.CheckoutStatusCompleted
+			updates["captured_amount"] = locked.Amount
+			updates["fee"] = transfer.Fee
+			updates["journal_entry_id"] = transfer.Entry.ID
+			updates["completed_at"] = &now
+			result.Transfer = transfer
+		}
+
+		if err := tx.Model(locked).Updates(updates).Error; err != nil {
+			return err
+		}
+		if err := tx.First(locked, "id = ?", locked.ID).Error; err != nil {
+			return err
+		}
+		result.Session = locked
+		return queueWebhook(tx, &merchant, event, locked)
+	})
+	if errors.Is(err, ErrCheckoutExpired) {
+		cs.db.Model(&models.CheckoutSession{}).
+			Where("id = ? AND status = ?", session.ID, models.CheckoutStatusOpen).
+			Update("status", models.CheckoutStatusExpired)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result.RedirectURL = checkoutRedirectURL(result.Session)
+	return &result, nil
+}
+
+// Capture books an authorized checkout session, for its full amount or less
+// (nil captures everything). Whatever is not captured goes back to the customer.
+func (cs *CheckoutService) Capture(merchant *models.Merchant, sessionID string, amount *models.Money) (*models.CheckoutSession, error) {
+	id, err := uuid.Parse(sessionID)
+	if err != nil {
+		return nil, ErrCheckoutNotFound
+	}
+
+	var session *models.CheckoutSession
+	err = cs.transfers.withRetry(func(tx *gorm.DB) error {
+		locked, err := lockCheckout(tx, id, merchant.ID)
+		if err != nil {
+			return err
+		}
+		if locked.Status != models.CheckoutStatusAuthorized || locked.HoldID == nil || locked.PayerID == nil {
+			return ErrCheckoutNotAuthorized
+		}
+		if locked.AuthorizationExpiresAt != nil && !time.Now().Before(*locked.AuthorizationExpiresAt) {
+			return ErrCheckoutExpired
+		}
+
+		// Both wallets are locked up front, in lockWallets order, before the hold
+		if locked.PayerWalletID != nil {
+			if _, err := cs.transfers.lockWallets(tx, *locked.PayerWalletID, locked.WalletID); err != nil {
+				return err
+			}
+		}
+		hold, err := cs.holds.settle(tx, *locked.HoldID, amount)
+		if errors.Is(err, ErrHoldNotActive) {
+			return ErrCheckoutExpired
+		}
+		if err != nil {
+			return err
+		}
+
+		var payer models.User
+		if err := tx.First(&payer, "id = ?", *locked.PayerID).Error; err != nil {
+			return err
+		}
+		description := locked.Description
+		if description == "" {
+			description = "Checkout payment"
+		}
+		transfer, err := cs.transfers.transfer(tx, TransferInput{
+			SenderWalletID:    hold.WalletID,
+			RecipientWalletID: locked.WalletID,
+			Amount:            hold.CapturedAmount,
+			Description:       description,
+			SenderMemo:        description + " (" + merchant.Name + ")",
+			RecipientMemo:     description + " (paid by " + payer.Username + ")",
+			InitiatedBy:       *locked.PayerID,
+			feeType:           models.FeeTypeCheckout,
+		})
+		if err != nil {
+			return err
+		}
+		if err := tx.Model(hold).Update("journal_entry_id", transfer.Entry.ID).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		if err := tx.Model(locked).Updates(map[string]interface{}{
+			"status":           models.CheckoutStatusCompleted,
+			"captured_amount":  hold.CapturedAmount,
+			"fee":              transfer.Fee,
+			"journal_entry_id": transfer.Entry.ID,
+			"completed_at":     &now,
+		}).Error; err != nil {
+			return err
+		}
+		if err := tx.First(locked, "id = ?", locked.ID).Error; err != nil {
+			return err
+		}
+		session = locked
+		return queueWebhook(tx, merchant, WebhookEventCheckoutCompleted, locked)
+	})
+	if errors.Is(err, ErrCheckoutExpired) {
+		cs.db.Model(&models.CheckoutSession{}).
+			Where("id = ? AND status = ?", id, models.CheckoutStatusAuthorized).
+			Update("status", models.CheckoutStatusExpired)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// Cancel closes an open session, or releases the hold of an authorized one
+func (cs *CheckoutService) Cancel(merchant *models.Merchant, sessionID string) (*models.CheckoutSession, error) {
+	id, err := uuid.Parse(sessionID)
+	if err != nil {
+		return nil, ErrCheckoutNotFound
+	}
+
+	var session *models.CheckoutSession
+	err = cs.transfers.withRetry(func(tx *gorm.DB) error {
+		locked, err := lockCheckout(tx, id, merchant.ID)
+		if err != nil {
+			return err
+		}
+		switch locked.Status {
+		case models.CheckoutStatusOpen:
+		case models.CheckoutStatusAuthorized:
+			// An expired authorisation may already have been released
+			if _, err := cs.holds.release(tx, *locked.HoldID, models.HoldStatusReleased); err != nil && !errors.Is(err, ErrHoldNotActive) {
+				return err
+			}
+		default:
+			return ErrCheckoutNotCancellable
+		}
+
+		now := time.Now()
+		if err := tx.Model(locked).Updates(map[string]interface{}{
+			"status":       models.CheckoutStatusCancelled,
+			"cancelled_at": &now,
+		}).Error; err != nil {
+			return err
+		}
+		locked.Status = models.CheckoutStatusCancelled
+		locked.CancelledAt = &now
+		session = locked
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// Refund returns part or all of a completed checkout (nil refunds what has not
+// been refunded yet) from the merchant's wallet to the customer's. The merchant
+// fee is not refunded.
+func (cs *CheckoutService) Refund(merchant *models.Merchant, sessionID string, amount *models.Money, reason string) (*models.CheckoutRefund, error) {
+	id, err := uuid.Parse(sessionID)
+	if err != nil {
+		return nil, ErrCheckoutNotFound
+	}
+	reason = strings.TrimSpace(reason)
+
+	var refund models.CheckoutRefund
+	err = cs.transfers.withRetry(func(tx *gorm.DB) error {
+		locked, err := lockCheckout(tx, id, merchant.ID)
+		if err != nil {
+			return err
+		}
+		if locked.Status != models.CheckoutStatusCompleted || locked.PayerID == nil || locked.PayerWalletID == nil {
+			return ErrCheckoutNotCompleted
+		}
+
+		remaining, _ := locked.CapturedAmount.Sub(locked.RefundedAmount)
+		if !remaining.IsPositive() {
+			return ErrNothingToReverse
+		}
+		refundAmount := remaining
+		if amount != nil {
+			if refundAmount, err = amount.In(locked.Currency); err != nil {
+				return fmt.Errorf("%w: %v", ErrInvalidAmount, err)
+			}
+			if !refundAmount.IsPositive() {
+				return ErrInvalidAmount
+			}
+			if refundAmount.Amount > remaining.Amount {
+				return ErrRefundTooLarge
+			}
+		}
+
+		// Refund into another wallet in the same currency if the customer closed the one they paid from
+		payerWalletID := *locked.PayerWalletID
+		var payerWallet models.Wallet
+		if err := tx.First(&payerWallet, "id = ?", payerWalletID).Error; err != nil {
+			return err
+		}
+		if payerWallet.Status == models.WalletStatusClosed {
+			fallback, err := cs.wallets.RecipientWallet(*locked.PayerID, locked.Currency)
+			if err != nil {
+				return err
+			}
+			payerWalletID = fallback.ID
+		}
+
+		wallets, err := cs.transfers.lockWallets(tx, locked.WalletID, payerWalletID)
+		if err != nil {
+			return err
+		}
+		merchantWallet := wallets[locked.WalletID]
+		customerWallet := wallets[payerWalletID]
+		if merchantWallet.Available().Amount < refundAmount.Amount {
+			return &InsufficientFundsError{Balance: merchantWallet.Available(), Required: refundAmount}
+		}
+
+		merchantAccount, err := cs.ledger.WalletAccount(tx, &merchantWallet)
+		if err != nil {
+			return err
+		}
+		customerAccount, err := cs.ledger.WalletAccount(tx, &customerWallet)
+		if err != nil {
+			return err
+		}
+		memo := "Refund"
+		if reason != "" {
+			memo = "Refund: " + reason
+		}
+		entry, err := cs.ledger.PostEntry(tx, LedgerEntry{
+			Type:        "CHECKOUT_REFUND",
+			Description: memo,
+			Reference:   locked.ID.String(),
+			Lines: []LedgerLine{
+				{AccountID: merchantAccount.ID, Amount: refundAmount.Neg(), Memo: memo},
+				{AccountID: customerAccount.ID, Amount: refundAmount, Memo: memo + " (" + merchant.Name + ")"},
+			},
+		})
+		if err != nil {
+			return err
+		}
+
+		refund = models.CheckoutRefund{
+			SessionID:      locked.ID,
+			Amount:         refundAmount,
+			Currency:       refundAmount.Currency,
+			Reason:         reason,
+			JournalEntryID: entry.ID,
+		}
+		if err := tx.Create(&refund).Error; err != nil {
+			return fmt.Errorf("failed to record refund: %w", err)
+		}
+		locked.RefundedAmount, _ = locked.RefundedAmount.Add(refundAmount)
+		if err := tx.Model(locked).Update("refunded_amount", locked.RefundedAmount).Error; err != nil {
+			return err
+		}
+
+		return queueWebhook(tx, merchant, WebhookEventCheckoutRefunded, map[string]interface{}{
+			"session": locked,
+			"refund":  refund,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &refund, nil
+}
+
+// expire marks an open session past its expiry, or an authorized one past its
+// authorisation expiry, as expired. The hold expiry job releases the funds.
+func (cs *CheckoutService) expire(session *models.CheckoutSession) {
+	now := time.Now()
+	switch {
+	case session.Status == models.CheckoutStatusOpen && !now.Before(session.ExpiresAt):
+	case session.Status == models.CheckoutStatusAuthorized && session.AuthorizationExpiresAt != nil && !now.Before(*session.AuthorizationExpiresAt):
+	default:
+		return
+	}
+
+	result := cs.db.Model(&models.CheckoutSession{}).
+		Where("id = ? AND status = ?", session.ID, session.Status).
+		Update("status", models.CheckoutStatusExpired)
+	if result.Error == nil && result.RowsAffected > 0 {
+		session.Status = models.CheckoutStatusExpired
+	}
+}
+
+// lockCheckout loads one of a merchant's checkout sessions FOR UPDATE
+func lockCheckout(tx *gorm.DB, sessionID, merchantID uuid.UUID) (*models.CheckoutSession, error) {
+	var session models.CheckoutSession
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&session, "id = ? AND merchant_id = ?", sessionID, merchantID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrCheckoutNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// lockAuthorizationCheckout locks the checkout session an authorization hold
+// was placed for, so that a hold expiring on its own can expire the session in
+// the same transaction. The session is locked before the hold and its wallet,
+// as the checkout paths do. Other holds have no session and return nil.
+func lockAuthorizationCheckout(tx *gorm.DB, holdID uuid.UUID) (*models.CheckoutSession, error) {
+	var hold models.Hold
+	if err := tx.Select("id", "kind", "reference").First(&hold, "id = ?", holdID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrHoldNotFound
+		}
+		return nil, err
+	}
+	if hold.Kind != models.HoldKindAuthorization {
+		return nil, nil
+	}
+	sessionID, err := uuid.Parse(hold.Reference)
+	if err != nil {
+		return nil, nil
+	}
+
+	var sessions []models.CheckoutSession
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ? AND hold_id = ?", sessionID, holdID).
+		Limit(1).Find(&sessions).Error; err != nil {
+		return nil, err
+	}
+	if len(sessions) == 0 {
+		return nil, nil
+	}
+	return &sessions[0], nil
+}
+
+// expireAuthorization marks a session whose authorization hold expired as
+// expired; sessions already completed or cancelled are left alone
+func expireAuthorization(tx *gorm.DB, session *models.CheckoutSession) error {
+	if session == nil || session.Status != models.CheckoutStatusAuthorized {
+		return nil
+	}
+	return tx.Model(session).Update("status", models.CheckoutStatusExpired).Error
+}
+
+// checkoutRedirectURL is the session's return URL with the session ID and status added
+func checkoutRedirectURL(session *models.CheckoutSession) string {
+	redirect, err := url.Parse(session.ReturnURL)
+	if err != nil {
+		return session.ReturnURL
+	}
+	query := redirect.Query()
+	query.Set("session_id", session.ID.String())
+	query.Set("status", session.Status)
+	redirect.RawQuery = query.Encode()
+	return redirect.String()
+}
@@ -0,0 +1,222 @@
+package services
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"securewallet/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// createTestMerchant creates a merchant paid into a new wallet
+func createTestMerchant(t *testing.T, db *gorm.DB) (*models.Merchant, *models.Wallet) {
+	t.Helper()
+
+	wallet := createTestWallet(t, db, "USD")
+	merchant := models.Merchant{
+		UserID:        wallet.UserID,
+		WalletID:      wallet.ID,
+		Name:          "Test Shop",
+		WebhookSecret: "secret",
+		Status:        models.MerchantStatusActive,
+	}
+	if err := db.Create(&merchant).Error; err != nil {
+		t.Fatalf("failed to create merchant: %v", err)
+	}
+	return &merchant, wallet
+}
+
+// openTestCheckout creates a checkout session for amount
+func openTestCheckout(t *testing.T, merchant *models.Merchant, amount, captureMethod string) *models.CheckoutSession {
+	t.Helper()
+
+	session, err := NewCheckoutService().Create(merchant, CheckoutInput{
+		Amount:        mustMoney(amount, "USD"),
+		Description:   "Order",
+		ReturnURL:     "https://shop.example.com/return",
+		CaptureMethod: captureMethod,
+	})
+	if err != nil {
+		t.Fatalf("failed to create checkout session: %v", err)
+	}
+	return session
+}
+
+// loadCheckout reloads a checkout session
+func loadCheckout(t *testing.T, db *gorm.DB, session *models.CheckoutSession) models.CheckoutSession {
+	t.Helper()
+
+	var stored models.CheckoutSession
+	if err := db.First(&stored, "id = ?", session.ID).Error; err != nil {
+		t.Fatalf("failed to load checkout session: %v", err)
+	}
+	return stored
+}
+
+func TestCheckoutAutomaticCapture(t *testing.T) {
+	db := setupTestDB(t)
+	merchant, merchantWallet := createTestMerchant(t, db)
+	payer := createTestWallet(t, db, "USD")
+	deposit(t, payer, "200.00")
+	checkout := NewCheckoutService()
+	session := openTestCheckout(t, merchant, "100.00", models.CaptureMethodAutomatic)
+
+	if _, err := checkout.Confirm(merchant.UserID, session.ID.String(), ""); !errors.Is(err, ErrSelfCheckout) {
+		t.Errorf("Confirm by the merchant = %v, want %v", err, ErrSelfCheckout)
+	}
+	result, err := checkout.Confirm(payer.UserID, session.ID.String(), "")
+	if err != nil {
+		t.Fatalf("Confirm failed: %v", err)
+	}
+	if result.Session.Status != models.CheckoutStatusCompleted || result.Transfer == nil {
+		t.Fatalf("confirmed session has status %s, want completed with a transfer", result.Session.Status)
+	}
+	if _, err := checkout.Confirm(payer.UserID, session.ID.String(), ""); !errors.Is(err, ErrCheckoutClosed) {
+		t.Errorf("second Confirm = %v, want %v", err, ErrCheckoutClosed)
+	}
+
+	// The merchant pays the fee out of the amount received
+	if got := walletBalance(t, db, payer.ID); got.Amount != 10000 {
+		t.Errorf("payer balance = %s, want 100.00", got)
+	}
+	if got := walletBalance(t, db, merchantWallet.ID); got.Amount != 10000-result.Session.Fee.Amount || !result.Session.Fee.IsPositive() {
+		t.Errorf("merchant balance = %s with fee %s, want 100.00 less the fee", got, result.Session.Fee)
+	}
+	if total := postingTotal(t, db, "USD"); !total.IsZero() {
+		t.Errorf("postings sum to %s, want zero", total)
+	}
+}
+
+func TestCheckoutManualCapture(t *testing.T) {
+	db := setupTestDB(t)
+	merchant, merchantWallet := createTestMerchant(t, db)
+	payer := createTestWallet(t, db, "USD")
+	deposit(t, payer, "200.00")
+	checkout := NewCheckoutService()
+	session := openTestCheckout(t, merchant, "100.00", models.CaptureMethodManual)
+
+	result, err := checkout.Confirm(payer.UserID, session.ID.String(), "")
+	if err != nil {
+		t.Fatalf("Confirm failed: %v", err)
+	}
+	if result.Session.Status != models.CheckoutStatusAuthorized || result.Session.HoldID == nil || result.Transfer != nil {
+		t.Fatalf("confirmed session has status %s and hold %v, want authorized with a hold", result.Session.Status, result.Session.HoldID)
+	}
+	if got := walletHeld(t, db, payer.ID); got.Amount != 10000 {
+		t.Errorf("payer held = %s, want 100.00", got)
+	}
+
+	partial := mustMoney("60.00", "USD")
+	captured, err := checkout.Capture(merchant, session.ID.String(), &partial)
+	if err != nil {
+		t.Fatalf("Capture failed: %v", err)
+	}
+	if captured.Status != models.CheckoutStatusCompleted || captured.CapturedAmount.Amount != 6000 {
+		t.Errorf("captured session has status %s for %s, want completed for 60.00", captured.Status, captured.CapturedAmount)
+	}
+	if _, err := checkout.Capture(merchant, session.ID.String(), nil); !errors.Is(err, ErrCheckoutNotAuthorized) {
+		t.Errorf("second Capture = %v, want %v", err, ErrCheckoutNotAuthorized)
+	}
+
+	// What was not captured goes back to the payer
+	if got := walletHeld(t, db, payer.ID); !got.IsZero() {
+		t.Errorf("payer held = %s, want zero", got)
+	}
+	if got := walletBalance(t, db, payer.ID); got.Amount != 14000 {
+		t.Errorf("payer balance = %s, want 140.00", got)
+	}
+	if got := walletBalance(t, db, merchantWallet.ID); got.Amount != 6000-captured.Fee.Amount {
+		t.Errorf("merchant balance = %s with fee %s, want 60.00 less the fee", got, captured.Fee)
+	}
+	if total := postingTotal(t, db, "USD"); !total.IsZero() {
+		t.Errorf("postings sum to %s, want zero", total)
+	}
+}
+
+func TestCheckoutAuthorizationExpires(t *testing.T) {
+	db := setupTestDB(t)
+	merchant, _ := createTestMerchant(t, db)
+	payer := createTestWallet(t, db, "USD")
+	deposit(t, payer, "200.00")
+	checkout := NewCheckoutService()
+	session := openTestCheckout(t, merchant, "100.00", models.CaptureMethodManual)
+	if _, err := checkout.Confirm(payer.UserID, session.ID.String(), ""); err != nil {
+		t.Fatalf("Confirm failed: %v", err)
+	}
+
+	// The session expires together with its hold
+	later := time.Now().Add(checkoutAuthorizationTTL + time.Hour)
+	if expired, err := NewHoldService().ExpireHolds(later); err != nil || expired != 1 {
+		t.Fatalf("ExpireHolds = %d, %v, want 1", expired, err)
+	}
+	if stored := loadCheckout(t, db, session); stored.Status != models.CheckoutStatusExpired {
+		t.Errorf("session status = %s, want expired", stored.Status)
+	}
+	if got := walletHeld(t, db, payer.ID); !got.IsZero() {
+		t.Errorf("payer held = %s, want zero", got)
+	}
+	if _, err := checkout.Capture(merchant, session.ID.String(), nil); !errors.Is(err, ErrCheckoutNotAuthorized) {
+		t.Errorf("Capture after expiry = %v, want %v", err, ErrCheckoutNotAuthorized)
+	}
+	if got := walletBalance(t, db, payer.ID); got.Amount != 20000 {
+		t.Errorf("payer balance = %s, want 200.00", got)
+	}
+}
+
+func TestCheckoutCaptureRacesExpiry(t *testing.T) {
+	db := setupTestDB(t)
+	merchant, _ := createTestMerchant(t, db)
+	payer := createTestWallet(t, db, "USD")
+	deposit(t, payer, "200.00")
+	checkout := NewCheckoutService()
+
+	var sessions []*models.CheckoutSession
+	for i := 0; i < 5; i++ {
+		session := openTestCheckout(t, merchant, "10.00", models.CaptureMethodManual)
+		if _, err := checkout.Confirm(payer.UserID, session.ID.String(), ""); err != nil {
+			t.Fatalf("Confirm failed: %v", err)
+		}
+		sessions = append(sessions, session)
+	}
+
+	var wg sync.WaitGroup
+	for _, session := range sessions {
+		wg.Add(1)
+		go func(session *models.CheckoutSession) {
+			defer wg.Done()
+			if _, err := checkout.Capture(merchant, session.ID.String(), nil); err != nil && !errors.Is(err, ErrCheckoutExpired) && !errors.Is(err, ErrCheckoutNotAuthorized) {
+				t.Errorf("Capture of session %s failed: %v", session.ID, err)
+			}
+		}(session)
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if _, err := NewHoldService().ExpireHolds(time.Now().Add(checkoutAuthorizationTTL + time.Hour)); err != nil {
+			t.Errorf("ExpireHolds failed: %v", err)
+		}
+	}()
+	wg.Wait()
+
+	// A session and its hold always end up in matching states
+	for _, session := range sessions {
+		stored := loadCheckout(t, db, session)
+		var hold models.Hold
+		db.First(&hold, "id = ?", *stored.HoldID)
+		switch {
+		case stored.Status == models.CheckoutStatusCompleted && hold.Status == models.HoldStatusCaptured:
+		case stored.Status == models.CheckoutStatusExpired && hold.Status == models.HoldStatusExpired:
+		default:
+			t.Errorf("session %s is %s with its hold %s", session.ID, stored.Status, hold.Status)
+		}
+	}
+	if got := walletHeld(t, db, payer.ID); !got.IsZero() {
+		t.Errorf("payer held = %s, want zero", got)
+	}
+	if total := postingTotal(t, db, "USD"); !total.IsZero() {
+		t.Errorf("postings sum to %s, want zero", total)
+	}
+}
@@ -19,10 +19,13 @@ type CommentConfig struct {
 	Enabled          bool          // Whether auto-approval is enabled
 }
 
-// Default comment configuration
-var DefaultCommentConfig = CommentConfig{
-	AutoApproveDelay: 1 * time.Minute, // Auto-approve after 1 minute (for testing)
-	Enabled:          true,              // Enable auto-approval
+// CurrentCommentConfig returns the comment configuration from the system settings
+func CurrentCommentConfig() CommentConfig {
+	settings := CurrentSettings().Comments
+	return CommentConfig{
+		AutoApproveDelay: time.Duration(settings.AutoApproveDelaySeconds) * time.Second,
+		Enabled:          settings.AutoApprovalEnabled,
+	}
 }
 
 // NewCommentService creates a new comment service
@@ -37,15 +40,11 @@ func NewCommentService() *CommentService {
 	return cs
 }
 
-// startCommentApprovalScheduler starts the automatic comment approval scheduler
+// startCommentApprovalScheduler starts the automatic comment approval scheduler.
+// The settings are read on every tick so that admins can change them at runtime.
 func (cs *CommentService) startCommentApprovalScheduler() {
-	if !DefaultCommentConfig.Enabled {
-		log.Println("Automatic comment approval is disabled")
-		return
-	}
-
 	log.Printf("Starting automatic comment approval scheduler (delay: %v)",
-		DefaultCommentConfig.AutoApproveDelay)
+		CurrentCommentConfig().AutoApproveDelay)
 
 	ticker := time.NewTicker(1 * time.Minute) // Check every minute
 	defer ticker.Stop()
@@ -57,7 +56,12 @@ func (cs *CommentService) startCommentApprovalScheduler() {
 
 // approvePendingComments approves comments that are older than the configured delay
 func (cs *CommentService) approvePendingComments() {
-	cutoffTime := time.Now().Add(-DefaultCommentConfig.AutoApproveDelay)
+	commentConfig := CurrentCommentConfig()
+	if !commentConfig.Enabled {
+		return
+	}
+
+	cutoffTime := time.Now().Add(-commentConfig.AutoApproveDelay)
 	
 	// Update comments that are pending and older than cutoff time
 	result := cs.db.Table("blog_comments").
@@ -84,13 +88,15 @@ func (cs *CommentService) GetCommentStats() map[string]interface{} {
 	cs.db.Table("blog_comments").Where("status = ?", "pending").Count(&pendingComments)
 	cs.db.Table("blog_comments").Where("status = ?", "approved").Count(&approvedComments)
 
+	commentConfig := CurrentCommentConfig()
+
 	return map[string]interface{}{
 		"total_comments":    totalComments,
 		"pending_comments":  pendingComments,
 		"approved_comments": approvedComments,
 		"auto_approval": map[string]interface{}{
-			"enabled": DefaultCommentConfig.Enabled,
-			"delay":   DefaultCommentConfig.AutoApproveDelay.String(),
+			"enabled": commentConfig.Enabled,
+			"delay":   commentConfig.AutoApproveDelay.String(),
 		},
 	}
 }
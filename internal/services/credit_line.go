@@ -0,0 +1,345 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"time"
+
+	"securewallet/internal/config"
+	"securewallet/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Credit line errors
+var (
+	ErrCreditLineNotFound  = errors.New("credit line not found")
+	ErrInvalidCreditLimit  = errors.New("credit limit must not be negative")
+	ErrInvalidCreditRate   = errors.New("annual rate must be between 0 and 10000 basis points")
+	ErrInvalidGracePeriod  = errors.New("grace period must be between 0 and 90 days")
+	ErrInvalidCreditStatus = errors.New("status must be active or suspended")
+)
+
+// maxGraceDays bounds the interest-free period of a credit line
+const maxGraceDays = 90
+
+// CreditLineService manages overdraft limits on wallets and charges interest
+// on negative balances
+type CreditLineService struct {
+	db        *gorm.DB
+	ledger    *LedgerService
+	transfers *TransferService
+	wallets   *WalletService
+}
+
+// CreditLineInput describes a credit line an admin grants or changes
+type CreditLineInput struct {
+	WalletID      uuid.UUID
+	Limit         models.Money
+	AnnualRateBps int64
+	GraceDays     int
+	Status        string // Defaults to active
+	UpdatedBy     uuid.UUID
+}
+
+// WalletCredit summarises the credit line of a wallet for its holder
+type WalletCredit struct {
+	CreditLine      *models.CreditLine    `json:"credit_line"`
+	Balance         models.Money          `json:"balance"`
+	Owed            models.Money          `json:"owed"`             // How far the balance is below zero
+	AvailableCredit models.Money          `json:"available_credit"` // What can still be borrowed
+	Charges         []models.CreditCharge `json:"charges"`
+}
+
+// NewCreditLineService creates a new credit line service
+func NewCreditLineService() *CreditLineService {
+	return &CreditLineService{
+		db:        config.GetDB(),
+		ledger:    NewLedgerService(),
+		transfers: NewTransferService(),
+		wallets:   NewWalletService(),
+	}
+}
+
+// spendableBalance returns what a locked wallet can pay out: its available
+// balance plus the limit of an active credit line
+func spendableBalance(tx *gorm.DB, wallet *models.Wallet) (models.Money, error) {
+	available := wallet.Available()
+
+	var line models.CreditLine
+	err := tx.Where("wallet_id = ? AND status = ?", wallet.ID, models.CreditLineStatusActive).First(&line).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return available, nil
+	}
+	if err != nil {
+		return models.Money{}, fmt.Errorf("failed to load credit line: %w", err)
+	}
+	return available.Add(line.Limit)
+}
+
+// List lists credit lines with their wallets, optionally only those over their limit
+func (cs *CreditLineService) List(overLimitOnly bool) ([]models.CreditLine, error) {
+	query := cs.db.Preload("Wallet").Order("created_at DESC")
+	if overLimitOnly {
+		query = query.Where("over_limit = ?", true)
+	}
+
+	var lines []models.CreditLine
+	if err := query.Find(&lines).Error; err != nil {
+		return nil, err
+	}
+	return lines, nil
+}
+
+// Set grants a credit line to a wallet or changes its terms. The wallet row is
+// locked so that a transfer in flight sees either the old or the new limit.
+func (cs *CreditLineService) Set(input CreditLineInput) (*models.CreditLine, error) {
+	if input.AnnualRateBps < 0 || input.AnnualRateBps > 10000 {
+		return nil, ErrInvalidCreditRate
+	}
+	if input.GraceDays < 0 || input.GraceDays > maxGraceDays {
+		return nil, ErrInvalidGracePeriod
+	}
+	if input.Status == "" {
+		input.Status = models.CreditLineStatusActive
+	}
+	if input.Status != models.CreditLineStatusActive && input.Status != models.CreditLineStatusSuspended {
+		return nil, ErrInvalidCreditStatus
+	}
+
+	var line models.CreditLine
+	err := cs.transfers.withRetry(func(tx *gorm.DB) error {
+		wallets, err := cs.transfers.lockWallets(tx, input.WalletID)
+		if err != nil {
+			return err
+		}
+		wallet := wallets[input.WalletID]
+
+		limit, err := input.Limit.In(wallet.Currency)
+		if err != nil {
+			return ErrCurrencyMismatch
+		}
+		if limit.IsNegative() {
+			return ErrInvalidCreditLimit
+		}
+
+		line = models.CreditLine{}
+		err = tx.Where("wallet_id = ?", wallet.ID).First(&line).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		line.WalletID = wallet.ID
+		line.Limit = limit
+		line.Currency = wallet.Currency
+		line.AnnualRateBps = input.AnnualRateBps
+		line.GraceDays = input.GraceDays
+		line.Status = input.Status
+		line.UpdatedBy = input.UpdatedBy
+		if err := tx.Save(&line).Error; err != nil {
+			return fmt.Errorf("failed to save credit line: %w", err)
+		}
+		line.Wallet = wallet
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &line, nil
+}
+
+// WalletCredit returns the credit line of one of the user's wallets with what
+// is owed on it and the interest charged so far
+func (cs *CreditLineService) WalletCredit(userID uuid.UUID, walletID string) (*WalletCredit, error) {
+	wallet, err := cs.wallets.UserWallet(userID, walletID)
+	if err != nil {
+		return nil, err
+	}
+
+	var line models.CreditLine
+	if err := cs.db.Where("wallet_id = ?", wallet.ID).First(&line).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCreditLineNotFound
+		}
+		return nil, err
+	}
+
+	charges := []models.CreditCharge{}
+	if err := cs.db.Where("credit_line_id = ?", line.ID).Order("period_start DESC").Find(&charges).Error; err != nil {
+		return nil, fmt.Errorf("failed to load credit charges: %w", err)
+	}
+
+	return &WalletCredit{
+		CreditLine:      &line,
+		Balance:         wallet.Balance,
+		Owed:            wallet.Balance.Min(models.ZeroMoney(wallet.Currency)).Neg(),
+		AvailableCredit: line.Available(wallet.Balance),
+		Charges:         charges,
+	}, nil
+}
+
+// RunMonthly charges interest for the month before now on every credit line
+// and flags the lines whose wallet is over its limit
+func (cs *CreditLineService) RunMonthly(now time.Time) (int, int, error) {
+	return cs.ChargeMonth(monthStart(dayStart(now)).AddDate(0, -1, 0))
+}
+
+// ChargeMonth charges the overdraft interest of a month to every credit line
+// not yet charged for it, then updates each line's over-limit flag. A line that
+// fails is logged and skipped so the rest are still charged. It returns how
+// many lines were charged and how many are over their limit.
+func (cs *CreditLineService) ChargeMonth(start time.Time) (int, int, error) {
+	start = monthStart(dayStart(start))
+	end := start.AddDate(0, 1, 0)
+
+	var lines []models.CreditLine
+	if err := cs.db.Where("created_at < ?", end).Find(&lines).Error; err != nil {
+		return 0, 0, fmt.Errorf("failed to load credit lines: %w", err)
+	}
+
+	charged, overLimit := 0, 0
+	var errs []error
+	for i := range lines {
+		ok, over, err := cs.chargeLine(&lines[i], start, end)
+		if errors.Is(err, ErrWalletClosed) || errors.Is(err, ErrWalletNotFound) {
+			continue
+		}
+		if err != nil {
+			err = fmt.Errorf("failed to charge credit line %s: %w", lines[i].ID, err)
+			log.Println(err)
+			errs = append(errs, err)
+			continue
+		}
+		if ok {
+			charged++
+		}
+		if over {
+			overLimit++
+		}
+	}
+	return charged, overLimit, errors.Join(errs...)
+}
+
+// chargeLine charges one credit line for a month and updates its over-limit
+// flag, reporting whether interest was charged and whether the line is over its limit
+func (cs *CreditLineService) chargeLine(line *models.CreditLine, start, end time.Time) (bool, bool, error) {
+	charged, over := false, false
+	err := cs.transfers.withRetry(func(tx *gorm.DB) error {
+		charged, over = false, false
+		wallets, err := cs.transfers.lockWallets(tx, line.WalletID)
+		if err != nil {
+			return err
+		}
+		wallet := wallets[line.WalletID]
+
+		var existing int64
+		if err := tx.Model(&models.CreditCharge{}).
+			Where("credit_line_id = ? AND period_start = ?", line.ID, start).
+			Count(&existing).Error; err != nil {
+			return err
+		}
+		if existing == 0 {
+			if charged, err = cs.charge(tx, line, &wallet, start, end); err != nil {
+				return err
+			}
+		}
+
+		over = wallet.Balance.Amount < -line.Limit.Amount
+		if over == line.OverLimit {
+			return nil
+		}
+		updates := map[string]interface{}{"over_limit": over, "over_limit_since": nil}
+		if over {
+			now := time.Now()
+			updates["over_limit_since"] = &now
+			log.Printf("Credit line %s of wallet %s is over its limit: balance %s, limit %s", line.ID, wallet.ID, wallet.Balance, line.Limit)
+		}
+		return tx.Model(&models.CreditLine{}).Where("id = ?", line.ID).Updates(updates).Error
+	})
+	return charged, over, err
+}
+
+// charge works out the interest a wallet owes for a month from its daily
+// closing balances, posts it and records the charge. The wallet's balance is
+// updated in place. A charge with no amount is still recorded so the month is
+// not looked at again.
+func (cs *CreditLineService) charge(tx *gorm.DB, line *models.CreditLine, wallet *models.Wallet, start, end time.Time) (bool, error) {
+	account, err := cs.ledger.WalletAccount(tx, wallet)
+	if err != nil {
+		return false, err
+	}
+
+	// Start early enough to know whether the grace period ran out before the month began
+	from := start.AddDate(0, 0, -line.GraceDays)
+	balance := models.ZeroMoney(wallet.Currency)
+	if err := tx.Model(&models.Posting{}).
+		Where("account_id = ? AND created_at < ?", account.ID, from).
+		Select("COALESCE(SUM(amount), 0)").
+		Row().Scan(&balance); err != nil {
+		return false, fmt.Errorf("failed to sum postings: %w", err)
+	}
+	var postings []models.Posting
+	if err := tx.Where("account_id = ? AND created_at >= ? AND created_at < ?", account.ID, from, end).
+		Order("created_at").
+		Find(&postings).Error; err != nil {
+		return false, fmt.Errorf("failed to load postings: %w", err)
+	}
+
+	var accrued int64
+	daysCharged, daysNegative := 0, 0
+	for day, next := from, 0; day.Before(end); day = day.AddDate(0, 0, 1) {
+		dayEnd := day.AddDate(0, 0, 1)
+		for ; next < len(postings) && postings[next].CreatedAt.Before(dayEnd); next++ {
+			if balance, err = balance.Add(postings[next].Amount); err != nil {
+				return false, err
+			}
+		}
+		if !balance.IsNegative() {
+			daysNegative = 0
+			continue
+		}
+		daysNegative++
+		if day.Before(start) || daysNegative <= line.GraceDays {
+			continue
+		}
+		n := new(big.Int).Mul(big.NewInt(-balance.Amount), big.NewInt(line.AnnualRateBps*accrualScale))
+		accrued += n.Quo(n, big.NewInt(10000*365)).Int64()
+		daysCharged++
+	}
+
+	creditCharge := models.CreditCharge{
+		CreditLineID: line.ID,
+		WalletID:     wallet.ID,
+		PeriodStart:  start,
+		DaysCharged:  daysCharged,
+		Amount:       models.NewMoney(roundAccrued(accrued), wallet.Currency),
+		Currency:     wallet.Currency,
+	}
+	if creditCharge.Amount.IsPositive() {
+		income, err := cs.ledger.SystemAccount(tx, SystemAccountInterestIncome, wallet.Currency)
+		if err != nil {
+			return false, err
+		}
+		memo := fmt.Sprintf("Overdraft interest for %s", start.Format("January 2006"))
+		entry, err := cs.ledger.PostEntry(tx, LedgerEntry{
+			Type:        "OVERDRAFT_INTEREST",
+			Description: memo,
+			Reference:   fmt.Sprintf("credit:%s", start.Format("2006-01")),
+			Lines: []LedgerLine{
+				{AccountID: account.ID, Amount: creditCharge.Amount.Neg(), Memo: memo},
+				{AccountID: income.ID, Amount: creditCharge.Amount, Memo: memo},
+			},
+		})
+		if err != nil {
+			return false, err
+		}
+		creditCharge.JournalEntryID = &entry.ID
+		wallet.Balance, _ = wallet.Balance.Sub(creditCharge.Amount)
+	}
+	if err := tx.Create(&creditCharge).Error; err != nil {
+		return false, fmt.Errorf("failed to record credit charge: %w", err)
+	}
+	return creditCharge.JournalEntryID != nil, nil
+}
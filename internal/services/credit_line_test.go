@@ -0,0 +1,117 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"securewallet/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// overdraw grants a wallet a credit line and spends past zero, then dates the
+// line and the wallet's postings back to the given time
+func overdraw(t *testing.T, db *gorm.DB, wallet *models.Wallet, amount string, at time.Time) *models.CreditLine {
+	t.Helper()
+
+	line, err := NewCreditLineService().Set(CreditLineInput{
+		WalletID:      wallet.ID,
+		Limit:         mustMoney("1000.00", wallet.Currency),
+		AnnualRateBps: 3650, // 0.1% a day
+		UpdatedBy:     uuid.New(),
+	})
+	if err != nil {
+		t.Fatalf("failed to set credit line: %v", err)
+	}
+	payee := createTestWallet(t, db, wallet.Currency)
+	if _, err := NewTransferService().Transfer(TransferInput{
+		SenderWalletID:    wallet.ID,
+		RecipientWalletID: payee.ID,
+		Amount:            mustMoney(amount, wallet.Currency),
+		InitiatedBy:       wallet.UserID,
+	}); err != nil {
+		t.Fatalf("failed to overdraw wallet: %v", err)
+	}
+
+	account, err := NewLedgerService().WalletAccount(db, wallet)
+	if err != nil {
+		t.Fatalf("failed to load wallet account: %v", err)
+	}
+	if err := db.Model(&models.Posting{}).Where("account_id = ?", account.ID).Update("created_at", at).Error; err != nil {
+		t.Fatalf("failed to backdate postings: %v", err)
+	}
+	if err := db.Model(&models.CreditLine{}).Where("id = ?", line.ID).Update("created_at", at).Error; err != nil {
+		t.Fatalf("failed to backdate credit line: %v", err)
+	}
+	return line
+}
+
+func TestChargeMonth(t *testing.T) {
+	db := setupTestDB(t)
+	june := time.Date(2026, time.June, 1, 0, 0, 0, 0, time.Local)
+	wallet := createTestWallet(t, db, "USD")
+	line := overdraw(t, db, wallet, "100.00", june.Add(10*time.Hour))
+	lines := NewCreditLineService()
+
+	// 101.00 owed (with the fee) for 30 days at 0.1% a day
+	charged, overLimit, err := lines.ChargeMonth(june)
+	if err != nil || charged != 1 || overLimit != 0 {
+		t.Fatalf("ChargeMonth = %d, %d, %v, want 1 charged and none over the limit", charged, overLimit, err)
+	}
+	if got := walletBalance(t, db, wallet.ID); got.Amount != -10403 {
+		t.Errorf("wallet balance = %s, want -104.03", got)
+	}
+	if got := systemBalance(t, db, SystemAccountInterestIncome, "USD"); got.Amount != 303 {
+		t.Errorf("interest income = %s, want 3.03", got)
+	}
+	var charge models.CreditCharge
+	if err := db.First(&charge, "credit_line_id = ?", line.ID).Error; err != nil {
+		t.Fatalf("failed to load credit charge: %v", err)
+	}
+	if charge.DaysCharged != 30 || charge.Amount.Amount != 303 {
+		t.Errorf("charge = %d days, %s, want 30 days, 3.03", charge.DaysCharged, charge.Amount)
+	}
+
+	// A month is only charged once
+	if charged, _, err := lines.ChargeMonth(june); err != nil || charged != 0 {
+		t.Errorf("second ChargeMonth = %d, %v, want nothing charged", charged, err)
+	}
+	if got := walletBalance(t, db, wallet.ID); got.Amount != -10403 {
+		t.Errorf("wallet balance after second run = %s, want -104.03", got)
+	}
+}
+
+func TestChargeMonthContinuesPastFailingLine(t *testing.T) {
+	db := setupTestDB(t)
+	june := time.Date(2026, time.June, 1, 0, 0, 0, 0, time.Local)
+	broken := createTestWallet(t, db, "USD")
+	brokenLine := overdraw(t, db, broken, "100.00", june.Add(10*time.Hour))
+	wallet := createTestWallet(t, db, "USD")
+	overdraw(t, db, wallet, "100.00", june.Add(10*time.Hour))
+
+	// A posting in the wrong currency makes the broken line's charge fail
+	account, err := NewLedgerService().WalletAccount(db, broken)
+	if err != nil {
+		t.Fatalf("failed to load wallet account: %v", err)
+	}
+	if err := db.Model(&models.Posting{}).Where("account_id = ?", account.ID).Update("currency", "EUR").Error; err != nil {
+		t.Fatalf("failed to corrupt postings: %v", err)
+	}
+
+	charged, _, err := NewCreditLineService().ChargeMonth(june)
+	if err == nil {
+		t.Fatal("ChargeMonth succeeded despite a failing credit line")
+	}
+	if charged != 1 {
+		t.Errorf("charged %d credit lines, want 1", charged)
+	}
+	if got := walletBalance(t, db, wallet.ID); got.Amount != -10403 {
+		t.Errorf("wallet balance = %s, want -104.03", got)
+	}
+	var count int64
+	db.Model(&models.CreditCharge{}).Where("credit_line_id = ?", brokenLine.ID).Count(&count)
+	if count != 0 {
+		t.Errorf("failing credit line has %d charges, want none", count)
+	}
+}
@@ -75,7 +75,7 @@ func (cs *CronService) SetupCronJobs() {
 		Name:        "comment-auto-approval",
 		Schedule:    "*/5 * * * *",
 		Command:     "go run main.go --cron=comment-approval",
-		Description: "Auto-approve pending comments older than 10 minutes",
+		Description: "Auto-approve pending comments older than the configured delay",
 		Enabled:     true,
 		LogFile:     filepath.Join(DefaultCronConfig.LogDir, "comment-approval.log"),
 	})
@@ -110,6 +110,96 @@ func (cs *CronService) SetupCronJobs() {
 		LogFile:     filepath.Join(DefaultCronConfig.LogDir, "security-monitor.log"),
 	})
 
+	// Payout processing job (every minute)
+	cs.addCronJob(CronJob{
+		Name:        "payout-processing",
+		Schedule:    "* * * * *",
+		Command:     "go run main.go --cron=payout-processing",
+		Description: "Submit pending withdrawals and update payout statuses",
+		Enabled:     true,
+		LogFile:     filepath.Join(DefaultCronConfig.LogDir, "payout-processing.log"),
+	})
+
+	// Scheduled transfer execution job (every 5 minutes)
+	cs.addCronJob(CronJob{
+		Name:        "scheduled-transfers",
+		Schedule:    "*/5 * * * *",
+		Command:     "go run main.go --cron=scheduled-transfers",
+		Description: "Execute due scheduled and recurring transfers",
+		Enabled:     true,
+		LogFile:     filepath.Join(DefaultCronConfig.LogDir, "scheduled-transfers.log"),
+	})
+
+	// Monthly statement generation job (1st of each month at 02:00)
+	cs.addCronJob(CronJob{
+		Name:        "monthly-statements",
+		Schedule:    "0 2 1 * *",
+		Command:     "go run main.go --cron=monthly-statements",
+		Description: "Pre-generate last month's wallet statements",
+		Enabled:     true,
+		LogFile:     filepath.Join(DefaultCronConfig.LogDir, "monthly-statements.log"),
+	})
+
+	// Insight rollup refresh job (every hour)
+	cs.addCronJob(CronJob{
+		Name:        "insight-rollups",
+		Schedule:    "10 * * * *",
+		Command:     "go run main.go --cron=insight-rollups",
+		Description: "Roll up completed days of transactions for spending insights",
+		Enabled:     true,
+		LogFile:     filepath.Join(DefaultCronConfig.LogDir, "insight-rollups.log"),
+	})
+
+	// Bulk payout execution job (every minute)
+	cs.addCronJob(CronJob{
+		Name:        "bulk-payouts",
+		Schedule:    "* * * * *",
+		Command:     "go run main.go --cron=bulk-payouts",
+		Description: "Pay out queued bulk payout batches",
+		Enabled:     true,
+		LogFile:     filepath.Join(DefaultCronConfig.LogDir, "bulk-payouts.log"),
+	})
+
+	// Hold expiry job (every 5 minutes)
+	cs.addCronJob(CronJob{
+		Name:        "hold-expiry",
+		Schedule:    "*/5 * * * *",
+		Command:     "go run main.go --cron=hold-expiry",
+		Description: "Release holds whose expiry has passed",
+		Enabled:     true,
+		LogFile:     filepath.Join(DefaultCronConfig.LogDir, "hold-expiry.log"),
+	})
+
+	// Merchant webhook delivery job (every minute)
+	cs.addCronJob(CronJob{
+		Name:        "merchant-webhooks",
+		Schedule:    "* * * * *",
+		Command:     "go run main.go --cron=merchant-webhooks",
+		Description: "Deliver queued merchant webhooks",
+		Enabled:     true,
+		LogFile:     filepath.Join(DefaultCronConfig.LogDir, "merchant-webhooks.log"),
+	})
+
+	// Interest accrual job (daily at 00:30)
+	cs.addCronJob(CronJob{
+		Name:        "interest-accrual",
+		Schedule:    "30 0 * * *",
+		Command:     "go run main.go --cron=interest-accrual",
+		Description: "Accrue yesterday's interest and capitalise last month's",
+		Enabled:     true,
+		LogFile:     filepath.Join(DefaultCronConfig.LogDir, "interest-accrual.log"),
+	})
+
+	// Credit line interest job (monthly on the 1st at 03:00)
+	cs.addCronJob(CronJob{
+		Name:        "credit-line-interest",
+		Schedule:    "0 3 1 * *",
+		Command:     "go run main.go --cron=credit-line-interest",
+		Description: "Charge last month's overdraft interest and flag credit lines over their limit",
+		Enabled:     true,
+		LogFile:     filepath.Join(DefaultCronConfig.LogDir, "credit-line-interest.log"),
+	})
+
 	log.Printf("Setup %d cron jobs", len(cs.getCronJobs()))
 }
 
@@ -167,7 +257,7 @@ func (cs *CronService) getCronJobs() []CronJob {
 			Name:        "comment-auto-approval",
 			Schedule:    "*/5 * * * *",
 			Command:     "go run main.go --cron=comment-approval",
-			Description: "Auto-approve pending comments older than 10 minutes",
+			Description: "Auto-approve pending comments older than the configured delay",
 			Enabled:     true,
 		},
 		{
@@ -191,6 +281,69 @@ func (cs *CronService) getCronJobs() []CronJob {
 			Description: "Monitor security events and generate alerts",
 			Enabled:     true,
 		},
+		{
+			Name:        "payout-processing",
+			Schedule:    "* * * * *",
+			Command:     "go run main.go --cron=payout-processing",
+			Description: "Submit pending withdrawals and update payout statuses",
+			Enabled:     true,
+		},
+		{
+			Name:        "scheduled-transfers",
+			Schedule:    "*/5 * * * *",
+			Command:     "go run main.go --cron=scheduled-transfers",
+			Description: "Execute due scheduled and recurring transfers",
+			Enabled:     true,
+		},
+		{
+			Name:        "monthly-statements",
+			Schedule:    "0 2 1 * *",
+			Command:     "go run main.go --cron=monthly-statements",
+			Description: "Pre-generate last month's wallet statements",
+			Enabled:     true,
+		},
+		{
+			Name:        "insight-rollups",
+			Schedule:    "10 * * * *",
+			Command:     "go run main.go --cron=insight-rollups",
+			Description: "Roll up completed days of transactions for spending insights",
+			Enabled:     true,
+		},
+		{
+			Name:        "bulk-payouts",
+			Schedule:    "* * * * *",
+			Command:     "go run main.go --cron=bulk-payouts",
+			Description: "Pay out queued bulk payout batches",
+			Enabled:     true,
+		},
+		{
+			Name:        "hold-expiry",
+			Schedule:    "*/5 * * * *",
+			Command:     "go run main.go --cron=hold-expiry",
+			Description: "Release holds whose expiry has passed",
+			Enabled:     true,
+		},
+		{
+			Name:        "merchant-webhooks",
+			Schedule:    "* * * * *",
+			Command:     "go run main.go --cron=merchant-webhooks",
+			Description: "Deliver queued merchant webhooks",
+			Enabled:     true,
+		},
+		{
+			Name:        "interest-accrual",
+			Schedule:    "30 0 * * *",
+			Command:     "go run main.go --cron=interest-accrual",
+			Description: "Accrue yesterday's interest and capitalise last month's",
+			Enabled:     true,
+		},
+		{
+			Name:        "credit-line-interest",
+			Schedule:    "0 3 1 * *",
+			Command:     "go run main.go --cron=credit-line-interest",
+			Description: "Charge last month's overdraft interest and flag credit lines over their limit",
+			Enabled:     true,
+		},
 	}
 }
 
@@ -269,6 +422,24 @@ func (cs *CronService) ExecuteCronJob(jobName string) error {
 		return cs.executeLogCleanup()
 	case "security-monitor":
 		return cs.executeSecurityMonitoring()
+	case "payout-processing":
+		return cs.executePayoutProcessing()
+	case "scheduled-transfers":
+		return cs.executeScheduledTransfers()
+	case "monthly-statements":
+		return cs.executeMonthlyStatements()
+	case "insight-rollups":
+		return cs.executeInsightRollups()
+	case "bulk-payouts":
+		return cs.executeBulkPayouts()
+	case "hold-expiry":
+		return cs.executeHoldExpiry()
+	case "merchant-webhooks":
+		return cs.executeMerchantWebhooks()
+	case "interest-accrual":
+		return cs.executeInterestAccrual()
+	case "credit-line-interest":
+		return cs.executeCreditLineInterest()
 	default:
 		log.Printf("Unknown cron job: %s", jobName)
 		return nil
@@ -279,8 +450,14 @@ func (cs *CronService) ExecuteCronJob(jobName string) error {
 func (cs *CronService) executeCommentApproval() error {
 	log.Println("Executing comment auto-approval...")
 	
-	// Get pending comments older than 10 minutes
-	cutoffTime := time.Now().Add(-10 * time.Minute)
+	commentConfig := CurrentCommentConfig()
+	if !commentConfig.Enabled {
+		log.Println("Comment auto-approval is disabled")
+		return nil
+	}
+
+	// Get pending comments older than the configured delay
+	cutoffTime := time.Now().Add(-commentConfig.AutoApproveDelay)
 	
 	result := cs.db.Table("blog_comments").
 		Where("status = ? AND created_at <= ?", "pending", cutoffTime).
@@ -347,3 +524,126 @@ func (cs *CronService) executeSecurityMonitoring() error {
 	
 	return nil
 }
+
+// executePayoutProcessing executes the payout processing job
+func (cs *CronService) executePayoutProcessing() error {
+	log.Println("Executing payout processing...")
+
+	updated, err := NewPayoutService().ProcessPayouts()
+	if err != nil {
+		log.Printf("Failed to process payouts: %v", err)
+		return err
+	}
+
+	log.Printf("Updated %d payouts", updated)
+	return nil
+}
+
+// executeScheduledTransfers executes the scheduled transfer job
+func (cs *CronService) executeScheduledTransfers() error {
+	log.Println("Executing scheduled transfers...")
+
+	attempted, err := NewScheduledTransferService().RunDue(time.Now())
+	if err != nil {
+		log.Printf("Failed to run scheduled transfers: %v", err)
+		return err
+	}
+
+	log.Printf("Attempted %d scheduled transfers", attempted)
+	return nil
+}
+
+// executeMonthlyStatements executes the monthly statement generation job
+func (cs *CronService) executeMonthlyStatements() error {
+	log.Println("Executing monthly statement generation...")
+
+	generated, err := NewStatementService().GenerateMonthly(time.Now())
+	log.Printf("Generated %d statements", generated)
+	if err != nil {
+		log.Printf("Failed to generate monthly statements: %v", err)
+		return err
+	}
+	return nil
+}
+
+// executeInsightRollups executes the insight rollup refresh job
+func (cs *CronService) executeInsightRollups() error {
+	log.Println("Executing insight rollup refresh...")
+
+	rows, err := NewInsightService().RefreshRollups(time.Now())
+	if err != nil {
+		log.Printf("Failed to refresh insight rollups: %v", err)
+		return err
+	}
+
+	log.Printf("Stored %d insight rollup rows", rows)
+	return nil
+}
+
+// executeBulkPayouts executes the bulk payout job
+func (cs *CronService) executeBulkPayouts() error {
+	log.Println("Executing bulk payouts...")
+
+	processed, err := NewBulkPayoutService().ProcessQueued(time.Now())
+	if err != nil {
+		log.Printf("Failed to process bulk payouts: %v", err)
+		return err
+	}
+
+	log.Printf("Processed %d bulk payouts", processed)
+	return nil
+}
+
+// executeHoldExpiry executes the hold expiry job
+func (cs *CronService) executeHoldExpiry() error {
+	log.Println("Executing hold expiry...")
+
+	expired, err := NewHoldService().ExpireHolds(time.Now())
+	if err != nil {
+		log.Printf("Failed to expire holds: %v", err)
+		return err
+	}
+
+	log.Printf("Expired %d holds", expired)
+	return nil
+}
+
+// executeMerchantWebhooks executes the merchant webhook delivery job
+func (cs *CronService) executeMerchantWebhooks() error {
+	log.Println("Executing merchant webhook delivery...")
+
+	delivered, err := NewWebhookService().DeliverDue(time.Now())
+	if err != nil {
+		log.Printf("Failed to deliver merchant webhooks: %v", err)
+		return err
+	}
+
+	log.Printf("Delivered %d merchant webhooks", delivered)
+	return nil
+}
+
+// executeInterestAccrual executes the interest accrual job
+func (cs *CronService) executeInterestAccrual() error {
+	log.Println("Executing interest accrual...")
+
+	accrued, posted, err := NewInterestService().RunDaily(time.Now())
+	log.Printf("Accrued %d daily interest entries, posted %d interest entries", accrued, posted)
+	if err != nil {
+		log.Printf("Failed to accrue interest: %v", err)
+		return err
+	}
+	return nil
+}
+
+// executeCreditLineInterest executes the credit line interest job
+func (cs *CronService) executeCreditLineInterest() error {
+	log.Println("Executing credit line interest...")
+
+	charged, overLimit, err := NewCreditLineService().RunMonthly(time.Now())
+	log.Printf("Charged overdraft interest on %d credit lines, %d over their limit", charged, overLimit)
+	if err != nil {
+		log.Printf("Failed to charge credit line interest: %v", err)
+		return err
+	}
+	return nil
+}
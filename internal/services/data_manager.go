@@ -6,6 +6,7 @@ import (
 	"os"
 	"securewallet/internal/config"
 	"securewallet/internal/models"
+	"strconv"
 	"strings"
 
 	"golang.org/x/crypto/bcrypt"
@@ -32,6 +33,51 @@ func (dm *SampleDataManager) InitializeDatabase() error {
 	// Drop all existing tables first to ensure clean slate
 	log.Println("Dropping all existing tables...")
 	if err := dm.db.Migrator().DropTable(
+		&models.CreditCharge{},
+		&models.CreditLine{},
+		&models.InterestPosting{},
+		&models.InterestAccrual{},
+		&models.InterestRate{},
+		&models.FeeCharge{},
+		&models.FeeRule{},
+		&models.WebhookDelivery{},
+		&models.CheckoutRefund{},
+		&models.CheckoutSession{},
+		&models.MerchantAPIKey{},
+		&models.Merchant{},
+		&models.Hold{},
+		&models.BulkPayoutLine{},
+		&models.BulkPayout{},
+		&models.InstructionDecision{},
+		&models.PaymentInstruction{},
+		&models.ApprovalRule{},
+		&models.OrganizationMember{},
+		&models.Organization{},
+		&models.WalletInvite{},
+		&models.WalletMember{},
+		&models.Pocket{},
+		&models.InsightRollup{},
+		&models.TransactionAttachment{},
+		&models.TransactionTag{},
+		&models.TransactionAnnotation{},
+		&models.CategoryRule{},
+		&models.Category{},
+		&models.Statement{},
+		&models.Dispute{},
+		&models.Reversal{},
+		&models.PaymentRequest{},
+		&models.ScheduledTransferRun{},
+		&models.ScheduledTransfer{},
+		&models.SettingsVersion{},
+		&models.LimitUsage{},
+		&models.TransferLimit{},
+		&models.Payout{},
+		&models.ExternalAccount{},
+		&models.ExchangeQuote{},
+		&models.ExchangeRate{},
+		&models.Posting{},
+		&models.JournalEntry{},
+		&models.LedgerAccount{},
 		&models.Transaction{},
 		&models.LoginHistory{},
 		&models.SupportTicket{},
@@ -57,6 +103,51 @@ func (dm *SampleDataManager) InitializeDatabase() error {
 		&models.BlogComment{},
 		&models.BlogCategory{},
 		&models.BlogTag{},
+		&models.LedgerAccount{},
+		&models.JournalEntry{},
+		&models.Posting{},
+		&models.ExchangeRate{},
+		&models.ExchangeQuote{},
+		&models.ExternalAccount{},
+		&models.Payout{},
+		&models.TransferLimit{},
+		&models.LimitUsage{},
+		&models.SettingsVersion{},
+		&models.ScheduledTransfer{},
+		&models.ScheduledTransferRun{},
+		&models.PaymentRequest{},
+		&models.Reversal{},
+		&models.Dispute{},
+		&models.Statement{},
+		&models.Category{},
+		&models.CategoryRule{},
+		&models.TransactionAnnotation{},
+		&models.TransactionTag{},
+		&models.TransactionAttachment{},
+		&models.InsightRollup{},
+		&models.Pocket{},
+		&models.WalletMember{},
+		&models.WalletInvite{},
+		&models.Organization{},
+		&models.OrganizationMember{},
+		&models.ApprovalRule{},
+		&models.PaymentInstruction{},
+		&models.InstructionDecision{},
+		&models.BulkPayout{},
+		&models.BulkPayoutLine{},
+		&models.Hold{},
+		&models.Merchant{},
+		&models.MerchantAPIKey{},
+		&models.CheckoutSession{},
+		&models.CheckoutRefund{},
+		&models.WebhookDelivery{},
+		&models.FeeRule{},
+		&models.FeeCharge{},
+		&models.InterestRate{},
+		&models.InterestAccrual{},
+		&models.InterestPosting{},
+		&models.CreditLine{},
+		&models.CreditCharge{},
 	); err != nil {
 		log.Printf("Error migrating database: %v", err)
 		return err
@@ -80,6 +171,216 @@ func (dm *SampleDataManager) ClearSampleData() error {
 
 	// Clear data in the correct order to avoid foreign key constraint issues
 	// Use Where("1=1") to satisfy GORM's requirement for WHERE conditions
+	if err := tx.Unscoped().Where("1=1").Delete(&models.CreditCharge{}).Error; err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to clear credit charges: %v", err)
+	}
+
+	if err := tx.Unscoped().Where("1=1").Delete(&models.CreditLine{}).Error; err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to clear credit lines: %v", err)
+	}
+
+	if err := tx.Unscoped().Where("1=1").Delete(&models.InterestPosting{}).Error; err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to clear interest postings: %v", err)
+	}
+
+	if err := tx.Unscoped().Where("1=1").Delete(&models.InterestAccrual{}).Error; err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to clear interest accruals: %v", err)
+	}
+
+	if err := tx.Unscoped().Where("1=1").Delete(&models.InterestRate{}).Error; err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to clear interest rates: %v", err)
+	}
+
+	if err := tx.Unscoped().Where("1=1").Delete(&models.FeeCharge{}).Error; err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to clear fee charges: %v", err)
+	}
+
+	if err := tx.Unscoped().Where("1=1").Delete(&models.FeeRule{}).Error; err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to clear fee rules: %v", err)
+	}
+
+	if err := tx.Unscoped().Where("1=1").Delete(&models.WebhookDelivery{}).Error; err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to clear webhook deliveries: %v", err)
+	}
+
+	if err := tx.Unscoped().Where("1=1").Delete(&models.CheckoutRefund{}).Error; err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to clear checkout refunds: %v", err)
+	}
+
+	if err := tx.Unscoped().Where("1=1").Delete(&models.CheckoutSession{}).Error; err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to clear checkout sessions: %v", err)
+	}
+
+	if err := tx.Unscoped().Where("1=1").Delete(&models.MerchantAPIKey{}).Error; err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to clear merchant API keys: %v", err)
+	}
+
+	if err := tx.Unscoped().Where("1=1").Delete(&models.Merchant{}).Error; err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to clear merchants: %v", err)
+	}
+
+	if err := tx.Unscoped().Where("1=1").Delete(&models.Hold{}).Error; err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to clear holds: %v", err)
+	}
+
+	if err := tx.Unscoped().Where("1=1").Delete(&models.BulkPayoutLine{}).Error; err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to clear bulk payout lines: %v", err)
+	}
+
+	if err := tx.Unscoped().Where("1=1").Delete(&models.BulkPayout{}).Error; err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to clear bulk payouts: %v", err)
+	}
+
+	if err := tx.Unscoped().Where("1=1").Delete(&models.InstructionDecision{}).Error; err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to clear instruction decisions: %v", err)
+	}
+
+	if err := tx.Unscoped().Where("1=1").Delete(&models.PaymentInstruction{}).Error; err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to clear payment instructions: %v", err)
+	}
+
+	if err := tx.Unscoped().Where("1=1").Delete(&models.ApprovalRule{}).Error; err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to clear approval rules: %v", err)
+	}
+
+	if err := tx.Unscoped().Where("1=1").Delete(&models.OrganizationMember{}).Error; err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to clear organisation members: %v", err)
+	}
+
+	if err := tx.Unscoped().Where("1=1").Delete(&models.Organization{}).Error; err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to clear organisations: %v", err)
+	}
+
+	if err := tx.Unscoped().Where("1=1").Delete(&models.WalletInvite{}).Error; err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to clear wallet invites: %v", err)
+	}
+
+	if err := tx.Unscoped().Where("1=1").Delete(&models.WalletMember{}).Error; err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to clear wallet members: %v", err)
+	}
+
+	if err := tx.Unscoped().Where("1=1").Delete(&models.Pocket{}).Error; err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to clear pockets: %v", err)
+	}
+
+	if err := tx.Unscoped().Where("1=1").Delete(&models.InsightRollup{}).Error; err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to clear insight rollups: %v", err)
+	}
+
+	if err := tx.Unscoped().Where("1=1").Delete(&models.TransactionAttachment{}).Error; err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to clear transaction attachments: %v", err)
+	}
+
+	if err := tx.Unscoped().Where("1=1").Delete(&models.TransactionTag{}).Error; err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to clear transaction tags: %v", err)
+	}
+
+	if err := tx.Unscoped().Where("1=1").Delete(&models.TransactionAnnotation{}).Error; err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to clear transaction annotations: %v", err)
+	}
+
+	if err := tx.Unscoped().Where("1=1").Delete(&models.CategoryRule{}).Error; err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to clear category rules: %v", err)
+	}
+
+	if err := tx.Unscoped().Where("1=1").Delete(&models.Category{}).Error; err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to clear categories: %v", err)
+	}
+
+	if err := tx.Unscoped().Where("1=1").Delete(&models.Statement{}).Error; err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to clear statements: %v", err)
+	}
+
+	if err := tx.Unscoped().Where("1=1").Delete(&models.Dispute{}).Error; err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to clear disputes: %v", err)
+	}
+
+	if err := tx.Unscoped().Where("1=1").Delete(&models.Reversal{}).Error; err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to clear reversals: %v", err)
+	}
+
+	if err := tx.Unscoped().Where("1=1").Delete(&models.PaymentRequest{}).Error; err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to clear payment requests: %v", err)
+	}
+
+	if err := tx.Unscoped().Where("1=1").Delete(&models.ScheduledTransferRun{}).Error; err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to clear scheduled transfer runs: %v", err)
+	}
+
+	if err := tx.Unscoped().Where("1=1").Delete(&models.ScheduledTransfer{}).Error; err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to clear scheduled transfers: %v", err)
+	}
+
+	if err := tx.Unscoped().Where("1=1").Delete(&models.LimitUsage{}).Error; err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to clear limit usage: %v", err)
+	}
+
+	if err := tx.Unscoped().Where("1=1").Delete(&models.Payout{}).Error; err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to clear payouts: %v", err)
+	}
+
+	if err := tx.Unscoped().Where("1=1").Delete(&models.ExternalAccount{}).Error; err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to clear external accounts: %v", err)
+	}
+
+	if err := tx.Unscoped().Where("1=1").Delete(&models.ExchangeQuote{}).Error; err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to clear exchange quotes: %v", err)
+	}
+
+	if err := tx.Unscoped().Where("1=1").Delete(&models.Posting{}).Error; err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to clear ledger postings: %v", err)
+	}
+
+	if err := tx.Unscoped().Where("1=1").Delete(&models.JournalEntry{}).Error; err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to clear journal entries: %v", err)
+	}
+
+	if err := tx.Unscoped().Where("1=1").Delete(&models.LedgerAccount{}).Error; err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to clear ledger accounts: %v", err)
+	}
+
 	if err := tx.Unscoped().Where("1=1").Delete(&models.Transaction{}).Error; err != nil {
 		tx.Rollback()
 		return fmt.Errorf("failed to clear transactions: %v", err)
@@ -470,9 +771,9 @@ func (dm *SampleDataManager) CreateSampleWallets() error {
 		// Random balance between 100 and 50000
 		// Use a hash of the user ID to generate consistent but varied balances
 		userIDHash := fmt.Sprintf("%x", user.ID)
-		balance := float64(100 + (i * 50) + (len(userIDHash) * 10))
+		balance := int64(100 + (i * 50) + (len(userIDHash) * 10))
 		if balance > 50000 {
-			balance = 50000 - float64(i*10)
+			balance = 50000 - int64(i*10)
 		}
 
 		// Random currency
@@ -480,8 +781,10 @@ func (dm *SampleDataManager) CreateSampleWallets() error {
 
 		wallet := models.Wallet{
 			UserID:   user.ID,
-			Balance:  balance,
+			Name:     currency + " wallet",
+			Balance:  models.MustParseMoney(strconv.FormatInt(balance, 10), currency),
 			Currency: currency,
+			Status:   models.WalletStatusActive,
 		}
 
 		if err := dm.db.Create(&wallet).Error; err != nil {
@@ -528,16 +831,17 @@ func (dm *SampleDataManager) CreateSampleTransactions() error {
 		// Random amount between 1 and 5000
 		// Use a hash of the wallet ID to generate consistent but varied amounts
 		walletIDHash := fmt.Sprintf("%x", wallet.ID)
-		amount := float64(1 + (i * 10) + (len(walletIDHash) * 5))
+		amount := int64(1 + (i * 10) + (len(walletIDHash) * 5))
 		if amount > 5000 {
-			amount = 5000 - float64(i*5)
+			amount = 5000 - int64(i*5)
 		}
+		currency := currencies[i%len(currencies)]
 
 		transaction := models.Transaction{
 			WalletID:    wallet.ID,
 			Type:        transactionTypes[i%len(transactionTypes)],
-			Amount:      amount,
-			Currency:    currencies[i%len(currencies)],
+			Amount:      models.MustParseMoney(strconv.FormatInt(amount, 10), currency),
+			Currency:    currency,
 			Description: descriptions[i%len(descriptions)],
 			Status:      statuses[i%len(statuses)],
 		}
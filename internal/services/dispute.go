@@ -0,0 +1,263 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"securewallet/internal/config"
+	"securewallet/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// disputeWindow is how long after a transfer the sender can still dispute it
+const disputeWindow = 120 * 24 * time.Hour
+
+// Dispute decisions an admin can make
+const (
+	DisputeDecisionReverse = "reverse"
+	DisputeDecisionReject  = "reject"
+)
+
+// disputeReasonLabels describes each dispute reason code for the support ticket
+var disputeReasonLabels = map[string]string{
+	models.DisputeReasonUnauthorized:    "Unauthorized transfer",
+	models.DisputeReasonNotReceived:     "Goods or services not received",
+	models.DisputeReasonDuplicate:       "Duplicate transfer",
+	models.DisputeReasonIncorrectAmount: "Incorrect amount",
+	models.DisputeReasonFraud:           "Fraud or scam",
+	models.DisputeReasonOther:           "Other",
+}
+
+// Dispute errors
+var (
+	ErrDisputeNotFound     = errors.New("dispute not found")
+	ErrDisputeExists       = errors.New("transfer already has an open dispute")
+	ErrDisputeClosed       = errors.New("dispute is no longer open")
+	ErrDisputeWindowClosed = errors.New("transfer is too old to dispute")
+	ErrInvalidReasonCode   = errors.New("invalid dispute reason code")
+	ErrInvalidDecision     = errors.New("decision must be reverse or reject")
+)
+
+// DisputeService lets senders dispute transfers and admins decide them
+type DisputeService struct {
+	db        *gorm.DB
+	reversals *ReversalService
+	transfers *TransferService
+}
+
+// NewDisputeService creates a new dispute service
+func NewDisputeService() *DisputeService {
+	return &DisputeService{
+		db:        config.GetDB(),
+		reversals: NewReversalService(),
+		transfers: NewTransferService(),
+	}
+}
+
+// Open disputes a transfer the user sent and files a support ticket for an admin to decide it
+func (ds *DisputeService) Open(userID uuid.UUID, transactionID, reasonCode, description string) (*models.Dispute, error) {
+	label, ok := disputeReasonLabels[reasonCode]
+	if !ok {
+		return nil, ErrInvalidReasonCode
+	}
+
+	transaction, err := ds.reversals.UserTransaction(userID, transactionID)
+	if err != nil {
+		return nil, err
+	}
+	if transaction.JournalEntryID == nil {
+		return nil, ErrNotReversible
+	}
+	if time.Since(transaction.CreatedAt) > disputeWindow {
+		return nil, ErrDisputeWindowClosed
+	}
+
+	var dispute models.Dispute
+	err = ds.db.Transaction(func(tx *gorm.DB) error {
+		parties, err := ds.reversals.LoadTransfer(tx, *transaction.JournalEntryID)
+		if err != nil {
+			return err
+		}
+		if transaction.WalletID != parties.SenderWalletID {
+			return ErrNotTransferSide
+		}
+
+		var recipientWallet models.Wallet
+		if err := tx.Unscoped().First(&recipientWallet, "id = ?", parties.RecipientWalletID).Error; err != nil {
+			return err
+		}
+
+		// Lock the sender's transaction row so that two disputes cannot be opened at once
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&models.Transaction{}, "id = ?", transaction.ID).Error; err != nil {
+			return err
+		}
+		var open int64
+		if err := tx.Model(&models.Dispute{}).
+			Where("journal_entry_id = ? AND status = ?", parties.EntryID, models.DisputeStatusOpen).
+			Count(&open).Error; err != nil {
+			return err
+		}
+		if open > 0 {
+			return ErrDisputeExists
+		}
+
+		ticket := models.SupportTicket{
+			UserID:  userID,
+			Subject: fmt.Sprintf("Dispute: %s (%s)", label, parties.Amount),
+			Description: fmt.Sprintf("Reason: %s\nTransaction: %s\nJournal entry: %s\nAmount: %s\n\n%s",
+				reasonCode, transaction.ID, parties.EntryID, parties.Amount, strings.TrimSpace(description)),
+			Priority: "high",
+			Status:   "open",
+		}
+		if err := tx.Create(&ticket).Error; err != nil {
+			return fmt.Errorf("failed to create support ticket: %w", err)
+		}
+
+		dispute = models.Dispute{
+			TransactionID:   transaction.ID,
+			JournalEntryID:  parties.EntryID,
+			SenderID:        userID,
+			RecipientID:     recipientWallet.UserID,
+			Amount:          parties.Amount,
+			Currency:        parties.Amount.Currency,
+			ReasonCode:      reasonCode,
+			Description:     strings.TrimSpace(description),
+			Status:          models.DisputeStatusOpen,
+			SupportTicketID: ticket.ID,
+		}
+		return tx.Create(&dispute).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &dispute, nil
+}
+
+// List returns the disputes the user opened
+func (ds *DisputeService) List(userID uuid.UUID) ([]models.Dispute, error) {
+	var disputes []models.Dispute
+	if err := ds.db.Where("sender_id = ?", userID).Order("created_at DESC").Find(&disputes).Error; err != nil {
+		return nil, err
+	}
+	return disputes, nil
+}
+
+// Get returns one of the user's disputes with its support ticket
+func (ds *DisputeService) Get(userID uuid.UUID, disputeID string) (*models.Dispute, error) {
+	id, err := uuid.Parse(disputeID)
+	if err != nil {
+		return nil, ErrDisputeNotFound
+	}
+
+	var dispute models.Dispute
+	if err := ds.db.Preload("SupportTicket").Where("id = ? AND sender_id = ?", id, userID).First(&dispute).Error; err != nil {
+		return nil, ErrDisputeNotFound
+	}
+	return &dispute, nil
+}
+
+// Withdraw lets the sender close their own open dispute
+func (ds *DisputeService) Withdraw(userID uuid.UUID, disputeID string) (*models.Dispute, error) {
+	dispute, err := ds.Get(userID, disputeID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = ds.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		result := tx.Model(&models.Dispute{}).
+			Where("id = ? AND status = ?", dispute.ID, models.DisputeStatusOpen).
+			Updates(map[string]interface{}{"status": models.DisputeStatusWithdrawn, "resolved_at": &now})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrDisputeClosed
+		}
+		return tx.Model(&models.SupportTicket{}).Where("id = ?", dispute.SupportTicketID).Update("status", "resolved").Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ds.Get(userID, disputeID)
+}
+
+// AdminList returns disputes for review, optionally filtered by status
+func (ds *DisputeService) AdminList(status string, limit int) ([]models.Dispute, error) {
+	query := ds.db.Preload("SupportTicket").Order("created_at ASC").Limit(limit)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var disputes []models.Dispute
+	if err := query.Find(&disputes).Error; err != nil {
+		return nil, err
+	}
+	return disputes, nil
+}
+
+// Resolve records an admin decision. Reversing posts a compensating entry that
+// returns what is left of the transfer and its fee to the sender; either way the
+// support ticket is resolved.
+func (ds *DisputeService) Resolve(adminID uuid.UUID, disputeID, decision, note string) (*models.Dispute, error) {
+	id, err := uuid.Parse(disputeID)
+	if err != nil {
+		return nil, ErrDisputeNotFound
+	}
+	if decision != DisputeDecisionReverse && decision != DisputeDecisionReject {
+		return nil, ErrInvalidDecision
+	}
+
+	var dispute models.Dispute
+	err = ds.transfers.withRetry(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&dispute, "id = ?", id).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrDisputeNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if dispute.Status != models.DisputeStatusOpen {
+			return ErrDisputeClosed
+		}
+
+		now := time.Now()
+		updates := map[string]interface{}{
+			"status":      models.DisputeStatusRejected,
+			"resolution":  note,
+			"resolved_by": adminID,
+			"resolved_at": &now,
+		}
+		if decision == DisputeDecisionReverse {
+			reason := "Dispute decided for sender"
+			if note != "" {
+				reason = note
+			}
+			reversal, err := ds.reversals.Reverse(tx, dispute.JournalEntryID, adminID, reason, &dispute.ID)
+			if err != nil {
+				return err
+			}
+			updates["status"] = models.DisputeStatusReversed
+			updates["reversal_id"] = reversal.ID
+		}
+
+		if err := tx.Model(&dispute).Updates(updates).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.SupportTicket{}).Where("id = ?", dispute.SupportTicketID).Update("status", "resolved").Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ds.db.Preload("SupportTicket").First(&dispute, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &dispute, nil
+}
@@ -0,0 +1,258 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"securewallet/internal/config"
+	"securewallet/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ExchangeQuoteTTL is how long a quoted rate stays locked
+const ExchangeQuoteTTL = 30 * time.Second
+
+// Exchange errors
+var (
+	ErrSameCurrency    = errors.New("wallets use the same currency")
+	ErrQuoteNotFound   = errors.New("exchange quote not found")
+	ErrQuoteExpired    = errors.New("exchange quote has expired")
+	ErrQuoteNotPending = errors.New("exchange quote has already been used")
+)
+
+// ExchangeService converts funds between a user's wallets in different currencies
+type ExchangeService struct {
+	db        *gorm.DB
+	ledger    *LedgerService
+	transfers *TransferService
+	wallets   *WalletService
+	rates     RateProvider
+}
+
+// ExchangeQuoteInput describes the conversion a user wants priced
+type ExchangeQuoteInput struct {
+	UserID       uuid.UUID
+	FromWalletID string
+	ToWalletID   string
+	Amount       models.Money // Amount to sell, in the source wallet currency
+}
+
+// ExchangeResult is the outcome of an executed exchange
+type ExchangeResult struct {
+	Quote      models.ExchangeQuote
+	FromWallet models.Wallet
+	ToWallet   models.Wallet
+	Entry      *models.JournalEntry
+}
+
+// NewExchangeService creates a new exchange service backed by the database rates
+func NewExchangeService() *ExchangeService {
+	return &ExchangeService{
+		db:        config.GetDB(),
+		ledger:    NewLedgerService(),
+		transfers: NewTransferService(),
+		wallets:   NewWalletService(),
+		rates:     NewDBRateProvider(),
+	}
+}
+
+// Quote prices a conversion and locks the rate for ExchangeQuoteTTL.
+// The spread is taken from the amount sold; the rest is converted at the mid rate.
+func (es *ExchangeService) Quote(input ExchangeQuoteInput) (*models.ExchangeQuote, error) {
+	fromWallet, err := es.wallets.MemberWallet(input.UserID, input.FromWalletID, models.WalletRoleSpender)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkOrganizationPayment(fromWallet); err != nil {
+		return nil, err
+	}
+	toWallet, err := es.wallets.MemberWallet(input.UserID, input.ToWalletID, models.WalletRoleSpender)
+	if err != nil {
+		return nil, err
+	}
+	if fromWallet.ID == toWallet.ID {
+		return nil, ErrSameWallet
+	}
+	if fromWallet.Currency == toWallet.Currency {
+		return nil, ErrSameCurrency
+	}
+
+	sell, err := input.Amount.In(fromWallet.Currency)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidAmount, err)
+	}
+	if !sell.IsPositive() {
+		return nil, ErrInvalidAmount
+	}
+
+	rate, err := es.rates.Rate(fromWallet.Currency, toWallet.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	// Round in the platform's favour on both legs
+	fee := sell.Mul(rate.SpreadBasisPoints, 10000, models.RoundUp)
+	net, _ := sell.Sub(fee)
+	buy := net.Convert(rate.Mid, toWallet.Currency, models.RoundDown)
+	if !buy.IsPositive() {
+		return nil, fmt.Errorf("%w: amount is too small to exchange", ErrInvalidAmount)
+	}
+
+	quote := models.ExchangeQuote{
+		UserID:            input.UserID,
+		FromWalletID:      fromWallet.ID,
+		ToWalletID:        toWallet.ID,
+		FromCurrency:      fromWallet.Currency,
+		ToCurrency:        toWallet.Currency,
+		SellAmount:        sell,
+		Fee:               fee,
+		BuyAmount:         buy,
+		Rate:              rate.Mid.FloatString(10),
+		SpreadBasisPoints: rate.SpreadBasisPoints,
+		Status:            models.ExchangeQuoteStatusPending,
+		ExpiresAt:         time.Now().Add(ExchangeQuoteTTL),
+	}
+	if err := es.db.Create(&quote).Error; err != nil {
+		return nil, fmt.Errorf("failed to save exchange quote: %w", err)
+	}
+
+	return &quote, nil
+}
+
+// Execute books a pending quote owned by the user
+func (es *ExchangeService) Execute(userID uuid.UUID, quoteID string) (*ExchangeResult, error) {
+	id, err := uuid.Parse(quoteID)
+	if err != nil {
+		return nil, ErrQuoteNotFound
+	}
+
+	var result ExchangeResult
+	err = es.transfers.withRetry(func(tx *gorm.DB) error {
+		// Lock the quote so that it can only be executed once
+		var quote models.ExchangeQuote
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND user_id = ?", id, userID).
+			First(&quote).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrQuoteNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if quote.Status != models.ExchangeQuoteStatusPending {
+			return ErrQuoteNotPending
+		}
+		if time.Now().After(quote.ExpiresAt) {
+			return ErrQuoteExpired
+		}
+
+		wallets, err := es.transfers.lockWallets(tx, quote.FromWalletID, quote.ToWalletID)
+		if err != nil {
+			return err
+		}
+		fromWallet := wallets[quote.FromWalletID]
+		toWallet := wallets[quote.ToWalletID]
+		if fromWallet.Currency != quote.FromCurrency || toWallet.Currency != quote.ToCurrency {
+			return ErrCurrencyMismatch
+		}
+
+		// The source row is locked, so this check cannot race with another debit
+		if fromWallet.Available().Amount < quote.SellAmount.Amount {
+			return &InsufficientFundsError{Balance: fromWallet.Available(), Required: quote.SellAmount}
+		}
+		if err := checkOrganizationPayment(&fromWallet); err != nil {
+			return err
+		}
+		if err := checkMemberSpend(tx, &fromWallet, quote.UserID, quote.SellAmount); err != nil {
+			return err
+		}
+
+		entry, err := es.postExchange(tx, &quote, &fromWallet, &toWallet)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		if err := tx.Model(&quote).Updates(map[string]interface{}{
+			"status":           models.ExchangeQuoteStatusExecuted,
+			"executed_at":      &now,
+			"journal_entry_id": entry.ID,
+		}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.First(&result.FromWallet, "id = ?", fromWallet.ID).Error; err != nil {
+			return err
+		}
+		if err := tx.First(&result.ToWallet, "id = ?", toWallet.ID).Error; err != nil {
+			return err
+		}
+		result.Quote = quote
+		result.Entry = entry
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// Exchange quotes and immediately executes a conversion
+func (es *ExchangeService) Exchange(input ExchangeQuoteInput) (*ExchangeResult, error) {
+	quote, err := es.Quote(input)
+	if err != nil {
+		return nil, err
+	}
+	return es.Execute(input.UserID, quote.ID.String())
+}
+
+// postExchange writes the journal entry of an exchange. Each currency balances
+// on its own: the source currency goes to the FX position and fee revenue, the
+// target currency comes out of the FX position.
+func (es *ExchangeService) postExchange(tx *gorm.DB, quote *models.ExchangeQuote, fromWallet, toWallet *models.Wallet) (*models.JournalEntry, error) {
+	fromAccount, err := es.ledger.WalletAccount(tx, fromWallet)
+	if err != nil {
+		return nil, err
+	}
+	toAccount, err := es.ledger.WalletAccount(tx, toWallet)
+	if err != nil {
+		return nil, err
+	}
+	fxFromAccount, err := es.ledger.SystemAccount(tx, SystemAccountFXPosition, quote.FromCurrency)
+	if err != nil {
+		return nil, err
+	}
+	fxToAccount, err := es.ledger.SystemAccount(tx, SystemAccountFXPosition, quote.ToCurrency)
+	if err != nil {
+		return nil, err
+	}
+
+	net, _ := quote.SellAmount.Sub(quote.Fee)
+	lines := []LedgerLine{
+		{AccountID: fromAccount.ID, Amount: quote.SellAmount.Neg(), Memo: fmt.Sprintf("Exchange to %s", quote.ToCurrency)},
+		{AccountID: fxFromAccount.ID, Amount: net},
+		{AccountID: fxToAccount.ID, Amount: quote.BuyAmount.Neg()},
+		{AccountID: toAccount.ID, Amount: quote.BuyAmount, Memo: fmt.Sprintf("Exchange from %s", quote.FromCurrency)},
+	}
+	// The spread is recorded as fee income
+	if quote.Fee.IsPositive() {
+		feeAccount, err := es.ledger.SystemAccount(tx, SystemAccountFeeRevenue, quote.FromCurrency)
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, LedgerLine{AccountID: feeAccount.ID, Amount: quote.Fee, Memo: "Exchange spread"})
+	}
+
+	return es.ledger.PostEntry(tx, LedgerEntry{
+		Type:        "EXCHANGE",
+		Description: fmt.Sprintf("Exchange %s to %s", quote.SellAmount, quote.BuyAmount),
+		Reference:   quote.ID.String(),
+		InitiatedBy: initiator(quote.UserID),
+		Lines:       lines,
+	})
+}
@@ -0,0 +1,343 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"securewallet/internal/config"
+	"securewallet/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Fee rule errors
+var (
+	ErrFeeRuleNotFound = errors.New("fee rule not found")
+	ErrInvalidFeeRule  = errors.New("invalid fee rule")
+	ErrInvalidFeeType  = errors.New("unknown transaction type")
+)
+
+// FeeQuote is the fee a transaction is charged and the rule that set it
+type FeeQuote struct {
+	TransactionType    string       `json:"transaction_type"`
+	Amount             models.Money `json:"amount"`
+	Fee                models.Money `json:"fee"`
+	Total              models.Money `json:"total"` // Debited from the sender: amount plus fee, or the amount alone for checkout payments
+	RuleID             *uuid.UUID   `json:"rule_id,omitempty"`
+	RuleName           string       `json:"rule_name,omitempty"`
+	FreeQuotaRemaining *int         `json:"free_quota_remaining,omitempty"` // Free transactions left this month after this one
+}
+
+// FeeRuleInput describes a fee rule an admin creates or replaces
+type FeeRuleInput struct {
+	Name             string
+	TransactionType  string
+	Currency         string
+	Tier             string // Empty for every tier
+	MinAmount        *models.Money
+	MaxAmount        *models.Money
+	BasisPoints      int64
+	FlatFee          *models.Money
+	MinFee           *models.Money
+	MaxFee           *models.Money
+	FreeMonthlyQuota int
+	Priority         int
+	StartsAt         *time.Time
+	EndsAt           *time.Time
+	Active           bool
+	UpdatedBy        uuid.UUID
+}
+
+// FeeService prices transactions with the configured fee rules. Transactions
+// no rule matches pay the default fees from the system settings.
+type FeeService struct {
+	db *gorm.DB
+}
+
+// NewFeeService creates a new fee service
+func NewFeeService() *FeeService {
+	return &FeeService{
+		db: config.GetDB(),
+	}
+}
+
+// Preview returns the fee a payer would be charged right now, without
+// reserving anything. The charged fee may differ if the free quota is used up
+// or a rule changes in the meantime.
+func (fs *FeeService) Preview(payerID uuid.UUID, feeType string, amount models.Money) (*FeeQuote, error) {
+	return fs.quote(fs.db, payerID, feeType, amount, false)
+}
+
+// Quote prices a transaction inside the transaction that books it. When the
+// rule has a free monthly quota the payer's user row is locked, so that
+// concurrent payments cannot both take the last free one.
+func (fs *FeeService) Quote(tx *gorm.DB, payerID uuid.UUID, feeType string, amount models.Money) (*FeeQuote, error) {
+	return fs.quote(tx, payerID, feeType, amount, true)
+}
+
+// quote finds the rule for a transaction and applies it
+func (fs *FeeService) quote(tx *gorm.DB, payerID uuid.UUID, feeType string, amount models.Money, lock bool) (*FeeQuote, error) {
+	if !isValidFeeType(feeType) {
+		return nil, ErrInvalidFeeType
+	}
+	if !amount.IsPositive() {
+		return nil, ErrInvalidAmount
+	}
+
+	var payer models.User
+	if err := tx.Select("id", "tier").First(&payer, "id = ?", payerID).Error; err != nil {
+		return nil, fmt.Errorf("failed to load user: %w", err)
+	}
+
+	rule, err := fs.match(tx, feeType, payer.Tier, amount, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	quote := FeeQuote{TransactionType: feeType, Amount: amount}
+	if rule == nil {
+		if feeType == models.FeeTypeCheckout {
+			quote.Fee = CalculateMerchantFee(amount)
+		} else {
+			quote.Fee = CalculateTransferFee(amount)
+		}
+	} else {
+		quote.RuleID = &rule.ID
+		quote.RuleName = rule.Name
+		quote.Fee = rule.Price(amount)
+
+		if rule.FreeMonthlyQuota > 0 {
+			if lock {
+				if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+					Select("id").
+					First(&models.User{}, "id = ?", payerID).Error; err != nil {
+					return nil, fmt.Errorf("failed to lock user: %w", err)
+				}
+			}
+			var used int64
+			if err := tx.Model(&models.FeeCharge{}).
+				Where("rule_id = ? AND user_id = ? AND created_at >= ?", rule.ID, payerID, monthStart(time.Now())).
+				Count(&used).Error; err != nil {
+				return nil, fmt.Errorf("failed to count fee charges: %w", err)
+			}
+			if used < int64(rule.FreeMonthlyQuota) {
+				remaining := rule.FreeMonthlyQuota - int(used) - 1
+				quote.FreeQuotaRemaining = &remaining
+				quote.Fee = models.ZeroMoney(amount.Currency)
+			} else {
+				remaining := 0
+				quote.FreeQuotaRemaining = &remaining
+			}
+		}
+	}
+
+	// Checkout fees come out of what the merchant receives, so they cannot exceed it
+	quote.Total, _ = amount.Add(quote.Fee)
+	if feeType == models.FeeTypeCheckout {
+		quote.Fee = quote.Fee.Min(amount)
+		quote.Total = amount
+	}
+	return &quote, nil
+}
+
+// match returns the highest priority active rule for a transaction, or nil
+// when the default fee applies
+func (fs *FeeService) match(tx *gorm.DB, feeType, tier string, amount models.Money, now time.Time) (*models.FeeRule, error) {
+	var rules []models.FeeRule
+	if err := tx.Where("transaction_type = ? AND currency = ? AND active = ?", feeType, amount.Currency, true).
+		Where("tier = '' OR tier = ?", tier).
+		Where("starts_at IS NULL OR starts_at <= ?", now).
+		Where("ends_at IS NULL OR ends_at > ?", now).
+		Order("priority DESC, created_at ASC").
+		Find(&rules).Error; err != nil {
+		return nil, fmt.Errorf("failed to load fee rules: %w", err)
+	}
+
+	for i := range rules {
+		rule := &rules[i]
+		if rule.MinAmount != nil && amount.Amount < rule.MinAmount.Amount {
+			continue
+		}
+		if rule.MaxAmount != nil && amount.Amount >= rule.MaxAmount.Amount {
+			continue
+		}
+		return rule, nil
+	}
+	return nil, nil
+}
+
+// Record counts a priced transaction towards its rule's free quota. Transactions
+// that paid the default fee are not recorded.
+func (fs *FeeService) Record(tx *gorm.DB, quote *FeeQuote, payerID, entryID uuid.UUID) error {
+	if quote.RuleID == nil {
+		return nil
+	}
+	charge := models.FeeCharge{
+		RuleID:   *quote.RuleID,
+		UserID:   payerID,
+		EntryID:  entryID,
+		Amount:   quote.Fee,
+		Currency: quote.Fee.Currency,
+	}
+	if err := tx.Create(&charge).Error; err != nil {
+		return fmt.Errorf("failed to record fee charge: %w", err)
+	}
+	return nil
+}
+
+// ListRules returns all fee rules, in the order they are matched
+func (fs *FeeService) ListRules() ([]models.FeeRule, error) {
+	rules := []models.FeeRule{}
+	if err := fs.db.Order("transaction_type, currency, priority DESC, created_at ASC").Find(&rules).Error; err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// CreateRule adds a fee rule
+func (fs *FeeService) CreateRule(input FeeRuleInput) (*models.FeeRule, error) {
+	rule, err := buildFeeRule(input)
+	if err != nil {
+		return nil, err
+	}
+	if err := fs.db.Create(rule).Error; err != nil {
+		return nil, fmt.Errorf("failed to create fee rule: %w", err)
+	}
+	return rule, nil
+}
+
+// UpdateRule replaces a fee rule. Charges already made keep pointing at it.
+func (fs *FeeService) UpdateRule(ruleID string, input FeeRuleInput) (*models.FeeRule, error) {
+	id, err := uuid.Parse(ruleID)
+	if err != nil {
+		return nil, ErrFeeRuleNotFound
+	}
+	var existing models.FeeRule
+	if err := fs.db.First(&existing, "id = ?", id).Error; err != nil {
+		return nil, ErrFeeRuleNotFound
+	}
+
+	rule, err := buildFeeRule(input)
+	if err != nil {
+		return nil, err
+	}
+	rule.ID = existing.ID
+	rule.CreatedAt = existing.CreatedAt
+	if err := fs.db.Save(rule).Error; err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// DeleteRule removes a fee rule. Deactivate a rule instead to keep its charges
+// reportable by name.
+func (fs *FeeService) DeleteRule(ruleID string) error {
+	id, err := uuid.Parse(ruleID)
+	if err != nil {
+		return ErrFeeRuleNotFound
+	}
+	result := fs.db.Delete(&models.FeeRule{}, "id = ?", id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrFeeRuleNotFound
+	}
+	return nil
+}
+
+// buildFeeRule validates the input and returns the rule it describes
+func buildFeeRule(input FeeRuleInput) (*models.FeeRule, error) {
+	name := strings.TrimSpace(input.Name)
+	if name == "" || len(name) > 100 {
+		return nil, fmt.Errorf("%w: name is required and must be at most 100 characters", ErrInvalidFeeRule)
+	}
+	if !isValidFeeType(input.TransactionType) {
+		return nil, fmt.Errorf("%w: unknown transaction type %q", ErrInvalidFeeRule, input.TransactionType)
+	}
+	currency := strings.ToUpper(input.Currency)
+	if !models.IsSupportedCurrency(currency) {
+		return nil, ErrUnsupportedCurrency
+	}
+	if input.Tier != "" && !isValidTier(input.Tier) {
+		return nil, fmt.Errorf("%w: unknown tier %q", ErrInvalidFeeRule, input.Tier)
+	}
+	if input.BasisPoints < 0 || input.BasisPoints > 1000 {
+		return nil, fmt.Errorf("%w: basis points must be between 0 and 1000", ErrInvalidFeeRule)
+	}
+	if input.FreeMonthlyQuota < 0 {
+		return nil, fmt.Errorf("%w: free monthly quota cannot be negative", ErrInvalidFeeRule)
+	}
+	if input.StartsAt != nil && input.EndsAt != nil && !input.EndsAt.After(*input.StartsAt) {
+		return nil, fmt.Errorf("%w: end must be after start", ErrInvalidFeeRule)
+	}
+
+	rule := models.FeeRule{
+		Name:             name,
+		TransactionType:  input.TransactionType,
+		Currency:         currency,
+		Tier:             input.Tier,
+		BasisPoints:      input.BasisPoints,
+		FlatFee:          models.ZeroMoney(currency),
+		FreeMonthlyQuota: input.FreeMonthlyQuota,
+		Priority:         input.Priority,
+		StartsAt:         input.StartsAt,
+		EndsAt:           input.EndsAt,
+		Active:           input.Active,
+		UpdatedBy:        &input.UpdatedBy,
+	}
+	var err error
+	if rule.MinAmount, err = bindFeeAmount(input.MinAmount, currency); err != nil {
+		return nil, err
+	}
+	if rule.MaxAmount, err = bindFeeAmount(input.MaxAmount, currency); err != nil {
+		return nil, err
+	}
+	if rule.MinFee, err = bindFeeAmount(input.MinFee, currency); err != nil {
+		return nil, err
+	}
+	if rule.MaxFee, err = bindFeeAmount(input.MaxFee, currency); err != nil {
+		return nil, err
+	}
+	flatFee, err := bindFeeAmount(input.FlatFee, currency)
+	if err != nil {
+		return nil, err
+	}
+	if flatFee != nil {
+		rule.FlatFee = *flatFee
+	}
+
+	if rule.MinAmount != nil && rule.MaxAmount != nil && rule.MaxAmount.Amount <= rule.MinAmount.Amount {
+		return nil, fmt.Errorf("%w: max amount must be above min amount", ErrInvalidFeeRule)
+	}
+	if rule.MinFee != nil && rule.MaxFee != nil && rule.MaxFee.Amount < rule.MinFee.Amount {
+		return nil, fmt.Errorf("%w: max fee must not be below min fee", ErrInvalidFeeRule)
+	}
+	return &rule, nil
+}
+
+// isValidFeeType reports whether feeType is a transaction type fee rules can price
+func isValidFeeType(feeType string) bool {
+	switch feeType {
+	case models.FeeTypeTransfer, models.FeeTypeScheduledTransfer, models.FeeTypePaymentRequest,
+		models.FeeTypeBulkPayout, models.FeeTypeCheckout:
+		return true
+	}
+	return false
+}
+
+// bindFeeAmount binds an optional fee rule amount to the rule currency
+func bindFeeAmount(amount *models.Money, currency string) (*models.Money, error) {
+	if amount == nil {
+		return nil, nil
+	}
+	bound, err := amount.In(currency)
+	if err != nil || bound.IsNegative() {
+		return nil, fmt.Errorf("%w: amounts must be non-negative %s values", ErrInvalidFeeRule, currency)
+	}
+	return &bound, nil
+}
@@ -0,0 +1,177 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"securewallet/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// createTestFeeRule creates an active USD fee rule for a transaction type
+func createTestFeeRule(t *testing.T, input FeeRuleInput) *models.FeeRule {
+	t.Helper()
+
+	input.Currency = "USD"
+	input.Active = true
+	input.UpdatedBy = uuid.New()
+	rule, err := NewFeeService().CreateRule(input)
+	if err != nil {
+		t.Fatalf("failed to create fee rule %q: %v", input.Name, err)
+	}
+	return rule
+}
+
+// moneyPtr returns a pointer to a parsed USD amount
+func moneyPtr(amount string) *models.Money {
+	money := mustMoney(amount, "USD")
+	return &money
+}
+
+func TestFeeRuleMatching(t *testing.T) {
+	db := setupTestDB(t)
+	payer := createTestWallet(t, db, "USD")
+	fees := NewFeeService()
+
+	quoteFee := func(feeType, amount string) models.Money {
+		t.Helper()
+		quote, err := fees.Preview(payer.UserID, feeType, mustMoney(amount, "USD"))
+		if err != nil {
+			t.Fatalf("Preview of %s %s failed: %v", feeType, amount, err)
+		}
+		return quote.Fee
+	}
+
+	// Without rules the default 1% fee applies, at least 1.00
+	if fee := quoteFee(models.FeeTypeTransfer, "200.00"); fee.Amount != 200 {
+		t.Errorf("default fee = %s, want 2.00", fee)
+	}
+
+	createTestFeeRule(t, FeeRuleInput{
+		Name:            "Standard",
+		TransactionType: models.FeeTypeTransfer,
+		BasisPoints:     50,
+		FlatFee:         moneyPtr("0.25"),
+		MinFee:          moneyPtr("0.50"),
+	})
+	createTestFeeRule(t, FeeRuleInput{
+		Name:            "Large transfers",
+		TransactionType: models.FeeTypeTransfer,
+		MinAmount:       moneyPtr("1000.00"),
+		BasisPoints:     10,
+		MaxFee:          moneyPtr("2.00"),
+		Priority:        1,
+	})
+	past, future := time.Now().Add(-48*time.Hour), time.Now().Add(48*time.Hour)
+	yesterday := time.Now().Add(-24 * time.Hour)
+	createTestFeeRule(t, FeeRuleInput{
+		Name:            "Ended promotion",
+		TransactionType: models.FeeTypeTransfer,
+		Priority:        10,
+		StartsAt:        &past,
+		EndsAt:          &yesterday,
+	})
+	createTestFeeRule(t, FeeRuleInput{
+		Name:            "Premium",
+		TransactionType: models.FeeTypeTransfer,
+		Tier:            "premium",
+		Priority:        5,
+	})
+
+	tests := []struct {
+		amount string
+		want   int64
+	}{
+		{"200.00", 125},  // 0.5% plus 0.25
+		{"10.00", 50},    // Raised to the minimum fee
+		{"999.99", 525},  // Below the large transfer band
+		{"1000.00", 100}, // 0.1% in the band
+		{"5000.00", 200}, // Capped at the band's maximum fee
+	}
+	for _, tt := range tests {
+		if fee := quoteFee(models.FeeTypeTransfer, tt.amount); fee.Amount != tt.want {
+			t.Errorf("fee on %s = %s, want %d cents", tt.amount, fee, tt.want)
+		}
+	}
+
+	// Payment requests have no rule and keep the default fee
+	if fee := quoteFee(models.FeeTypePaymentRequest, "200.00"); fee.Amount != 200 {
+		t.Errorf("payment request fee = %s, want 2.00", fee)
+	}
+
+	// A promotion that is running and the payer's tier both outrank the standard rule
+	createTestFeeRule(t, FeeRuleInput{
+		Name:            "Running promotion",
+		TransactionType: models.FeeTypeTransfer,
+		Priority:        10,
+		StartsAt:        &past,
+		EndsAt:          &future,
+		MaxAmount:       moneyPtr("100.00"),
+	})
+	if fee := quoteFee(models.FeeTypeTransfer, "50.00"); !fee.IsZero() {
+		t.Errorf("fee during the promotion = %s, want zero", fee)
+	}
+	db.Model(&models.User{}).Where("id = ?", payer.UserID).Update("tier", "premium")
+	if fee := quoteFee(models.FeeTypeTransfer, "200.00"); !fee.IsZero() {
+		t.Errorf("premium fee = %s, want zero", fee)
+	}
+}
+
+func TestFeeFreeMonthlyQuota(t *testing.T) {
+	db := setupTestDB(t)
+	sender := createTestWallet(t, db, "USD")
+	recipient := createTestWallet(t, db, "USD")
+	deposit(t, sender, "100.00")
+	rule := createTestFeeRule(t, FeeRuleInput{
+		Name:             "Two free a month",
+		TransactionType:  models.FeeTypeTransfer,
+		FlatFee:          moneyPtr("1.00"),
+		FreeMonthlyQuota: 2,
+	})
+
+	for i, want := range []int64{0, 0, 100} {
+		result, err := NewTransferService().Transfer(TransferInput{
+			SenderWalletID:    sender.ID,
+			RecipientWalletID: recipient.ID,
+			Amount:            mustMoney("10.00", "USD"),
+			InitiatedBy:       sender.UserID,
+		})
+		if err != nil {
+			t.Fatalf("transfer %d failed: %v", i+1, err)
+		}
+		if result.Fee.Amount != want || result.FeeRuleID == nil || *result.FeeRuleID != rule.ID {
+			t.Errorf("transfer %d: fee %s from rule %v, want %d cents from %s", i+1, result.Fee, result.FeeRuleID, want, rule.ID)
+		}
+	}
+
+	var charges int64
+	db.Model(&models.FeeCharge{}).Where("rule_id = ?", rule.ID).Count(&charges)
+	if charges != 3 {
+		t.Errorf("recorded %d fee charges, want 3", charges)
+	}
+	if got := walletBalance(t, db, sender.ID); got.Amount != 6900 {
+		t.Errorf("sender balance = %s, want 69.00", got)
+	}
+	if got := feeRevenue(t, db, "USD"); got.Amount != 100 {
+		t.Errorf("fee revenue = %s, want 1.00", got)
+	}
+}
+
+func TestCheckoutFeeCappedAtAmount(t *testing.T) {
+	db := setupTestDB(t)
+	payer := createTestWallet(t, db, "USD")
+	createTestFeeRule(t, FeeRuleInput{
+		Name:            "Flat checkout",
+		TransactionType: models.FeeTypeCheckout,
+		FlatFee:         moneyPtr("5.00"),
+	})
+
+	quote, err := NewFeeService().Preview(payer.UserID, models.FeeTypeCheckout, mustMoney("2.00", "USD"))
+	if err != nil {
+		t.Fatalf("Preview failed: %v", err)
+	}
+	if quote.Fee.Amount != 200 || quote.Total.Amount != 200 {
+		t.Errorf("checkout quote: fee %s, total %s, want 2.00 and 2.00", quote.Fee, quote.Total)
+	}
+}
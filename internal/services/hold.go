@@ -0,0 +1,385 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"securewallet/internal/config"
+	"securewallet/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Hold errors
+var (
+	ErrHoldNotFound      = errors.New("hold not found")
+	ErrHoldNotActive     = errors.New("hold is no longer active")
+	ErrInvalidCapture    = errors.New("capture amount must be positive and no more than the held amount")
+	ErrInvalidHoldExpiry = errors.New("hold expiry must be in the future")
+	ErrInvalidHoldStatus = errors.New("status must be active, captured, released, expired or all")
+	ErrHoldNotReleasable = errors.New("hold belongs to a payment and is released with it")
+)
+
+// HoldInput describes funds to reserve in a wallet
+type HoldInput struct {
+	WalletID    uuid.UUID
+	Amount      models.Money
+	Kind        string
+	Reference   string
+	Description string
+	PlacedBy    uuid.UUID
+	ExpiresAt   *time.Time // Nil keeps the hold until it is captured or released
+}
+
+// HoldCapture describes where the captured part of a hold is booked
+type HoldCapture struct {
+	Amount      *models.Money // Defaults to the full held amount
+	AccountID   uuid.UUID     // Ledger account credited with the captured amount
+	Type        string        // Journal entry type, e.g. WITHDRAWAL
+	Description string
+	Memo        string // Shown on the wallet's transaction
+	InitiatedBy uuid.UUID
+}
+
+// HoldService reserves wallet funds and later captures or releases them.
+// Holds change the wallet's available balance only; the ledger is posted on capture.
+type HoldService struct {
+	db        *gorm.DB
+	ledger    *LedgerService
+	transfers *TransferService
+	wallets   *WalletService
+}
+
+// NewHoldService creates a new hold service
+func NewHoldService() *HoldService {
+	return &HoldService{
+		db:        config.GetDB(),
+		ledger:    NewLedgerService(),
+		transfers: NewTransferService(),
+		wallets:   NewWalletService(),
+	}
+}
+
+// Authorize places a hold on a wallet if its available balance covers the amount
+func (hs *HoldService) Authorize(input HoldInput) (*models.Hold, error) {
+	var hold *models.Hold
+	err := hs.transfers.withRetry(func(tx *gorm.DB) error {
+		wallets, err := hs.transfers.lockWallets(tx, input.WalletID)
+		if err != nil {
+			return err
+		}
+		locked := wallets[input.WalletID]
+		hold, err = hs.place(tx, &locked, input)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return hold, nil
+}
+
+// AdminRelease releases a hold placed by an administrator. Holds that belong
+// to a payment are released by that payment's workflow.
+func (hs *HoldService) AdminRelease(holdID string) (*models.Hold, error) {
+	id, err := uuid.Parse(holdID)
+	if err != nil {
+		return nil, ErrHoldNotFound
+	}
+
+	var hold *models.Hold
+	err = hs.transfers.withRetry(func(tx *gorm.DB) error {
+		var kind string
+		if err := tx.Model(&models.Hold{}).Where("id = ?", id).Pluck("kind", &kind).Error; err != nil {
+			return err
+		}
+		if kind == "" {
+			return ErrHoldNotFound
+		}
+		if kind != models.HoldKindAdmin {
+			return ErrHoldNotReleasable
+		}
+		hold, err = hs.release(tx, id, models.HoldStatusReleased)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return hold, nil
+}
+
+// AdminList returns holds across all wallets, newest first, optionally filtered by status
+func (hs *HoldService) AdminList(status string, limit int) ([]models.Hold, error) {
+	query := hs.db.Model(&models.Hold{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	holds := []models.Hold{}
+	if err := query.Order("created_at DESC").Limit(limit).Find(&holds).Error; err != nil {
+		return nil, err
+	}
+	return holds, nil
+}
+
+// List returns the holds on one of the user's wallets, newest first,
+// optionally filtered by status
+func (hs *HoldService) List(userID uuid.UUID, walletID, status string) ([]models.Hold, error) {
+	wallet, err := hs.wallets.MemberWallet(userID, walletID, models.WalletRoleViewer)
+	if err != nil {
+		return nil, err
+	}
+
+	query := hs.db.Where("wallet_id = ?", wallet.ID)
+	switch status {
+	case "all":
+	case models.HoldStatusActive, models.HoldStatusCaptured,
+		models.HoldStatusReleased, models.HoldStatusExpired:
+		query = query.Where("status = ?", status)
+	default:
+		return nil, ErrInvalidHoldStatus
+	}
+
+	holds := []models.Hold{}
+	if err := query.Order("created_at DESC").Limit(100).Find(&holds).Error; err != nil {
+		return nil, err
+	}
+	return holds, nil
+}
+
+// Get returns one of the holds on one of the user's wallets
+func (hs *HoldService) Get(userID uuid.UUID, walletID, holdID string) (*models.Hold, error) {
+	wallet, err := hs.wallets.MemberWallet(userID, walletID, models.WalletRoleViewer)
+	if err != nil {
+		return nil, err
+	}
+	id, err := uuid.Parse(holdID)
+	if err != nil {
+		return nil, ErrHoldNotFound
+	}
+
+	var hold models.Hold
+	if err := hs.db.First(&hold, "id = ? AND wallet_id = ?", id, wallet.ID).Error; err != nil {
+		return nil, ErrHoldNotFound
+	}
+	return &hold, nil
+}
+
+// ExpireHolds releases the active holds whose expiry has passed and returns
+// how many were expired. The checkout session an expired authorization belongs
+// to is expired with it.
+func (hs *HoldService) ExpireHolds(now time.Time) (int, error) {
+	var ids []uuid.UUID
+	if err := hs.db.Model(&models.Hold{}).
+		Where("status = ? AND expires_at <= ?", models.HoldStatusActive, now).
+		Order("expires_at ASC").
+		Pluck("id", &ids).Error; err != nil {
+		return 0, err
+	}
+
+	expired := 0
+	for _, id := range ids {
+		err := hs.transfers.withRetry(func(tx *gorm.DB) error {
+			session, err := lockAuthorizationCheckout(tx, id)
+			if err != nil {
+				return err
+			}
+			if _, err := hs.release(tx, id, models.HoldStatusExpired); err != nil {
+				return err
+			}
+			return expireAuthorization(tx, session)
+		})
+		// A hold captured or released since the query is not an error
+		if errors.Is(err, ErrHoldNotActive) {
+			continue
+		}
+		if err != nil {
+			log.Printf("Failed to expire hold %s: %v", id, err)
+			continue
+		}
+		expired++
+	}
+
+	return expired, nil
+}
+
+// place reserves funds in a wallet the caller has locked, so that the
+// available balance check cannot race with a debit
+func (hs *HoldService) place(tx *gorm.DB, wallet *models.Wallet, input HoldInput) (*models.Hold, error) {
+	if wallet.Status != models.WalletStatusActive {
+		return nil, ErrWalletClosed
+	}
+	amount, err := input.Amount.In(wallet.Currency)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidAmount, err)
+	}
+	if !amount.IsPositive() {
+		return nil, ErrInvalidAmount
+	}
+	if input.ExpiresAt != nil && !input.ExpiresAt.After(time.Now()) {
+		return nil, ErrInvalidHoldExpiry
+	}
+	if wallet.Available().Amount < amount.Amount {
+		return nil, &InsufficientFundsError{Balance: wallet.Available(), Required: amount}
+	}
+
+	hold := models.Hold{
+		WalletID:       wallet.ID,
+		Kind:           input.Kind,
+		Reference:      input.Reference,
+		Description:    input.Description,
+		Amount:         amount,
+		CapturedAmount: models.ZeroMoney(amount.Currency),
+		Currency:       amount.Currency,
+		Status:         models.HoldStatusActive,
+		PlacedBy:       input.PlacedBy,
+		ExpiresAt:      input.ExpiresAt,
+	}
+	if err := tx.Create(&hold).Error; err != nil {
+		return nil, fmt.Errorf("failed to create hold: %w", err)
+	}
+	if err := adjustHeld(tx, wallet.ID, amount); err != nil {
+		return nil, err
+	}
+	wallet.Held, _ = wallet.Held.Add(amount)
+	return &hold, nil
+}
+
+// capture posts the captured amount from the hold's wallet to the capture
+// account and releases whatever is left of the hold
+func (hs *HoldService) capture(tx *gorm.DB, holdID uuid.UUID, capture HoldCapture) (*models.Hold, *models.JournalEntry, error) {
+	// Settle before posting so the debit comes out of the reserved funds
+	hold, err := hs.settle(tx, holdID, capture.Amount)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var wallet models.Wallet
+	if err := tx.First(&wallet, "id = ?", hold.WalletID).Error; err != nil {
+		return nil, nil, err
+	}
+	walletAccount, err := hs.ledger.WalletAccount(tx, &wallet)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	entry, err := hs.ledger.PostEntry(tx, LedgerEntry{
+		Type:        capture.Type,
+		Description: capture.Description,
+		Reference:   hold.Reference,
+		InitiatedBy: initiator(capture.InitiatedBy),
+		Lines: []LedgerLine{
+			{AccountID: walletAccount.ID, Amount: hold.CapturedAmount.Neg(), Memo: capture.Memo},
+			{AccountID: capture.AccountID, Amount: hold.CapturedAmount},
+		},
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := tx.Model(hold).Update("journal_entry_id", entry.ID).Error; err != nil {
+		return nil, nil, err
+	}
+	hold.JournalEntryID = &entry.ID
+	return hold, entry, nil
+}
+
+// settle marks a hold captured for the given amount (nil captures all of it) and
+// drops it from the wallet's held amount without posting anything. The caller
+// books the money movement in the same transaction, e.g. as a transfer.
+func (hs *HoldService) settle(tx *gorm.DB, holdID uuid.UUID, amount *models.Money) (*models.Hold, error) {
+	hold, err := hs.lockHold(tx, holdID)
+	if err != nil {
+		return nil, err
+	}
+
+	captured := hold.Amount
+	if amount != nil {
+		if captured, err = amount.In(hold.Currency); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidCapture, err)
+		}
+	}
+	if !captured.IsPositive() || captured.Amount > hold.Amount.Amount {
+		return nil, ErrInvalidCapture
+	}
+
+	if err := adjustHeld(tx, hold.WalletID, hold.Amount.Neg()); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if err := tx.Model(hold).Updates(map[string]interface{}{
+		"status":          models.HoldStatusCaptured,
+		"captured_amount": captured,
+		"captured_at":     &now,
+	}).Error; err != nil {
+		return nil, err
+	}
+	hold.Status = models.HoldStatusCaptured
+	hold.CapturedAmount = captured
+	hold.CapturedAt = &now
+	return hold, nil
+}
+
+// release ends an active hold without moving money, as released or expired
+func (hs *HoldService) release(tx *gorm.DB, holdID uuid.UUID, status string) (*models.Hold, error) {
+	hold, err := hs.lockHold(tx, holdID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := adjustHeld(tx, hold.WalletID, hold.Amount.Neg()); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if err := tx.Model(hold).Updates(map[string]interface{}{
+		"status":      status,
+		"released_at": &now,
+	}).Error; err != nil {
+		return nil, err
+	}
+	hold.Status = status
+	hold.ReleasedAt = &now
+	return hold, nil
+}
+
+// lockHold loads an active hold FOR UPDATE. Its wallet is locked first, through
+// lockWallets like every other path that changes a wallet, so that a hold and
+// its wallet are always locked in the same order.
+func (hs *HoldService) lockHold(tx *gorm.DB, holdID uuid.UUID) (*models.Hold, error) {
+	var walletIDs []uuid.UUID
+	if err := tx.Model(&models.Hold{}).Where("id = ?", holdID).Pluck("wallet_id", &walletIDs).Error; err != nil {
+		return nil, err
+	}
+	if len(walletIDs) == 0 {
+		return nil, ErrHoldNotFound
+	}
+	if _, err := hs.transfers.lockWallets(tx, walletIDs[0]); err != nil {
+		return nil, err
+	}
+
+	var hold models.Hold
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&hold, "id = ?", holdID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrHoldNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if hold.Status != models.HoldStatusActive {
+		return nil, ErrHoldNotActive
+	}
+	return &hold, nil
+}
+
+// adjustHeld changes a wallet's held amount atomically
+func adjustHeld(tx *gorm.DB, walletID uuid.UUID, amount models.Money) error {
+	if err := tx.Model(&models.Wallet{}).
+		Where("id = ?", walletID).
+		Update("held", gorm.Expr("held + CAST(? AS DECIMAL(15,2))", amount)).Error; err != nil {
+		return fmt.Errorf("failed to update held amount: %w", err)
+	}
+	return nil
+}
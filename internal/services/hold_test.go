@@ -0,0 +1,209 @@
+package services
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"securewallet/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// placeTestHold authorizes a hold of amount on a wallet
+func placeTestHold(t *testing.T, wallet *models.Wallet, amount, kind string, expiresAt *time.Time) *models.Hold {
+	t.Helper()
+
+	hold, err := NewHoldService().Authorize(HoldInput{
+		WalletID:    wallet.ID,
+		Amount:      mustMoney(amount, wallet.Currency),
+		Kind:        kind,
+		Description: "Test hold",
+		PlacedBy:    wallet.UserID,
+		ExpiresAt:   expiresAt,
+	})
+	if err != nil {
+		t.Fatalf("failed to place hold: %v", err)
+	}
+	return hold
+}
+
+// walletHeld reads a wallet's held amount
+func walletHeld(t *testing.T, db *gorm.DB, walletID uuid.UUID) models.Money {
+	t.Helper()
+
+	var wallet models.Wallet
+	if err := db.First(&wallet, "id = ?", walletID).Error; err != nil {
+		t.Fatalf("failed to load wallet %s: %v", walletID, err)
+	}
+	return wallet.Held
+}
+
+// captureTestHold captures part of a hold into external cash
+func captureTestHold(hs *HoldService, hold *models.Hold, amount *models.Money) error {
+	return hs.transfers.withRetry(func(tx *gorm.DB) error {
+		cash, err := hs.ledger.SystemAccount(tx, SystemAccountExternalCash, hold.Currency)
+		if err != nil {
+			return err
+		}
+		_, _, err = hs.capture(tx, hold.ID, HoldCapture{
+			Amount:      amount,
+			AccountID:   cash.ID,
+			Type:        "CAPTURE",
+			Description: "Test capture",
+		})
+		return err
+	})
+}
+
+func TestHoldReservesAvailableBalance(t *testing.T) {
+	db := setupTestDB(t)
+	wallet := createTestWallet(t, db, "USD")
+	recipient := createTestWallet(t, db, "USD")
+	deposit(t, wallet, "100.00")
+	holds := NewHoldService()
+
+	hold := placeTestHold(t, wallet, "60.00", models.HoldKindAdmin, nil)
+	if got := walletHeld(t, db, wallet.ID); got.Amount != 6000 {
+		t.Errorf("held = %s, want 60.00", got)
+	}
+	var insufficient *InsufficientFundsError
+	if _, err := holds.Authorize(HoldInput{WalletID: wallet.ID, Amount: mustMoney("50.00", "USD"), Kind: models.HoldKindAdmin}); !errors.As(err, &insufficient) {
+		t.Errorf("Authorize past the available balance = %v, want insufficient funds", err)
+	}
+	if _, err := NewTransferService().Transfer(TransferInput{
+		SenderWalletID:    wallet.ID,
+		RecipientWalletID: recipient.ID,
+		Amount:            mustMoney("45.00", "USD"),
+		InitiatedBy:       wallet.UserID,
+	}); !errors.As(err, &insufficient) {
+		t.Errorf("transfer of held funds = %v, want insufficient funds", err)
+	}
+
+	// Only admin holds are released by hand
+	payout := placeTestHold(t, wallet, "10.00", models.HoldKindWithdrawal, nil)
+	if _, err := holds.AdminRelease(payout.ID.String()); !errors.Is(err, ErrHoldNotReleasable) {
+		t.Errorf("AdminRelease of a withdrawal hold = %v, want %v", err, ErrHoldNotReleasable)
+	}
+	if _, err := holds.AdminRelease(hold.ID.String()); err != nil {
+		t.Fatalf("AdminRelease failed: %v", err)
+	}
+	if _, err := holds.AdminRelease(hold.ID.String()); !errors.Is(err, ErrHoldNotActive) {
+		t.Errorf("second AdminRelease = %v, want %v", err, ErrHoldNotActive)
+	}
+	if got := walletHeld(t, db, wallet.ID); got.Amount != 1000 {
+		t.Errorf("held after release = %s, want 10.00", got)
+	}
+	if got := walletBalance(t, db, wallet.ID); got.Amount != 10000 {
+		t.Errorf("balance = %s, want 100.00", got)
+	}
+}
+
+func TestCaptureHold(t *testing.T) {
+	db := setupTestDB(t)
+	wallet := createTestWallet(t, db, "USD")
+	deposit(t, wallet, "100.00")
+	holds := NewHoldService()
+	hold := placeTestHold(t, wallet, "40.00", models.HoldKindAuthorization, nil)
+
+	tooMuch := mustMoney("40.01", "USD")
+	if err := captureTestHold(holds, hold, &tooMuch); !errors.Is(err, ErrInvalidCapture) {
+		t.Errorf("capture above the hold = %v, want %v", err, ErrInvalidCapture)
+	}
+	partial := mustMoney("25.00", "USD")
+	if err := captureTestHold(holds, hold, &partial); err != nil {
+		t.Fatalf("capture failed: %v", err)
+	}
+	if err := captureTestHold(holds, hold, nil); !errors.Is(err, ErrHoldNotActive) {
+		t.Errorf("second capture = %v, want %v", err, ErrHoldNotActive)
+	}
+
+	// The rest of the hold is released
+	if got := walletHeld(t, db, wallet.ID); !got.IsZero() {
+		t.Errorf("held = %s, want zero", got)
+	}
+	if got := walletBalance(t, db, wallet.ID); got.Amount != 7500 {
+		t.Errorf("balance = %s, want 75.00", got)
+	}
+	if total := postingTotal(t, db, "USD"); !total.IsZero() {
+		t.Errorf("postings sum to %s, want zero", total)
+	}
+}
+
+func TestExpireHolds(t *testing.T) {
+	db := setupTestDB(t)
+	wallet := createTestWallet(t, db, "USD")
+	deposit(t, wallet, "100.00")
+	holds := NewHoldService()
+
+	expiresAt := time.Now().Add(time.Hour)
+	hold := placeTestHold(t, wallet, "30.00", models.HoldKindAdmin, &expiresAt)
+	placeTestHold(t, wallet, "20.00", models.HoldKindAdmin, nil)
+
+	if expired, err := holds.ExpireHolds(time.Now()); err != nil || expired != 0 {
+		t.Errorf("ExpireHolds before the expiry = %d, %v, want none", expired, err)
+	}
+	if expired, err := holds.ExpireHolds(expiresAt.Add(time.Minute)); err != nil || expired != 1 {
+		t.Fatalf("ExpireHolds = %d, %v, want 1", expired, err)
+	}
+	var stored models.Hold
+	db.First(&stored, "id = ?", hold.ID)
+	if stored.Status != models.HoldStatusExpired || stored.ReleasedAt == nil {
+		t.Errorf("hold status %s, released at %v, want expired", stored.Status, stored.ReleasedAt)
+	}
+	if got := walletHeld(t, db, wallet.ID); got.Amount != 2000 {
+		t.Errorf("held = %s, want 20.00", got)
+	}
+}
+
+func TestHoldCaptureRacesExpiry(t *testing.T) {
+	db := setupTestDB(t)
+	wallet := createTestWallet(t, db, "USD")
+	deposit(t, wallet, "100.00")
+	holds := NewHoldService()
+
+	expiresAt := time.Now().Add(time.Hour)
+	var placed []*models.Hold
+	for i := 0; i < 10; i++ {
+		placed = append(placed, placeTestHold(t, wallet, "5.00", models.HoldKindAdmin, &expiresAt))
+	}
+
+	// Every hold is either captured or expired, never both
+	var wg sync.WaitGroup
+	for _, hold := range placed {
+		wg.Add(1)
+		go func(hold *models.Hold) {
+			defer wg.Done()
+			if err := captureTestHold(holds, hold, nil); err != nil && !errors.Is(err, ErrHoldNotActive) {
+				t.Errorf("capture of hold %s failed: %v", hold.ID, err)
+			}
+		}(hold)
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if _, err := holds.ExpireHolds(expiresAt.Add(time.Minute)); err != nil {
+			t.Errorf("ExpireHolds failed: %v", err)
+		}
+	}()
+	wg.Wait()
+
+	var captured int64
+	db.Model(&models.Hold{}).Where("wallet_id = ? AND status = ?", wallet.ID, models.HoldStatusCaptured).Count(&captured)
+	var active int64
+	db.Model(&models.Hold{}).Where("wallet_id = ? AND status = ?", wallet.ID, models.HoldStatusActive).Count(&active)
+	if active != 0 {
+		t.Errorf("%d holds still active, want none", active)
+	}
+	if got := walletHeld(t, db, wallet.ID); !got.IsZero() {
+		t.Errorf("held = %s, want zero", got)
+	}
+	if got := walletBalance(t, db, wallet.ID); got.Amount != 10000-captured*500 {
+		t.Errorf("balance = %s with %d holds captured, want 100.00 less 5.00 each", got, captured)
+	}
+	if total := postingTotal(t, db, "USD"); !total.IsZero() {
+		t.Errorf("postings sum to %s, want zero", total)
+	}
+}
@@ -0,0 +1,531 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"securewallet/internal/config"
+	"securewallet/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Insight sizes
+const (
+	insightHistoryMonths     = 6  // Months of income vs spending shown, including the selected one
+	insightTopTransactions   = 5  // Largest transactions listed per direction
+	insightTopCounterparties = 10 // Counterparties listed
+)
+
+// insightExcludedTypes move money between a user's own balances, so they count
+// as neither income nor spending
+var insightExcludedTypes = []string{"EXCHANGE", "OPENING_BALANCE", "POCKET_TRANSFER", "ROUND_UP"}
+
+// ErrInvalidInsightMonth is returned for a month that is not YYYY-MM
+var ErrInvalidInsightMonth = errors.New("month must look like 2006-01")
+
+// MonthSummary is the income and spending of one month
+type MonthSummary struct {
+	Month         string       `json:"month"` // 2006-01
+	Income        models.Money `json:"income"`
+	Spending      models.Money `json:"spending"`
+	Net           models.Money `json:"net"`
+	IncomeCount   int64        `json:"income_count"`
+	SpendingCount int64        `json:"spending_count"`
+}
+
+// MonthComparison compares the selected month with the one before. Percentages
+// are omitted when the previous month had nothing to compare with.
+type MonthComparison struct {
+	IncomeChange          models.Money `json:"income_change"`
+	SpendingChange        models.Money `json:"spending_change"`
+	IncomeChangePercent   *float64     `json:"income_change_percent,omitempty"`
+	SpendingChangePercent *float64     `json:"spending_change_percent,omitempty"`
+}
+
+// InsightAverages are the selected month's averages
+type InsightAverages struct {
+	DailySpending          models.Money `json:"daily_spending"`
+	DailyIncome            models.Money `json:"daily_income"`
+	SpendingPerTransaction models.Money `json:"spending_per_transaction"`
+	Days                   int          `json:"days"` // Days of the month averaged over, up to today
+}
+
+// CategoryBreakdown is the spending filed under one of the user's categories
+type CategoryBreakdown struct {
+	CategoryID *uuid.UUID   `json:"category_id"` // Nil for uncategorised spending
+	Name       string       `json:"name"`
+	Color      string       `json:"color,omitempty"`
+	Total      models.Money `json:"total"`
+	Count      int64        `json:"count"`
+	Share      float64      `json:"share"` // Percentage of the month's spending
+}
+
+// CounterpartyBreakdown is what the user sent to and received from another user
+type CounterpartyBreakdown struct {
+	UserID   uuid.UUID    `json:"user_id"`
+	Username string       `json:"username"`
+	Name     string       `json:"name"`
+	Sent     models.Money `json:"sent"`
+	Received models.Money `json:"received"`
+	Count    int64        `json:"count"`
+}
+
+// Insights summarises a user's income and spending in one currency for a month
+type Insights struct {
+	Month           string                  `json:"month"`
+	Currency        string                  `json:"currency"`
+	Current         MonthSummary            `json:"current"`
+	Previous        MonthSummary            `json:"previous"`
+	Comparison      MonthComparison         `json:"comparison"`
+	Averages        InsightAverages         `json:"averages"`
+	History         []MonthSummary          `json:"history"` // Oldest first, ending with the selected month
+	Categories      []CategoryBreakdown     `json:"categories"`
+	Counterparties  []CounterpartyBreakdown `json:"counterparties"`
+	LargestSpending []models.Transaction    `json:"largest_spending"`
+	LargestIncome   []models.Transaction    `json:"largest_income"`
+}
+
+// rollupRow is one group of the daily rollup, read from the table or computed live
+type rollupRow struct {
+	UserID         uuid.UUID
+	Day            time.Time
+	Currency       string
+	Direction      string
+	Type           string
+	CounterpartyID *uuid.UUID
+	Total          models.Money
+	Count          int64
+}
+
+// counted reports whether a row is income or spending rather than a move
+// between the user's own wallets
+func (r rollupRow) counted() bool {
+	for _, excluded := range insightExcludedTypes {
+		if r.Type == excluded {
+			return false
+		}
+	}
+	return r.Type != "TRANSFER" || r.CounterpartyID != nil
+}
+
+// InsightService computes spending insights from the daily rollup table, adding
+// days that have not been rolled up yet from the transactions themselves
+type InsightService struct {
+	db *gorm.DB
+}
+
+// NewInsightService creates a new insight service
+func NewInsightService() *InsightService {
+	return &InsightService{db: config.GetDB()}
+}
+
+// Insights returns the insights of a month (2006-01, defaulting to the current
+// one) in the given currency, defaulting to the user's default wallet currency
+func (is *InsightService) Insights(userID uuid.UUID, month, currency string, now time.Time) (*Insights, error) {
+	start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.Local)
+	if month != "" {
+		parsed, err := time.ParseInLocation("2006-01", month, time.Local)
+		if err != nil {
+			return nil, ErrInvalidInsightMonth
+		}
+		start = parsed
+	}
+	end := start.AddDate(0, 1, 0)
+
+	if currency == "" {
+		currency = "USD"
+		if wallet, err := NewWalletService().DefaultWallet(userID); err == nil {
+			currency = wallet.Currency
+		}
+	}
+	if !models.IsSupportedCurrency(currency) {
+		return nil, ErrUnsupportedCurrency
+	}
+
+	historyStart := start.AddDate(0, 1-insightHistoryMonths, 0)
+	rows, err := is.rows(userID, currency, historyStart, end)
+	if err != nil {
+		return nil, err
+	}
+
+	insights := &Insights{Month: start.Format("2006-01"), Currency: currency}
+
+	// Monthly history, oldest first
+	months := make(map[string]*MonthSummary, insightHistoryMonths)
+	for m := historyStart; m.Before(end); m = m.AddDate(0, 1, 0) {
+		months[m.Format("2006-01")] = &MonthSummary{
+			Month:    m.Format("2006-01"),
+			Income:   models.ZeroMoney(currency),
+			Spending: models.ZeroMoney(currency),
+			Net:      models.ZeroMoney(currency),
+		}
+	}
+	counterparties := map[uuid.UUID]*CounterpartyBreakdown{}
+	for _, row := range rows {
+		if !row.counted() {
+			continue
+		}
+		summary := months[row.Day.Format("2006-01")]
+		if summary == nil {
+			continue
+		}
+		if row.Direction == "out" {
+			summary.Spending, _ = summary.Spending.Add(row.Total)
+			summary.Net, _ = summary.Net.Sub(row.Total)
+			summary.SpendingCount += row.Count
+		} else {
+			summary.Income, _ = summary.Income.Add(row.Total)
+			summary.Net, _ = summary.Net.Add(row.Total)
+			summary.IncomeCount += row.Count
+		}
+
+		if row.CounterpartyID != nil && !row.Day.Before(start) {
+			breakdown := counterparties[*row.CounterpartyID]
+			if breakdown == nil {
+				breakdown = &CounterpartyBreakdown{
+					UserID:   *row.CounterpartyID,
+					Sent:     models.ZeroMoney(currency),
+					Received: models.ZeroMoney(currency),
+				}
+				counterparties[*row.CounterpartyID] = breakdown
+			}
+			if row.Direction == "out" {
+				breakdown.Sent, _ = breakdown.Sent.Add(row.Total)
+			} else {
+				breakdown.Received, _ = breakdown.Received.Add(row.Total)
+			}
+			breakdown.Count += row.Count
+		}
+	}
+	for m := historyStart; m.Before(end); m = m.AddDate(0, 1, 0) {
+		insights.History = append(insights.History, *months[m.Format("2006-01")])
+	}
+	insights.Current = *months[start.Format("2006-01")]
+	insights.Previous = *months[start.AddDate(0, -1, 0).Format("2006-01")]
+
+	insights.Comparison = MonthComparison{
+		IncomeChangePercent:   percentChange(insights.Previous.Income, insights.Current.Income),
+		SpendingChangePercent: percentChange(insights.Previous.Spending, insights.Current.Spending),
+	}
+	insights.Comparison.IncomeChange, _ = insights.Current.Income.Sub(insights.Previous.Income)
+	insights.Comparison.SpendingChange, _ = insights.Current.Spending.Sub(insights.Previous.Spending)
+
+	// Averages run up to today for the current month
+	days := end.AddDate(0, 0, -1).Day()
+	if today := localDay(now); !today.Before(start) && today.Before(end) {
+		days = today.Day()
+	}
+	insights.Averages = InsightAverages{
+		DailySpending:          insights.Current.Spending.Mul(1, int64(days), models.RoundHalfEven),
+		DailyIncome:            insights.Current.Income.Mul(1, int64(days), models.RoundHalfEven),
+		SpendingPerTransaction: models.ZeroMoney(currency),
+		Days:                   days,
+	}
+	if insights.Current.SpendingCount > 0 {
+		insights.Averages.SpendingPerTransaction = insights.Current.Spending.Mul(1, insights.Current.SpendingCount, models.RoundHalfEven)
+	}
+
+	if insights.Counterparties, err = is.counterparties(counterparties); err != nil {
+		return nil, err
+	}
+	if insights.Categories, err = is.categories(userID, currency, start, end, insights.Current.Spending); err != nil {
+		return nil, err
+	}
+	if insights.LargestSpending, err = is.largest(userID, currency, "out", start, end); err != nil {
+		return nil, err
+	}
+	if insights.LargestIncome, err = is.largest(userID, currency, "in", start, end); err != nil {
+		return nil, err
+	}
+
+	return insights, nil
+}
+
+// RefreshRollups rebuilds the rollup of every completed day since the last
+// refresh. The last rolled-up day is rebuilt as well, to pick up transactions
+// that committed just after midnight.
+func (is *InsightService) RefreshRollups(now time.Time) (int, error) {
+	today := localDay(now)
+
+	from, err := is.rolledUntil()
+	if err != nil {
+		return 0, err
+	}
+	if from.IsZero() {
+		// First run: start from the oldest ledger transaction
+		var oldest *time.Time
+		if err := is.db.Model(&models.Transaction{}).Where("journal_entry_id IS NOT NULL").
+			Select("MIN(created_at)").Row().Scan(&oldest); err != nil {
+			return 0, fmt.Errorf("failed to find oldest transaction: %w", err)
+		}
+		if oldest == nil {
+			return 0, nil
+		}
+		from = localDay(*oldest)
+	} else {
+		from = from.AddDate(0, 0, -1)
+	}
+	if !from.Before(today) {
+		return 0, nil
+	}
+
+	var rows []rollupRow
+	if err := is.rollupQuery(from, today).Scan(&rows).Error; err != nil {
+		return 0, fmt.Errorf("failed to aggregate transactions: %w", err)
+	}
+
+	rollups := make([]models.InsightRollup, 0, len(rows))
+	for _, row := range rows {
+		total, err := row.Total.In(row.Currency)
+		if err != nil {
+			return 0, err
+		}
+		rollups = append(rollups, models.InsightRollup{
+			UserID:         row.UserID,
+			Day:            row.Day,
+			Currency:       row.Currency,
+			Direction:      row.Direction,
+			Type:           row.Type,
+			CounterpartyID: row.CounterpartyID,
+			Total:          total,
+			Count:          row.Count,
+		})
+	}
+
+	err = is.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("day >= ?", from).Delete(&models.InsightRollup{}).Error; err != nil {
+			return err
+		}
+		if len(rollups) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(rollups, 500).Error
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to store rollups: %w", err)
+	}
+	return len(rollups), nil
+}
+
+// rows returns the rollup of a user's transactions in one currency over [from, to):
+// stored rows for days already rolled up, live aggregates for the rest
+func (is *InsightService) rows(userID uuid.UUID, currency string, from, to time.Time) ([]rollupRow, error) {
+	rolledUntil, err := is.rolledUntil()
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []rollupRow
+	if rolledUntil.After(from) {
+		var stored []models.InsightRollup
+		if err := is.db.Where("user_id = ? AND currency = ? AND day >= ? AND day < ?", userID, currency, from, minTime(rolledUntil, to)).
+			Find(&stored).Error; err != nil {
+			return nil, fmt.Errorf("failed to load rollups: %w", err)
+		}
+		for _, rollup := range stored {
+			rows = append(rows, rollupRow{
+				UserID:         rollup.UserID,
+				Day:            rollup.Day,
+				Currency:       rollup.Currency,
+				Direction:      rollup.Direction,
+				Type:           rollup.Type,
+				CounterpartyID: rollup.CounterpartyID,
+				Total:          rollup.Total,
+				Count:          rollup.Count,
+			})
+		}
+	}
+
+	if liveFrom := maxTime(rolledUntil, from); liveFrom.Before(to) {
+		var live []rollupRow
+		if err := is.rollupQuery(liveFrom, to).
+			Where("w.user_id = ? AND t.currency = ?", userID, currency).
+			Scan(&live).Error; err != nil {
+			return nil, fmt.Errorf("failed to aggregate transactions: %w", err)
+		}
+		for _, row := range live {
+			if row.Total, err = row.Total.In(row.Currency); err != nil {
+				return nil, err
+			}
+			rows = append(rows, row)
+		}
+	}
+
+	return rows, nil
+}
+
+// rollupQuery groups personal wallet transactions over [from, to) by owner, day,
+// currency, direction, type and the user on the other side of a transfer
+func (is *InsightService) rollupQuery(from, to time.Time) *gorm.DB {
+	return is.db.Table("transactions AS t").
+		Select("w.user_id, DATE(t.created_at) AS day, t.currency, t.direction, t.type, "+
+			"ow.user_id AS counterparty_id, SUM(t.amount) AS total, COUNT(*) AS count").
+		Joins("JOIN wallets w ON w.id = t.wallet_id").
+		Joins("LEFT JOIN (transactions o JOIN wallets ow ON ow.id = o.wallet_id) "+
+			"ON o.journal_entry_id = t.journal_entry_id AND ow.user_id <> w.user_id").
+		Where("t.journal_entry_id IS NOT NULL AND t.deleted_at IS NULL AND t.created_at >= ? AND t.created_at < ?", from, to).
+		Where("w.organization_id IS NULL").
+		Group("w.user_id, DATE(t.created_at), t.currency, t.direction, t.type, ow.user_id")
+}
+
+// rolledUntil returns the first day the rollup table does not cover yet, or the
+// zero time when nothing has been rolled up
+func (is *InsightService) rolledUntil() (time.Time, error) {
+	var last *time.Time
+	if err := is.db.Model(&models.InsightRollup{}).Select("MAX(day)").Row().Scan(&last); err != nil {
+		return time.Time{}, fmt.Errorf("failed to read rollup progress: %w", err)
+	}
+	if last == nil {
+		return time.Time{}, nil
+	}
+	return localDay(*last).AddDate(0, 0, 1), nil
+}
+
+// countedTransactions selects a user's income or spending in one currency over [from, to)
+func (is *InsightService) countedTransactions(userID uuid.UUID, currency, direction string, from, to time.Time) *gorm.DB {
+	return is.db.Table("transactions AS t").
+		Joins("JOIN wallets w ON w.id = t.wallet_id").
+		Where("w.user_id = ? AND w.organization_id IS NULL AND t.currency = ? AND t.direction = ?", userID, currency, direction).
+		Where("t.journal_entry_id IS NOT NULL AND t.deleted_at IS NULL AND t.created_at >= ? AND t.created_at < ?", from, to).
+		Where("t.type NOT IN ?", insightExcludedTypes).
+		Where("(t.type <> 'TRANSFER' OR EXISTS (SELECT 1 FROM transactions o JOIN wallets ow ON ow.id = o.wallet_id " +
+			"WHERE o.journal_entry_id = t.journal_entry_id AND ow.user_id <> w.user_id))")
+}
+
+// categories breaks the month's spending down by the user's own categories.
+// Categories can be changed after the fact, so this is grouped live over the month.
+func (is *InsightService) categories(userID uuid.UUID, currency string, from, to time.Time, spending models.Money) ([]CategoryBreakdown, error) {
+	var rows []struct {
+		CategoryID *uuid.UUID
+		Total      models.Money
+		Count      int64
+	}
+	if err := is.countedTransactions(userID, currency, "out", from, to).
+		Select("a.category_id, SUM(t.amount) AS total, COUNT(*) AS count").
+		Joins("LEFT JOIN transaction_annotations a ON a.transaction_id = t.id AND a.user_id = ?", userID).
+		Group("a.category_id").
+		Order("total DESC").
+		Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to group spending by category: %w", err)
+	}
+
+	var categories []models.Category
+	if err := is.db.Where("user_id = ?", userID).Find(&categories).Error; err != nil {
+		return nil, err
+	}
+	byID := make(map[uuid.UUID]models.Category, len(categories))
+	for _, category := range categories {
+		byID[category.ID] = category
+	}
+
+	breakdown := []CategoryBreakdown{}
+	for _, row := range rows {
+		total, err := row.Total.In(currency)
+		if err != nil {
+			return nil, err
+		}
+		item := CategoryBreakdown{CategoryID: row.CategoryID, Name: "Uncategorised", Total: total, Count: row.Count}
+		if row.CategoryID != nil {
+			category, ok := byID[*row.CategoryID]
+			if !ok {
+				continue
+			}
+			item.Name, item.Color = category.Name, category.Color
+		}
+		if spending.IsPositive() {
+			item.Share = math.Round(float64(total.Amount)*1000/float64(spending.Amount)) / 10
+		}
+		breakdown = append(breakdown, item)
+	}
+	return breakdown, nil
+}
+
+// largest returns the month's biggest income or spending transactions
+func (is *InsightService) largest(userID uuid.UUID, currency, direction string, from, to time.Time) ([]models.Transaction, error) {
+	transactions := []models.Transaction{}
+	if err := is.countedTransactions(userID, currency, direction, from, to).
+		Select("t.*").
+		Order("t.amount DESC, t.created_at DESC").
+		Limit(insightTopTransactions).
+		Find(&transactions).Error; err != nil {
+		return nil, fmt.Errorf("failed to load largest transactions: %w", err)
+	}
+	return transactions, nil
+}
+
+// counterparties names the month's counterparties, largest volume first
+func (is *InsightService) counterparties(breakdowns map[uuid.UUID]*CounterpartyBreakdown) ([]CounterpartyBreakdown, error) {
+	result := []CounterpartyBreakdown{}
+	if len(breakdowns) == 0 {
+		return result, nil
+	}
+
+	ids := make([]uuid.UUID, 0, len(breakdowns))
+	for id, breakdown := range breakdowns {
+		ids = append(ids, id)
+		result = append(result, *breakdown)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		vi := result[i].Sent.Amount + result[i].Received.Amount
+		vj := result[j].Sent.Amount + result[j].Received.Amount
+		if vi != vj {
+			return vi > vj
+		}
+		return result[i].UserID.String() < result[j].UserID.String()
+	})
+	if len(result) > insightTopCounterparties {
+		result = result[:insightTopCounterparties]
+	}
+
+	var users []models.User
+	if err := is.db.Unscoped().Where("id IN ?", ids).Find(&users).Error; err != nil {
+		return nil, fmt.Errorf("failed to load counterparties: %w", err)
+	}
+	byID := make(map[uuid.UUID]models.User, len(users))
+	for _, user := range users {
+		byID[user.ID] = user
+	}
+	for i := range result {
+		result[i].Username = byID[result[i].UserID].Username
+		result[i].Name = byID[result[i].UserID].Name
+	}
+	return result, nil
+}
+
+// percentChange returns the change from previous to current in percent, rounded
+// to one decimal, or nil when previous is zero
+func percentChange(previous, current models.Money) *float64 {
+	if previous.IsZero() {
+		return nil
+	}
+	change := math.Round(float64(current.Amount-previous.Amount)*1000/float64(previous.Amount)) / 10
+	return &change
+}
+
+// localDay truncates a time to midnight in the server's time zone, which is the
+// zone transactions are stored in
+func localDay(t time.Time) time.Time {
+	year, month, day := t.In(time.Local).Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.Local)
+}
+
+// minTime returns the earlier of two times
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
+
+// maxTime returns the later of two times
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,529 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"strings"
+	"time"
+
+	"securewallet/internal/config"
+	"securewallet/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Interest errors
+var (
+	ErrInvalidInterestRate  = errors.New("annual rate must be between 0 and 10000 basis points")
+	ErrInvalidDayCount      = errors.New("day count must be actual/365, actual/360, actual/actual or 30/360")
+	ErrInvalidInterestRange = errors.New("recompute range must end before today and span at most two years")
+)
+
+// accrualScale is the number of Accrued units per minor unit of currency
+const accrualScale = 1000000
+
+// maxRecomputeDays bounds how far a single recompute reaches
+const maxRecomputeDays = 731
+
+// InterestService accrues interest on wallet balances every day and
+// capitalises it into the wallets once a month
+type InterestService struct {
+	db        *gorm.DB
+	ledger    *LedgerService
+	transfers *TransferService
+}
+
+// InterestRateInput describes a rate an admin sets for a wallet type and currency
+type InterestRateInput struct {
+	WalletType    string
+	Currency      string
+	AnnualRateBps int64
+	DayCount      string
+	EffectiveFrom time.Time // Truncated to the day; may be in the past
+	CreatedBy     uuid.UUID
+}
+
+// WalletInterest summarises the interest of one wallet
+type WalletInterest struct {
+	WalletID         uuid.UUID                `json:"wallet_id"`
+	WalletType       string                   `json:"wallet_type"`
+	Rate             *models.InterestRate     `json:"rate"`               // Rate applying today, nil when none
+	AccruedThisMonth models.Money             `json:"accrued_this_month"` // Rounded, credited after the month ends
+	Postings         []models.InterestPosting `json:"postings"`
+}
+
+// RecomputeResult reports what a recompute changed
+type RecomputeResult struct {
+	From     time.Time `json:"from"`
+	To       time.Time `json:"to"`
+	Days     int       `json:"days"`
+	Accruals int       `json:"accruals"`
+	Postings int       `json:"postings"` // Capitalisations and corrections posted
+}
+
+// NewInterestService creates a new interest service
+func NewInterestService() *InterestService {
+	return &InterestService{
+		db:        config.GetDB(),
+		ledger:    NewLedgerService(),
+		transfers: NewTransferService(),
+	}
+}
+
+// dayStart returns midnight of the day t falls on, in local time
+func dayStart(t time.Time) time.Time {
+	t = t.In(time.Local)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+}
+
+// isValidDayCount reports whether a day-count convention is supported
+func isValidDayCount(dayCount string) bool {
+	switch dayCount {
+	case models.DayCountActual365, models.DayCountActual360, models.DayCountActualActual, models.DayCount30360:
+		return true
+	}
+	return false
+}
+
+// dayFraction returns the share of a year one day counts for under a
+// day-count convention, as a fraction. Under 30/360 the 31st counts for
+// nothing and the last day of February makes up the rest of a 30-day month.
+func dayFraction(dayCount string, day time.Time) (int64, int64) {
+	switch dayCount {
+	case models.DayCountActual360:
+		return 1, 360
+	case models.DayCountActualActual:
+		if year := day.Year(); year%4 == 0 && (year%100 != 0 || year%400 == 0) {
+			return 1, 366
+		}
+		return 1, 365
+	case models.DayCount30360:
+		if day.Day() == 31 {
+			return 0, 360
+		}
+		if day.Month() == time.February && day.AddDate(0, 0, 1).Month() != time.February {
+			return int64(30 - day.Day() + 1), 360
+		}
+		return 1, 360
+	default:
+		return 1, 365
+	}
+}
+
+// accrue returns the interest a balance earns in one day, in Accrued units
+func accrue(balance models.Money, rate *models.InterestRate, day time.Time) int64 {
+	num, den := dayFraction(rate.DayCount, day)
+	n := new(big.Int).Mul(big.NewInt(balance.Amount), big.NewInt(rate.AnnualRateBps))
+	n.Mul(n, big.NewInt(num*accrualScale))
+	d := big.NewInt(10000 * den)
+	return n.Quo(n, d).Int64()
+}
+
+// roundAccrued converts Accrued units to minor units, rounding half up
+func roundAccrued(accrued int64) int64 {
+	if accrued < 0 {
+		return -((-accrued + accrualScale/2) / accrualScale)
+	}
+	return (accrued + accrualScale/2) / accrualScale
+}
+
+// ListRates lists every interest rate, newest first
+func (is *InterestService) ListRates() ([]models.InterestRate, error) {
+	var rates []models.InterestRate
+	if err := is.db.Order("wallet_type, currency, effective_from DESC, created_at DESC").Find(&rates).Error; err != nil {
+		return nil, err
+	}
+	return rates, nil
+}
+
+// SetRate records a rate taking over from its effective date. A backdated rate
+// only changes interest already accrued once that period is recomputed.
+func (is *InterestService) SetRate(input InterestRateInput) (*models.InterestRate, error) {
+	currency := strings.ToUpper(input.Currency)
+	if !models.IsSupportedCurrency(currency) {
+		return nil, ErrUnsupportedCurrency
+	}
+	if input.WalletType != models.WalletTypeCurrent && input.WalletType != models.WalletTypeSavings {
+		return nil, ErrInvalidWalletType
+	}
+	if input.AnnualRateBps < 0 || input.AnnualRateBps > 10000 {
+		return nil, ErrInvalidInterestRate
+	}
+	if input.DayCount == "" {
+		input.DayCount = models.DayCountActual365
+	}
+	if !isValidDayCount(input.DayCount) {
+		return nil, ErrInvalidDayCount
+	}
+
+	rate := models.InterestRate{
+		WalletType:    input.WalletType,
+		Currency:      currency,
+		AnnualRateBps: input.AnnualRateBps,
+		DayCount:      input.DayCount,
+		EffectiveFrom: dayStart(input.EffectiveFrom),
+		CreatedBy:     initiator(input.CreatedBy),
+	}
+	if err := is.db.Create(&rate).Error; err != nil {
+		return nil, fmt.Errorf("failed to save interest rate: %w", err)
+	}
+	return &rate, nil
+}
+
+// ratesOn returns the rate applying on a day to each wallet type and currency
+func (is *InterestService) ratesOn(tx *gorm.DB, day time.Time) (map[string]*models.InterestRate, error) {
+	var rates []models.InterestRate
+	if err := tx.Where("effective_from <= ?", day).
+		Order("effective_from DESC, created_at DESC").
+		Find(&rates).Error; err != nil {
+		return nil, fmt.Errorf("failed to load interest rates: %w", err)
+	}
+
+	applying := make(map[string]*models.InterestRate)
+	for i := range rates {
+		key := rates[i].WalletType + ":" + rates[i].Currency
+		if _, seen := applying[key]; !seen {
+			applying[key] = &rates[i]
+		}
+	}
+	return applying, nil
+}
+
+// AccrueDay computes the interest every wallet earned on its balance at the
+// end of a day, replacing what was accrued for that day before. Only positive
+// balances earn interest.
+func (is *InterestService) AccrueDay(day time.Time) (int, error) {
+	day = dayStart(day)
+	end := day.AddDate(0, 0, 1)
+
+	accrued := 0
+	err := is.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("date = ?", day).Delete(&models.InterestAccrual{}).Error; err != nil {
+			return fmt.Errorf("failed to clear accruals: %w", err)
+		}
+
+		rates, err := is.ratesOn(tx, day)
+		if err != nil || len(rates) == 0 {
+			return err
+		}
+
+		var rows []struct {
+			WalletID   uuid.UUID
+			WalletType string
+			Currency   string
+			Balance    models.Money
+		}
+		if err := tx.Table("wallets AS w").
+			Select("w.id AS wallet_id, w.type AS wallet_type, w.currency, SUM(p.amount) AS balance").
+			Joins("JOIN ledger_accounts a ON a.wallet_id = w.id AND a.type = ?", models.LedgerAccountTypeWallet).
+			Joins("JOIN ledger_postings p ON p.account_id = a.id AND p.created_at < ?", end).
+			Where("w.created_at < ? AND (w.status <> ? OR w.closed_at >= ?)", end, models.WalletStatusClosed, end).
+			Group("w.id, w.type, w.currency").
+			Having("SUM(p.amount) > 0").
+			Scan(&rows).Error; err != nil {
+			return fmt.Errorf("failed to load balances: %w", err)
+		}
+
+		accruals := make([]models.InterestAccrual, 0, len(rows))
+		for _, row := range rows {
+			rate, ok := rates[row.WalletType+":"+row.Currency]
+			if !ok {
+				continue
+			}
+			balance, err := row.Balance.In(row.Currency)
+			if err != nil {
+				return err
+			}
+			amount := accrue(balance, rate, day)
+			if amount <= 0 {
+				continue
+			}
+			accruals = append(accruals, models.InterestAccrual{
+				WalletID:      row.WalletID,
+				Date:          day,
+				Currency:      row.Currency,
+				Balance:       balance,
+				RateID:        rate.ID,
+				AnnualRateBps: rate.AnnualRateBps,
+				DayCount:      rate.DayCount,
+				Accrued:       amount,
+			})
+		}
+		if len(accruals) == 0 {
+			return nil
+		}
+		if err := tx.CreateInBatches(&accruals, 500).Error; err != nil {
+			return fmt.Errorf("failed to save accruals: %w", err)
+		}
+		accrued = len(accruals)
+		return nil
+	})
+	return accrued, err
+}
+
+// CapitaliseMonth credits each wallet with the interest accrued over a month,
+// less what was already credited for it. Running it again after a recompute
+// posts the difference as a correction, so it is safe to repeat. A wallet that
+// fails is logged and skipped so the rest are still credited.
+func (is *InterestService) CapitaliseMonth(start time.Time) (int, error) {
+	start = monthStart(dayStart(start))
+	end := start.AddDate(0, 1, 0)
+
+	// Wallets with accruals in the month, or with postings a recompute may have to take back
+	var walletIDs []uuid.UUID
+	if err := is.db.Raw(
+		"SELECT wallet_id FROM interest_accruals WHERE date >= ? AND date < ? UNION SELECT wallet_id FROM interest_postings WHERE period_start = ?",
+		start, end, start,
+	).Scan(&walletIDs).Error; err != nil {
+		return 0, fmt.Errorf("failed to load wallets with interest: %w", err)
+	}
+
+	posted := 0
+	var errs []error
+	for _, walletID := range walletIDs {
+		ok, err := is.capitaliseWallet(walletID, start, end)
+		if errors.Is(err, ErrWalletClosed) || errors.Is(err, ErrWalletNotFound) {
+			continue
+		}
+		if err != nil {
+			err = fmt.Errorf("failed to capitalise interest of wallet %s: %w", walletID, err)
+			log.Println(err)
+			errs = append(errs, err)
+			continue
+		}
+		if ok {
+			posted++
+		}
+	}
+	return posted, errors.Join(errs...)
+}
+
+// capitaliseWallet posts the outstanding interest of one wallet for a month
+// and reports whether anything was posted
+func (is *InterestService) capitaliseWallet(walletID uuid.UUID, start, end time.Time) (bool, error) {
+	posted := false
+	err := is.transfers.withRetry(func(tx *gorm.DB) error {
+		posted = false
+		wallets, err := is.transfers.lockWallets(tx, walletID)
+		if err != nil {
+			return err
+		}
+		wallet := wallets[walletID]
+
+		var accrued int64
+		if err := tx.Model(&models.InterestAccrual{}).
+			Where("wallet_id = ? AND date >= ? AND date < ?", walletID, start, end).
+			Select("COALESCE(SUM(accrued), 0)").
+			Row().Scan(&accrued); err != nil {
+			return fmt.Errorf("failed to sum accruals: %w", err)
+		}
+
+		var postings []models.InterestPosting
+		if err := tx.Where("wallet_id = ? AND period_start = ?", walletID, start).Find(&postings).Error; err != nil {
+			return fmt.Errorf("failed to load interest postings: %w", err)
+		}
+		credited := models.ZeroMoney(wallet.Currency)
+		for _, posting := range postings {
+			if credited, err = credited.Add(posting.Amount); err != nil {
+				return err
+			}
+		}
+
+		due := models.NewMoney(roundAccrued(accrued), wallet.Currency)
+		delta, err := due.Sub(credited)
+		if err != nil {
+			return err
+		}
+		// Interest taken back cannot overdraw the wallet; the rest is retried next run
+		if delta.IsNegative() {
+			if !wallet.Available().IsPositive() {
+				return nil
+			}
+			delta = delta.Max(wallet.Available().Neg())
+		}
+		if delta.IsZero() {
+			return nil
+		}
+
+		kind := models.InterestPostingCapitalisation
+		memo := fmt.Sprintf("Interest for %s", start.Format("January 2006"))
+		if len(postings) > 0 {
+			kind = models.InterestPostingCorrection
+			memo = fmt.Sprintf("Interest correction for %s", start.Format("January 2006"))
+		}
+
+		walletAccount, err := is.ledger.WalletAccount(tx, &wallet)
+		if err != nil {
+			return err
+		}
+		expense, err := is.ledger.SystemAccount(tx, SystemAccountInterestExpense, wallet.Currency)
+		if err != nil {
+			return err
+		}
+		entry, err := is.ledger.PostEntry(tx, LedgerEntry{
+			Type:        "INTEREST",
+			Description: memo,
+			Reference:   fmt.Sprintf("interest:%s", start.Format("2006-01")),
+			Lines: []LedgerLine{
+				{AccountID: walletAccount.ID, Amount: delta, Memo: memo},
+				{AccountID: expense.ID, Amount: delta.Neg(), Memo: memo},
+			},
+		})
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Create(&models.InterestPosting{
+			WalletID:       walletID,
+			PeriodStart:    start,
+			Kind:           kind,
+			Amount:         delta,
+			Currency:       wallet.Currency,
+			JournalEntryID: entry.ID,
+		}).Error; err != nil {
+			return fmt.Errorf("failed to record interest posting: %w", err)
+		}
+		posted = true
+		return nil
+	})
+	return posted, err
+}
+
+// RunDaily accrues interest for every day from the one after the last accrual
+// up to the day before now, so days missed while the job was not running are
+// caught up, then capitalises each month those days finished. The previous
+// month is always capitalised, which only posts anything on the first run of
+// a month or after a recompute.
+func (is *InterestService) RunDaily(now time.Time) (int, int, error) {
+	today := dayStart(now)
+	from, err := is.nextAccrualDay(today)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	accrued := 0
+	for day := from; day.Before(today); day = day.AddDate(0, 0, 1) {
+		n, err := is.AccrueDay(day)
+		if err != nil {
+			return accrued, 0, fmt.Errorf("failed to accrue %s: %w", day.Format("2006-01-02"), err)
+		}
+		accrued += n
+	}
+
+	// The month of the last day accrued before may not have been capitalised either
+	month := monthStart(today).AddDate(0, -1, 0)
+	if first := monthStart(from.AddDate(0, 0, -1)); first.Before(month) {
+		month = first
+	}
+	posted := 0
+	var errs []error
+	for ; month.Before(monthStart(today)); month = month.AddDate(0, 1, 0) {
+		n, err := is.CapitaliseMonth(month)
+		posted += n
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return accrued, posted, errors.Join(errs...)
+}
+
+// nextAccrualDay returns the first day RunDaily accrues: the day after the
+// latest accrual, going back at most maxRecomputeDays, or yesterday when
+// nothing was accrued since. Yesterday is always accrued again, as rerunning
+// a day replaces its accruals.
+func (is *InterestService) nextAccrualDay(today time.Time) (time.Time, error) {
+	yesterday := today.AddDate(0, 0, -1)
+
+	var last models.InterestAccrual
+	err := is.db.Where("date < ?", today).Order("date DESC").First(&last).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return yesterday, nil
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to load last accrual: %w", err)
+	}
+
+	from := dayStart(last.Date).AddDate(0, 0, 1)
+	if earliest := today.AddDate(0, 0, -maxRecomputeDays); from.Before(earliest) {
+		from = earliest
+	}
+	if from.After(yesterday) {
+		from = yesterday
+	}
+	return from, nil
+}
+
+// Recompute accrues every day from from to to (inclusive) again with the rates
+// now on file, then capitalises each month of the range that has ended, posting
+// corrections for interest already credited
+func (is *InterestService) Recompute(from, to time.Time) (*RecomputeResult, error) {
+	from, to = dayStart(from), dayStart(to)
+	today := dayStart(time.Now())
+	if to.Before(from) || !to.Before(today) || to.Sub(from) > maxRecomputeDays*24*time.Hour {
+		return nil, ErrInvalidInterestRange
+	}
+
+	result := &RecomputeResult{From: from, To: to}
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		accrued, err := is.AccrueDay(day)
+		if err != nil {
+			return result, fmt.Errorf("failed to accrue %s: %w", day.Format("2006-01-02"), err)
+		}
+		result.Days++
+		result.Accruals += accrued
+	}
+
+	for month := monthStart(from); !month.After(to); month = month.AddDate(0, 1, 0) {
+		if month.AddDate(0, 1, 0).After(today) {
+			break
+		}
+		posted, err := is.CapitaliseMonth(month)
+		result.Postings += posted
+		if err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// WalletSummary returns the rate, current month's accrued interest and past
+// postings of one of the user's wallets
+func (is *InterestService) WalletSummary(userID uuid.UUID, walletID string) (*WalletInterest, error) {
+	wallet, err := NewWalletService().UserWallet(userID, walletID)
+	if err != nil {
+		return nil, err
+	}
+
+	today := dayStart(time.Now())
+	rates, err := is.ratesOn(is.db, today)
+	if err != nil {
+		return nil, err
+	}
+
+	var accrued int64
+	start := monthStart(today)
+	if err := is.db.Model(&models.InterestAccrual{}).
+		Where("wallet_id = ? AND date >= ? AND date < ?", wallet.ID, start, start.AddDate(0, 1, 0)).
+		Select("COALESCE(SUM(accrued), 0)").
+		Row().Scan(&accrued); err != nil {
+		return nil, fmt.Errorf("failed to sum accruals: %w", err)
+	}
+
+	postings := []models.InterestPosting{}
+	if err := is.db.Where("wallet_id = ?", wallet.ID).
+		Order("period_start DESC, created_at DESC").
+		Find(&postings).Error; err != nil {
+		return nil, fmt.Errorf("failed to load interest postings: %w", err)
+	}
+
+	return &WalletInterest{
+		WalletID:         wallet.ID,
+		WalletType:       wallet.Type,
+		Rate:             rates[wallet.Type+":"+wallet.Currency],
+		AccruedThisMonth: models.NewMoney(roundAccrued(accrued), wallet.Currency),
+		Postings:         postings,
+	}, nil
+}
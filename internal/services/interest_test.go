@@ -0,0 +1,144 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"securewallet/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// interestWallet creates a current-account wallet holding amount since the given time
+func interestWallet(t *testing.T, db *gorm.DB, amount string, since time.Time) *models.Wallet {
+	t.Helper()
+
+	wallet := createTestWallet(t, db, "USD")
+	deposit(t, wallet, amount)
+	account, err := NewLedgerService().WalletAccount(db, wallet)
+	if err != nil {
+		t.Fatalf("failed to load wallet account: %v", err)
+	}
+	if err := db.Model(&models.Posting{}).Where("account_id = ?", account.ID).Update("created_at", since).Error; err != nil {
+		t.Fatalf("failed to backdate postings: %v", err)
+	}
+	if err := db.Model(&models.Wallet{}).Where("id = ?", wallet.ID).Update("created_at", since).Error; err != nil {
+		t.Fatalf("failed to backdate wallet: %v", err)
+	}
+	return wallet
+}
+
+// setTestRate pays 0.1% a day on current accounts in USD
+func setTestRate(t *testing.T) {
+	t.Helper()
+
+	if _, err := NewInterestService().SetRate(InterestRateInput{
+		WalletType:    models.WalletTypeCurrent,
+		Currency:      "USD",
+		AnnualRateBps: 3650,
+		EffectiveFrom: time.Date(2026, time.January, 1, 0, 0, 0, 0, time.Local),
+		CreatedBy:     uuid.New(),
+	}); err != nil {
+		t.Fatalf("failed to set interest rate: %v", err)
+	}
+}
+
+func TestRunDailyCatchesUpMissedDays(t *testing.T) {
+	db := setupTestDB(t)
+	setTestRate(t)
+	may31 := time.Date(2026, time.May, 31, 0, 0, 0, 0, time.Local)
+	wallet := interestWallet(t, db, "1000.00", may31.AddDate(0, 0, -10))
+	interest := NewInterestService()
+
+	// The job last ran for May 31 and then missed June 1 to 3
+	if _, err := interest.AccrueDay(may31); err != nil {
+		t.Fatalf("AccrueDay failed: %v", err)
+	}
+	june4 := time.Date(2026, time.June, 4, 9, 0, 0, 0, time.Local)
+	accrued, posted, err := interest.RunDaily(june4)
+	if err != nil || accrued != 3 || posted != 1 {
+		t.Fatalf("RunDaily = %d, %d, %v, want 3 accrued and 1 posted", accrued, posted, err)
+	}
+
+	var days int64
+	db.Model(&models.InterestAccrual{}).Where("wallet_id = ?", wallet.ID).Count(&days)
+	if days != 4 {
+		t.Errorf("wallet has %d accruals, want 4", days)
+	}
+	// Only May 31 was accrued in May: 0.1% of 1000.00
+	if got := walletBalance(t, db, wallet.ID); got.Amount != 100100 {
+		t.Errorf("wallet balance = %s, want 1001.00", got)
+	}
+
+	// Running again the same day only redoes yesterday
+	accrued, posted, err = interest.RunDaily(june4)
+	if err != nil || accrued != 1 || posted != 0 {
+		t.Errorf("second RunDaily = %d, %d, %v, want 1 accrued and nothing posted", accrued, posted, err)
+	}
+}
+
+func TestRunDailyCapitalisesMissedMonths(t *testing.T) {
+	db := setupTestDB(t)
+	setTestRate(t)
+	april30 := time.Date(2026, time.April, 30, 0, 0, 0, 0, time.Local)
+	wallet := interestWallet(t, db, "1000.00", april30.AddDate(0, 0, -10))
+	interest := NewInterestService()
+
+	// The job was down from May 1 to June 1, so April was never capitalised
+	if _, err := interest.AccrueDay(april30); err != nil {
+		t.Fatalf("AccrueDay failed: %v", err)
+	}
+	accrued, posted, err := interest.RunDaily(time.Date(2026, time.June, 2, 9, 0, 0, 0, time.Local))
+	if err != nil || accrued != 32 || posted != 2 {
+		t.Fatalf("RunDaily = %d, %d, %v, want 32 accrued and 2 posted", accrued, posted, err)
+	}
+
+	var postings []models.InterestPosting
+	db.Where("wallet_id = ?", wallet.ID).Order("period_start").Find(&postings)
+	if len(postings) != 2 || postings[0].Amount.Amount != 100 || postings[1].Amount.Amount != 3100 {
+		t.Errorf("interest postings = %+v, want 1.00 for April and 31.00 for May", postings)
+	}
+}
+
+func TestCapitaliseMonthContinuesPastFailingWallet(t *testing.T) {
+	db := setupTestDB(t)
+	setTestRate(t)
+	may31 := time.Date(2026, time.May, 31, 0, 0, 0, 0, time.Local)
+	broken := interestWallet(t, db, "1000.00", may31)
+	wallet := interestWallet(t, db, "1000.00", may31)
+	// Wallets come back in ID order; break the first one
+	if wallet.ID.String() < broken.ID.String() {
+		broken, wallet = wallet, broken
+	}
+	interest := NewInterestService()
+	if _, err := interest.AccrueDay(may31); err != nil {
+		t.Fatalf("AccrueDay failed: %v", err)
+	}
+
+	// An earlier posting in the wrong currency makes the broken wallet fail
+	if err := db.Create(&models.InterestPosting{
+		WalletID:       broken.ID,
+		PeriodStart:    monthStart(may31),
+		Kind:           models.InterestPostingCapitalisation,
+		Amount:         mustMoney("1.00", "EUR"),
+		Currency:       "EUR",
+		JournalEntryID: uuid.New(),
+	}).Error; err != nil {
+		t.Fatalf("failed to create interest posting: %v", err)
+	}
+
+	posted, err := interest.CapitaliseMonth(may31)
+	if err == nil {
+		t.Fatal("CapitaliseMonth succeeded despite a failing wallet")
+	}
+	if posted != 1 {
+		t.Errorf("posted %d interest entries, want 1", posted)
+	}
+	if got := walletBalance(t, db, wallet.ID); got.Amount != 100100 {
+		t.Errorf("wallet balance = %s, want 1001.00", got)
+	}
+	if got := walletBalance(t, db, broken.ID); got.Amount != 100000 {
+		t.Errorf("failing wallet balance = %s, want 1000.00", got)
+	}
+}
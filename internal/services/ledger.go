@@ -0,0 +1,368 @@
+package services
+
+import (
+	"fmt"
+
+	"securewallet/internal/config"
+	"securewallet/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// System ledger account kinds
+const (
+	SystemAccountExternalCash     = "external_cash"      // Money entering or leaving the platform
+	SystemAccountFeeRevenue       = "fee_revenue"        // Fees charged to users
+	SystemAccountOpeningBalance   = "opening_balance"    // Balances that existed before the ledger
+	SystemAccountFXPosition       = "fx_position"        // Currency bought and sold by exchanges
+	SystemAccountPayoutsInTransit = "payouts_in_transit" // Withdrawals held until the payout rail settles them
+	SystemAccountInterestExpense  = "interest_expense"   // Interest credited to wallets
+	SystemAccountInterestIncome   = "interest_income"    // Overdraft interest charged to wallets
+)
+
+// LedgerService records balanced journal entries and derives wallet balances from postings
+type LedgerService struct {
+	db *gorm.DB
+}
+
+// LedgerLine describes one side of a journal entry
+type LedgerLine struct {
+	AccountID uuid.UUID
+	Amount    models.Money // Positive increases the account balance, negative decreases it
+	Memo      string       // Shown as the transaction description for wallet accounts
+}
+
+// LedgerEntry describes a journal entry to be posted
+type LedgerEntry struct {
+	Type        string
+	Description string
+	Reference   string
+	InitiatedBy *uuid.UUID // User who made the movement, recorded on its transactions
+	FeeRuleID   *uuid.UUID // Fee rule that priced the movement, recorded on its transactions
+	Lines       []LedgerLine
+}
+
+// WalletDiscrepancy describes a wallet whose stored balance differs from its postings
+type WalletDiscrepancy struct {
+	WalletID      uuid.UUID    `json:"wallet_id"`
+	Currency      string       `json:"currency"`
+	StoredBalance models.Money `json:"stored_balance"`
+	LedgerBalance models.Money `json:"ledger_balance"`
+}
+
+// NewLedgerService creates a new ledger service
+func NewLedgerService() *LedgerService {
+	return &LedgerService{
+		db: config.GetDB(),
+	}
+}
+
+// WalletAccount returns the ledger account of a wallet, opening it on first use.
+// A wallet that already holds a balance gets an OPENING_BALANCE entry so that
+// its postings add up to the stored balance.
+func (ls *LedgerService) WalletAccount(tx *gorm.DB, wallet *models.Wallet) (*models.LedgerAccount, error) {
+	var account models.LedgerAccount
+	err := tx.Where("wallet_id = ?", wallet.ID).First(&account).Error
+	if err == nil {
+		return &account, nil
+	}
+	if err != gorm.ErrRecordNotFound {
+		return nil, fmt.Errorf("failed to load wallet account: %w", err)
+	}
+
+	walletID := wallet.ID
+	account = models.LedgerAccount{
+		Code:     fmt.Sprintf("wallet:%s", wallet.ID),
+		Type:     models.LedgerAccountTypeWallet,
+		WalletID: &walletID,
+		Currency: wallet.Currency,
+		Name:     "Wallet " + wallet.ID.String(),
+	}
+	if err := tx.Create(&account).Error; err != nil {
+		// Another request may have opened the account concurrently
+		if err := tx.Where("wallet_id = ?", wallet.ID).First(&account).Error; err == nil {
+			return &account, nil
+		}
+		return nil, fmt.Errorf("failed to create wallet account: %w", err)
+	}
+
+	// Carry over a balance that was written before the ledger existed
+	var stored models.Wallet
+	if err := tx.First(&stored, "id = ?", wallet.ID).Error; err != nil {
+		return nil, fmt.Errorf("failed to load wallet: %w", err)
+	}
+	if !stored.Balance.IsZero() {
+		equity, err := ls.SystemAccount(tx, SystemAccountOpeningBalance, wallet.Currency)
+		if err != nil {
+			return nil, err
+		}
+		entry := LedgerEntry{
+			Type:        "OPENING_BALANCE",
+			Description: "Opening balance",
+			Lines: []LedgerLine{
+				{AccountID: equity.ID, Amount: stored.Balance.Neg()},
+				{AccountID: account.ID, Amount: stored.Balance, Memo: "Opening balance"},
+			},
+		}
+		if _, err := ls.postEntry(tx, entry, false); err != nil {
+			return nil, err
+		}
+	}
+
+	return &account, nil
+}
+
+// PocketAccount returns the ledger account of a pocket, opening it on first use
+func (ls *LedgerService) PocketAccount(tx *gorm.DB, pocket *models.Pocket) (*models.LedgerAccount, error) {
+	var account models.LedgerAccount
+	err := tx.Where("pocket_id = ?", pocket.ID).First(&account).Error
+	if err == nil {
+		return &account, nil
+	}
+	if err != gorm.ErrRecordNotFound {
+		return nil, fmt.Errorf("failed to load pocket account: %w", err)
+	}
+
+	pocketID := pocket.ID
+	account = models.LedgerAccount{
+		Code:     fmt.Sprintf("pocket:%s", pocket.ID),
+		Type:     models.LedgerAccountTypePocket,
+		PocketID: &pocketID,
+		Currency: pocket.Currency,
+		Name:     "Pocket " + pocket.ID.String(),
+	}
+	if err := tx.Create(&account).Error; err != nil {
+		if err := tx.Where("pocket_id = ?", pocket.ID).First(&account).Error; err == nil {
+			return &account, nil
+		}
+		return nil, fmt.Errorf("failed to create pocket account: %w", err)
+	}
+	return &account, nil
+}
+
+// SystemAccount returns the system account of the given kind and currency, creating it if needed
+func (ls *LedgerService) SystemAccount(tx *gorm.DB, kind, currency string) (*models.LedgerAccount, error) {
+	code := fmt.Sprintf("system:%s:%s", kind, currency)
+
+	var account models.LedgerAccount
+	err := tx.Where(models.LedgerAccount{Code: code}).
+		Attrs(models.LedgerAccount{
+			Type:     models.LedgerAccountTypeSystem,
+			Currency: currency,
+			Name:     fmt.Sprintf("%s (%s)", kind, currency),
+		}).
+		FirstOrCreate(&account).Error
+	if err != nil {
+		// Another request may have created the account concurrently
+		if err := tx.Where("code = ?", code).First(&account).Error; err == nil {
+			return &account, nil
+		}
+		return nil, fmt.Errorf("failed to load system account %s: %w", code, err)
+	}
+
+	return &account, nil
+}
+
+// PostEntry records a balanced journal entry inside the given database transaction.
+// Wallet and pocket balances are updated and a Transaction view is written for every wallet touched.
+func (ls *LedgerService) PostEntry(tx *gorm.DB, entry LedgerEntry) (*models.JournalEntry, error) {
+	return ls.postEntry(tx, entry, true)
+}
+
+// postEntry validates and writes a journal entry, optionally applying it to wallet balances
+func (ls *LedgerService) postEntry(tx *gorm.DB, entry LedgerEntry, applyToWallets bool) (*models.JournalEntry, error) {
+	if len(entry.Lines) < 2 {
+		return nil, fmt.Errorf("journal entry needs at least two lines")
+	}
+
+	// Load every account referenced by the entry
+	accountIDs := make([]uuid.UUID, 0, len(entry.Lines))
+	for _, line := range entry.Lines {
+		accountIDs = append(accountIDs, line.AccountID)
+	}
+	var accounts []models.LedgerAccount
+	if err := tx.Where("id IN ?", accountIDs).Find(&accounts).Error; err != nil {
+		return nil, fmt.Errorf("failed to load ledger accounts: %w", err)
+	}
+	accountsByID := make(map[uuid.UUID]models.LedgerAccount, len(accounts))
+	for _, account := range accounts {
+		accountsByID[account.ID] = account
+	}
+
+	// Every currency in the entry must net to zero
+	totals := make(map[string]models.Money)
+	for _, line := range entry.Lines {
+		account, ok := accountsByID[line.AccountID]
+		if !ok {
+			return nil, fmt.Errorf("ledger account %s not found", line.AccountID)
+		}
+		// Also rejects amounts that were never bound to a currency
+		if line.Amount.Currency != account.Currency {
+			return nil, fmt.Errorf("line in %s posted to %s account %s", line.Amount.Currency, account.Currency, account.Code)
+		}
+		if line.Amount.IsZero() {
+			return nil, fmt.Errorf("journal entry line cannot be zero")
+		}
+		total, seen := totals[account.Currency]
+		if !seen {
+			total = models.ZeroMoney(account.Currency)
+		}
+		// Currencies were checked above, so Add cannot fail
+		totals[account.Currency], _ = total.Add(line.Amount)
+	}
+	for _, total := range totals {
+		if !total.IsZero() {
+			return nil, fmt.Errorf("journal entry is unbalanced by %s", total)
+		}
+	}
+
+	journalEntry := models.JournalEntry{
+		Type:        entry.Type,
+		Description: entry.Description,
+		Reference:   entry.Reference,
+	}
+	if err := tx.Create(&journalEntry).Error; err != nil {
+		return nil, fmt.Errorf("failed to create journal entry: %w", err)
+	}
+
+	// Write postings and collect the net movement of each wallet
+	var walletOrder []uuid.UUID
+	walletNet := make(map[uuid.UUID]models.Money)
+	walletMemo := make(map[uuid.UUID]string)
+
+	for _, line := range entry.Lines {
+		account := accountsByID[line.AccountID]
+		posting := models.Posting{
+			EntryID:   journalEntry.ID,
+			AccountID: account.ID,
+			Amount:    line.Amount,
+			Currency:  account.Currency,
+			Memo:      line.Memo,
+		}
+		if err := tx.Create(&posting).Error; err != nil {
+			return nil, fmt.Errorf("failed to create posting: %w", err)
+		}
+		journalEntry.Postings = append(journalEntry.Postings, posting)
+
+		if account.PocketID != nil && applyToWallets {
+			if err := tx.Model(&models.Pocket{}).
+				Where("id = ?", *account.PocketID).
+				Update("balance", gorm.Expr("balance + CAST(? AS DECIMAL(15,2))", line.Amount)).Error; err != nil {
+				return nil, fmt.Errorf("failed to update pocket balance: %w", err)
+			}
+		}
+		if account.WalletID == nil {
+			continue
+		}
+		walletID := *account.WalletID
+		if _, seen := walletNet[walletID]; !seen {
+			walletOrder = append(walletOrder, walletID)
+			walletNet[walletID] = models.ZeroMoney(account.Currency)
+			walletMemo[walletID] = line.Memo
+		}
+		walletNet[walletID], _ = walletNet[walletID].Add(line.Amount)
+	}
+
+	for _, walletID := range walletOrder {
+		net := walletNet[walletID]
+
+		if applyToWallets {
+			if err := tx.Model(&models.Wallet{}).
+				Where("id = ?", walletID).
+				Update("balance", gorm.Expr("balance + CAST(? AS DECIMAL(15,2))", net)).Error; err != nil {
+				return nil, fmt.Errorf("failed to update wallet balance: %w", err)
+			}
+		}
+
+		direction := "in"
+		if net.IsNegative() {
+			direction = "out"
+		}
+		entryID := journalEntry.ID
+		transaction := models.Transaction{
+			WalletID:       walletID,
+			JournalEntryID: &entryID,
+			Type:           entry.Type,
+			Direction:      direction,
+			Amount:         net.Abs(),
+			Currency:       net.Currency,
+			Description:    walletMemo[walletID],
+			Status:         "completed",
+			InitiatedBy:    entry.InitiatedBy,
+			FeeRuleID:      entry.FeeRuleID,
+		}
+		if err := tx.Create(&transaction).Error; err != nil {
+			return nil, fmt.Errorf("failed to create transaction record: %w", err)
+		}
+		autoCategorize(tx, &transaction)
+		journalEntry.Transactions = append(journalEntry.Transactions, transaction)
+	}
+
+	return &journalEntry, nil
+}
+
+// initiator returns the user to record on an entry's transactions, or nil for system movements
+func initiator(userID uuid.UUID) *uuid.UUID {
+	if userID == uuid.Nil {
+		return nil
+	}
+	return &userID
+}
+
+// TransactionFor returns the Transaction view an entry produced for a wallet
+func (ls *LedgerService) TransactionFor(entry *models.JournalEntry, walletID uuid.UUID) *models.Transaction {
+	for i := range entry.Transactions {
+		if entry.Transactions[i].WalletID == walletID {
+			return &entry.Transactions[i]
+		}
+	}
+	return nil
+}
+
+// AccountBalance derives an account balance from its postings
+func (ls *LedgerService) AccountBalance(tx *gorm.DB, account *models.LedgerAccount) (models.Money, error) {
+	balance := models.ZeroMoney(account.Currency)
+	if err := tx.Model(&models.Posting{}).
+		Where("account_id = ?", account.ID).
+		Select("COALESCE(SUM(amount), 0)").
+		Row().Scan(&balance); err != nil {
+		return models.Money{}, fmt.Errorf("failed to sum postings: %w", err)
+	}
+	return balance, nil
+}
+
+// ReconcileWallets compares every stored wallet balance with the sum of its postings
+func (ls *LedgerService) ReconcileWallets() ([]WalletDiscrepancy, error) {
+	var rows []struct {
+		WalletID      uuid.UUID
+		Currency      string
+		StoredBalance models.Money
+		LedgerBalance models.Money
+	}
+
+	err := ls.db.Table("ledger_accounts AS a").
+		Select("a.wallet_id, a.currency, w.balance AS stored_balance, COALESCE(SUM(p.amount), 0) AS ledger_balance").
+		Joins("JOIN wallets w ON w.id = a.wallet_id").
+		Joins("LEFT JOIN ledger_postings p ON p.account_id = a.id").
+		Where("a.type = ?", models.LedgerAccountTypeWallet).
+		Group("a.wallet_id, a.currency, w.balance").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to reconcile wallets: %w", err)
+	}
+
+	discrepancies := []WalletDiscrepancy{}
+	for _, row := range rows {
+		if row.StoredBalance.Amount != row.LedgerBalance.Amount {
+			discrepancies = append(discrepancies, WalletDiscrepancy{
+				WalletID:      row.WalletID,
+				Currency:      row.Currency,
+				StoredBalance: row.StoredBalance,
+				LedgerBalance: row.LedgerBalance,
+			})
+		}
+	}
+
+	return discrepancies, nil
+}
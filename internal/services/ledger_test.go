@@ -0,0 +1,203 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"securewallet/internal/models"
+
+	"gorm.io/gorm"
+)
+
+func TestPostEntryRejectsInvalidEntries(t *testing.T) {
+	db := setupTestDB(t)
+	ledger := NewLedgerService()
+	wallet := createTestWallet(t, db, "USD")
+	other := createTestWallet(t, db, "USD")
+	euros := createTestWallet(t, db, "EUR")
+
+	var walletAccount, otherAccount, euroAccount *models.LedgerAccount
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if walletAccount, err = ledger.WalletAccount(tx, wallet); err != nil {
+			return err
+		}
+		if otherAccount, err = ledger.WalletAccount(tx, other); err != nil {
+			return err
+		}
+		euroAccount, err = ledger.WalletAccount(tx, euros)
+		return err
+	}); err != nil {
+		t.Fatalf("failed to open accounts: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		lines   []LedgerLine
+		wantErr string
+	}{
+		{
+			name:    "single line",
+			lines:   []LedgerLine{{AccountID: walletAccount.ID, Amount: mustMoney("10.00", "USD")}},
+			wantErr: "at least two lines",
+		},
+		{
+			name: "unbalanced",
+			lines: []LedgerLine{
+				{AccountID: walletAccount.ID, Amount: mustMoney("-10.00", "USD")},
+				{AccountID: otherAccount.ID, Amount: mustMoney("9.99", "USD")},
+			},
+			wantErr: "unbalanced",
+		},
+		{
+			name: "zero line",
+			lines: []LedgerLine{
+				{AccountID: walletAccount.ID, Amount: mustMoney("-10.00", "USD")},
+				{AccountID: otherAccount.ID, Amount: mustMoney("10.00", "USD")},
+				{AccountID: otherAccount.ID, Amount: models.ZeroMoney("USD")},
+			},
+			wantErr: "cannot be zero",
+		},
+		{
+			name: "wrong currency",
+			lines: []LedgerLine{
+				{AccountID: walletAccount.ID, Amount: mustMoney("-10.00", "USD")},
+				{AccountID: euroAccount.ID, Amount: mustMoney("10.00", "USD")},
+			},
+			wantErr: "posted to EUR account",
+		},
+		{
+			name: "unbound amount",
+			lines: []LedgerLine{
+				{AccountID: walletAccount.ID, Amount: models.Money{Amount: -1000}},
+				{AccountID: otherAccount.ID, Amount: models.Money{Amount: 1000}},
+			},
+			wantErr: "posted to USD account",
+		},
+		{
+			// Each currency must net to zero on its own
+			name: "balanced across currencies only",
+			lines: []LedgerLine{
+				{AccountID: walletAccount.ID, Amount: mustMoney("-10.00", "USD")},
+				{AccountID: euroAccount.ID, Amount: mustMoney("10.00", "EUR")},
+			},
+			wantErr: "unbalanced",
+		},
+	}
+
+	for _, tt := range tests {
+		err := db.Transaction(func(tx *gorm.DB) error {
+			_, err := ledger.PostEntry(tx, LedgerEntry{Type: "TEST", Lines: tt.lines})
+			return err
+		})
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%s: PostEntry error = %v, want %q", tt.name, err, tt.wantErr)
+		}
+	}
+
+	var entries int64
+	db.Model(&models.JournalEntry{}).Count(&entries)
+	if entries != 0 {
+		t.Errorf("rejected entries left %d journal entries behind", entries)
+	}
+	if balance := walletBalance(t, db, wallet.ID); !balance.IsZero() {
+		t.Errorf("rejected entries changed the wallet balance to %s", balance)
+	}
+}
+
+func TestPostEntryUpdatesWalletBalances(t *testing.T) {
+	db := setupTestDB(t)
+	ledger := NewLedgerService()
+	sender := createTestWallet(t, db, "USD")
+	recipient := createTestWallet(t, db, "USD")
+	deposit(t, sender, "100.00")
+
+	var entry *models.JournalEntry
+	err := db.Transaction(func(tx *gorm.DB) error {
+		senderAccount, err := ledger.WalletAccount(tx, sender)
+		if err != nil {
+			return err
+		}
+		recipientAccount, err := ledger.WalletAccount(tx, recipient)
+		if err != nil {
+			return err
+		}
+		feeAccount, err := ledger.SystemAccount(tx, SystemAccountFeeRevenue, "USD")
+		if err != nil {
+			return err
+		}
+		entry, err = ledger.PostEntry(tx, LedgerEntry{
+			Type: "TRANSFER",
+			Lines: []LedgerLine{
+				{AccountID: senderAccount.ID, Amount: mustMoney("-25.00", "USD"), Memo: "Rent"},
+				{AccountID: recipientAccount.ID, Amount: mustMoney("25.00", "USD"), Memo: "Rent"},
+				{AccountID: senderAccount.ID, Amount: mustMoney("-1.00", "USD"), Memo: "Fee"},
+				{AccountID: feeAccount.ID, Amount: mustMoney("1.00", "USD"), Memo: "Fee"},
+			},
+		})
+		return err
+	})
+	if err != nil {
+		t.Fatalf("PostEntry failed: %v", err)
+	}
+
+	if got := walletBalance(t, db, sender.ID); got.Amount != 7400 {
+		t.Errorf("sender balance = %s, want 74.00", got)
+	}
+	if got := walletBalance(t, db, recipient.ID); got.Amount != 2500 {
+		t.Errorf("recipient balance = %s, want 25.00", got)
+	}
+
+	// One transaction per wallet, netting the lines of the entry
+	senderTransaction := ledger.TransactionFor(entry, sender.ID)
+	if senderTransaction == nil || senderTransaction.Direction != "out" || senderTransaction.Amount.Amount != 2600 {
+		t.Errorf("sender transaction = %+v, want 26.00 out", senderTransaction)
+	}
+	recipientTransaction := ledger.TransactionFor(entry, recipient.ID)
+	if recipientTransaction == nil || recipientTransaction.Direction != "in" || recipientTransaction.Amount.Amount != 2500 {
+		t.Errorf("recipient transaction = %+v, want 25.00 in", recipientTransaction)
+	}
+
+	if total := postingTotal(t, db, "USD"); !total.IsZero() {
+		t.Errorf("postings sum to %s, want zero", total)
+	}
+	discrepancies, err := ledger.ReconcileWallets()
+	if err != nil {
+		t.Fatalf("ReconcileWallets failed: %v", err)
+	}
+	if len(discrepancies) != 0 {
+		t.Errorf("wallets out of balance with the ledger: %+v", discrepancies)
+	}
+}
+
+func TestWalletAccountCarriesOpeningBalance(t *testing.T) {
+	db := setupTestDB(t)
+	ledger := NewLedgerService()
+	wallet := createTestWallet(t, db, "USD")
+
+	// A balance written before the wallet had a ledger account
+	if err := db.Model(wallet).Update("balance", mustMoney("42.50", "USD")).Error; err != nil {
+		t.Fatalf("failed to set balance: %v", err)
+	}
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		_, err := ledger.WalletAccount(tx, wallet)
+		return err
+	}); err != nil {
+		t.Fatalf("WalletAccount failed: %v", err)
+	}
+
+	// The opening entry matches the stored balance without changing it
+	if got := walletBalance(t, db, wallet.ID); got.Amount != 4250 {
+		t.Errorf("balance = %s, want 42.50", got)
+	}
+	discrepancies, err := ledger.ReconcileWallets()
+	if err != nil {
+		t.Fatalf("ReconcileWallets failed: %v", err)
+	}
+	if len(discrepancies) != 0 {
+		t.Errorf("wallets out of balance with the ledger: %+v", discrepancies)
+	}
+	if total := postingTotal(t, db, "USD"); !total.IsZero() {
+		t.Errorf("postings sum to %s, want zero", total)
+	}
+}
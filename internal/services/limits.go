@@ -0,0 +1,434 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"securewallet/internal/config"
+	"securewallet/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Default limits used when no global limit is configured for a currency
+const (
+	DEFAULT_DAILY_TRANSFER_LIMIT   = "10000.00"
+	DEFAULT_MONTHLY_TRANSFER_LIMIT = "50000.00"
+	DEFAULT_DAILY_TRANSFER_COUNT   = 50
+	DEFAULT_MONTHLY_TRANSFER_COUNT = 500
+)
+
+// Rolling windows the amount and count caps apply to
+const (
+	limitDailyWindow   = 24 * time.Hour
+	limitMonthlyWindow = 30 * 24 * time.Hour
+)
+
+// Limit names reported when a transfer is rejected
+const (
+	LimitMinPerTransaction = "min_per_transaction"
+	LimitMaxPerTransaction = "max_per_transaction"
+	LimitDailyAmount       = "daily_amount"
+	LimitMonthlyAmount     = "monthly_amount"
+	LimitDailyCount        = "daily_count"
+	LimitMonthlyCount      = "monthly_count"
+)
+
+// Limit errors
+var (
+	ErrLimitNotFound = errors.New("transfer limit not found")
+	ErrInvalidLimit  = errors.New("invalid transfer limit")
+	ErrUserNotFound  = errors.New("user not found")
+)
+
+// LimitExceededError reports the limit a transfer would break
+type LimitExceededError struct {
+	Limit   string
+	Allowed string // The configured cap, or what is left of it
+}
+
+func (e *LimitExceededError) Error() string {
+	switch e.Limit {
+	case LimitMinPerTransaction:
+		return fmt.Sprintf("transfer amount is below the minimum of %s", e.Allowed)
+	case LimitMaxPerTransaction:
+		return fmt.Sprintf("transfer amount is above the maximum of %s", e.Allowed)
+	case LimitDailyCount, LimitMonthlyCount:
+		return fmt.Sprintf("%s limit of %s transfers reached", strings.TrimSuffix(e.Limit, "_count"), e.Allowed)
+	}
+	return fmt.Sprintf("%s limit exceeded, %s remaining", strings.TrimSuffix(e.Limit, "_amount"), e.Allowed)
+}
+
+// EffectiveLimits are the limits that apply to a user in one currency
+type EffectiveLimits struct {
+	Currency          string       `json:"currency"`
+	MinPerTransaction models.Money `json:"min_per_transaction"`
+	MaxPerTransaction models.Money `json:"max_per_transaction"`
+	DailyAmount       models.Money `json:"daily_amount"`
+	MonthlyAmount     models.Money `json:"monthly_amount"`
+	DailyCount        int          `json:"daily_count"`
+	MonthlyCount      int          `json:"monthly_count"`
+}
+
+// LimitUsageSummary shows a user's limits together with what has been used
+type LimitUsageSummary struct {
+	EffectiveLimits
+	DailyUsed          models.Money `json:"daily_used"`
+	MonthlyUsed        models.Money `json:"monthly_used"`
+	DailyTransfers     int          `json:"daily_transfers"`
+	MonthlyTransfers   int          `json:"monthly_transfers"`
+	DailyRemaining     models.Money `json:"daily_remaining"`
+	MonthlyRemaining   models.Money `json:"monthly_remaining"`
+	TransfersRemaining int          `json:"transfers_remaining"`
+}
+
+// TransferLimitInput describes a limit row an admin creates or updates
+type TransferLimitInput struct {
+	Scope             string
+	Subject           string // Tier name or user ID, empty for global
+	Currency          string
+	MinPerTransaction *models.Money
+	MaxPerTransaction *models.Money
+	DailyAmount       *models.Money
+	MonthlyAmount     *models.Money
+	DailyCount        *int
+	MonthlyCount      *int
+	UpdatedBy         uuid.UUID
+}
+
+// LimitService resolves and enforces transfer limits
+type LimitService struct {
+	db *gorm.DB
+}
+
+// NewLimitService creates a new limit service
+func NewLimitService() *LimitService {
+	return &LimitService{
+		db: config.GetDB(),
+	}
+}
+
+// defaultLimits returns the built-in limits for a currency
+func defaultLimits(currency string) EffectiveLimits {
+	return EffectiveLimits{
+		Currency:          currency,
+		MinPerTransaction: models.MustParseMoney(MIN_TRANSFER_AMOUNT, currency),
+		MaxPerTransaction: models.MustParseMoney(MAX_TRANSFER_AMOUNT, currency),
+		DailyAmount:       models.MustParseMoney(DEFAULT_DAILY_TRANSFER_LIMIT, currency),
+		MonthlyAmount:     models.MustParseMoney(DEFAULT_MONTHLY_TRANSFER_LIMIT, currency),
+		DailyCount:        DEFAULT_DAILY_TRANSFER_COUNT,
+		MonthlyCount:      DEFAULT_MONTHLY_TRANSFER_COUNT,
+	}
+}
+
+// GlobalLimits returns the limits that apply to everyone in a currency
+func (ls *LimitService) GlobalLimits(currency string) (*EffectiveLimits, error) {
+	return ls.resolve(ls.db, "", uuid.Nil, currency)
+}
+
+// EffectiveLimits returns the limits that apply to a user in a currency
+func (ls *LimitService) EffectiveLimits(tx *gorm.DB, userID uuid.UUID, currency string) (*EffectiveLimits, error) {
+	var user models.User
+	if err := tx.Select("id", "tier").First(&user, "id = ?", userID).Error; err != nil {
+		return nil, fmt.Errorf("failed to load user: %w", err)
+	}
+	return ls.resolve(tx, user.Tier, user.ID, currency)
+}
+
+// resolve layers the global, tier and user limits over the defaults
+func (ls *LimitService) resolve(tx *gorm.DB, tier string, userID uuid.UUID, currency string) (*EffectiveLimits, error) {
+	query := tx.Where("currency = ?", currency).
+		Where(ls.db.Where("scope = ?", models.LimitScopeGlobal).
+			Or("scope = ? AND subject = ?", models.LimitScopeTier, tier).
+			Or("scope = ? AND subject = ?", models.LimitScopeUser, userID.String()))
+
+	var rows []models.TransferLimit
+	if err := query.Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to load transfer limits: %w", err)
+	}
+
+	limits := defaultLimits(currency)
+	for _, scope := range []string{models.LimitScopeGlobal, models.LimitScopeTier, models.LimitScopeUser} {
+		for _, row := range rows {
+			if row.Scope != scope {
+				continue
+			}
+			if row.MinPerTransaction != nil {
+				limits.MinPerTransaction = *row.MinPerTransaction
+			}
+			if row.MaxPerTransaction != nil {
+				limits.MaxPerTransaction = *row.MaxPerTransaction
+			}
+			if row.DailyAmount != nil {
+				limits.DailyAmount = *row.DailyAmount
+			}
+			if row.MonthlyAmount != nil {
+				limits.MonthlyAmount = *row.MonthlyAmount
+			}
+			if row.DailyCount != nil {
+				limits.DailyCount = *row.DailyCount
+			}
+			if row.MonthlyCount != nil {
+				limits.MonthlyCount = *row.MonthlyCount
+			}
+		}
+	}
+
+	return &limits, nil
+}
+
+// usage sums a user's counted movements since a point in time
+func (ls *LimitService) usage(tx *gorm.DB, userID uuid.UUID, currency string, since time.Time) (models.Money, int, error) {
+	total := models.ZeroMoney(currency)
+	var count int
+	err := tx.Model(&models.LimitUsage{}).
+		Where("user_id = ? AND currency = ? AND created_at >= ?", userID, currency, since).
+		Select("COALESCE(SUM(amount), 0), COUNT(*)").
+		Row().Scan(&total, &count)
+	if err != nil {
+		return models.Money{}, 0, fmt.Errorf("failed to sum limit usage: %w", err)
+	}
+	return total, count, nil
+}
+
+// Check verifies that an outgoing amount fits the user's limits. It locks the
+// user row so that concurrent transfers from any of the user's wallets are
+// checked one after another; call it inside the transfer transaction after
+// the wallets have been locked.
+func (ls *LimitService) Check(tx *gorm.DB, userID uuid.UUID, amount models.Money) error {
+	var user models.User
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id", "tier").
+		First(&user, "id = ?", userID).Error; err != nil {
+		return fmt.Errorf("failed to lock user: %w", err)
+	}
+
+	limits, err := ls.resolve(tx, user.Tier, user.ID, amount.Currency)
+	if err != nil {
+		return err
+	}
+
+	if amount.Amount < limits.MinPerTransaction.Amount {
+		return &LimitExceededError{Limit: LimitMinPerTransaction, Allowed: limits.MinPerTransaction.String()}
+	}
+	if amount.Amount > limits.MaxPerTransaction.Amount {
+		return &LimitExceededError{Limit: LimitMaxPerTransaction, Allowed: limits.MaxPerTransaction.String()}
+	}
+
+	now := time.Now()
+	dailyUsed, dailyCount, err := ls.usage(tx, userID, amount.Currency, now.Add(-limitDailyWindow))
+	if err != nil {
+		return err
+	}
+	monthlyUsed, monthlyCount, err := ls.usage(tx, userID, amount.Currency, now.Add(-limitMonthlyWindow))
+	if err != nil {
+		return err
+	}
+
+	if dailyCount >= limits.DailyCount {
+		return &LimitExceededError{Limit: LimitDailyCount, Allowed: fmt.Sprint(limits.DailyCount)}
+	}
+	if monthlyCount >= limits.MonthlyCount {
+		return &LimitExceededError{Limit: LimitMonthlyCount, Allowed: fmt.Sprint(limits.MonthlyCount)}
+	}
+	if dailyUsed.Amount+amount.Amount > limits.DailyAmount.Amount {
+		remaining, _ := limits.DailyAmount.Sub(dailyUsed)
+		return &LimitExceededError{Limit: LimitDailyAmount, Allowed: remaining.Max(models.ZeroMoney(amount.Currency)).String()}
+	}
+	if monthlyUsed.Amount+amount.Amount > limits.MonthlyAmount.Amount {
+		remaining, _ := limits.MonthlyAmount.Sub(monthlyUsed)
+		return &LimitExceededError{Limit: LimitMonthlyAmount, Allowed: remaining.Max(models.ZeroMoney(amount.Currency)).String()}
+	}
+
+	return nil
+}
+
+// Record counts an outgoing amount towards the user's limits
+func (ls *LimitService) Record(tx *gorm.DB, userID uuid.UUID, amount models.Money, entryID uuid.UUID) error {
+	usage := models.LimitUsage{
+		UserID:   userID,
+		Currency: amount.Currency,
+		Amount:   amount,
+		EntryID:  entryID,
+	}
+	if err := tx.Create(&usage).Error; err != nil {
+		return fmt.Errorf("failed to record limit usage: %w", err)
+	}
+	return nil
+}
+
+// Summary returns a user's limits and usage in a currency
+func (ls *LimitService) Summary(userID uuid.UUID, currency string) (*LimitUsageSummary, error) {
+	limits, err := ls.EffectiveLimits(ls.db, userID, currency)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	dailyUsed, dailyCount, err := ls.usage(ls.db, userID, currency, now.Add(-limitDailyWindow))
+	if err != nil {
+		return nil, err
+	}
+	monthlyUsed, monthlyCount, err := ls.usage(ls.db, userID, currency, now.Add(-limitMonthlyWindow))
+	if err != nil {
+		return nil, err
+	}
+
+	zero := models.ZeroMoney(currency)
+	dailyRemaining, _ := limits.DailyAmount.Sub(dailyUsed)
+	monthlyRemaining, _ := limits.MonthlyAmount.Sub(monthlyUsed)
+	transfersRemaining := limits.DailyCount - dailyCount
+	if monthlyLeft := limits.MonthlyCount - monthlyCount; monthlyLeft < transfersRemaining {
+		transfersRemaining = monthlyLeft
+	}
+	if transfersRemaining < 0 {
+		transfersRemaining = 0
+	}
+
+	return &LimitUsageSummary{
+		EffectiveLimits:    *limits,
+		DailyUsed:          dailyUsed,
+		MonthlyUsed:        monthlyUsed,
+		DailyTransfers:     dailyCount,
+		MonthlyTransfers:   monthlyCount,
+		DailyRemaining:     dailyRemaining.Max(zero),
+		MonthlyRemaining:   monthlyRemaining.Max(zero),
+		TransfersRemaining: transfersRemaining,
+	}, nil
+}
+
+// ListLimits returns all configured limit rows
+func (ls *LimitService) ListLimits() ([]models.TransferLimit, error) {
+	var limits []models.TransferLimit
+	if err := ls.db.Order("currency, scope, subject").Find(&limits).Error; err != nil {
+		return nil, err
+	}
+	return limits, nil
+}
+
+// SetLimit creates or replaces the limit row of a scope, subject and currency
+func (ls *LimitService) SetLimit(input TransferLimitInput) (*models.TransferLimit, error) {
+	currency := strings.ToUpper(input.Currency)
+	if !models.IsSupportedCurrency(currency) {
+		return nil, ErrUnsupportedCurrency
+	}
+
+	subject := strings.TrimSpace(input.Subject)
+	switch input.Scope {
+	case models.LimitScopeGlobal:
+		subject = ""
+	case models.LimitScopeTier:
+		if !isValidTier(subject) {
+			return nil, fmt.Errorf("%w: unknown tier %q", ErrInvalidLimit, subject)
+		}
+	case models.LimitScopeUser:
+		userID, err := uuid.Parse(subject)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid user ID", ErrInvalidLimit)
+		}
+		subject = userID.String()
+	default:
+		return nil, fmt.Errorf("%w: unknown scope %q", ErrInvalidLimit, input.Scope)
+	}
+
+	limit := models.TransferLimit{
+		Scope:        input.Scope,
+		Subject:      subject,
+		Currency:     currency,
+		DailyCount:   input.DailyCount,
+		MonthlyCount: input.MonthlyCount,
+		UpdatedBy:    &input.UpdatedBy,
+	}
+	var err error
+	if limit.MinPerTransaction, err = bindLimitAmount(input.MinPerTransaction, currency); err != nil {
+		return nil, err
+	}
+	if limit.MaxPerTransaction, err = bindLimitAmount(input.MaxPerTransaction, currency); err != nil {
+		return nil, err
+	}
+	if limit.DailyAmount, err = bindLimitAmount(input.DailyAmount, currency); err != nil {
+		return nil, err
+	}
+	if limit.MonthlyAmount, err = bindLimitAmount(input.MonthlyAmount, currency); err != nil {
+		return nil, err
+	}
+	for _, count := range []*int{input.DailyCount, input.MonthlyCount} {
+		if count != nil && *count < 0 {
+			return nil, fmt.Errorf("%w: counts must be non-negative", ErrInvalidLimit)
+		}
+	}
+
+	err = ls.db.Transaction(func(tx *gorm.DB) error {
+		var existing models.TransferLimit
+		err := tx.Where("scope = ? AND subject = ? AND currency = ?", limit.Scope, limit.Subject, limit.Currency).
+			First(&existing).Error
+		if err == nil {
+			limit.ID = existing.ID
+			limit.CreatedAt = existing.CreatedAt
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		return tx.Save(&limit).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &limit, nil
+}
+
+// DeleteLimit removes a limit row so that the less specific scope applies again
+func (ls *LimitService) DeleteLimit(limitID string) error {
+	id, err := uuid.Parse(limitID)
+	if err != nil {
+		return ErrLimitNotFound
+	}
+	result := ls.db.Delete(&models.TransferLimit{}, "id = ?", id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrLimitNotFound
+	}
+	return nil
+}
+
+// SetUserTier assigns a user to a limit tier
+func (ls *LimitService) SetUserTier(userID uuid.UUID, tier string) error {
+	if !isValidTier(tier) {
+		return fmt.Errorf("%w: unknown tier %q", ErrInvalidLimit, tier)
+	}
+	result := ls.db.Model(&models.User{}).Where("id = ?", userID).Update("tier", tier)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+// isValidTier reports whether tier is a known user tier
+func isValidTier(tier string) bool {
+	switch tier {
+	case models.UserTierStandard, models.UserTierVerified, models.UserTierPremium:
+		return true
+	}
+	return false
+}
+
+// bindLimitAmount binds an optional limit amount to the limit currency
+func bindLimitAmount(amount *models.Money, currency string) (*models.Money, error) {
+	if amount == nil {
+		return nil, nil
+	}
+	bound, err := amount.In(currency)
+	if err != nil || bound.IsNegative() {
+		return nil, fmt.Errorf("%w: amounts must be non-negative %s values", ErrInvalidLimit, currency)
+	}
+	return &bound, nil
+}
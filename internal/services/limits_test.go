@@ -0,0 +1,73 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"securewallet/internal/models"
+)
+
+func TestTransferLimits(t *testing.T) {
+	db := setupTestDB(t)
+	sender := createTestWallet(t, db, "USD")
+	recipient := createTestWallet(t, db, "USD")
+	deposit(t, sender, "500.00")
+
+	// A user override wins over the global limit
+	globalMax := mustMoney("1000.00", "USD")
+	userMax, userDaily := mustMoney("20.00", "USD"), mustMoney("30.00", "USD")
+	dailyCount := 3
+	for _, limit := range []models.TransferLimit{
+		{Scope: models.LimitScopeGlobal, Currency: "USD", MaxPerTransaction: &globalMax},
+		{Scope: models.LimitScopeUser, Subject: sender.UserID.String(), Currency: "USD",
+			MaxPerTransaction: &userMax, DailyAmount: &userDaily, DailyCount: &dailyCount},
+	} {
+		limit := limit
+		if err := db.Create(&limit).Error; err != nil {
+			t.Fatalf("failed to create limit: %v", err)
+		}
+	}
+
+	steps := []struct {
+		amount  string
+		limit   string // Expected limit hit, empty when the transfer goes through
+		allowed string
+	}{
+		{"0.50", LimitMinPerTransaction, "1.00 USD"},
+		{"25.00", LimitMaxPerTransaction, "20.00 USD"},
+		{"10.00", "", ""},
+		{"15.00", "", ""},
+		{"10.00", LimitDailyAmount, "5.00 USD"},
+		{"5.00", "", ""},
+		{"1.00", LimitDailyCount, "3"},
+	}
+	for _, step := range steps {
+		_, err := NewTransferService().Transfer(TransferInput{
+			SenderWalletID:    sender.ID,
+			RecipientWalletID: recipient.ID,
+			Amount:            mustMoney(step.amount, "USD"),
+			InitiatedBy:       sender.UserID,
+		})
+		var limitErr *LimitExceededError
+		switch {
+		case step.limit == "" && err != nil:
+			t.Errorf("transfer of %s failed: %v", step.amount, err)
+		case step.limit == "":
+		case !errors.As(err, &limitErr):
+			t.Errorf("transfer of %s: error %v, want %s limit", step.amount, err, step.limit)
+		case limitErr.Limit != step.limit || limitErr.Allowed != step.allowed:
+			t.Errorf("transfer of %s: %s limit allowing %s, want %s allowing %s",
+				step.amount, limitErr.Limit, limitErr.Allowed, step.limit, step.allowed)
+		}
+	}
+
+	// Rejected transfers are not counted
+	summary, err := NewLimitService().Summary(sender.UserID, "USD")
+	if err != nil {
+		t.Fatalf("Summary failed: %v", err)
+	}
+	if summary.DailyUsed.Amount != 3000 || summary.DailyTransfers != 3 || !summary.DailyRemaining.IsZero() {
+		t.Errorf("summary used %s in %d transfers with %s left, want 30.00 in 3 with nothing left",
+			summary.DailyUsed, summary.DailyTransfers, summary.DailyRemaining)
+	}
+}
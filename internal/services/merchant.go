@@ -0,0 +1,298 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"securewallet/internal/config"
+	"securewallet/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Merchant limits
+const (
+	maxMerchantsPerUser    = 5
+	maxAPIKeysPerMerchant  = 10
+	merchantAPIKeyPrefix   = "sk_"
+	webhookSecretPrefix    = "whsec_"
+	merchantAPIKeyShownLen = 11 // Characters of a key kept as its prefix
+)
+
+// Merchant errors
+var (
+	ErrMerchantNotFound  = errors.New("merchant not found")
+	ErrInvalidMerchant   = errors.New("merchant name is required and must be at most 100 characters")
+	ErrInvalidWebhookURL = errors.New("webhook URL must be an absolute https URL")
+	ErrTooManyMerchants  = errors.New("a user can have at most 5 merchant profiles")
+	ErrTooManyAPIKeys    = errors.New("a merchant can have at most 10 active API keys")
+	ErrAPIKeyNotFound    = errors.New("API key not found")
+	ErrInvalidAPIKey     = errors.New("invalid API key")
+	ErrMerchantSuspended = errors.New("merchant is suspended")
+)
+
+// MerchantInput describes a merchant profile to create or update
+type MerchantInput struct {
+	Name       string
+	Website    string
+	WalletID   string // Receives checkout payments; defaults to the user's default wallet
+	WebhookURL string // Optional; completed checkouts are posted here
+}
+
+// MerchantService manages merchant profiles and their API keys
+type MerchantService struct {
+	db      *gorm.DB
+	wallets *WalletService
+}
+
+// NewMerchantService creates a new merchant service
+func NewMerchantService() *MerchantService {
+	return &MerchantService{
+		db:      config.GetDB(),
+		wallets: NewWalletService(),
+	}
+}
+
+// Create sets up a merchant profile receiving payments into one of the user's
+// wallets. The webhook signing secret is returned once.
+func (ms *MerchantService) Create(userID uuid.UUID, input MerchantInput) (*models.Merchant, string, error) {
+	merchant := models.Merchant{UserID: userID, Status: models.MerchantStatusActive}
+	if err := ms.apply(userID, &merchant, input); err != nil {
+		return nil, "", err
+	}
+
+	var count int64
+	if err := ms.db.Model(&models.Merchant{}).Where("user_id = ?", userID).Count(&count).Error; err != nil {
+		return nil, "", err
+	}
+	if count >= maxMerchantsPerUser {
+		return nil, "", ErrTooManyMerchants
+	}
+
+	secret, err := randomSecret(webhookSecretPrefix)
+	if err != nil {
+		return nil, "", err
+	}
+	merchant.WebhookSecret = secret
+	if err := ms.db.Create(&merchant).Error; err != nil {
+		return nil, "", fmt.Errorf("failed to create merchant: %w", err)
+	}
+	return &merchant, secret, nil
+}
+
+// List returns the user's merchant profiles
+func (ms *MerchantService) List(userID uuid.UUID) ([]models.Merchant, error) {
+	merchants := []models.Merchant{}
+	if err := ms.db.Where("user_id = ?", userID).Order("created_at ASC").Find(&merchants).Error; err != nil {
+		return nil, err
+	}
+	return merchants, nil
+}
+
+// Get returns one of the user's merchant profiles
+func (ms *MerchantService) Get(userID uuid.UUID, merchantID string) (*models.Merchant, error) {
+	id, err := uuid.Parse(merchantID)
+	if err != nil {
+		return nil, ErrMerchantNotFound
+	}
+
+	var merchant models.Merchant
+	if err := ms.db.First(&merchant, "id = ? AND user_id = ?", id, userID).Error; err != nil {
+		return nil, ErrMerchantNotFound
+	}
+	return &merchant, nil
+}
+
+// Update changes a merchant's name, website, wallet or webhook URL
+func (ms *MerchantService) Update(userID uuid.UUID, merchantID string, input MerchantInput) (*models.Merchant, error) {
+	merchant, err := ms.Get(userID, merchantID)
+	if err != nil {
+		return nil, err
+	}
+	if err := ms.apply(userID, merchant, input); err != nil {
+		return nil, err
+	}
+
+	if err := ms.db.Model(merchant).Updates(map[string]interface{}{
+		"name":        merchant.Name,
+		"website":     merchant.Website,
+		"wallet_id":   merchant.WalletID,
+		"webhook_url": merchant.WebhookURL,
+	}).Error; err != nil {
+		return nil, err
+	}
+	return merchant, nil
+}
+
+// RotateWebhookSecret replaces the webhook signing secret and returns the new one
+func (ms *MerchantService) RotateWebhookSecret(userID uuid.UUID, merchantID string) (*models.Merchant, string, error) {
+	merchant, err := ms.Get(userID, merchantID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	secret, err := randomSecret(webhookSecretPrefix)
+	if err != nil {
+		return nil, "", err
+	}
+	if err := ms.db.Model(merchant).Update("webhook_secret", secret).Error; err != nil {
+		return nil, "", err
+	}
+	return merchant, secret, nil
+}
+
+// CreateAPIKey issues a new API key for the checkout API. The key is returned
+// once; only its hash is stored.
+func (ms *MerchantService) CreateAPIKey(userID uuid.UUID, merchantID, name string) (*models.MerchantAPIKey, string, error) {
+	merchant, err := ms.Get(userID, merchantID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var active int64
+	if err := ms.db.Model(&models.MerchantAPIKey{}).
+		Where("merchant_id = ? AND revoked_at IS NULL", merchant.ID).
+		Count(&active).Error; err != nil {
+		return nil, "", err
+	}
+	if active >= maxAPIKeysPerMerchant {
+		return nil, "", ErrTooManyAPIKeys
+	}
+
+	key, err := randomSecret(merchantAPIKeyPrefix)
+	if err != nil {
+		return nil, "", err
+	}
+	apiKey := models.MerchantAPIKey{
+		MerchantID: merchant.ID,
+		Name:       strings.TrimSpace(name),
+		Prefix:     key[:merchantAPIKeyShownLen],
+		KeyHash:    hashAPIKey(key),
+	}
+	if err := ms.db.Create(&apiKey).Error; err != nil {
+		return nil, "", fmt.Errorf("failed to create API key: %w", err)
+	}
+	return &apiKey, key, nil
+}
+
+// ListAPIKeys returns a merchant's API keys, including revoked ones
+func (ms *MerchantService) ListAPIKeys(userID uuid.UUID, merchantID string) ([]models.MerchantAPIKey, error) {
+	merchant, err := ms.Get(userID, merchantID)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := []models.MerchantAPIKey{}
+	if err := ms.db.Where("merchant_id = ?", merchant.ID).Order("created_at DESC").Find(&keys).Error; err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// RevokeAPIKey stops an API key from working
+func (ms *MerchantService) RevokeAPIKey(userID uuid.UUID, merchantID, keyID string) error {
+	merchant, err := ms.Get(userID, merchantID)
+	if err != nil {
+		return err
+	}
+	id, err := uuid.Parse(keyID)
+	if err != nil {
+		return ErrAPIKeyNotFound
+	}
+
+	result := ms.db.Model(&models.MerchantAPIKey{}).
+		Where("id = ? AND merchant_id = ? AND revoked_at IS NULL", id, merchant.ID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+// ListWebhookDeliveries returns a merchant's most recent webhook deliveries
+func (ms *MerchantService) ListWebhookDeliveries(userID uuid.UUID, merchantID string, limit int) ([]models.WebhookDelivery, error) {
+	merchant, err := ms.Get(userID, merchantID)
+	if err != nil {
+		return nil, err
+	}
+
+	deliveries := []models.WebhookDelivery{}
+	if err := ms.db.Where("merchant_id = ?", merchant.ID).Order("created_at DESC").Limit(limit).Find(&deliveries).Error; err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// Authenticate returns the active merchant an API key belongs to
+func (ms *MerchantService) Authenticate(key string) (*models.Merchant, error) {
+	if !strings.HasPrefix(key, merchantAPIKeyPrefix) {
+		return nil, ErrInvalidAPIKey
+	}
+
+	var apiKey models.MerchantAPIKey
+	if err := ms.db.Where("key_hash = ? AND revoked_at IS NULL", hashAPIKey(key)).First(&apiKey).Error; err != nil {
+		return nil, ErrInvalidAPIKey
+	}
+	var merchant models.Merchant
+	if err := ms.db.First(&merchant, "id = ?", apiKey.MerchantID).Error; err != nil {
+		return nil, ErrInvalidAPIKey
+	}
+	if merchant.Status != models.MerchantStatusActive {
+		return nil, ErrMerchantSuspended
+	}
+
+	ms.db.Model(&apiKey).Update("last_used_at", time.Now())
+	return &merchant, nil
+}
+
+// apply validates the input and copies it onto the merchant
+func (ms *MerchantService) apply(userID uuid.UUID, merchant *models.Merchant, input MerchantInput) error {
+	name := strings.TrimSpace(input.Name)
+	if name == "" || len(name) > 100 {
+		return ErrInvalidMerchant
+	}
+	webhookURL := strings.TrimSpace(input.WebhookURL)
+	if webhookURL != "" {
+		parsed, err := url.Parse(webhookURL)
+		if err != nil || parsed.Scheme != "https" || parsed.Host == "" {
+			return ErrInvalidWebhookURL
+		}
+	}
+
+	wallet, err := ms.wallets.MemberWallet(userID, input.WalletID, models.WalletRoleOwner)
+	if err != nil {
+		return err
+	}
+
+	merchant.Name = name
+	merchant.Website = strings.TrimSpace(input.Website)
+	merchant.WalletID = wallet.ID
+	merchant.WebhookURL = webhookURL
+	return nil
+}
+
+// randomSecret returns the prefix followed by 32 random bytes in hex
+func randomSecret(prefix string) (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate secret: %w", err)
+	}
+	return prefix + hex.EncodeToString(buf), nil
+}
+
+// hashAPIKey returns the SHA-256 of a key as stored in the database. Keys are
+// long random strings, so a fast unsalted hash is enough.
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
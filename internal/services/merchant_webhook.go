@@ -0,0 +1,186 @@
+package services
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"securewallet/internal/config"
+	"securewallet/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Webhook delivery settings
+const (
+	WebhookSignatureHeader = "X-SecureWallet-Signature"
+	maxWebhookAttempts     = 8
+	webhookTimeout         = 10 * time.Second
+)
+
+// Checkout webhook events
+const (
+	WebhookEventCheckoutAuthorized = "checkout.session.authorized"
+	WebhookEventCheckoutCompleted  = "checkout.session.completed"
+	WebhookEventCheckoutRefunded   = "checkout.session.refunded"
+)
+
+// WebhookEvent is the JSON body posted to a merchant's webhook URL
+type WebhookEvent struct {
+	ID        uuid.UUID   `json:"id"`
+	Type      string      `json:"type"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+// WebhookService delivers queued merchant webhooks, retrying with backoff
+type WebhookService struct {
+	db     *gorm.DB
+	client *http.Client
+}
+
+// NewWebhookService creates a new webhook service
+func NewWebhookService() *WebhookService {
+	return &WebhookService{
+		db:     config.GetDB(),
+		client: &http.Client{Timeout: webhookTimeout},
+	}
+}
+
+// DeliverDue posts the pending deliveries whose next attempt is due and returns
+// how many were delivered. Failed attempts are retried after 1, 4, 9, ...
+// minutes until maxWebhookAttempts is reached.
+func (wh *WebhookService) DeliverDue(now time.Time) (int, error) {
+	var deliveries []models.WebhookDelivery
+	if err := wh.db.Where("status = ? AND next_attempt_at <= ?", models.WebhookStatusPending, now).
+		Order("next_attempt_at ASC").
+		Limit(100).
+		Find(&deliveries).Error; err != nil {
+		return 0, err
+	}
+
+	delivered := 0
+	for i := range deliveries {
+		delivery := &deliveries[i]
+
+		// Claim the delivery by moving its next attempt past the request timeout,
+		// so that an overlapping run does not post it twice
+		claim := wh.db.Model(&models.WebhookDelivery{}).
+			Where("id = ? AND status = ? AND next_attempt_at = ?", delivery.ID, models.WebhookStatusPending, delivery.NextAttemptAt).
+			Update("next_attempt_at", now.Add(2*webhookTimeout))
+		if claim.Error != nil {
+			log.Printf("Failed to claim webhook delivery %s: %v", delivery.ID, claim.Error)
+			continue
+		}
+		if claim.RowsAffected == 0 {
+			continue
+		}
+
+		var merchant models.Merchant
+		if err := wh.db.First(&merchant, "id = ?", delivery.MerchantID).Error; err != nil {
+			log.Printf("Failed to load merchant of webhook delivery %s: %v", delivery.ID, err)
+			continue
+		}
+
+		attempts := delivery.Attempts + 1
+		updates := map[string]interface{}{"attempts": attempts}
+		code, err := wh.post(&merchant, delivery)
+		updates["response_code"] = code
+		switch {
+		case err == nil:
+			updates["status"] = models.WebhookStatusDelivered
+			updates["delivered_at"] = time.Now()
+			updates["last_error"] = ""
+			delivered++
+		case attempts >= maxWebhookAttempts:
+			updates["status"] = models.WebhookStatusFailed
+			updates["last_error"] = truncateError(err)
+		default:
+			updates["next_attempt_at"] = now.Add(time.Duration(attempts*attempts) * time.Minute)
+			updates["last_error"] = truncateError(err)
+		}
+		if err := wh.db.Model(delivery).Updates(updates).Error; err != nil {
+			log.Printf("Failed to update webhook delivery %s: %v", delivery.ID, err)
+		}
+	}
+
+	return delivered, nil
+}
+
+// post sends one delivery to the merchant's current webhook URL. Any 2xx
+// response counts as delivered.
+func (wh *WebhookService) post(merchant *models.Merchant, delivery *models.WebhookDelivery) (int, error) {
+	if merchant.WebhookURL == "" {
+		return 0, fmt.Errorf("merchant has no webhook URL")
+	}
+
+	payload := []byte(delivery.Payload)
+	req, err := http.NewRequest(http.MethodPost, merchant.WebhookURL, bytes.NewReader(payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "SecureWallet-Webhooks/1.0")
+	req.Header.Set(WebhookSignatureHeader, SignWebhook(merchant.WebhookSecret, time.Now(), payload))
+
+	resp, err := wh.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("webhook endpoint returned %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// SignWebhook returns the signature header for a payload: the timestamp and an
+// HMAC-SHA256 of "<timestamp>.<payload>" keyed with the merchant's webhook secret.
+// Receivers should recompute it and reject old timestamps to prevent replays.
+func SignWebhook(secret string, timestamp time.Time, payload []byte) string {
+	ts := strconv.FormatInt(timestamp.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(ts))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return "t=" + ts + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// queueWebhook stores an event for the merchant's webhook in the caller's
+// transaction, so that it is only sent once the change it describes is committed.
+// Merchants without a webhook URL get no deliveries.
+func queueWebhook(tx *gorm.DB, merchant *models.Merchant, eventType string, data interface{}) error {
+	if merchant.WebhookURL == "" {
+		return nil
+	}
+
+	event := WebhookEvent{ID: uuid.New(), Type: eventType, CreatedAt: time.Now(), Data: data}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook event: %w", err)
+	}
+
+	delivery := models.WebhookDelivery{
+		ID:            event.ID,
+		MerchantID:    merchant.ID,
+		EventType:     eventType,
+		Payload:       string(payload),
+		Status:        models.WebhookStatusPending,
+		NextAttemptAt: event.CreatedAt,
+	}
+	if err := tx.Create(&delivery).Error; err != nil {
+		return fmt.Errorf("failed to queue webhook: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,444 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"securewallet/internal/config"
+	"securewallet/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Organisation limits
+const (
+	maxMembersPerOrganization = 50
+	maxApprovalRules          = 10
+	maxRequiredApprovals      = 5
+)
+
+// orgWalletRoles maps each organisation role to the role it gives on the organisation's wallets
+var orgWalletRoles = map[string]string{
+	models.OrgRoleAdmin:    models.WalletRoleOwner,
+	models.OrgRoleMaker:    models.WalletRoleSpender,
+	models.OrgRoleApprover: models.WalletRoleViewer,
+	models.OrgRoleViewer:   models.WalletRoleViewer,
+}
+
+// Organisation errors
+var (
+	ErrOrganizationNotFound    = errors.New("organisation not found")
+	ErrInvalidOrganizationName = errors.New("organisation name is required")
+	ErrOrganizationPermission  = errors.New("your role in this organisation does not allow this")
+	ErrInvalidOrgRole          = errors.New("role must be admin, approver, maker or viewer")
+	ErrOrgMemberNotFound       = errors.New("organisation member not found")
+	ErrAlreadyOrgMember        = errors.New("user is already a member of this organisation")
+	ErrTooManyOrgMembers       = errors.New("organisation already has the maximum number of members")
+	ErrLastOrgAdmin            = errors.New("an organisation needs at least one admin")
+	ErrInvalidApprovalPolicy   = errors.New("approvals must be between 1 and 5, with at most 10 rules and one rule per currency and threshold")
+	ErrOrganizationWallet      = errors.New("organisation wallets are shared through the organisation's members")
+	ErrApprovalRequired        = errors.New("payments from organisation wallets need approval; send them as transfers")
+)
+
+// OrganizationInfo is an organisation together with the current user's role in it
+type OrganizationInfo struct {
+	models.Organization
+	Role    string          `json:"role"`
+	Wallets []models.Wallet `json:"wallets,omitempty"`
+}
+
+// ApprovalRuleInput describes one rule of an approval policy
+type ApprovalRuleInput struct {
+	Currency          string
+	Threshold         models.Money
+	RequiredApprovals int
+}
+
+// OrganizationService manages company accounts, their members, approval policy and wallets
+type OrganizationService struct {
+	db      *gorm.DB
+	wallets *WalletService
+}
+
+// NewOrganizationService creates a new organisation service
+func NewOrganizationService() *OrganizationService {
+	return &OrganizationService{
+		db:      config.GetDB(),
+		wallets: NewWalletService(),
+	}
+}
+
+// Create sets up an organisation with the user as its first admin
+func (s *OrganizationService) Create(userID uuid.UUID, name string) (*models.Organization, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, ErrInvalidOrganizationName
+	}
+
+	org := models.Organization{
+		Name:             name,
+		DefaultApprovals: 1,
+		CreatedBy:        userID,
+	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&org).Error; err != nil {
+			return fmt.Errorf("failed to create organisation: %w", err)
+		}
+		return tx.Create(&models.OrganizationMember{
+			OrganizationID: org.ID,
+			UserID:         userID,
+			Role:           models.OrgRoleAdmin,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &org, nil
+}
+
+// List returns the organisations the user is a member of
+func (s *OrganizationService) List(userID uuid.UUID) ([]OrganizationInfo, error) {
+	var memberships []models.OrganizationMember
+	if err := s.db.Where("user_id = ?", userID).Find(&memberships).Error; err != nil {
+		return nil, err
+	}
+	roles := make(map[uuid.UUID]string, len(memberships))
+	ids := make([]uuid.UUID, 0, len(memberships))
+	for _, membership := range memberships {
+		roles[membership.OrganizationID] = membership.Role
+		ids = append(ids, membership.OrganizationID)
+	}
+
+	infos := []OrganizationInfo{}
+	if len(ids) == 0 {
+		return infos, nil
+	}
+	var orgs []models.Organization
+	if err := s.db.Where("id IN ?", ids).Order("name ASC").Find(&orgs).Error; err != nil {
+		return nil, err
+	}
+	for _, org := range orgs {
+		infos = append(infos, OrganizationInfo{Organization: org, Role: roles[org.ID]})
+	}
+	return infos, nil
+}
+
+// Get returns an organisation with its members, approval rules and open wallets
+func (s *OrganizationService) Get(userID uuid.UUID, orgID string) (*OrganizationInfo, error) {
+	org, membership, err := s.organization(userID, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.db.Preload("Members", func(db *gorm.DB) *gorm.DB {
+		return db.Order("created_at ASC")
+	}).Preload("Members.User").Preload("ApprovalRules", func(db *gorm.DB) *gorm.DB {
+		return db.Order("currency ASC, threshold ASC")
+	}).First(org, "id = ?", org.ID).Error; err != nil {
+		return nil, err
+	}
+
+	info := OrganizationInfo{Organization: *org, Role: membership.Role, Wallets: []models.Wallet{}}
+	if err := s.db.Where("organization_id = ? AND status = ?", org.ID, models.WalletStatusActive).
+		Order("created_at ASC").Find(&info.Wallets).Error; err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+// AddMember gives a registered user, found by email or username, a role in the
+// organisation. Only admins can add members.
+func (s *OrganizationService) AddMember(userID uuid.UUID, orgID, identifier, role string) (*models.OrganizationMember, error) {
+	org, _, err := s.organization(userID, orgID, models.OrgRoleAdmin)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := orgWalletRoles[role]; !ok {
+		return nil, ErrInvalidOrgRole
+	}
+	user, err := s.wallets.FindRecipient(identifier)
+	if err != nil {
+		return nil, err
+	}
+
+	addedBy := userID
+	member := models.OrganizationMember{
+		OrganizationID: org.ID,
+		UserID:         user.ID,
+		Role:           role,
+		AddedBy:        &addedBy,
+	}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		var members int64
+		if err := tx.Model(&models.OrganizationMember{}).Where("organization_id = ?", org.ID).Count(&members).Error; err != nil {
+			return err
+		}
+		if members >= maxMembersPerOrganization {
+			return ErrTooManyOrgMembers
+		}
+		if _, err := orgMembership(tx, org.ID, user.ID); err == nil {
+			return ErrAlreadyOrgMember
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		return tx.Create(&member).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	member.User = *user
+	return &member, nil
+}
+
+// UpdateMember changes a member's role. Only admins can do this.
+func (s *OrganizationService) UpdateMember(userID uuid.UUID, orgID, memberID, role string) (*models.OrganizationMember, error) {
+	org, _, err := s.organization(userID, orgID, models.OrgRoleAdmin)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := orgWalletRoles[role]; !ok {
+		return nil, ErrInvalidOrgRole
+	}
+
+	var member models.OrganizationMember
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.member(tx, org.ID, memberID, &member); err != nil {
+			return err
+		}
+		if member.Role == models.OrgRoleAdmin && role != models.OrgRoleAdmin {
+			if err := checkOtherAdmins(tx, org.ID, member.ID); err != nil {
+				return err
+			}
+		}
+		return tx.Model(&member).Update("role", role).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	member.Role = role
+	return &member, nil
+}
+
+// RemoveMember takes a member out of the organisation. Admins can remove anyone
+// and every member can leave, as long as an admin remains.
+func (s *OrganizationService) RemoveMember(userID uuid.UUID, orgID, memberID string) error {
+	org, membership, err := s.organization(userID, orgID)
+	if err != nil {
+		return err
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var member models.OrganizationMember
+		if err := s.member(tx, org.ID, memberID, &member); err != nil {
+			return err
+		}
+		if member.UserID != userID && membership.Role != models.OrgRoleAdmin {
+			return ErrOrganizationPermission
+		}
+		if member.Role == models.OrgRoleAdmin {
+			if err := checkOtherAdmins(tx, org.ID, member.ID); err != nil {
+				return err
+			}
+		}
+		return tx.Delete(&member).Error
+	})
+}
+
+// SetPolicy replaces the organisation's approval policy: the approvals needed by
+// default and the rules that require more for larger payments. Only admins can
+// change it; instructions already pending keep the approvals they were created with.
+func (s *OrganizationService) SetPolicy(userID uuid.UUID, orgID string, defaultApprovals int, rules []ApprovalRuleInput) (*models.Organization, error) {
+	org, _, err := s.organization(userID, orgID, models.OrgRoleAdmin)
+	if err != nil {
+		return nil, err
+	}
+	if defaultApprovals < 1 || defaultApprovals > maxRequiredApprovals || len(rules) > maxApprovalRules {
+		return nil, ErrInvalidApprovalPolicy
+	}
+
+	validated := make([]models.ApprovalRule, 0, len(rules))
+	seen := make(map[string]bool, len(rules))
+	for _, rule := range rules {
+		currency := strings.ToUpper(rule.Currency)
+		if !models.IsSupportedCurrency(currency) {
+			return nil, ErrUnsupportedCurrency
+		}
+		threshold, err := rule.Threshold.In(currency)
+		if err != nil || threshold.IsNegative() {
+			return nil, ErrInvalidApprovalPolicy
+		}
+		if rule.RequiredApprovals < 1 || rule.RequiredApprovals > maxRequiredApprovals {
+			return nil, ErrInvalidApprovalPolicy
+		}
+		key := currency + " " + threshold.String()
+		if seen[key] {
+			return nil, ErrInvalidApprovalPolicy
+		}
+		seen[key] = true
+		validated = append(validated, models.ApprovalRule{
+			OrganizationID:    org.ID,
+			Currency:          currency,
+			Threshold:         threshold,
+			RequiredApprovals: rule.RequiredApprovals,
+		})
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("organization_id = ?", org.ID).Delete(&models.ApprovalRule{}).Error; err != nil {
+			return err
+		}
+		if len(validated) > 0 {
+			if err := tx.Create(&validated).Error; err != nil {
+				return fmt.Errorf("failed to save approval rules: %w", err)
+			}
+		}
+		return tx.Model(org).Update("default_approvals", defaultApprovals).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	org.DefaultApprovals = defaultApprovals
+	org.ApprovalRules = validated
+	return org, nil
+}
+
+// CreateWallet opens a wallet owned by the organisation. Only admins can do this.
+func (s *OrganizationService) CreateWallet(userID uuid.UUID, orgID, name, currency string) (*models.Wallet, error) {
+	org, _, err := s.organization(userID, orgID, models.OrgRoleAdmin)
+	if err != nil {
+		return nil, err
+	}
+	currency = strings.ToUpper(currency)
+	if !models.IsSupportedCurrency(currency) {
+		return nil, ErrUnsupportedCurrency
+	}
+
+	var openWallets int64
+	if err := s.db.Model(&models.Wallet{}).
+		Where("organization_id = ? AND status = ?", org.ID, models.WalletStatusActive).
+		Count(&openWallets).Error; err != nil {
+		return nil, err
+	}
+	if openWallets >= maxWalletsPerUser {
+		return nil, fmt.Errorf("cannot hold more than %d open wallets", maxWalletsPerUser)
+	}
+
+	if name == "" {
+		name = org.Name + " " + currency
+	}
+	orgRef := org.ID
+	wallet := models.Wallet{
+		UserID:         userID,
+		OrganizationID: &orgRef,
+		Name:           name,
+		Balance:        models.ZeroMoney(currency),
+		Currency:       currency,
+		Status:         models.WalletStatusActive,
+	}
+	if err := s.db.Create(&wallet).Error; err != nil {
+		return nil, fmt.Errorf("failed to create wallet: %w", err)
+	}
+	return &wallet, nil
+}
+
+// organization loads an organisation the user is a member of. When roles are
+// given, the user must hold one of them.
+func (s *OrganizationService) organization(userID uuid.UUID, orgID string, roles ...string) (*models.Organization, *models.OrganizationMember, error) {
+	return memberOrganization(s.db, userID, orgID, roles...)
+}
+
+// member loads a member row of an organisation
+func (s *OrganizationService) member(tx *gorm.DB, orgID uuid.UUID, memberID string, member *models.OrganizationMember) error {
+	id, err := uuid.Parse(memberID)
+	if err != nil {
+		return ErrOrgMemberNotFound
+	}
+	if err := tx.Where("id = ? AND organization_id = ?", id, orgID).First(member).Error; err != nil {
+		return ErrOrgMemberNotFound
+	}
+	return nil
+}
+
+// memberOrganization loads an organisation together with the user's membership.
+// Organisations the user does not belong to are reported as missing.
+func memberOrganization(db *gorm.DB, userID uuid.UUID, orgID string, roles ...string) (*models.Organization, *models.OrganizationMember, error) {
+	id, err := uuid.Parse(orgID)
+	if err != nil {
+		return nil, nil, ErrOrganizationNotFound
+	}
+
+	membership, err := orgMembership(db, id, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil, ErrOrganizationNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(roles) > 0 && !hasOrgRole(membership.Role, roles...) {
+		return nil, nil, ErrOrganizationPermission
+	}
+
+	var org models.Organization
+	if err := db.First(&org, "id = ?", id).Error; err != nil {
+		return nil, nil, ErrOrganizationNotFound
+	}
+	return &org, membership, nil
+}
+
+// orgMembership loads the user's member row in an organisation
+func orgMembership(db *gorm.DB, orgID, userID uuid.UUID) (*models.OrganizationMember, error) {
+	var member models.OrganizationMember
+	if err := db.Where("organization_id = ? AND user_id = ?", orgID, userID).First(&member).Error; err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+// hasOrgRole reports whether a role is one of the allowed roles
+func hasOrgRole(role string, allowed ...string) bool {
+	for _, candidate := range allowed {
+		if role == candidate {
+			return true
+		}
+	}
+	return false
+}
+
+// checkOtherAdmins makes sure that someone other than the given member remains an admin
+func checkOtherAdmins(tx *gorm.DB, orgID, memberID uuid.UUID) error {
+	var admins int64
+	if err := tx.Model(&models.OrganizationMember{}).
+		Where("organization_id = ? AND role = ? AND id <> ?", orgID, models.OrgRoleAdmin, memberID).
+		Count(&admins).Error; err != nil {
+		return err
+	}
+	if admins == 0 {
+		return ErrLastOrgAdmin
+	}
+	return nil
+}
+
+// organizationWalletRole returns the wallet role a user's organisation role gives
+// on the organisation's wallets, or an empty string if they are not a member
+func organizationWalletRole(db *gorm.DB, orgID, userID uuid.UUID) (string, error) {
+	member, err := orgMembership(db, orgID, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return orgWalletRoles[member.Role], nil
+}
+
+// checkOrganizationPayment rejects money leaving an organisation wallet other than
+// through an approved payment instruction
+func checkOrganizationPayment(wallet *models.Wallet) error {
+	if wallet.OrganizationID != nil {
+		return ErrApprovalRequired
+	}
+	return nil
+}
@@ -0,0 +1,363 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"securewallet/internal/config"
+	"securewallet/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// instructionApprovers are the organisation roles that can approve or reject payments
+var instructionApprovers = []string{models.OrgRoleAdmin, models.OrgRoleApprover}
+
+// Payment instruction errors
+var (
+	ErrInstructionNotFound      = errors.New("payment instruction not found")
+	ErrInstructionNotPending    = errors.New("payment instruction is no longer pending")
+	ErrInstructionDecided       = errors.New("you have already approved or rejected this instruction")
+	ErrSelfApproval             = errors.New("the maker of a payment instruction cannot approve it")
+	ErrInvalidInstructionStatus = errors.New("status must be pending, executed, rejected, failed or all")
+)
+
+// InstructionInput describes a transfer out of an organisation wallet
+type InstructionInput struct {
+	UserID          uuid.UUID // The maker
+	Wallet          *models.Wallet
+	Recipient       *models.User
+	RecipientWallet *models.Wallet
+	Amount          models.Money
+	Description     string
+}
+
+// PaymentInstructionService runs the maker-checker workflow for organisation
+// payments: a maker creates an instruction and it is booked as a transfer once
+// the number of approvals required by the organisation's policy is reached
+type PaymentInstructionService struct {
+	db        *gorm.DB
+	transfers *TransferService
+	holds     *HoldService
+}
+
+// NewPaymentInstructionService creates a new payment instruction service
+func NewPaymentInstructionService() *PaymentInstructionService {
+	return &PaymentInstructionService{
+		db:        config.GetDB(),
+		transfers: NewTransferService(),
+		holds:     NewHoldService(),
+	}
+}
+
+// Create records a pending instruction for a transfer out of an organisation
+// wallet and holds the amount and fee until it is decided. Only admins and
+// makers can create one.
+func (is *PaymentInstructionService) Create(input InstructionInput) (*models.PaymentInstruction, error) {
+	if input.Wallet.OrganizationID == nil {
+		return nil, ErrOrganizationNotFound
+	}
+	if input.Wallet.ID == input.RecipientWallet.ID {
+		return nil, ErrSameWallet
+	}
+	if input.RecipientWallet.Currency != input.Wallet.Currency {
+		return nil, ErrCurrencyMismatch
+	}
+	amount, err := input.Amount.In(input.Wallet.Currency)
+	if err != nil || !amount.IsPositive() {
+		return nil, ErrInvalidAmount
+	}
+
+	var org models.Organization
+	if err := is.db.First(&org, "id = ?", *input.Wallet.OrganizationID).Error; err != nil {
+		return nil, ErrOrganizationNotFound
+	}
+	membership, err := orgMembership(is.db, org.ID, input.UserID)
+	if err != nil || !hasOrgRole(membership.Role, models.OrgRoleAdmin, models.OrgRoleMaker) {
+		return nil, ErrOrganizationPermission
+	}
+
+	required, err := is.requiredApprovals(&org, amount)
+	if err != nil {
+		return nil, err
+	}
+	instruction := models.PaymentInstruction{
+		ID:                uuid.New(),
+		OrganizationID:    org.ID,
+		WalletID:          input.Wallet.ID,
+		RecipientUserID:   input.Recipient.ID,
+		RecipientWalletID: input.RecipientWallet.ID,
+		Amount:            amount,
+		Currency:          amount.Currency,
+		Description:       input.Description,
+		Status:            models.InstructionStatusPending,
+		RequiredApprovals: required,
+		CreatedBy:         input.UserID,
+	}
+	// Hold what the transfer is expected to cost; it is priced again when executed
+	quote, err := is.transfers.fees.Preview(input.Wallet.UserID, models.FeeTypeTransfer, amount)
+	if err != nil {
+		return nil, err
+	}
+	total := quote.Total
+
+	err = is.transfers.withRetry(func(tx *gorm.DB) error {
+		wallets, err := is.transfers.lockWallets(tx, input.Wallet.ID)
+		if err != nil {
+			return err
+		}
+		locked := wallets[input.Wallet.ID]
+		hold, err := is.holds.place(tx, &locked, HoldInput{
+			WalletID:    locked.ID,
+			Amount:      total,
+			Kind:        models.HoldKindInstruction,
+			Reference:   instruction.ID.String(),
+			Description: "Payment awaiting approval",
+			PlacedBy:    input.UserID,
+		})
+		if err != nil {
+			return err
+		}
+
+		instruction.HoldID = &hold.ID
+		if err := tx.Create(&instruction).Error; err != nil {
+			return fmt.Errorf("failed to create payment instruction: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &instruction, nil
+}
+
+// List returns an organisation's instructions, newest first, optionally filtered
+// by status. Every member can see them.
+func (is *PaymentInstructionService) List(userID uuid.UUID, orgID, status string) ([]models.PaymentInstruction, error) {
+	org, _, err := memberOrganization(is.db, userID, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	query := is.db.Preload("Decisions").Where("organization_id = ?", org.ID)
+	switch status {
+	case "all":
+	case models.InstructionStatusPending, models.InstructionStatusExecuted,
+		models.InstructionStatusRejected, models.InstructionStatusFailed:
+		query = query.Where("status = ?", status)
+	default:
+		return nil, ErrInvalidInstructionStatus
+	}
+
+	instructions := []models.PaymentInstruction{}
+	if err := query.Order("created_at DESC").Limit(100).Find(&instructions).Error; err != nil {
+		return nil, err
+	}
+	return instructions, nil
+}
+
+// Get returns one of an organisation's instructions with its decisions
+func (is *PaymentInstructionService) Get(userID uuid.UUID, orgID, instructionID string) (*models.PaymentInstruction, error) {
+	org, _, err := memberOrganization(is.db, userID, orgID)
+	if err != nil {
+		return nil, err
+	}
+	id, err := uuid.Parse(instructionID)
+	if err != nil {
+		return nil, ErrInstructionNotFound
+	}
+
+	var instruction models.PaymentInstruction
+	if err := is.db.Preload("Decisions").
+		First(&instruction, "id = ? AND organization_id = ?", id, org.ID).Error; err != nil {
+		return nil, ErrInstructionNotFound
+	}
+	return &instruction, nil
+}
+
+// Approve records the user's approval. When it is the last one required, the
+// transfer is booked in the same transaction; if that fails (e.g. the wallet no
+// longer holds enough money) the instruction is marked failed instead.
+func (is *PaymentInstructionService) Approve(userID uuid.UUID, orgID, instructionID, comment string) (*models.PaymentInstruction, error) {
+	return is.decide(userID, orgID, instructionID, models.InstructionDecisionApprove, comment)
+}
+
+// Reject turns an instruction down. Approvers can reject any pending instruction
+// and makers can withdraw their own.
+func (is *PaymentInstructionService) Reject(userID uuid.UUID, orgID, instructionID, comment string) (*models.PaymentInstruction, error) {
+	return is.decide(userID, orgID, instructionID, models.InstructionDecisionReject, comment)
+}
+
+// decide records an approval or rejection under a lock on the instruction, so
+// that concurrent approvals cannot execute the transfer twice
+func (is *PaymentInstructionService) decide(userID uuid.UUID, orgID, instructionID, decision, comment string) (*models.PaymentInstruction, error) {
+	org, membership, err := memberOrganization(is.db, userID, orgID)
+	if err != nil {
+		return nil, err
+	}
+	id, err := uuid.Parse(instructionID)
+	if err != nil {
+		return nil, ErrInstructionNotFound
+	}
+
+	var instruction models.PaymentInstruction
+	err = is.transfers.withRetry(func(tx *gorm.DB) error {
+		instruction = models.PaymentInstruction{}
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&instruction, "id = ? AND organization_id = ?", id, org.ID).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInstructionNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if instruction.Status != models.InstructionStatusPending {
+			return ErrInstructionNotPending
+		}
+
+		ownInstruction := instruction.CreatedBy == userID
+		if decision == models.InstructionDecisionApprove && ownInstruction {
+			return ErrSelfApproval
+		}
+		if !hasOrgRole(membership.Role, instructionApprovers...) && !(decision == models.InstructionDecisionReject && ownInstruction) {
+			return ErrOrganizationPermission
+		}
+
+		var decided int64
+		if err := tx.Model(&models.InstructionDecision{}).
+			Where("instruction_id = ? AND user_id = ?", instruction.ID, userID).
+			Count(&decided).Error; err != nil {
+			return err
+		}
+		if decided > 0 {
+			return ErrInstructionDecided
+		}
+		if err := tx.Create(&models.InstructionDecision{
+			InstructionID: instruction.ID,
+			UserID:        userID,
+			Decision:      decision,
+			Comment:       comment,
+		}).Error; err != nil {
+			return fmt.Errorf("failed to record decision: %w", err)
+		}
+
+		now := time.Now()
+		updates := map[string]interface{}{}
+		if decision == models.InstructionDecisionReject {
+			if err := is.releaseHold(tx, &instruction); err != nil {
+				return err
+			}
+			updates["status"] = models.InstructionStatusRejected
+			updates["decided_at"] = now
+		} else {
+			instruction.Approvals++
+			updates["approvals"] = instruction.Approvals
+			if instruction.Approvals >= instruction.RequiredApprovals {
+				updates["decided_at"] = now
+				if err := is.execute(tx, &instruction, updates); err != nil {
+					return err
+				}
+			}
+		}
+		return tx.Model(&instruction).Updates(updates).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := is.db.Preload("Decisions").First(&instruction, "id = ?", instruction.ID).Error; err != nil {
+		return nil, err
+	}
+	return &instruction, nil
+}
+
+// execute books a fully approved instruction as a transfer from the maker. The
+// hold is released first so the transfer can spend the reserved funds. The
+// transfer runs in a savepoint so that a failure can be recorded on the
+// instruction without losing the approval; lock conflicts are returned so that
+// the whole transaction is retried.
+func (is *PaymentInstructionService) execute(tx *gorm.DB, instruction *models.PaymentInstruction, updates map[string]interface{}) error {
+	var org models.Organization
+	if err := tx.First(&org, "id = ?", instruction.OrganizationID).Error; err != nil {
+		return err
+	}
+	var recipient models.User
+	if err := tx.First(&recipient, "id = ?", instruction.RecipientUserID).Error; err != nil {
+		return err
+	}
+
+	if err := is.releaseHold(tx, instruction); err != nil {
+		return err
+	}
+
+	var result *TransferResult
+	err := tx.Transaction(func(tx *gorm.DB) error {
+		var err error
+		result, err = is.transfers.transfer(tx, TransferInput{
+			SenderWalletID:    instruction.WalletID,
+			RecipientWalletID: instruction.RecipientWalletID,
+			Amount:            instruction.Amount,
+			Description:       instruction.Description,
+			SenderMemo:        instruction.Description + " (to " + recipient.Username + ")",
+			RecipientMemo:     instruction.Description + " (from " + org.Name + ")",
+			InitiatedBy:       instruction.CreatedBy,
+			approved:          true,
+		})
+		return err
+	})
+	if isRetryableLockError(err) {
+		return err
+	}
+
+	if err != nil {
+		message := err.Error()
+		if len(message) > 255 {
+			message = message[:255]
+		}
+		instruction.Status = models.InstructionStatusFailed
+		instruction.LastError = message
+		updates["status"] = models.InstructionStatusFailed
+		updates["last_error"] = message
+		return nil
+	}
+
+	now := time.Now()
+	instruction.Status = models.InstructionStatusExecuted
+	instruction.JournalEntryID = &result.Entry.ID
+	updates["status"] = models.InstructionStatusExecuted
+	updates["journal_entry_id"] = result.Entry.ID
+	updates["executed_at"] = now
+	return nil
+}
+
+// releaseHold gives back the funds reserved for a decided instruction.
+// Instructions created before holds existed have none.
+func (is *PaymentInstructionService) releaseHold(tx *gorm.DB, instruction *models.PaymentInstruction) error {
+	if instruction.HoldID == nil {
+		return nil
+	}
+	_, err := is.holds.release(tx, *instruction.HoldID, models.HoldStatusReleased)
+	if errors.Is(err, ErrHoldNotActive) {
+		return nil
+	}
+	return err
+}
+
+// requiredApprovals applies the organisation's policy: the rule with the highest
+// threshold below the amount, or the default when no rule matches
+func (is *PaymentInstructionService) requiredApprovals(org *models.Organization, amount models.Money) (int, error) {
+	var rule models.ApprovalRule
+	err := is.db.Where("organization_id = ? AND currency = ? AND threshold < ?", org.ID, amount.Currency, amount).
+		Order("threshold DESC").
+		First(&rule).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return org.DefaultApprovals, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return rule.RequiredApprovals, nil
+}
@@ -0,0 +1,502 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"securewallet/internal/config"
+	"securewallet/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Payment request expiry bounds
+const (
+	DefaultPaymentRequestTTL = 7 * 24 * time.Hour
+	MaxPaymentRequestTTL     = 30 * 24 * time.Hour
+)
+
+// defaultPublicAppURL is used for payment links when PUBLIC_APP_URL is not set
+const defaultPublicAppURL = "http://localhost:3000"
+
+// Payment request errors
+var (
+	ErrPaymentRequestNotFound = errors.New("payment request not found")
+	ErrPaymentRequestClosed   = errors.New("payment request is no longer pending")
+	ErrPaymentRequestExpired  = errors.New("payment request has expired")
+	ErrSelfPaymentRequest     = errors.New("cannot request money from yourself")
+	ErrInvalidPaymentLink     = errors.New("invalid payment link")
+	ErrInvalidExpiry          = errors.New("expiry must be between 1 minute and 30 days")
+)
+
+// PaymentRequestService handles requests for money between users and shareable payment links
+type PaymentRequestService struct {
+	db        *gorm.DB
+	transfers *TransferService
+	wallets   *WalletService
+}
+
+// PaymentRequestInput describes a payment request or link to create
+type PaymentRequestInput struct {
+	RequesterID uuid.UUID
+	WalletID    string // Receiving wallet, defaults to the requester's default wallet
+	Payer       string // Email or username; empty creates a payment link
+	Amount      models.Money
+	Memo        string
+	ExpiresIn   time.Duration // Zero uses DefaultPaymentRequestTTL
+}
+
+// PaymentLink is the shareable form of a payment link request
+type PaymentLink struct {
+	Token     string `json:"token"`
+	URL       string `json:"url"`
+	QRPayload string `json:"qr_payload"` // Text to encode in a QR code
+}
+
+// PaymentResult is the outcome of paying a request
+type PaymentResult struct {
+	Request  *models.PaymentRequest
+	Transfer *TransferResult
+}
+
+// NewPaymentRequestService creates a new payment request service
+func NewPaymentRequestService() *PaymentRequestService {
+	return &PaymentRequestService{
+		db:        config.GetDB(),
+		transfers: NewTransferService(),
+		wallets:   NewWalletService(),
+	}
+}
+
+// Create saves a payment request addressed to a payer, or a payment link when no payer is given
+func (ps *PaymentRequestService) Create(input PaymentRequestInput) (*models.PaymentRequest, error) {
+	wallet, err := ps.wallets.MemberWallet(input.RequesterID, input.WalletID, models.WalletRoleSpender)
+	if err != nil {
+		return nil, err
+	}
+
+	amount, err := input.Amount.In(wallet.Currency)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidAmount, err)
+	}
+	if !amount.IsPositive() {
+		return nil, ErrInvalidAmount
+	}
+
+	ttl := input.ExpiresIn
+	if ttl == 0 {
+		ttl = DefaultPaymentRequestTTL
+	}
+	if ttl < time.Minute || ttl > MaxPaymentRequestTTL {
+		return nil, ErrInvalidExpiry
+	}
+
+	request := models.PaymentRequest{
+		Kind:              models.PaymentRequestKindLink,
+		RequesterID:       input.RequesterID,
+		RequesterWalletID: wallet.ID,
+		Amount:            amount,
+		Currency:          amount.Currency,
+		Memo:              strings.TrimSpace(input.Memo),
+		Status:            models.PaymentRequestStatusPending,
+		ExpiresAt:         time.Now().Add(ttl).Truncate(time.Second),
+	}
+
+	if input.Payer != "" {
+		payer, err := ps.wallets.FindRecipient(input.Payer)
+		if err != nil {
+			return nil, err
+		}
+		if payer.ID == input.RequesterID {
+			return nil, ErrSelfPaymentRequest
+		}
+		request.Kind = models.PaymentRequestKindRequest
+		request.PayerID = &payer.ID
+	}
+
+	if err := ps.db.Create(&request).Error; err != nil {
+		return nil, fmt.Errorf("failed to save payment request: %w", err)
+	}
+
+	ps.fillUsernames(&request)
+	return &request, nil
+}
+
+// List returns the user's payment requests. Role "incoming" lists requests the
+// user was asked to pay, "outgoing" the ones they created; anything else lists both.
+func (ps *PaymentRequestService) List(userID uuid.UUID, role, status string, limit int) ([]models.PaymentRequest, error) {
+	if err := ps.expireStale(userID); err != nil {
+		return nil, err
+	}
+
+	query := ps.db.Model(&models.PaymentRequest{})
+	switch role {
+	case "incoming":
+		query = query.Where("payer_id = ? AND kind = ?", userID, models.PaymentRequestKindRequest)
+	case "outgoing":
+		query = query.Where("requester_id = ?", userID)
+	default:
+		query = query.Where("requester_id = ? OR payer_id = ?", userID, userID)
+	}
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var requests []models.PaymentRequest
+	if err := query.Order("created_at DESC").Limit(limit).Find(&requests).Error; err != nil {
+		return nil, err
+	}
+
+	for i := range requests {
+		ps.fillUsernames(&requests[i])
+	}
+	return requests, nil
+}
+
+// Get returns a payment request the user created or was asked to pay
+func (ps *PaymentRequestService) Get(userID uuid.UUID, requestID string) (*models.PaymentRequest, error) {
+	id, err := uuid.Parse(requestID)
+	if err != nil {
+		return nil, ErrPaymentRequestNotFound
+	}
+	if err := ps.expireStale(userID); err != nil {
+		return nil, err
+	}
+
+	var request models.PaymentRequest
+	if err := ps.db.Where("id = ? AND (requester_id = ? OR payer_id = ?)", id, userID, userID).
+		First(&request).Error; err != nil {
+		return nil, ErrPaymentRequestNotFound
+	}
+
+	ps.fillUsernames(&request)
+	return &request, nil
+}
+
+// ResolveLink verifies a payment link token and returns the request behind it
+func (ps *PaymentRequestService) ResolveLink(token string) (*models.PaymentRequest, error) {
+	idPart, signature, found := strings.Cut(token, ".")
+	if !found {
+		return nil, ErrInvalidPaymentLink
+	}
+	id, err := uuid.Parse(idPart)
+	if err != nil {
+		return nil, ErrInvalidPaymentLink
+	}
+
+	var request models.PaymentRequest
+	if err := ps.db.Where("id = ? AND kind = ?", id, models.PaymentRequestKindLink).First(&request).Error; err != nil {
+		return nil, ErrInvalidPaymentLink
+	}
+
+	expected, err := signPaymentRequest(&request)
+	if err != nil {
+		return nil, err
+	}
+	if !hmac.Equal([]byte(signature), []byte(expected)) {
+		return nil, ErrInvalidPaymentLink
+	}
+
+	if request.Status == models.PaymentRequestStatusPending && !time.Now().Before(request.ExpiresAt) {
+		request.Status = models.PaymentRequestStatusExpired
+	}
+	ps.fillUsernames(&request)
+	return &request, nil
+}
+
+// Link builds the signed token, URL and QR payload for a payment link request
+func (ps *PaymentRequestService) Link(request *models.PaymentRequest) (*PaymentLink, error) {
+	if request.Kind != models.PaymentRequestKindLink {
+		return nil, ErrInvalidPaymentLink
+	}
+
+	signature, err := signPaymentRequest(request)
+	if err != nil {
+		return nil, err
+	}
+	token := request.ID.String() + "." + signature
+
+	payload := url.Values{}
+	payload.Set("token", token)
+	payload.Set("amount", request.Amount.String())
+	payload.Set("currency", request.Currency)
+	if request.Memo != "" {
+		payload.Set("memo", request.Memo)
+	}
+
+	return &PaymentLink{
+		Token:     token,
+		URL:       publicAppURL() + "/pay/" + url.PathEscape(token),
+		QRPayload: "securewallet://pay?" + payload.Encode(),
+	}, nil
+}
+
+// Pay pays a request the user was asked to pay. Payment links can only be paid
+// through PayLink, which checks their signature.
+func (ps *PaymentRequestService) Pay(payerID uuid.UUID, requestID, walletID string) (*PaymentResult, error) {
+	id, err := uuid.Parse(requestID)
+	if err != nil {
+		return nil, ErrPaymentRequestNotFound
+	}
+	return ps.pay(payerID, id, walletID, models.PaymentRequestKindRequest)
+}
+
+// PayLink pays a payment link after verifying its signature
+func (ps *PaymentRequestService) PayLink(payerID uuid.UUID, token, walletID string) (*PaymentResult, error) {
+	request, err := ps.ResolveLink(token)
+	if err != nil {
+		return nil, err
+	}
+	return ps.pay(payerID, request.ID, walletID, models.PaymentRequestKindLink)
+}
+
+// pay transfers the requested amount from the payer to the requester and marks
+// the request paid in the same database transaction, so a request is paid at most once.
+// A request of another kind than the caller pays is treated as missing.
+func (ps *PaymentRequestService) pay(payerID, requestID uuid.UUID, walletID, kind string) (*PaymentResult, error) {
+	var request models.PaymentRequest
+	if err := ps.db.First(&request, "id = ? AND kind = ?", requestID, kind).Error; err != nil {
+		return nil, ErrPaymentRequestNotFound
+	}
+	if err := checkPayer(&request, payerID); err != nil {
+		return nil, err
+	}
+
+	// Without an explicit wallet, pay from the payer's wallet in the requested currency
+	var payerWallet *models.Wallet
+	var err error
+	if walletID == "" {
+		payerWallet, err = ps.wallets.RecipientWallet(payerID, request.Currency)
+		if errors.Is(err, ErrNoRecipientWallet) {
+			err = fmt.Errorf("%w: no %s wallet to pay from", ErrCurrencyMismatch, request.Currency)
+		}
+	} else {
+		payerWallet, err = ps.wallets.MemberWallet(payerID, walletID, models.WalletRoleSpender)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := checkOrganizationPayment(payerWallet); err != nil {
+		return nil, err
+	}
+
+	// Pay into another wallet in the same currency if the requester closed the original one
+	var requesterWallet models.Wallet
+	if err := ps.db.First(&requesterWallet, "id = ?", request.RequesterWalletID).Error; err != nil {
+		return nil, err
+	}
+	if requesterWallet.Status == models.WalletStatusClosed {
+		fallback, err := ps.wallets.RecipientWallet(request.RequesterID, request.Currency)
+		if err != nil {
+			return nil, err
+		}
+		requesterWallet = *fallback
+	}
+
+	var payer, requester models.User
+	if err := ps.db.First(&payer, "id = ?", payerID).Error; err != nil {
+		return nil, err
+	}
+	if err := ps.db.First(&requester, "id = ?", request.RequesterID).Error; err != nil {
+		return nil, err
+	}
+
+	memo := request.Memo
+	if memo == "" {
+		memo = "Payment request"
+	}
+
+	var result PaymentResult
+	err = ps.transfers.withRetry(func(tx *gorm.DB) error {
+		var locked models.PaymentRequest
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&locked, "id = ?", requestID).Error; err != nil {
+			return err
+		}
+		if locked.Status != models.PaymentRequestStatusPending {
+			return ErrPaymentRequestClosed
+		}
+		if !time.Now().Before(locked.ExpiresAt) {
+			return ErrPaymentRequestExpired
+		}
+
+		transfer, err := ps.transfers.transfer(tx, TransferInput{
+			SenderWalletID:    payerWallet.ID,
+			RecipientWalletID: requesterWallet.ID,
+			Amount:            locked.Amount,
+			Description:       memo,
+			SenderMemo:        memo + " (paid to " + requester.Username + ")",
+			RecipientMemo:     memo + " (paid by " + payer.Username + ")",
+			InitiatedBy:       payerID,
+			feeType:           models.FeeTypePaymentRequest,
+		})
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		entryID := transfer.Entry.ID
+		if err := tx.Model(&locked).Updates(map[string]interface{}{
+			"status":           models.PaymentRequestStatusPaid,
+			"payer_id":         payerID,
+			"paid_at":          &now,
+			"journal_entry_id": &entryID,
+		}).Error; err != nil {
+			return err
+		}
+
+		locked.Status = models.PaymentRequestStatusPaid
+		locked.PayerID = &payerID
+		locked.PaidAt = &now
+		locked.JournalEntryID = &entryID
+		result.Request = &locked
+		result.Transfer = transfer
+		return nil
+	})
+	if errors.Is(err, ErrPaymentRequestExpired) {
+		ps.db.Model(&models.PaymentRequest{}).
+			Where("id = ? AND status = ?", requestID, models.PaymentRequestStatusPending).
+			Update("status", models.PaymentRequestStatusExpired)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	ps.fillUsernames(result.Request)
+	return &result, nil
+}
+
+// Decline lets the payer turn down a request addressed to them
+func (ps *PaymentRequestService) Decline(payerID uuid.UUID, requestID string) (*models.PaymentRequest, error) {
+	request, err := ps.Get(payerID, requestID)
+	if err != nil {
+		return nil, err
+	}
+	if request.Kind != models.PaymentRequestKindRequest || request.PayerID == nil || *request.PayerID != payerID {
+		return nil, ErrPaymentRequestNotFound
+	}
+
+	return ps.close(request, models.PaymentRequestStatusDeclined, "declined_at")
+}
+
+// Cancel lets the requester withdraw a pending request or link
+func (ps *PaymentRequestService) Cancel(requesterID uuid.UUID, requestID string) (*models.PaymentRequest, error) {
+	request, err := ps.Get(requesterID, requestID)
+	if err != nil {
+		return nil, err
+	}
+	if request.RequesterID != requesterID {
+		return nil, ErrPaymentRequestNotFound
+	}
+
+	return ps.close(request, models.PaymentRequestStatusCancelled, "cancelled_at")
+}
+
+// close moves a pending request to a final status. The status condition keeps it
+// from overwriting a payment that was made concurrently.
+func (ps *PaymentRequestService) close(request *models.PaymentRequest, status, timestampColumn string) (*models.PaymentRequest, error) {
+	now := time.Now()
+	result := ps.db.Model(&models.PaymentRequest{}).
+		Where("id = ? AND status = ?", request.ID, models.PaymentRequestStatusPending).
+		Updates(map[string]interface{}{"status": status, timestampColumn: &now})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrPaymentRequestClosed
+	}
+
+	request.Status = status
+	if status == models.PaymentRequestStatusDeclined {
+		request.DeclinedAt = &now
+	} else {
+		request.CancelledAt = &now
+	}
+	return request, nil
+}
+
+// expireStale marks the user's pending requests that are past their expiry as expired
+func (ps *PaymentRequestService) expireStale(userID uuid.UUID) error {
+	return ps.db.Model(&models.PaymentRequest{}).
+		Where("status = ? AND expires_at <= ? AND (requester_id = ? OR payer_id = ?)",
+			models.PaymentRequestStatusPending, time.Now(), userID, userID).
+		Update("status", models.PaymentRequestStatusExpired).Error
+}
+
+// fillUsernames sets the requester and payer usernames shown to the parties
+func (ps *PaymentRequestService) fillUsernames(request *models.PaymentRequest) {
+	ids := []uuid.UUID{request.RequesterID}
+	if request.PayerID != nil {
+		ids = append(ids, *request.PayerID)
+	}
+
+	var users []models.User
+	if err := ps.db.Select("id", "username").Where("id IN ?", ids).Find(&users).Error; err != nil {
+		return
+	}
+	for _, user := range users {
+		if user.ID == request.RequesterID {
+			request.RequesterUsername = user.Username
+		}
+		if request.PayerID != nil && user.ID == *request.PayerID {
+			request.PayerUsername = user.Username
+		}
+	}
+}
+
+// checkPayer reports whether the user may pay the request: requests only by
+// the addressed payer, links by anyone except the requester
+func checkPayer(request *models.PaymentRequest, payerID uuid.UUID) error {
+	switch request.Kind {
+	case models.PaymentRequestKindRequest:
+		if request.PayerID == nil || *request.PayerID != payerID {
+			return ErrPaymentRequestNotFound
+		}
+	case models.PaymentRequestKindLink:
+		if request.RequesterID == payerID {
+			return ErrSelfPaymentRequest
+		}
+	}
+	return nil
+}
+
+// signPaymentRequest signs the fields a payment link must not be able to change
+func signPaymentRequest(request *models.PaymentRequest) (string, error) {
+	key, err := paymentLinkKey()
+	if err != nil {
+		return "", err
+	}
+
+	mac := hmac.New(sha256.New, key)
+	fmt.Fprintf(mac, "%s|%s|%s|%d", request.ID, request.Amount.String(), request.Currency, request.ExpiresAt.Unix())
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)), nil
+}
+
+// publicAppURL returns the base URL of the web app that customer-facing links point to
+func publicAppURL() string {
+	baseURL := os.Getenv("PUBLIC_APP_URL")
+	if baseURL == "" {
+		baseURL = defaultPublicAppURL
+	}
+	return strings.TrimRight(baseURL, "/")
+}
+
+// paymentLinkKey returns the payment link signing key, falling back to the JWT secret
+func paymentLinkKey() ([]byte, error) {
+	if secret := os.Getenv("PAYMENT_LINK_SECRET"); secret != "" {
+		return []byte(secret), nil
+	}
+	secret, err := GetJWTSecret()
+	if err != nil {
+		return nil, err
+	}
+	return []byte(secret), nil
+}
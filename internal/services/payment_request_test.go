@@ -0,0 +1,131 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"securewallet/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// requestTestPayment creates a payment request for amount into the requester's
+// wallet, addressed to payer or as a link when payer is nil
+func requestTestPayment(t *testing.T, db *gorm.DB, requester, payer *models.Wallet, amount string) *models.PaymentRequest {
+	t.Helper()
+
+	input := PaymentRequestInput{
+		RequesterID: requester.UserID,
+		Amount:      mustMoney(amount, requester.Currency),
+		Memo:        "Dinner",
+	}
+	if payer != nil {
+		var user models.User
+		db.First(&user, "id = ?", payer.UserID)
+		input.Payer = user.Email
+	}
+	request, err := NewPaymentRequestService().Create(input)
+	if err != nil {
+		t.Fatalf("failed to create payment request: %v", err)
+	}
+	return request
+}
+
+func TestPayRequest(t *testing.T) {
+	db := setupTestDB(t)
+	requester := createTestWallet(t, db, "USD")
+	payer := createTestWallet(t, db, "USD")
+	other := createTestWallet(t, db, "USD")
+	deposit(t, payer, "100.00")
+	deposit(t, other, "100.00")
+	requests := NewPaymentRequestService()
+	request := requestTestPayment(t, db, requester, payer, "20.00")
+
+	// Only the addressed payer can pay, and a request is not a payment link
+	if _, err := requests.Pay(other.UserID, request.ID.String(), ""); !errors.Is(err, ErrPaymentRequestNotFound) {
+		t.Errorf("Pay by another user = %v, want %v", err, ErrPaymentRequestNotFound)
+	}
+	if _, err := requests.PayLink(payer.UserID, request.ID.String()+".x", ""); !errors.Is(err, ErrInvalidPaymentLink) {
+		t.Errorf("PayLink of a request = %v, want %v", err, ErrInvalidPaymentLink)
+	}
+
+	result, err := requests.Pay(payer.UserID, request.ID.String(), "")
+	if err != nil {
+		t.Fatalf("Pay failed: %v", err)
+	}
+	if result.Request.Status != models.PaymentRequestStatusPaid || result.Request.JournalEntryID == nil {
+		t.Errorf("paid request has status %s and entry %v, want paid with an entry", result.Request.Status, result.Request.JournalEntryID)
+	}
+	if _, err := requests.Pay(payer.UserID, request.ID.String(), ""); !errors.Is(err, ErrPaymentRequestClosed) {
+		t.Errorf("second Pay = %v, want %v", err, ErrPaymentRequestClosed)
+	}
+	if got := walletBalance(t, db, requester.ID); got.Amount != 2000 {
+		t.Errorf("requester balance = %s, want 20.00", got)
+	}
+	if got := walletBalance(t, db, other.ID); got.Amount != 10000 {
+		t.Errorf("other balance = %s, want 100.00", got)
+	}
+}
+
+func TestPayLink(t *testing.T) {
+	db := setupTestDB(t)
+	t.Setenv("PAYMENT_LINK_SECRET", "test-secret")
+	requester := createTestWallet(t, db, "USD")
+	payer := createTestWallet(t, db, "USD")
+	other := createTestWallet(t, db, "USD")
+	deposit(t, payer, "100.00")
+	deposit(t, other, "100.00")
+	requests := NewPaymentRequestService()
+	request := requestTestPayment(t, db, requester, nil, "15.00")
+	link, err := requests.Link(request)
+	if err != nil {
+		t.Fatalf("Link failed: %v", err)
+	}
+
+	// A link can only be paid through its signed token, and not by its requester
+	if _, err := requests.Pay(payer.UserID, request.ID.String(), ""); !errors.Is(err, ErrPaymentRequestNotFound) {
+		t.Errorf("Pay of a link = %v, want %v", err, ErrPaymentRequestNotFound)
+	}
+	if _, err := requests.PayLink(payer.UserID, request.ID.String()+".forged", ""); !errors.Is(err, ErrInvalidPaymentLink) {
+		t.Errorf("PayLink with a forged token = %v, want %v", err, ErrInvalidPaymentLink)
+	}
+	if _, err := requests.PayLink(requester.UserID, link.Token, ""); !errors.Is(err, ErrSelfPaymentRequest) {
+		t.Errorf("PayLink by the requester = %v, want %v", err, ErrSelfPaymentRequest)
+	}
+
+	result, err := requests.PayLink(payer.UserID, link.Token, "")
+	if err != nil {
+		t.Fatalf("PayLink failed: %v", err)
+	}
+	if result.Request.PayerID == nil || *result.Request.PayerID != payer.UserID {
+		t.Errorf("paid link has payer %v, want %s", result.Request.PayerID, payer.UserID)
+	}
+	if _, err := requests.PayLink(other.UserID, link.Token, ""); !errors.Is(err, ErrPaymentRequestClosed) {
+		t.Errorf("second PayLink = %v, want %v", err, ErrPaymentRequestClosed)
+	}
+	if got := walletBalance(t, db, requester.ID); got.Amount != 1500 {
+		t.Errorf("requester balance = %s, want 15.00", got)
+	}
+}
+
+func TestPayExpiredRequest(t *testing.T) {
+	db := setupTestDB(t)
+	requester := createTestWallet(t, db, "USD")
+	payer := createTestWallet(t, db, "USD")
+	deposit(t, payer, "100.00")
+	request := requestTestPayment(t, db, requester, payer, "20.00")
+	db.Model(&models.PaymentRequest{}).Where("id = ?", request.ID).Update("expires_at", time.Now().Add(-time.Minute))
+
+	if _, err := NewPaymentRequestService().Pay(payer.UserID, request.ID.String(), ""); !errors.Is(err, ErrPaymentRequestExpired) {
+		t.Fatalf("Pay of an expired request = %v, want %v", err, ErrPaymentRequestExpired)
+	}
+	var stored models.PaymentRequest
+	db.First(&stored, "id = ?", request.ID)
+	if stored.Status != models.PaymentRequestStatusExpired {
+		t.Errorf("expired request has status %s, want expired", stored.Status)
+	}
+	if got := walletBalance(t, db, payer.ID); got.Amount != 10000 {
+		t.Errorf("payer balance = %s, want 100.00", got)
+	}
+}
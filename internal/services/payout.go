@@ -0,0 +1,464 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"securewallet/internal/config"
+	"securewallet/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// payoutReturnWindow is how long after settlement a bank can still return a payout
+const payoutReturnWindow = 5 * 24 * time.Hour
+
+// Payout errors
+var (
+	ErrExternalAccountNotFound = errors.New("external account not found")
+	ErrExternalAccountInUse    = errors.New("external account has payouts in progress")
+	ErrPayoutNotFound          = errors.New("payout not found")
+	ErrInvalidPayoutTransition = errors.New("invalid payout status transition")
+)
+
+// payoutTransitions lists the statuses each payout status can move to
+var payoutTransitions = map[string][]string{
+	models.PayoutStatusPending:    {models.PayoutStatusProcessing, models.PayoutStatusFailed},
+	models.PayoutStatusProcessing: {models.PayoutStatusSettled, models.PayoutStatusFailed},
+	models.PayoutStatusSettled:    {models.PayoutStatusReturned},
+}
+
+// PayoutService handles external accounts and withdrawals to them
+type PayoutService struct {
+	db        *gorm.DB
+	ledger    *LedgerService
+	transfers *TransferService
+	wallets   *WalletService
+	holds     *HoldService
+	rail      PayoutRail
+}
+
+// ExternalAccountInput describes a bank account to link
+type ExternalAccountInput struct {
+	Type          string // iban, us_ach
+	HolderName    string
+	BankName      string
+	AccountNumber string // IBAN for iban accounts
+	RoutingNumber string // Only for us_ach accounts
+	Currency      string
+}
+
+// WithdrawalInput describes a withdrawal request
+type WithdrawalInput struct {
+	UserID            uuid.UUID
+	WalletID          string
+	ExternalAccountID string
+	Amount            models.Money
+}
+
+// NewPayoutService creates a new payout service using the simulator rail
+func NewPayoutService() *PayoutService {
+	return &PayoutService{
+		db:        config.GetDB(),
+		ledger:    NewLedgerService(),
+		transfers: NewTransferService(),
+		wallets:   NewWalletService(),
+		holds:     NewHoldService(),
+		rail:      NewSimulatorRail(),
+	}
+}
+
+// AddExternalAccount validates and links a bank account to the user
+func (ps *PayoutService) AddExternalAccount(userID uuid.UUID, input ExternalAccountInput) (*models.ExternalAccount, error) {
+	account := models.ExternalAccount{
+		UserID:     userID,
+		Type:       input.Type,
+		HolderName: strings.TrimSpace(input.HolderName),
+		BankName:   strings.TrimSpace(input.BankName),
+		Currency:   strings.ToUpper(input.Currency),
+	}
+	if !models.IsSupportedCurrency(account.Currency) {
+		return nil, ErrUnsupportedCurrency
+	}
+
+	switch input.Type {
+	case models.ExternalAccountTypeIBAN:
+		iban := NormalizeIBAN(input.AccountNumber)
+		if err := ValidateIBAN(iban); err != nil {
+			return nil, err
+		}
+		account.AccountNumber = iban
+	case models.ExternalAccountTypeUSACH:
+		if err := ValidateRoutingNumber(input.RoutingNumber); err != nil {
+			return nil, err
+		}
+		if err := ValidateAccountNumber(input.AccountNumber); err != nil {
+			return nil, err
+		}
+		if account.Currency != "USD" {
+			return nil, fmt.Errorf("%w: US accounts only accept USD", ErrUnsupportedCurrency)
+		}
+		account.AccountNumber = input.AccountNumber
+		account.RoutingNumber = input.RoutingNumber
+	default:
+		return nil, fmt.Errorf("unsupported account type %q", input.Type)
+	}
+	account.Last4 = account.AccountNumber[len(account.AccountNumber)-4:]
+
+	if err := ps.db.Create(&account).Error; err != nil {
+		return nil, fmt.Errorf("failed to save external account: %w", err)
+	}
+	return &account, nil
+}
+
+// ListExternalAccounts returns the user's linked bank accounts
+func (ps *PayoutService) ListExternalAccounts(userID uuid.UUID) ([]models.ExternalAccount, error) {
+	var accounts []models.ExternalAccount
+	if err := ps.db.Where("user_id = ?", userID).Order("created_at ASC").Find(&accounts).Error; err != nil {
+		return nil, err
+	}
+	return accounts, nil
+}
+
+// RemoveExternalAccount unlinks a bank account that has no payouts in progress
+func (ps *PayoutService) RemoveExternalAccount(userID uuid.UUID, accountID string) error {
+	account, err := ps.externalAccount(userID, accountID)
+	if err != nil {
+		return err
+	}
+
+	var inFlight int64
+	if err := ps.db.Model(&models.Payout{}).
+		Where("external_account_id = ? AND status IN ?", account.ID,
+			[]string{models.PayoutStatusPending, models.PayoutStatusProcessing}).
+		Count(&inFlight).Error; err != nil {
+		return err
+	}
+	if inFlight > 0 {
+		return ErrExternalAccountInUse
+	}
+
+	return ps.db.Delete(account).Error
+}
+
+// RequestWithdrawal places a hold on the wallet and queues a payout. The hold is
+// captured into the payouts-in-transit account when the payout is submitted and
+// stays there until the rail settles it.
+func (ps *PayoutService) RequestWithdrawal(input WithdrawalInput) (*models.Payout, error) {
+	wallet, err := ps.wallets.MemberWallet(input.UserID, input.WalletID, models.WalletRoleSpender)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkOrganizationPayment(wallet); err != nil {
+		return nil, err
+	}
+	account, err := ps.externalAccount(input.UserID, input.ExternalAccountID)
+	if err != nil {
+		return nil, err
+	}
+	if account.Currency != wallet.Currency {
+		return nil, ErrCurrencyMismatch
+	}
+
+	amount, err := input.Amount.In(wallet.Currency)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidAmount, err)
+	}
+	if !amount.IsPositive() {
+		return nil, ErrInvalidAmount
+	}
+
+	var payout models.Payout
+	err = ps.transfers.withRetry(func(tx *gorm.DB) error {
+		wallets, err := ps.transfers.lockWallets(tx, wallet.ID)
+		if err != nil {
+			return err
+		}
+		locked := wallets[wallet.ID]
+
+		if err := checkOrganizationPayment(&locked); err != nil {
+			return err
+		}
+		if err := checkMemberSpend(tx, &locked, input.UserID, amount); err != nil {
+			return err
+		}
+
+		payout = models.Payout{
+			ID:                uuid.New(),
+			UserID:            input.UserID,
+			WalletID:          locked.ID,
+			ExternalAccountID: account.ID,
+			Amount:            amount,
+			Currency:          amount.Currency,
+			Status:            models.PayoutStatusPending,
+			Rail:              ps.rail.Name(),
+		}
+
+		// The money stays in the wallet, reserved, until the payout is submitted
+		hold, err := ps.holds.place(tx, &locked, HoldInput{
+			WalletID:    locked.ID,
+			Amount:      amount,
+			Kind:        models.HoldKindWithdrawal,
+			Reference:   payout.ID.String(),
+			Description: fmt.Sprintf("Withdrawal to account ending %s", account.Last4),
+			PlacedBy:    input.UserID,
+		})
+		if err != nil {
+			return err
+		}
+
+		payout.HoldID = &hold.ID
+		return tx.Create(&payout).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	payout.ExternalAccount = *account
+	return &payout, nil
+}
+
+// ListPayouts returns the user's most recent payouts
+func (ps *PayoutService) ListPayouts(userID uuid.UUID, limit int) ([]models.Payout, error) {
+	var payouts []models.Payout
+	if err := ps.db.Preload("ExternalAccount", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&payouts).Error; err != nil {
+		return nil, err
+	}
+	return payouts, nil
+}
+
+// GetPayout returns one of the user's payouts
+func (ps *PayoutService) GetPayout(userID uuid.UUID, payoutID string) (*models.Payout, error) {
+	id, err := uuid.Parse(payoutID)
+	if err != nil {
+		return nil, ErrPayoutNotFound
+	}
+
+	var payout models.Payout
+	if err := ps.db.Preload("ExternalAccount", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+		Where("id = ? AND user_id = ?", id, userID).
+		First(&payout).Error; err != nil {
+		return nil, ErrPayoutNotFound
+	}
+	return &payout, nil
+}
+
+// ProcessPayouts submits pending payouts to the rail and applies the status
+// the rail reports for payouts in flight. It returns the number of payouts updated.
+func (ps *PayoutService) ProcessPayouts() (int, error) {
+	var payouts []models.Payout
+	if err := ps.db.Preload("ExternalAccount", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+		Where("status IN ? OR (status = ? AND settled_at >= ?)",
+			[]string{models.PayoutStatusPending, models.PayoutStatusProcessing},
+			models.PayoutStatusSettled, time.Now().Add(-payoutReturnWindow)).
+		Order("created_at ASC").
+		Find(&payouts).Error; err != nil {
+		return 0, err
+	}
+
+	updated := 0
+	for i := range payouts {
+		payout := &payouts[i]
+
+		var update *RailUpdate
+		if payout.Status == models.PayoutStatusPending {
+			reference, err := ps.rail.Submit(payout, &payout.ExternalAccount)
+			if err != nil {
+				log.Printf("Failed to submit payout %s: %v", payout.ID, err)
+				continue
+			}
+			payout.RailReference = reference
+			update = &RailUpdate{Status: models.PayoutStatusProcessing}
+		} else {
+			var err error
+			if update, err = ps.rail.Poll(payout); err != nil {
+				log.Printf("Failed to poll payout %s: %v", payout.ID, err)
+				continue
+			}
+		}
+
+		if update.Status == payout.Status {
+			continue
+		}
+		if err := ps.Transition(payout.ID, update.Status, payout.RailReference, update.Reason); err != nil {
+			log.Printf("Failed to move payout %s to %s: %v", payout.ID, update.Status, err)
+			continue
+		}
+		updated++
+	}
+
+	return updated, nil
+}
+
+// Transition moves a payout to a new status and books the matching ledger entry:
+// submitted payouts capture their hold, settled payouts leave the platform, and
+// failed and returned payouts are credited back (or just released if never submitted).
+func (ps *PayoutService) Transition(payoutID uuid.UUID, status, railReference, reason string) error {
+	return ps.transfers.withRetry(func(tx *gorm.DB) error {
+		var payout models.Payout
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&payout, "id = ?", payoutID).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrPayoutNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if !canTransitionPayout(payout.Status, status) {
+			return fmt.Errorf("%w: %s to %s", ErrInvalidPayoutTransition, payout.Status, status)
+		}
+
+		now := time.Now()
+		updates := map[string]interface{}{"status": status}
+		holdStatus := ""
+
+		switch status {
+		case models.PayoutStatusProcessing:
+			if payout.HoldEntryID == nil {
+				entryID, err := ps.captureHold(tx, &payout)
+				if err != nil {
+					return err
+				}
+				updates["hold_entry_id"] = entryID
+			}
+			updates["submitted_at"] = &now
+			updates["rail_reference"] = railReference
+		case models.PayoutStatusSettled:
+			if err := ps.postPayoutEntry(tx, &payout, "PAYOUT_SETTLEMENT", SystemAccountPayoutsInTransit, false); err != nil {
+				return err
+			}
+			updates["settled_at"] = &now
+			holdStatus = "completed"
+		case models.PayoutStatusFailed:
+			if payout.HoldEntryID == nil && payout.HoldID != nil {
+				// Nothing left the wallet yet
+				if _, err := ps.holds.release(tx, *payout.HoldID, models.HoldStatusReleased); err != nil {
+					return err
+				}
+			} else if err := ps.postPayoutEntry(tx, &payout, "WITHDRAWAL_REVERSAL", SystemAccountPayoutsInTransit, true); err != nil {
+				return err
+			}
+			updates["failed_at"] = &now
+			updates["failure_reason"] = reason
+			holdStatus = "failed"
+		case models.PayoutStatusReturned:
+			if err := ps.postPayoutEntry(tx, &payout, "WITHDRAWAL_RETURN", SystemAccountExternalCash, true); err != nil {
+				return err
+			}
+			updates["returned_at"] = &now
+			updates["failure_reason"] = reason
+			holdStatus = "returned"
+		}
+
+		if holdStatus != "" && payout.HoldEntryID != nil {
+			if err := tx.Model(&models.Transaction{}).
+				Where("journal_entry_id = ? AND wallet_id = ?", *payout.HoldEntryID, payout.WalletID).
+				Update("status", holdStatus).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Model(&payout).Updates(updates).Error
+	})
+}
+
+// captureHold books a submitted payout's hold into the payouts-in-transit account.
+// The wallet transaction stays pending until the payout settles.
+func (ps *PayoutService) captureHold(tx *gorm.DB, payout *models.Payout) (uuid.UUID, error) {
+	var account models.ExternalAccount
+	if err := tx.Unscoped().First(&account, "id = ?", payout.ExternalAccountID).Error; err != nil {
+		return uuid.Nil, err
+	}
+	transitAccount, err := ps.ledger.SystemAccount(tx, SystemAccountPayoutsInTransit, payout.Currency)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	_, entry, err := ps.holds.capture(tx, *payout.HoldID, HoldCapture{
+		AccountID:   transitAccount.ID,
+		Type:        "WITHDRAWAL",
+		Description: "Withdrawal to bank account",
+		Memo:        fmt.Sprintf("Withdrawal to account ending %s", account.Last4),
+		InitiatedBy: payout.UserID,
+	})
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	if err := tx.Model(ps.ledger.TransactionFor(entry, payout.WalletID)).Update("status", "pending").Error; err != nil {
+		return uuid.Nil, err
+	}
+	payout.HoldEntryID = &entry.ID
+	return entry.ID, nil
+}
+
+// postPayoutEntry moves the payout amount out of the given system account, either
+// to external cash (settlement) or back into the wallet (failure or return).
+// Credits need no wallet lock because the ledger updates the balance atomically.
+func (ps *PayoutService) postPayoutEntry(tx *gorm.DB, payout *models.Payout, entryType, fromKind string, toWallet bool) error {
+	from, err := ps.ledger.SystemAccount(tx, fromKind, payout.Currency)
+	if err != nil {
+		return err
+	}
+
+	var to *models.LedgerAccount
+	memo := ""
+	if toWallet {
+		var wallet models.Wallet
+		if err := tx.Unscoped().First(&wallet, "id = ?", payout.WalletID).Error; err != nil {
+			return err
+		}
+		if to, err = ps.ledger.WalletAccount(tx, &wallet); err != nil {
+			return err
+		}
+		memo = "Withdrawal returned"
+		if entryType == "WITHDRAWAL_REVERSAL" {
+			memo = "Withdrawal failed"
+		}
+	} else if to, err = ps.ledger.SystemAccount(tx, SystemAccountExternalCash, payout.Currency); err != nil {
+		return err
+	}
+
+	_, err = ps.ledger.PostEntry(tx, LedgerEntry{
+		Type:        entryType,
+		Description: fmt.Sprintf("Payout %s", payout.ID),
+		Reference:   payout.ID.String(),
+		Lines: []LedgerLine{
+			{AccountID: from.ID, Amount: payout.Amount.Neg()},
+			{AccountID: to.ID, Amount: payout.Amount, Memo: memo},
+		},
+	})
+	return err
+}
+
+// canTransitionPayout reports whether a payout may move from one status to another
+func canTransitionPayout(from, to string) bool {
+	for _, allowed := range payoutTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// externalAccount loads one of the user's linked bank accounts
+func (ps *PayoutService) externalAccount(userID uuid.UUID, accountID string) (*models.ExternalAccount, error) {
+	id, err := uuid.Parse(accountID)
+	if err != nil {
+		return nil, ErrExternalAccountNotFound
+	}
+
+	var account models.ExternalAccount
+	if err := ps.db.Where("id = ? AND user_id = ?", id, userID).First(&account).Error; err != nil {
+		return nil, ErrExternalAccountNotFound
+	}
+	return &account, nil
+}
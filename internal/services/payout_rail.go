@@ -0,0 +1,71 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"securewallet/internal/models"
+)
+
+// RailUpdate is the status a payout rail reports for a submitted payout
+type RailUpdate struct {
+	Status string // processing, settled, failed, returned
+	Reason string // Set for failed and returned payouts
+}
+
+// PayoutRail sends payouts to external accounts. Implementations wrap a
+// banking partner (SEPA, ACH, ...) or simulate one.
+type PayoutRail interface {
+	// Name identifies the rail on stored payouts
+	Name() string
+	// Submit hands a payout to the rail and returns the rail's reference
+	Submit(payout *models.Payout, account *models.ExternalAccount) (string, error)
+	// Poll reports the current status of a submitted payout
+	Poll(payout *models.Payout) (*RailUpdate, error)
+}
+
+// SimulatorRail is a local payout rail for development and testing.
+// Payouts settle after SettleAfter. Amounts ending in .13 fail and amounts
+// ending in .66 are returned by the bank ReturnAfter the submission.
+type SimulatorRail struct {
+	SettleAfter time.Duration
+	ReturnAfter time.Duration
+}
+
+// NewSimulatorRail creates a simulator with short settlement times
+func NewSimulatorRail() *SimulatorRail {
+	return &SimulatorRail{
+		SettleAfter: 2 * time.Minute,
+		ReturnAfter: 10 * time.Minute,
+	}
+}
+
+// Name returns the rail name
+func (r *SimulatorRail) Name() string {
+	return "simulator"
+}
+
+// Submit accepts every payout
+func (r *SimulatorRail) Submit(payout *models.Payout, account *models.ExternalAccount) (string, error) {
+	return "SIM-" + strings.ToUpper(payout.ID.String()[:8]), nil
+}
+
+// Poll derives the outcome from the payout amount and its age
+func (r *SimulatorRail) Poll(payout *models.Payout) (*RailUpdate, error) {
+	if payout.SubmittedAt == nil {
+		return nil, fmt.Errorf("payout %s was never submitted", payout.ID)
+	}
+	age := time.Since(*payout.SubmittedAt)
+	cents := payout.Amount.Abs().Amount % 100
+
+	switch {
+	case age < r.SettleAfter:
+		return &RailUpdate{Status: models.PayoutStatusProcessing}, nil
+	case cents == 13:
+		return &RailUpdate{Status: models.PayoutStatusFailed, Reason: "Beneficiary account closed"}, nil
+	case cents == 66 && payout.Status == models.PayoutStatusSettled && age >= r.ReturnAfter:
+		return &RailUpdate{Status: models.PayoutStatusReturned, Reason: "Beneficiary bank returned the payment"}, nil
+	}
+	return &RailUpdate{Status: models.PayoutStatusSettled}, nil
+}
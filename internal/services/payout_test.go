@@ -0,0 +1,195 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"securewallet/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func TestCanTransitionPayout(t *testing.T) {
+	statuses := []string{
+		models.PayoutStatusPending,
+		models.PayoutStatusProcessing,
+		models.PayoutStatusSettled,
+		models.PayoutStatusFailed,
+		models.PayoutStatusReturned,
+	}
+	allowed := map[[2]string]bool{
+		{models.PayoutStatusPending, models.PayoutStatusProcessing}: true,
+		{models.PayoutStatusPending, models.PayoutStatusFailed}:     true,
+		{models.PayoutStatusProcessing, models.PayoutStatusSettled}: true,
+		{models.PayoutStatusProcessing, models.PayoutStatusFailed}:  true,
+		{models.PayoutStatusSettled, models.PayoutStatusReturned}:   true,
+	}
+
+	for _, from := range statuses {
+		for _, to := range statuses {
+			want := allowed[[2]string{from, to}]
+			if got := canTransitionPayout(from, to); got != want {
+				t.Errorf("canTransitionPayout(%s, %s) = %v, want %v", from, to, got, want)
+			}
+		}
+	}
+}
+
+// requestTestWithdrawal links a bank account to the wallet's owner and asks for a payout
+func requestTestWithdrawal(t *testing.T, db *gorm.DB, wallet *models.Wallet, amount string) *models.Payout {
+	t.Helper()
+
+	payouts := NewPayoutService()
+	account, err := payouts.AddExternalAccount(wallet.UserID, ExternalAccountInput{
+		Type:          models.ExternalAccountTypeUSACH,
+		HolderName:    "Test Holder",
+		BankName:      "Test Bank",
+		AccountNumber: "12345678",
+		RoutingNumber: "021000021",
+		Currency:      wallet.Currency,
+	})
+	if err != nil {
+		t.Fatalf("failed to link bank account: %v", err)
+	}
+	payout, err := payouts.RequestWithdrawal(WithdrawalInput{
+		UserID:            wallet.UserID,
+		WalletID:          wallet.ID.String(),
+		ExternalAccountID: account.ID.String(),
+		Amount:            mustMoney(amount, wallet.Currency),
+	})
+	if err != nil {
+		t.Fatalf("RequestWithdrawal failed: %v", err)
+	}
+	return payout
+}
+
+// checkPayoutWallet compares a wallet's balance and held amount, in minor units
+func checkPayoutWallet(t *testing.T, db *gorm.DB, walletID uuid.UUID, step string, balance, held int64) {
+	t.Helper()
+
+	var wallet models.Wallet
+	if err := db.First(&wallet, "id = ?", walletID).Error; err != nil {
+		t.Fatalf("failed to load wallet: %v", err)
+	}
+	if wallet.Balance.Amount != balance || wallet.Held.Amount != held {
+		t.Errorf("%s: balance %s held %s, want %s held %s", step, wallet.Balance, wallet.Held,
+			models.NewMoney(balance, wallet.Currency), models.NewMoney(held, wallet.Currency))
+	}
+}
+
+// systemBalance returns the balance of a system ledger account
+func systemBalance(t *testing.T, db *gorm.DB, kind, currency string) models.Money {
+	t.Helper()
+
+	ledger := NewLedgerService()
+	account, err := ledger.SystemAccount(db, kind, currency)
+	if err != nil {
+		t.Fatalf("failed to load %s account: %v", kind, err)
+	}
+	balance, err := ledger.AccountBalance(db, account)
+	if err != nil {
+		t.Fatalf("failed to load %s balance: %v", kind, err)
+	}
+	return balance
+}
+
+func TestPayoutSettledAndReturned(t *testing.T) {
+	db := setupTestDB(t)
+	wallet := createTestWallet(t, db, "USD")
+	deposit(t, wallet, "100.00")
+	payouts := NewPayoutService()
+
+	payout := requestTestWithdrawal(t, db, wallet, "30.00")
+	if payout.Status != models.PayoutStatusPending || payout.HoldID == nil {
+		t.Fatalf("new payout = %s with hold %v, want pending with a hold", payout.Status, payout.HoldID)
+	}
+	checkPayoutWallet(t, db, wallet.ID, "pending", 10000, 3000)
+
+	// Settling straight from pending skips the submission
+	if err := payouts.Transition(payout.ID, models.PayoutStatusSettled, "", ""); !errors.Is(err, ErrInvalidPayoutTransition) {
+		t.Errorf("pending to settled: error = %v, want %v", err, ErrInvalidPayoutTransition)
+	}
+
+	if err := payouts.Transition(payout.ID, models.PayoutStatusProcessing, "RAIL-1", ""); err != nil {
+		t.Fatalf("pending to processing failed: %v", err)
+	}
+	checkPayoutWallet(t, db, wallet.ID, "processing", 7000, 0)
+	if got := systemBalance(t, db, SystemAccountPayoutsInTransit, "USD"); got.Amount != 3000 {
+		t.Errorf("in transit while processing = %s, want 30.00", got)
+	}
+
+	if err := payouts.Transition(payout.ID, models.PayoutStatusSettled, "RAIL-1", ""); err != nil {
+		t.Fatalf("processing to settled failed: %v", err)
+	}
+	checkPayoutWallet(t, db, wallet.ID, "settled", 7000, 0)
+	if got := systemBalance(t, db, SystemAccountPayoutsInTransit, "USD"); !got.IsZero() {
+		t.Errorf("in transit after settlement = %s, want zero", got)
+	}
+
+	if err := payouts.Transition(payout.ID, models.PayoutStatusFailed, "RAIL-1", "late failure"); !errors.Is(err, ErrInvalidPayoutTransition) {
+		t.Errorf("settled to failed: error = %v, want %v", err, ErrInvalidPayoutTransition)
+	}
+
+	if err := payouts.Transition(payout.ID, models.PayoutStatusReturned, "RAIL-1", "account closed"); err != nil {
+		t.Fatalf("settled to returned failed: %v", err)
+	}
+	checkPayoutWallet(t, db, wallet.ID, "returned", 10000, 0)
+
+	// A returned payout is final
+	for _, status := range []string{models.PayoutStatusReturned, models.PayoutStatusSettled, models.PayoutStatusProcessing} {
+		if err := payouts.Transition(payout.ID, status, "RAIL-1", ""); !errors.Is(err, ErrInvalidPayoutTransition) {
+			t.Errorf("returned to %s: error = %v, want %v", status, err, ErrInvalidPayoutTransition)
+		}
+	}
+	checkPayoutWallet(t, db, wallet.ID, "after final", 10000, 0)
+
+	var stored models.Payout
+	db.First(&stored, "id = ?", payout.ID)
+	if stored.Status != models.PayoutStatusReturned || stored.SettledAt == nil || stored.ReturnedAt == nil {
+		t.Errorf("stored payout = %s settled %v returned %v", stored.Status, stored.SettledAt, stored.ReturnedAt)
+	}
+	if total := postingTotal(t, db, "USD"); !total.IsZero() {
+		t.Errorf("postings sum to %s, want zero", total)
+	}
+}
+
+func TestPayoutFailed(t *testing.T) {
+	db := setupTestDB(t)
+	wallet := createTestWallet(t, db, "USD")
+	deposit(t, wallet, "100.00")
+	payouts := NewPayoutService()
+
+	// Failing before submission only releases the hold
+	pending := requestTestWithdrawal(t, db, wallet, "40.00")
+	if err := payouts.Transition(pending.ID, models.PayoutStatusFailed, "", "rejected"); err != nil {
+		t.Fatalf("pending to failed failed: %v", err)
+	}
+	checkPayoutWallet(t, db, wallet.ID, "failed while pending", 10000, 0)
+	var hold models.Hold
+	db.First(&hold, "id = ?", *pending.HoldID)
+	if hold.Status != models.HoldStatusReleased {
+		t.Errorf("hold of failed payout is %s, want released", hold.Status)
+	}
+
+	// Failing after submission credits the captured amount back
+	submitted := requestTestWithdrawal(t, db, wallet, "25.00")
+	if err := payouts.Transition(submitted.ID, models.PayoutStatusProcessing, "RAIL-2", ""); err != nil {
+		t.Fatalf("pending to processing failed: %v", err)
+	}
+	checkPayoutWallet(t, db, wallet.ID, "processing", 7500, 0)
+	if err := payouts.Transition(submitted.ID, models.PayoutStatusFailed, "RAIL-2", "bounced"); err != nil {
+		t.Fatalf("processing to failed failed: %v", err)
+	}
+	checkPayoutWallet(t, db, wallet.ID, "failed while processing", 10000, 0)
+	if got := systemBalance(t, db, SystemAccountPayoutsInTransit, "USD"); !got.IsZero() {
+		t.Errorf("in transit after failure = %s, want zero", got)
+	}
+
+	if err := payouts.Transition(submitted.ID, models.PayoutStatusReturned, "RAIL-2", ""); !errors.Is(err, ErrInvalidPayoutTransition) {
+		t.Errorf("failed to returned: error = %v, want %v", err, ErrInvalidPayoutTransition)
+	}
+	if err := payouts.Transition(uuid.New(), models.PayoutStatusProcessing, "", ""); !errors.Is(err, ErrPayoutNotFound) {
+		t.Errorf("unknown payout: error = %v, want %v", err, ErrPayoutNotFound)
+	}
+}
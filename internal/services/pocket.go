@@ -0,0 +1,393 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"securewallet/internal/config"
+	"securewallet/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// maxPocketsPerWallet limits how many pockets a wallet can hold
+const maxPocketsPerWallet = 20
+
+// Pocket errors
+var (
+	ErrPocketNotFound = errors.New("pocket not found")
+	ErrInvalidPocket  = errors.New("pocket needs a name of up to 50 characters; target and round-up amounts cannot be negative and the target date must look like 2006-01-02")
+	ErrTooManyPockets = errors.New("wallet already has the maximum number of pockets")
+)
+
+// PocketInput describes a pocket to create or replace. Amounts may be unbound;
+// they are read in the wallet currency.
+type PocketInput struct {
+	Name         string
+	TargetAmount models.Money
+	TargetDate   string // Optional, 2006-01-02
+	RoundUpTo    models.Money
+}
+
+// WalletBalance splits a wallet's money into what can be spent and what is set aside
+type WalletBalance struct {
+	Balance   models.Money `json:"balance"`   // Ledger balance of the wallet itself
+	Held      models.Money `json:"held"`      // Reserved by active holds
+	Available models.Money `json:"available"` // Balance minus held
+	InPockets models.Money `json:"in_pockets"`
+	Total     models.Money `json:"total"`
+}
+
+// PocketService manages pockets and moves money between them and their wallet
+type PocketService struct {
+	db      *gorm.DB
+	ledger  *LedgerService
+	wallets *WalletService
+}
+
+// NewPocketService creates a new pocket service
+func NewPocketService() *PocketService {
+	return &PocketService{
+		db:      config.GetDB(),
+		ledger:  NewLedgerService(),
+		wallets: NewWalletService(),
+	}
+}
+
+// List returns the pockets of one of the user's wallets
+func (ps *PocketService) List(userID uuid.UUID, walletID string) ([]models.Pocket, error) {
+	wallet, err := ps.wallets.UserWallet(userID, walletID)
+	if err != nil {
+		return nil, err
+	}
+
+	pockets := []models.Pocket{}
+	if err := ps.db.Where("wallet_id = ?", wallet.ID).Order("created_at ASC").Find(&pockets).Error; err != nil {
+		return nil, err
+	}
+	return pockets, nil
+}
+
+// Create adds a pocket to one of the user's wallets
+func (ps *PocketService) Create(userID uuid.UUID, walletID string, input PocketInput) (*models.Pocket, error) {
+	wallet, err := ps.wallets.MemberWallet(userID, walletID, models.WalletRoleOwner)
+	if err != nil {
+		return nil, err
+	}
+
+	pocket := models.Pocket{
+		WalletID: wallet.ID,
+		Balance:  models.ZeroMoney(wallet.Currency),
+		Currency: wallet.Currency,
+	}
+	if err := fillPocket(&pocket, input); err != nil {
+		return nil, err
+	}
+
+	err = ps.db.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&models.Pocket{}).Where("wallet_id = ?", wallet.ID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count >= maxPocketsPerWallet {
+			return ErrTooManyPockets
+		}
+		if err := tx.Create(&pocket).Error; err != nil {
+			return fmt.Errorf("failed to create pocket: %w", err)
+		}
+		return clearOtherRoundUps(tx, &pocket)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &pocket, nil
+}
+
+// Update replaces a pocket's name, goal and round-up setting
+func (ps *PocketService) Update(userID uuid.UUID, walletID, pocketID string, input PocketInput) (*models.Pocket, error) {
+	_, pocket, err := ps.pocket(userID, walletID, pocketID, models.WalletRoleOwner)
+	if err != nil {
+		return nil, err
+	}
+	if err := fillPocket(pocket, input); err != nil {
+		return nil, err
+	}
+
+	err = ps.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(pocket).Updates(map[string]interface{}{
+			"name":          pocket.Name,
+			"target_amount": pocket.TargetAmount,
+			"target_date":   pocket.TargetDate,
+			"round_up_to":   pocket.RoundUpTo,
+		}).Error; err != nil {
+			return fmt.Errorf("failed to update pocket: %w", err)
+		}
+		return clearOtherRoundUps(tx, pocket)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pocket, nil
+}
+
+// Delete returns whatever the pocket holds to the wallet and removes the pocket
+func (ps *PocketService) Delete(userID uuid.UUID, walletID, pocketID string) error {
+	wallet, pocket, err := ps.pocket(userID, walletID, pocketID, models.WalletRoleOwner)
+	if err != nil {
+		return err
+	}
+
+	return ps.db.Transaction(func(tx *gorm.DB) error {
+		locked, lockedPocket, err := lockWalletAndPocket(tx, wallet.ID, pocket.ID)
+		if err != nil {
+			return err
+		}
+		if lockedPocket.Balance.IsPositive() {
+			if _, err := ps.move(tx, userID, locked, lockedPocket, lockedPocket.Balance.Neg()); err != nil {
+				return err
+			}
+		}
+		return tx.Delete(lockedPocket).Error
+	})
+}
+
+// AddMoney moves money from the wallet's available balance into a pocket
+func (ps *PocketService) AddMoney(userID uuid.UUID, walletID, pocketID string, amount models.Money) (*models.Pocket, error) {
+	return ps.transfer(userID, walletID, pocketID, amount, false)
+}
+
+// WithdrawMoney moves money from a pocket back to the wallet's available balance
+func (ps *PocketService) WithdrawMoney(userID uuid.UUID, walletID, pocketID string, amount models.Money) (*models.Pocket, error) {
+	return ps.transfer(userID, walletID, pocketID, amount, true)
+}
+
+// Balance returns a wallet's ledger, held and available balances next to the
+// money set aside in its pockets
+func (ps *PocketService) Balance(wallet *models.Wallet) (*WalletBalance, error) {
+	inPockets := models.ZeroMoney(wallet.Currency)
+	if err := ps.db.Model(&models.Pocket{}).
+		Where("wallet_id = ?", wallet.ID).
+		Select("COALESCE(SUM(balance), 0)").
+		Row().Scan(&inPockets); err != nil {
+		return nil, fmt.Errorf("failed to sum pocket balances: %w", err)
+	}
+
+	total, _ := wallet.Balance.Add(inPockets)
+	return &WalletBalance{
+		Balance:   wallet.Balance,
+		Held:      wallet.Held,
+		Available: wallet.Available(),
+		InPockets: inPockets,
+		Total:     total,
+	}, nil
+}
+
+// transfer moves an amount between a wallet and one of its pockets
+func (ps *PocketService) transfer(userID uuid.UUID, walletID, pocketID string, amount models.Money, withdraw bool) (*models.Pocket, error) {
+	wallet, pocket, err := ps.pocket(userID, walletID, pocketID, models.WalletRoleSpender)
+	if err != nil {
+		return nil, err
+	}
+	amount, err = amount.In(wallet.Currency)
+	if err != nil || !amount.IsPositive() {
+		return nil, ErrInvalidAmount
+	}
+
+	var result *models.Pocket
+	err = ps.db.Transaction(func(tx *gorm.DB) error {
+		locked, lockedPocket, err := lockWalletAndPocket(tx, wallet.ID, pocket.ID)
+		if err != nil {
+			return err
+		}
+		if withdraw {
+			amount = amount.Neg()
+		}
+		result, err = ps.move(tx, userID, locked, lockedPocket, amount)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// move posts a positive amount from the wallet into the pocket, or a negative
+// amount from the pocket back to the wallet. Both rows must be locked.
+func (ps *PocketService) move(tx *gorm.DB, userID uuid.UUID, wallet *models.Wallet, pocket *models.Pocket, amount models.Money) (*models.Pocket, error) {
+	if amount.IsPositive() && wallet.Available().Amount < amount.Amount {
+		return nil, &InsufficientFundsError{Balance: wallet.Available(), Required: amount}
+	}
+	if amount.IsNegative() && pocket.Balance.Amount < amount.Abs().Amount {
+		return nil, &InsufficientFundsError{Balance: pocket.Balance, Required: amount.Abs()}
+	}
+
+	walletAccount, err := ps.ledger.WalletAccount(tx, wallet)
+	if err != nil {
+		return nil, err
+	}
+	pocketAccount, err := ps.ledger.PocketAccount(tx, pocket)
+	if err != nil {
+		return nil, err
+	}
+
+	memo := "Moved to " + pocket.Name
+	if amount.IsNegative() {
+		memo = "Moved from " + pocket.Name
+	}
+	if _, err := ps.ledger.PostEntry(tx, LedgerEntry{
+		Type:        "POCKET_TRANSFER",
+		Description: memo,
+		InitiatedBy: initiator(userID),
+		Lines: []LedgerLine{
+			{AccountID: walletAccount.ID, Amount: amount.Neg(), Memo: memo},
+			{AccountID: pocketAccount.ID, Amount: amount, Memo: memo},
+		},
+	}); err != nil {
+		return nil, err
+	}
+
+	if err := tx.First(pocket, "id = ?", pocket.ID).Error; err != nil {
+		return nil, err
+	}
+	return pocket, nil
+}
+
+// pocket loads a pocket of an active wallet on which the user holds at least the given role
+func (ps *PocketService) pocket(userID uuid.UUID, walletID, pocketID, minRole string) (*models.Wallet, *models.Pocket, error) {
+	wallet, err := ps.wallets.MemberWallet(userID, walletID, minRole)
+	if err != nil {
+		return nil, nil, err
+	}
+	id, err := uuid.Parse(pocketID)
+	if err != nil {
+		return nil, nil, ErrPocketNotFound
+	}
+
+	var pocket models.Pocket
+	if err := ps.db.Where("id = ? AND wallet_id = ?", id, wallet.ID).First(&pocket).Error; err != nil {
+		return nil, nil, ErrPocketNotFound
+	}
+	return wallet, &pocket, nil
+}
+
+// sweepRoundUp moves the change of an outgoing transfer into the wallet's
+// round-up pocket. The wallet row must be locked and already debited. Round-ups
+// are skipped when the wallet cannot cover them; the swept amount is returned.
+func sweepRoundUp(tx *gorm.DB, ledger *LedgerService, walletID uuid.UUID, amount models.Money, initiatedBy uuid.UUID) (models.Money, error) {
+	swept := models.ZeroMoney(amount.Currency)
+
+	var pocket models.Pocket
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("wallet_id = ? AND round_up_to > 0", walletID).
+		First(&pocket).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return swept, nil
+	}
+	if err != nil {
+		return swept, err
+	}
+
+	step := pocket.RoundUpTo.Amount
+	change := (step - amount.Amount%step) % step
+	if change == 0 {
+		return swept, nil
+	}
+
+	var wallet models.Wallet
+	if err := tx.First(&wallet, "id = ?", walletID).Error; err != nil {
+		return swept, err
+	}
+	if wallet.Available().Amount < change {
+		return swept, nil
+	}
+
+	walletAccount, err := ledger.WalletAccount(tx, &wallet)
+	if err != nil {
+		return swept, err
+	}
+	pocketAccount, err := ledger.PocketAccount(tx, &pocket)
+	if err != nil {
+		return swept, err
+	}
+	swept = models.NewMoney(change, amount.Currency)
+	memo := "Round-up to " + pocket.Name
+	if _, err := ledger.PostEntry(tx, LedgerEntry{
+		Type:        "ROUND_UP",
+		Description: memo,
+		InitiatedBy: initiator(initiatedBy),
+		Lines: []LedgerLine{
+			{AccountID: walletAccount.ID, Amount: swept.Neg(), Memo: memo},
+			{AccountID: pocketAccount.ID, Amount: swept, Memo: memo},
+		},
+	}); err != nil {
+		return models.ZeroMoney(amount.Currency), err
+	}
+	return swept, nil
+}
+
+// lockWalletAndPocket loads a wallet and then one of its pockets with
+// SELECT ... FOR UPDATE, in the same order as transfers that sweep round-ups
+func lockWalletAndPocket(tx *gorm.DB, walletID, pocketID uuid.UUID) (*models.Wallet, *models.Pocket, error) {
+	var wallet models.Wallet
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&wallet, "id = ?", walletID).Error; err != nil {
+		return nil, nil, ErrWalletNotFound
+	}
+	if wallet.Status == models.WalletStatusClosed {
+		return nil, nil, ErrWalletClosed
+	}
+
+	var pocket models.Pocket
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ? AND wallet_id = ?", pocketID, walletID).
+		First(&pocket).Error; err != nil {
+		return nil, nil, ErrPocketNotFound
+	}
+	return &wallet, &pocket, nil
+}
+
+// clearOtherRoundUps turns round-ups off on the wallet's other pockets, so that
+// each transfer is swept into at most one pocket
+func clearOtherRoundUps(tx *gorm.DB, pocket *models.Pocket) error {
+	if !pocket.RoundUpTo.IsPositive() {
+		return nil
+	}
+	return tx.Model(&models.Pocket{}).
+		Where("wallet_id = ? AND id <> ? AND round_up_to > 0", pocket.WalletID, pocket.ID).
+		Update("round_up_to", models.ZeroMoney(pocket.Currency)).Error
+}
+
+// fillPocket validates a pocket input and copies it onto the pocket
+func fillPocket(pocket *models.Pocket, input PocketInput) error {
+	name := strings.TrimSpace(input.Name)
+	if name == "" || len([]rune(name)) > 50 {
+		return ErrInvalidPocket
+	}
+
+	target, err := input.TargetAmount.In(pocket.Currency)
+	if err != nil || target.IsNegative() {
+		return ErrInvalidPocket
+	}
+	roundUpTo, err := input.RoundUpTo.In(pocket.Currency)
+	if err != nil || roundUpTo.IsNegative() {
+		return ErrInvalidPocket
+	}
+
+	var targetDate *time.Time
+	if input.TargetDate != "" {
+		date, err := time.ParseInLocation(ScheduleDateLayout, input.TargetDate, time.Local)
+		if err != nil {
+			return ErrInvalidPocket
+		}
+		targetDate = &date
+	}
+
+	pocket.Name = name
+	pocket.TargetAmount = target
+	pocket.TargetDate = targetDate
+	pocket.RoundUpTo = roundUpTo
+	return nil
+}
@@ -0,0 +1,148 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+	"time"
+
+	"securewallet/internal/config"
+	"securewallet/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// maxSpreadBasisPoints caps the spread an admin can configure (10%)
+const maxSpreadBasisPoints = 1000
+
+// ratePattern accepts plain decimals that fit the decimal(20,10) rate column.
+// Fractions ("1/3") and exponents ("1e-30") are rejected before conversion.
+var ratePattern = regexp.MustCompile(`^\d{1,10}(\.\d{1,10})?$`)
+
+// Rate errors
+var (
+	ErrRateUnavailable = errors.New("no exchange rate for this currency pair")
+	ErrInvalidRate     = errors.New("invalid exchange rate")
+)
+
+// MarketRate is a mid-market rate for converting Base into Quote
+type MarketRate struct {
+	Base              string
+	Quote             string
+	Mid               *big.Rat // Units of Quote per unit of Base
+	SpreadBasisPoints int64    // Margin charged on top of the mid rate
+	AsOf              time.Time
+}
+
+// RateProvider supplies exchange rates. Implementations may read them from
+// the database, a file or an external market data feed.
+type RateProvider interface {
+	Rate(base, quote string) (*MarketRate, error)
+}
+
+// DBRateProvider serves rates that admins maintain in the exchange_rates table
+type DBRateProvider struct {
+	db *gorm.DB
+}
+
+// NewDBRateProvider creates a new database-backed rate provider
+func NewDBRateProvider() *DBRateProvider {
+	return &DBRateProvider{
+		db: config.GetDB(),
+	}
+}
+
+// ParseRate parses a positive decimal exchange rate such as "1.0850"
+func ParseRate(value string) (*big.Rat, error) {
+	value = strings.TrimSpace(value)
+	if !ratePattern.MatchString(value) {
+		return nil, ErrInvalidRate
+	}
+	rate, ok := new(big.Rat).SetString(value)
+	if !ok || rate.Sign() <= 0 {
+		return nil, ErrInvalidRate
+	}
+	return rate, nil
+}
+
+// Rate returns the rate for a pair. If only the opposite pair is stored its
+// inverse is used.
+func (p *DBRateProvider) Rate(base, quote string) (*MarketRate, error) {
+	var stored models.ExchangeRate
+	err := p.db.Where("base_currency = ? AND quote_currency = ?", base, quote).First(&stored).Error
+	if err == nil {
+		mid, err := ParseRate(stored.Rate)
+		if err != nil {
+			return nil, err
+		}
+		return &MarketRate{Base: base, Quote: quote, Mid: mid, SpreadBasisPoints: stored.SpreadBasisPoints, AsOf: stored.UpdatedAt}, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to load exchange rate: %w", err)
+	}
+
+	err = p.db.Where("base_currency = ? AND quote_currency = ?", quote, base).First(&stored).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrRateUnavailable
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load exchange rate: %w", err)
+	}
+	mid, err := ParseRate(stored.Rate)
+	if err != nil {
+		return nil, err
+	}
+	return &MarketRate{Base: base, Quote: quote, Mid: mid.Inv(mid), SpreadBasisPoints: stored.SpreadBasisPoints, AsOf: stored.UpdatedAt}, nil
+}
+
+// ListRates returns all stored rates
+func (p *DBRateProvider) ListRates() ([]models.ExchangeRate, error) {
+	var rates []models.ExchangeRate
+	if err := p.db.Order("base_currency, quote_currency").Find(&rates).Error; err != nil {
+		return nil, err
+	}
+	return rates, nil
+}
+
+// SetRate creates or updates the rate of a currency pair
+func (p *DBRateProvider) SetRate(base, quote, rate string, spreadBasisPoints int64, updatedBy uuid.UUID) (*models.ExchangeRate, error) {
+	base, quote = strings.ToUpper(base), strings.ToUpper(quote)
+	if !models.IsSupportedCurrency(base) || !models.IsSupportedCurrency(quote) || base == quote {
+		return nil, ErrUnsupportedCurrency
+	}
+	mid, err := ParseRate(rate)
+	if err != nil {
+		return nil, err
+	}
+	if spreadBasisPoints < 0 || spreadBasisPoints > maxSpreadBasisPoints {
+		return nil, fmt.Errorf("spread must be between 0 and %d basis points", maxSpreadBasisPoints)
+	}
+
+	var stored models.ExchangeRate
+	err = p.db.Transaction(func(tx *gorm.DB) error {
+		// Keep a single row per pair: drop the inverse so lookups stay unambiguous
+		if err := tx.Where("base_currency = ? AND quote_currency = ?", quote, base).
+			Delete(&models.ExchangeRate{}).Error; err != nil {
+			return err
+		}
+
+		err := tx.Where("base_currency = ? AND quote_currency = ?", base, quote).First(&stored).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		stored.BaseCurrency = base
+		stored.QuoteCurrency = quote
+		stored.Rate = mid.FloatString(10)
+		stored.SpreadBasisPoints = spreadBasisPoints
+		stored.UpdatedBy = &updatedBy
+		return tx.Save(&stored).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &stored, nil
+}
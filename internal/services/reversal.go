@@ -0,0 +1,312 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"securewallet/internal/config"
+	"securewallet/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Reversal errors
+var (
+	ErrTransactionNotFound = errors.New("transaction not found")
+	ErrNotReversible       = errors.New("only transfers can be refunded, disputed or reversed")
+	ErrNotTransferSide     = errors.New("transaction is on the wrong side of the transfer")
+	ErrRefundTooLarge      = errors.New("refund exceeds the amount that has not been returned yet")
+	ErrNothingToReverse    = errors.New("transfer has already been fully returned")
+)
+
+// ReversalService returns money from completed transfers by posting compensating
+// journal entries. Original entries and their transactions are never changed.
+type ReversalService struct {
+	db        *gorm.DB
+	ledger    *LedgerService
+	transfers *TransferService
+}
+
+// TransferParties describes a posted transfer as read back from its journal entry
+type TransferParties struct {
+	EntryID           uuid.UUID
+	SenderWalletID    uuid.UUID
+	RecipientWalletID uuid.UUID
+	Amount            models.Money // Principal the recipient received
+	Fee               models.Money // Fee the sender paid on top
+}
+
+// NewReversalService creates a new reversal service
+func NewReversalService() *ReversalService {
+	return &ReversalService{
+		db:        config.GetDB(),
+		ledger:    NewLedgerService(),
+		transfers: NewTransferService(),
+	}
+}
+
+// UserTransaction loads a transaction from a wallet the user can spend from
+func (rs *ReversalService) UserTransaction(userID uuid.UUID, transactionID string) (*models.Transaction, error) {
+	id, err := uuid.Parse(transactionID)
+	if err != nil {
+		return nil, ErrTransactionNotFound
+	}
+
+	var transaction models.Transaction
+	if err := rs.db.Where("id = ? AND wallet_id IN (?)", id, memberWallets(rs.db, userID, models.WalletRoleSpender)).
+		First(&transaction).Error; err != nil {
+		return nil, ErrTransactionNotFound
+	}
+	return &transaction, nil
+}
+
+// LoadTransfer reads the parties and amounts of the transfer behind a journal entry
+func (rs *ReversalService) LoadTransfer(tx *gorm.DB, entryID uuid.UUID) (*TransferParties, error) {
+	var entry models.JournalEntry
+	if err := tx.Preload("Postings.Account").First(&entry, "id = ?", entryID).Error; err != nil {
+		return nil, ErrTransactionNotFound
+	}
+	if entry.Type != "TRANSFER" {
+		return nil, ErrNotReversible
+	}
+
+	parties := TransferParties{EntryID: entry.ID}
+	for _, posting := range entry.Postings {
+		switch {
+		case posting.Account.WalletID != nil && posting.Amount.IsPositive():
+			parties.RecipientWalletID = *posting.Account.WalletID
+			parties.Amount = posting.Amount
+		case posting.Account.WalletID != nil:
+			parties.SenderWalletID = *posting.Account.WalletID
+		case posting.Account.Type == models.LedgerAccountTypeSystem && posting.Amount.IsPositive():
+			parties.Fee = posting.Amount
+		}
+	}
+	if parties.SenderWalletID == uuid.Nil || parties.RecipientWalletID == uuid.Nil {
+		return nil, ErrNotReversible
+	}
+	if parties.Fee.Currency == "" {
+		parties.Fee = models.ZeroMoney(parties.Amount.Currency)
+	}
+	return &parties, nil
+}
+
+// Refund lets the recipient of a transfer send part or all of it back to the sender.
+// A nil amount refunds everything that has not been returned yet. The fee is not refunded.
+func (rs *ReversalService) Refund(userID uuid.UUID, transactionID string, amount *models.Money, reason string) (*models.Reversal, error) {
+	transaction, err := rs.UserTransaction(userID, transactionID)
+	if err != nil {
+		return nil, err
+	}
+	if transaction.JournalEntryID == nil {
+		return nil, ErrNotReversible
+	}
+
+	var reversal *models.Reversal
+	err = rs.transfers.withRetry(func(tx *gorm.DB) error {
+		parties, err := rs.LoadTransfer(tx, *transaction.JournalEntryID)
+		if err != nil {
+			return err
+		}
+		if transaction.WalletID != parties.RecipientWalletID {
+			return ErrNotTransferSide
+		}
+
+		refund := parties.Amount
+		if amount != nil {
+			if refund, err = amount.In(parties.Amount.Currency); err != nil {
+				return fmt.Errorf("%w: %v", ErrInvalidAmount, err)
+			}
+			if !refund.IsPositive() {
+				return ErrInvalidAmount
+			}
+		}
+
+		// Both wallets are locked before reading earlier returns, so concurrent refunds serialize
+		wallets, err := rs.transfers.lockWallets(tx, parties.SenderWalletID, parties.RecipientWalletID)
+		if err != nil {
+			return err
+		}
+		recipient := wallets[parties.RecipientWalletID]
+
+		remaining, _, err := rs.remaining(tx, parties)
+		if err != nil {
+			return err
+		}
+		if amount == nil {
+			refund = remaining
+		}
+		if !refund.IsPositive() {
+			return ErrNothingToReverse
+		}
+		if refund.Amount > remaining.Amount {
+			return ErrRefundTooLarge
+		}
+		if recipient.Available().Amount < refund.Amount {
+			return &InsufficientFundsError{Balance: recipient.Available(), Required: refund}
+		}
+		if err := checkOrganizationPayment(&recipient); err != nil {
+			return err
+		}
+		if err := checkMemberSpend(tx, &recipient, userID, refund); err != nil {
+			return err
+		}
+
+		reversal, err = rs.post(tx, wallets, parties, models.ReversalKindRefund, refund, models.ZeroMoney(refund.Currency), reason, userID, nil)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return reversal, nil
+}
+
+// ReverseTransaction fully reverses the transfer behind a transaction, as an admin decision
+func (rs *ReversalService) ReverseTransaction(transactionID string, adminID uuid.UUID, reason string) (*models.Reversal, error) {
+	id, err := uuid.Parse(transactionID)
+	if err != nil {
+		return nil, ErrTransactionNotFound
+	}
+	var transaction models.Transaction
+	if err := rs.db.First(&transaction, "id = ?", id).Error; err != nil {
+		return nil, ErrTransactionNotFound
+	}
+	if transaction.JournalEntryID == nil {
+		return nil, ErrNotReversible
+	}
+
+	var reversal *models.Reversal
+	err = rs.transfers.withRetry(func(tx *gorm.DB) error {
+		var err error
+		reversal, err = rs.Reverse(tx, *transaction.JournalEntryID, adminID, reason, nil)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return reversal, nil
+}
+
+// Reverse returns whatever is left of a transfer to the sender inside an open
+// transaction: the principal not yet refunded comes back from the recipient and
+// the fee comes back from fee revenue
+func (rs *ReversalService) Reverse(tx *gorm.DB, entryID, initiatedBy uuid.UUID, reason string, disputeID *uuid.UUID) (*models.Reversal, error) {
+	parties, err := rs.LoadTransfer(tx, entryID)
+	if err != nil {
+		return nil, err
+	}
+
+	wallets, err := rs.transfers.lockWallets(tx, parties.SenderWalletID, parties.RecipientWalletID)
+	if err != nil {
+		return nil, err
+	}
+	recipient := wallets[parties.RecipientWalletID]
+
+	principal, fee, err := rs.remaining(tx, parties)
+	if err != nil {
+		return nil, err
+	}
+	if principal.IsZero() && fee.IsZero() {
+		return nil, ErrNothingToReverse
+	}
+	if recipient.Available().Amount < principal.Amount {
+		return nil, &InsufficientFundsError{Balance: recipient.Available(), Required: principal}
+	}
+
+	return rs.post(tx, wallets, parties, models.ReversalKindReversal, principal, fee, reason, initiatedBy, disputeID)
+}
+
+// Reversals lists the refunds and reversals booked against a transfer
+func (rs *ReversalService) Reversals(entryID uuid.UUID) ([]models.Reversal, error) {
+	var reversals []models.Reversal
+	if err := rs.db.Where("original_entry_id = ?", entryID).Order("created_at ASC").Find(&reversals).Error; err != nil {
+		return nil, err
+	}
+	return reversals, nil
+}
+
+// remaining returns the principal and fee of a transfer that have not been returned yet
+func (rs *ReversalService) remaining(tx *gorm.DB, parties *TransferParties) (models.Money, models.Money, error) {
+	returned := models.ZeroMoney(parties.Amount.Currency)
+	feeReturned := models.ZeroMoney(parties.Amount.Currency)
+	if err := tx.Model(&models.Reversal{}).
+		Where("original_entry_id = ?", parties.EntryID).
+		Select("COALESCE(SUM(amount), 0), COALESCE(SUM(fee_refunded), 0)").
+		Row().Scan(&returned, &feeReturned); err != nil {
+		return models.Money{}, models.Money{}, fmt.Errorf("failed to sum returns: %w", err)
+	}
+
+	principal, _ := parties.Amount.Sub(returned)
+	fee, _ := parties.Fee.Sub(feeReturned)
+	return principal, fee, nil
+}
+
+// post books the compensating entry for a refund or reversal and records it
+func (rs *ReversalService) post(tx *gorm.DB, wallets map[uuid.UUID]models.Wallet, parties *TransferParties, kind string, principal, fee models.Money, reason string, initiatedBy uuid.UUID, disputeID *uuid.UUID) (*models.Reversal, error) {
+	sender := wallets[parties.SenderWalletID]
+	recipient := wallets[parties.RecipientWalletID]
+
+	senderAccount, err := rs.ledger.WalletAccount(tx, &sender)
+	if err != nil {
+		return nil, err
+	}
+	recipientAccount, err := rs.ledger.WalletAccount(tx, &recipient)
+	if err != nil {
+		return nil, err
+	}
+
+	entryType, label := "REFUND", "Refund"
+	if kind == models.ReversalKindReversal {
+		entryType, label = "REVERSAL", "Reversal"
+	}
+	memo := label
+	if reason != "" {
+		memo = label + ": " + reason
+	}
+
+	var lines []LedgerLine
+	if principal.IsPositive() {
+		lines = append(lines,
+			LedgerLine{AccountID: recipientAccount.ID, Amount: principal.Neg(), Memo: memo},
+			LedgerLine{AccountID: senderAccount.ID, Amount: principal, Memo: memo},
+		)
+	}
+	if fee.IsPositive() {
+		feeAccount, err := rs.ledger.SystemAccount(tx, SystemAccountFeeRevenue, fee.Currency)
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines,
+			LedgerLine{AccountID: feeAccount.ID, Amount: fee.Neg(), Memo: "Transfer fee refund"},
+			LedgerLine{AccountID: senderAccount.ID, Amount: fee, Memo: memo},
+		)
+	}
+
+	entry, err := rs.ledger.PostEntry(tx, LedgerEntry{
+		Type:        entryType,
+		Description: memo,
+		Reference:   parties.EntryID.String(),
+		InitiatedBy: initiator(initiatedBy),
+		Lines:       lines,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	reversal := models.Reversal{
+		Kind:            kind,
+		OriginalEntryID: parties.EntryID,
+		EntryID:         entry.ID,
+		Amount:          principal,
+		FeeRefunded:     fee,
+		Currency:        principal.Currency,
+		Reason:          reason,
+		InitiatedBy:     initiatedBy,
+		DisputeID:       disputeID,
+	}
+	if err := tx.Create(&reversal).Error; err != nil {
+		return nil, fmt.Errorf("failed to record %s: %w", kind, err)
+	}
+	return &reversal, nil
+}
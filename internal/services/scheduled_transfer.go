@@ -0,0 +1,522 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"securewallet/internal/config"
+	"securewallet/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ScheduleDateLayout is the format of schedule start and end dates (interpreted as UTC)
+const ScheduleDateLayout = "2006-01-02"
+
+// scheduledTransferRetryDelays is the retry policy for a failed occurrence. Once every
+// delay has been used the occurrence is skipped, or a one-off transfer is marked failed.
+var scheduledTransferRetryDelays = []time.Duration{15 * time.Minute, time.Hour, 6 * time.Hour}
+
+// scheduledTransferClaimTimeout releases transfers left in processing by an executor that crashed
+const scheduledTransferClaimTimeout = 10 * time.Minute
+
+// maxScheduledTransfersPerRun bounds how many transfers one executor run picks up
+const maxScheduledTransfersPerRun = 500
+
+// Scheduled transfer errors
+var (
+	ErrScheduledTransferNotFound = errors.New("scheduled transfer not found")
+	ErrScheduledTransferState    = errors.New("scheduled transfer cannot be changed in its current status")
+	ErrInvalidSchedule           = errors.New("invalid schedule")
+)
+
+// ScheduledTransferService manages standing orders and executes the ones that are due
+type ScheduledTransferService struct {
+	db        *gorm.DB
+	transfers *TransferService
+	wallets   *WalletService
+}
+
+// ScheduledTransferInput describes a new scheduled transfer
+type ScheduledTransferInput struct {
+	UserID         uuid.UUID
+	WalletID       string // Defaults to the user's default wallet
+	Recipient      string // Email or username
+	Amount         models.Money
+	Description    string
+	Frequency      string
+	StartDate      string // YYYY-MM-DD
+	EndDate        string // Optional, YYYY-MM-DD
+	MaxOccurrences *int   // Optional
+}
+
+// NewScheduledTransferService creates a new scheduled transfer service
+func NewScheduledTransferService() *ScheduledTransferService {
+	return &ScheduledTransferService{
+		db:        config.GetDB(),
+		transfers: NewTransferService(),
+		wallets:   NewWalletService(),
+	}
+}
+
+// Create validates and saves a scheduled transfer. The recipient is checked now
+// and again on every run, since their wallets may change in between.
+func (ss *ScheduledTransferService) Create(input ScheduledTransferInput) (*models.ScheduledTransfer, error) {
+	wallet, err := ss.wallets.MemberWallet(input.UserID, input.WalletID, models.WalletRoleSpender)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkOrganizationPayment(wallet); err != nil {
+		return nil, err
+	}
+
+	amount, err := input.Amount.In(wallet.Currency)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidAmount, err)
+	}
+	if !amount.IsPositive() {
+		return nil, ErrInvalidAmount
+	}
+
+	switch input.Frequency {
+	case models.ScheduleFrequencyOnce, models.ScheduleFrequencyWeekly,
+		models.ScheduleFrequencyMonthly, models.ScheduleFrequencyMonthlyLastBusiness:
+	default:
+		return nil, fmt.Errorf("%w: unknown frequency %q", ErrInvalidSchedule, input.Frequency)
+	}
+
+	start, err := time.Parse(ScheduleDateLayout, input.StartDate)
+	if err != nil {
+		return nil, fmt.Errorf("%w: start date must be YYYY-MM-DD", ErrInvalidSchedule)
+	}
+	if start.Before(startOfDay(time.Now())) {
+		return nil, fmt.Errorf("%w: start date is in the past", ErrInvalidSchedule)
+	}
+
+	scheduled := models.ScheduledTransfer{
+		UserID:         input.UserID,
+		WalletID:       wallet.ID,
+		Amount:         amount,
+		Currency:       amount.Currency,
+		Description:    input.Description,
+		Frequency:      input.Frequency,
+		StartDate:      start,
+		MaxOccurrences: input.MaxOccurrences,
+		Status:         models.ScheduledTransferStatusActive,
+	}
+	if input.Frequency == models.ScheduleFrequencyMonthly {
+		scheduled.DayOfMonth = start.Day()
+	}
+	if input.EndDate != "" {
+		end, err := time.Parse(ScheduleDateLayout, input.EndDate)
+		if err != nil {
+			return nil, fmt.Errorf("%w: end date must be YYYY-MM-DD", ErrInvalidSchedule)
+		}
+		scheduled.EndDate = &end
+	}
+	if input.MaxOccurrences != nil && *input.MaxOccurrences < 1 {
+		return nil, fmt.Errorf("%w: max occurrences must be at least 1", ErrInvalidSchedule)
+	}
+
+	first := firstOccurrence(&scheduled)
+	if scheduled.EndDate != nil && first.After(*scheduled.EndDate) {
+		return nil, fmt.Errorf("%w: no occurrence falls before the end date", ErrInvalidSchedule)
+	}
+	scheduled.NextRunAt = &first
+
+	recipient, err := ss.wallets.FindRecipient(input.Recipient)
+	if err != nil {
+		return nil, err
+	}
+	recipientWallet, err := ss.wallets.RecipientWallet(recipient.ID, wallet.Currency)
+	if err != nil {
+		return nil, err
+	}
+	if recipientWallet.ID == wallet.ID {
+		return nil, ErrSameWallet
+	}
+	scheduled.RecipientUserID = recipient.ID
+	scheduled.RecipientEmail = recipient.Email
+
+	if err := ss.db.Create(&scheduled).Error; err != nil {
+		return nil, fmt.Errorf("failed to save scheduled transfer: %w", err)
+	}
+	return &scheduled, nil
+}
+
+// List returns the user's scheduled transfers, most recent first
+func (ss *ScheduledTransferService) List(userID uuid.UUID) ([]models.ScheduledTransfer, error) {
+	var scheduled []models.ScheduledTransfer
+	if err := ss.db.Where("user_id = ?", userID).Order("created_at DESC").Find(&scheduled).Error; err != nil {
+		return nil, err
+	}
+	return scheduled, nil
+}
+
+// Get returns one of the user's scheduled transfers with its most recent runs
+func (ss *ScheduledTransferService) Get(userID uuid.UUID, scheduledID string) (*models.ScheduledTransfer, error) {
+	id, err := uuid.Parse(scheduledID)
+	if err != nil {
+		return nil, ErrScheduledTransferNotFound
+	}
+
+	var scheduled models.ScheduledTransfer
+	if err := ss.db.Preload("Runs", func(db *gorm.DB) *gorm.DB { return db.Order("created_at DESC").Limit(20) }).
+		Where("id = ? AND user_id = ?", id, userID).
+		First(&scheduled).Error; err != nil {
+		return nil, ErrScheduledTransferNotFound
+	}
+	return &scheduled, nil
+}
+
+// Pause stops an active scheduled transfer until it is resumed
+func (ss *ScheduledTransferService) Pause(userID uuid.UUID, scheduledID string) (*models.ScheduledTransfer, error) {
+	scheduled, err := ss.Get(userID, scheduledID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The status condition keeps this from racing with the executor claiming the transfer
+	result := ss.db.Model(&models.ScheduledTransfer{}).
+		Where("id = ? AND status = ?", scheduled.ID, models.ScheduledTransferStatusActive).
+		Updates(map[string]interface{}{"status": models.ScheduledTransferStatusPaused, "retry_at": nil, "attempts": 0})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrScheduledTransferState
+	}
+
+	return ss.Get(userID, scheduledID)
+}
+
+// Resume restarts a paused or failed scheduled transfer. Occurrences that fell
+// due while it was stopped are skipped rather than sent all at once.
+func (ss *ScheduledTransferService) Resume(userID uuid.UUID, scheduledID string) (*models.ScheduledTransfer, error) {
+	scheduled, err := ss.Get(userID, scheduledID)
+	if err != nil {
+		return nil, err
+	}
+	if scheduled.Status != models.ScheduledTransferStatusPaused && scheduled.Status != models.ScheduledTransferStatusFailed {
+		return nil, ErrScheduledTransferState
+	}
+
+	updates := map[string]interface{}{"retry_at": nil, "attempts": 0, "last_error": ""}
+	today := startOfDay(time.Now())
+	if scheduled.Frequency == models.ScheduleFrequencyOnce {
+		if scheduled.NextRunAt == nil || scheduled.NextRunAt.Before(today) {
+			updates["next_run_at"] = today
+		}
+		updates["status"] = models.ScheduledTransferStatusActive
+	} else if scheduled.NextRunAt != nil && scheduled.NextRunAt.Before(today) {
+		advanceSchedule(scheduled, scheduled.Occurrences, today, updates)
+	} else {
+		updates["status"] = models.ScheduledTransferStatusActive
+	}
+
+	result := ss.db.Model(&models.ScheduledTransfer{}).
+		Where("id = ? AND status = ?", scheduled.ID, scheduled.Status).
+		Updates(updates)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrScheduledTransferState
+	}
+
+	return ss.Get(userID, scheduledID)
+}
+
+// Cancel permanently stops a scheduled transfer
+func (ss *ScheduledTransferService) Cancel(userID uuid.UUID, scheduledID string) (*models.ScheduledTransfer, error) {
+	scheduled, err := ss.Get(userID, scheduledID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := ss.db.Model(&models.ScheduledTransfer{}).
+		Where("id = ? AND status IN ?", scheduled.ID, []string{
+			models.ScheduledTransferStatusActive,
+			models.ScheduledTransferStatusPaused,
+			models.ScheduledTransferStatusFailed,
+		}).
+		Updates(map[string]interface{}{"status": models.ScheduledTransferStatusCancelled, "next_run_at": nil, "retry_at": nil})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrScheduledTransferState
+	}
+
+	return ss.Get(userID, scheduledID)
+}
+
+// RunDue executes every scheduled transfer whose occurrence or retry is due.
+// It returns the number of transfers that were attempted.
+func (ss *ScheduledTransferService) RunDue(now time.Time) (int, error) {
+	// Release transfers claimed by an executor that never finished
+	if err := ss.db.Model(&models.ScheduledTransfer{}).
+		Where("status = ? AND updated_at < ?", models.ScheduledTransferStatusProcessing, now.Add(-scheduledTransferClaimTimeout)).
+		Update("status", models.ScheduledTransferStatusActive).Error; err != nil {
+		return 0, err
+	}
+
+	var due []models.ScheduledTransfer
+	if err := ss.db.Where("status = ? AND ((retry_at IS NULL AND next_run_at <= ?) OR retry_at <= ?)",
+		models.ScheduledTransferStatusActive, now, now).
+		Order("next_run_at ASC").
+		Limit(maxScheduledTransfersPerRun).
+		Find(&due).Error; err != nil {
+		return 0, err
+	}
+
+	attempted := 0
+	for i := range due {
+		scheduled := &due[i]
+
+		// Claim the transfer so that a concurrent executor or a pause cannot interfere
+		claim := ss.db.Model(&models.ScheduledTransfer{}).
+			Where("id = ? AND status = ?", scheduled.ID, models.ScheduledTransferStatusActive).
+			Update("status", models.ScheduledTransferStatusProcessing)
+		if claim.Error != nil {
+			log.Printf("Failed to claim scheduled transfer %s: %v", scheduled.ID, claim.Error)
+			continue
+		}
+		if claim.RowsAffected == 0 {
+			continue
+		}
+
+		if err := ss.run(scheduled, now); err != nil {
+			log.Printf("Failed to record run of scheduled transfer %s: %v", scheduled.ID, err)
+			continue
+		}
+		attempted++
+	}
+
+	return attempted, nil
+}
+
+// run sends one occurrence of a claimed scheduled transfer and records the
+// outcome. A successful transfer is posted in the same transaction that records
+// the run and advances the schedule, so an occurrence is never paid without
+// the schedule moving on, and never paid twice.
+func (ss *ScheduledTransferService) run(scheduled *models.ScheduledTransfer, now time.Time) error {
+	attempt := scheduled.Attempts + 1
+	scheduledFor := *scheduled.NextRunAt
+
+	var sendErr error
+	err := ss.transfers.withRetry(func(tx *gorm.DB) error {
+		sendErr = nil
+		run := models.ScheduledTransferRun{
+			ScheduledTransferID: scheduled.ID,
+			ScheduledFor:        scheduledFor,
+			SucceededFor:        &scheduledFor,
+			Attempt:             attempt,
+			Status:              models.ScheduledRunStatusSucceeded,
+		}
+		updates := map[string]interface{}{
+			"last_run_at": now,
+			"occurrences": scheduled.Occurrences + 1,
+			"attempts":    0,
+			"retry_at":    nil,
+			"last_error":  "",
+		}
+
+		// An occurrence already paid only needs the schedule moved past it
+		var paid models.ScheduledTransferRun
+		err := tx.Where("scheduled_transfer_id = ? AND succeeded_for = ?", scheduled.ID, scheduledFor).First(&paid).Error
+		if err == nil {
+			delete(updates, "occurrences")
+			advanceSchedule(scheduled, scheduled.Occurrences, startOfDay(now), updates)
+			return tx.Model(scheduled).Updates(updates).Error
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		entryID, err := ss.send(tx, scheduled)
+		if err != nil {
+			sendErr = err
+			return err
+		}
+		run.JournalEntryID = &entryID
+		advanceSchedule(scheduled, scheduled.Occurrences+1, startOfDay(now), updates)
+		if err := tx.Create(&run).Error; err != nil {
+			return err
+		}
+		return tx.Model(scheduled).Updates(updates).Error
+	})
+	if sendErr == nil {
+		// Either the occurrence was paid, or recording it failed and rolled the
+		// transfer back; the claim then times out and the occurrence runs again
+		return err
+	}
+
+	// The transfer rolled back, so the failed attempt is recorded on its own
+	err = sendErr
+	run := models.ScheduledTransferRun{
+		ScheduledTransferID: scheduled.ID,
+		ScheduledFor:        scheduledFor,
+		Attempt:             attempt,
+	}
+	updates := map[string]interface{}{"last_run_at": now}
+	reason := err.Error()
+	if len(reason) > 255 {
+		reason = reason[:255]
+	}
+	run.Status = models.ScheduledRunStatusFailed
+	run.Error = reason
+	updates["last_error"] = reason
+
+	switch {
+	case !isRetryableScheduleError(err):
+		updates["status"] = models.ScheduledTransferStatusFailed
+		updates["retry_at"] = nil
+	case attempt <= len(scheduledTransferRetryDelays):
+		updates["status"] = models.ScheduledTransferStatusActive
+		updates["attempts"] = attempt
+		updates["retry_at"] = now.Add(scheduledTransferRetryDelays[attempt-1])
+	default:
+		// Retries are exhausted: give up on this occurrence
+		run.Status = models.ScheduledRunStatusSkipped
+		updates["attempts"] = 0
+		updates["retry_at"] = nil
+		advanceSchedule(scheduled, scheduled.Occurrences, startOfDay(now), updates)
+		if scheduled.Frequency == models.ScheduleFrequencyOnce {
+			updates["status"] = models.ScheduledTransferStatusFailed
+		}
+	}
+	log.Printf("Scheduled transfer %s attempt %d failed: %v", scheduled.ID, attempt, err)
+
+	return ss.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&run).Error; err != nil {
+			return err
+		}
+		return tx.Model(scheduled).Updates(updates).Error
+	})
+}
+
+// send runs the transfer through the normal transfer path inside the caller's
+// transaction, so fees, limits and balance checks apply exactly as for a
+// transfer made by hand
+func (ss *ScheduledTransferService) send(tx *gorm.DB, scheduled *models.ScheduledTransfer) (uuid.UUID, error) {
+	var sender, recipient models.User
+	if err := tx.First(&sender, "id = ?", scheduled.UserID).Error; err != nil {
+		return uuid.Nil, err
+	}
+	if err := tx.First(&recipient, "id = ?", scheduled.RecipientUserID).Error; err != nil {
+		return uuid.Nil, ErrRecipientNotFound
+	}
+	recipientWallet, err := ss.wallets.RecipientWallet(recipient.ID, scheduled.Currency)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	result, err := ss.transfers.transfer(tx, TransferInput{
+		SenderWalletID:    scheduled.WalletID,
+		RecipientWalletID: recipientWallet.ID,
+		Amount:            scheduled.Amount,
+		Description:       scheduled.Description,
+		SenderMemo:        scheduled.Description + " (scheduled, to " + recipient.Username + ")",
+		RecipientMemo:     scheduled.Description + " (from " + sender.Username + ")",
+		InitiatedBy:       scheduled.UserID,
+		feeType:           models.FeeTypeScheduledTransfer,
+	})
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return result.Entry.ID, nil
+}
+
+// isRetryableScheduleError reports whether a failed run may succeed later, e.g.
+// after a deposit. Missing or closed wallets need the user to fix the schedule.
+func isRetryableScheduleError(err error) bool {
+	for _, permanent := range []error{
+		ErrWalletNotFound, ErrWalletClosed, ErrNoRecipientWallet, ErrRecipientNotFound,
+		ErrCurrencyMismatch, ErrSameWallet, ErrInvalidAmount,
+	} {
+		if errors.Is(err, permanent) {
+			return false
+		}
+	}
+	return true
+}
+
+// advanceSchedule moves the schedule past its current occurrence, skipping dates
+// before today, and completes it once the end condition is reached
+func advanceSchedule(scheduled *models.ScheduledTransfer, occurrences int, today time.Time, updates map[string]interface{}) {
+	next, ok := nextOccurrence(scheduled, *scheduled.NextRunAt)
+	for ok && next.Before(today) {
+		next, ok = nextOccurrence(scheduled, next)
+	}
+	if ok && scheduled.EndDate != nil && next.After(*scheduled.EndDate) {
+		ok = false
+	}
+	if ok && scheduled.MaxOccurrences != nil && occurrences >= *scheduled.MaxOccurrences {
+		ok = false
+	}
+
+	if !ok {
+		updates["status"] = models.ScheduledTransferStatusCompleted
+		updates["next_run_at"] = nil
+		return
+	}
+	updates["status"] = models.ScheduledTransferStatusActive
+	updates["next_run_at"] = next
+}
+
+// firstOccurrence returns the first date on or after the start date the transfer runs on
+func firstOccurrence(scheduled *models.ScheduledTransfer) time.Time {
+	start := scheduled.StartDate
+	if scheduled.Frequency == models.ScheduleFrequencyMonthlyLastBusiness {
+		if last := lastBusinessDay(start.Year(), start.Month()); !last.Before(start) {
+			return last
+		}
+		return lastBusinessDay(start.Year(), start.Month()+1)
+	}
+	return start
+}
+
+// nextOccurrence returns the occurrence following the given one, or false for one-off transfers
+func nextOccurrence(scheduled *models.ScheduledTransfer, previous time.Time) (time.Time, bool) {
+	switch scheduled.Frequency {
+	case models.ScheduleFrequencyWeekly:
+		return previous.AddDate(0, 0, 7), true
+	case models.ScheduleFrequencyMonthly:
+		return dayOfMonth(previous.Year(), previous.Month()+1, scheduled.DayOfMonth), true
+	case models.ScheduleFrequencyMonthlyLastBusiness:
+		return lastBusinessDay(previous.Year(), previous.Month()+1), true
+	default:
+		return time.Time{}, false
+	}
+}
+
+// dayOfMonth returns the given day of a month, clamped to the month's last day
+// so that a transfer on the 31st runs on the 30th or 28th in shorter months
+func dayOfMonth(year int, month time.Month, day int) time.Time {
+	// Normalize month overflow (e.g. month 13) before clamping
+	first := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	last := first.AddDate(0, 1, -1).Day()
+	if day > last {
+		day = last
+	}
+	return first.AddDate(0, 0, day-1)
+}
+
+// lastBusinessDay returns the last weekday of a month. Bank holidays are not taken into account.
+func lastBusinessDay(year int, month time.Month) time.Time {
+	day := time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC)
+	for day.Weekday() == time.Saturday || day.Weekday() == time.Sunday {
+		day = day.AddDate(0, 0, -1)
+	}
+	return day
+}
+
+// startOfDay truncates a time to midnight UTC
+func startOfDay(t time.Time) time.Time {
+	year, month, day := t.UTC().Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
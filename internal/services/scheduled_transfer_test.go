@@ -0,0 +1,156 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"securewallet/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// scheduleTestTransfer schedules a transfer of amount between two new wallets starting today
+func scheduleTestTransfer(t *testing.T, db *gorm.DB, amount, frequency string, maxOccurrences int) (*models.ScheduledTransfer, *models.Wallet, *models.Wallet) {
+	t.Helper()
+
+	sender := createTestWallet(t, db, "USD")
+	recipient := createTestWallet(t, db, "USD")
+	var recipientUser models.User
+	db.First(&recipientUser, "id = ?", recipient.UserID)
+
+	scheduled, err := NewScheduledTransferService().Create(ScheduledTransferInput{
+		UserID:         sender.UserID,
+		Recipient:      recipientUser.Email,
+		Amount:         mustMoney(amount, "USD"),
+		Description:    "Rent",
+		Frequency:      frequency,
+		StartDate:      time.Now().UTC().Format(ScheduleDateLayout),
+		MaxOccurrences: &maxOccurrences,
+	})
+	if err != nil {
+		t.Fatalf("failed to schedule transfer: %v", err)
+	}
+	return scheduled, sender, recipient
+}
+
+// loadScheduled reloads a scheduled transfer
+func loadScheduled(t *testing.T, db *gorm.DB, scheduled *models.ScheduledTransfer) models.ScheduledTransfer {
+	t.Helper()
+
+	var stored models.ScheduledTransfer
+	if err := db.First(&stored, "id = ?", scheduled.ID).Error; err != nil {
+		t.Fatalf("failed to load scheduled transfer: %v", err)
+	}
+	return stored
+}
+
+func TestScheduledTransferPaysEachOccurrenceOnce(t *testing.T) {
+	db := setupTestDB(t)
+	scheduled, sender, recipient := scheduleTestTransfer(t, db, "10.00", models.ScheduleFrequencyWeekly, 2)
+	deposit(t, sender, "100.00")
+	executor := NewScheduledTransferService()
+	start := *scheduled.NextRunAt
+
+	if attempted, err := executor.RunDue(time.Now()); err != nil || attempted != 1 {
+		t.Fatalf("RunDue = %d, %v, want 1 attempted", attempted, err)
+	}
+	if attempted, err := executor.RunDue(time.Now()); err != nil || attempted != 0 {
+		t.Errorf("RunDue before the next occurrence = %d, %v, want nothing attempted", attempted, err)
+	}
+	stored := loadScheduled(t, db, scheduled)
+	if stored.Occurrences != 1 || stored.NextRunAt == nil || !stored.NextRunAt.Equal(start.AddDate(0, 0, 7)) {
+		t.Errorf("after the first run: %d occurrences, next run %v, want 1 and %v", stored.Occurrences, stored.NextRunAt, start.AddDate(0, 0, 7))
+	}
+
+	// An occurrence that was already paid only moves the schedule on
+	db.Model(&models.ScheduledTransfer{}).Where("id = ?", scheduled.ID).Update("next_run_at", start)
+	if attempted, err := executor.RunDue(time.Now()); err != nil || attempted != 1 {
+		t.Fatalf("RunDue of a paid occurrence = %d, %v, want 1 attempted", attempted, err)
+	}
+	if got := walletBalance(t, db, recipient.ID); got.Amount != 1000 {
+		t.Errorf("recipient balance = %s, want 10.00", got)
+	}
+
+	if attempted, err := executor.RunDue(start.AddDate(0, 0, 7).Add(time.Hour)); err != nil || attempted != 1 {
+		t.Fatalf("RunDue of the second occurrence = %d, %v, want 1 attempted", attempted, err)
+	}
+	stored = loadScheduled(t, db, scheduled)
+	if stored.Status != models.ScheduledTransferStatusCompleted || stored.Occurrences != 2 || stored.NextRunAt != nil {
+		t.Errorf("after the last run: status %s, %d occurrences, next run %v, want completed, 2, none",
+			stored.Status, stored.Occurrences, stored.NextRunAt)
+	}
+	if got := walletBalance(t, db, recipient.ID); got.Amount != 2000 {
+		t.Errorf("recipient balance = %s, want 20.00", got)
+	}
+	var runs int64
+	db.Model(&models.ScheduledTransferRun{}).Where("scheduled_transfer_id = ?", scheduled.ID).Count(&runs)
+	if runs != 2 {
+		t.Errorf("recorded %d runs, want 2", runs)
+	}
+	if total := postingTotal(t, db, "USD"); !total.IsZero() {
+		t.Errorf("postings sum to %s, want zero", total)
+	}
+}
+
+func TestScheduledTransferRetriesFailedOccurrence(t *testing.T) {
+	db := setupTestDB(t)
+	scheduled, sender, recipient := scheduleTestTransfer(t, db, "10.00", models.ScheduleFrequencyOnce, 1)
+	deposit(t, sender, "5.00")
+	executor := NewScheduledTransferService()
+	now := time.Now()
+
+	if attempted, err := executor.RunDue(now); err != nil || attempted != 1 {
+		t.Fatalf("RunDue = %d, %v, want 1 attempted", attempted, err)
+	}
+	stored := loadScheduled(t, db, scheduled)
+	if stored.Status != models.ScheduledTransferStatusActive || stored.Attempts != 1 || stored.RetryAt == nil || stored.LastError == "" {
+		t.Fatalf("after a failed run: status %s, %d attempts, retry at %v, error %q, want a retry pending",
+			stored.Status, stored.Attempts, stored.RetryAt, stored.LastError)
+	}
+	if attempted, _ := executor.RunDue(now.Add(time.Minute)); attempted != 0 {
+		t.Errorf("RunDue before the retry is due attempted %d, want 0", attempted)
+	}
+
+	deposit(t, sender, "50.00")
+	if attempted, err := executor.RunDue(stored.RetryAt.Add(time.Minute)); err != nil || attempted != 1 {
+		t.Fatalf("RunDue of the retry = %d, %v, want 1 attempted", attempted, err)
+	}
+	stored = loadScheduled(t, db, scheduled)
+	if stored.Status != models.ScheduledTransferStatusCompleted || stored.Attempts != 0 || stored.RetryAt != nil {
+		t.Errorf("after the retry: status %s, %d attempts, retry at %v, want completed", stored.Status, stored.Attempts, stored.RetryAt)
+	}
+	if got := walletBalance(t, db, recipient.ID); got.Amount != 1000 {
+		t.Errorf("recipient balance = %s, want 10.00", got)
+	}
+}
+
+func TestScheduledTransferGivesUpAfterRetries(t *testing.T) {
+	db := setupTestDB(t)
+	scheduled, _, recipient := scheduleTestTransfer(t, db, "10.00", models.ScheduleFrequencyOnce, 1)
+	executor := NewScheduledTransferService()
+
+	now := time.Now()
+	for attempt := 0; attempt <= len(scheduledTransferRetryDelays); attempt++ {
+		if attempted, err := executor.RunDue(now); err != nil || attempted != 1 {
+			t.Fatalf("attempt %d: RunDue = %d, %v, want 1 attempted", attempt+1, attempted, err)
+		}
+		if stored := loadScheduled(t, db, scheduled); stored.RetryAt != nil {
+			now = stored.RetryAt.Add(time.Minute)
+		}
+	}
+
+	stored := loadScheduled(t, db, scheduled)
+	if stored.Status != models.ScheduledTransferStatusFailed || stored.RetryAt != nil {
+		t.Errorf("after the last retry: status %s, retry at %v, want failed with no retry", stored.Status, stored.RetryAt)
+	}
+	var skipped int64
+	db.Model(&models.ScheduledTransferRun{}).
+		Where("scheduled_transfer_id = ? AND status = ?", scheduled.ID, models.ScheduledRunStatusSkipped).
+		Count(&skipped)
+	if skipped != 1 {
+		t.Errorf("recorded %d skipped runs, want 1", skipped)
+	}
+	if got := walletBalance(t, db, recipient.ID); !got.IsZero() {
+		t.Errorf("recipient balance = %s, want zero", got)
+	}
+}
@@ -0,0 +1,412 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"reflect"
+	"sort"
+	"time"
+
+	"securewallet/internal/config"
+	"securewallet/internal/models"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Settings cache keys
+const (
+	settingsCacheKey = "settings:current"
+	settingsCacheTTL = 10 * time.Minute
+)
+
+// mysqlErrDuplicateEntry is returned when two admins save the same version concurrently
+const mysqlErrDuplicateEntry = 1062
+
+// Settings errors
+var (
+	ErrSettingsConflict        = errors.New("settings were changed by someone else")
+	ErrSettingsVersionNotFound = errors.New("settings version not found")
+)
+
+// SettingsValidationError lists the settings that failed validation
+type SettingsValidationError struct {
+	Problems []string
+}
+
+func (e *SettingsValidationError) Error() string {
+	return fmt.Sprintf("invalid settings: %v", e.Problems)
+}
+
+// SystemSettings is the typed schema of the admin-editable configuration
+type SystemSettings struct {
+	Security  SecuritySettings  `json:"security"`
+	Transfers TransferSettings  `json:"transfers"`
+	Merchants MerchantSettings  `json:"merchants"`
+	RateLimit RateLimitSettings `json:"rateLimit"`
+	Comments  CommentSettings   `json:"comments"`
+	Backups   BackupSettings    `json:"backups"`
+}
+
+// SecuritySettings holds authentication related settings
+type SecuritySettings struct {
+	TwoFactorEnabled bool           `json:"twoFactorEnabled"`
+	SessionTimeout   int            `json:"sessionTimeout"` // Minutes
+	PasswordPolicy   PasswordPolicy `json:"passwordPolicy"`
+}
+
+// PasswordPolicy describes password requirements
+type PasswordPolicy struct {
+	MinLength           int  `json:"minLength"`
+	RequireUppercase    bool `json:"requireUppercase"`
+	RequireLowercase    bool `json:"requireLowercase"`
+	RequireNumbers      bool `json:"requireNumbers"`
+	RequireSpecialChars bool `json:"requireSpecialChars"`
+}
+
+// TransferSettings configures the transfer fee (amounts are in the wallet currency)
+type TransferSettings struct {
+	FeeBasisPoints int64        `json:"feeBasisPoints"`
+	MinFee         models.Money `json:"minFee"`
+	MaxFee         models.Money `json:"maxFee"`
+}
+
+// MerchantSettings configures the merchant fee on checkout payments: a percentage
+// plus a fixed amount, taken from what the merchant receives
+type MerchantSettings struct {
+	FeeBasisPoints int64        `json:"feeBasisPoints"`
+	FixedFee       models.Money `json:"fixedFee"`
+}
+
+// RateLimitSettings configures the login and registration rate limiter
+type RateLimitSettings struct {
+	MaxAttempts   int `json:"maxAttempts"`
+	WindowSeconds int `json:"windowSeconds"`
+}
+
+// CommentSettings configures blog comment auto-approval
+type CommentSettings struct {
+	AutoApprovalEnabled     bool `json:"autoApprovalEnabled"`
+	AutoApproveDelaySeconds int  `json:"autoApproveDelaySeconds"`
+}
+
+// BackupSettings configures automatic backups
+type BackupSettings struct {
+	Enabled         bool `json:"enabled"`
+	MaxBackups      int  `json:"maxBackups"`
+	IntervalMinutes int  `json:"intervalMinutes"`
+}
+
+// SettingChange describes one changed key between two versions
+type SettingChange struct {
+	Key string      `json:"key"`
+	Old interface{} `json:"old"`
+	New interface{} `json:"new"`
+}
+
+// cachedSettings is what gets stored in Redis
+type cachedSettings struct {
+	Version  int            `json:"version"`
+	Settings SystemSettings `json:"settings"`
+}
+
+// DefaultSystemSettings returns the settings used until an admin saves the first version
+func DefaultSystemSettings() SystemSettings {
+	return SystemSettings{
+		Security: SecuritySettings{
+			TwoFactorEnabled: true,
+			SessionTimeout:   30,
+			PasswordPolicy: PasswordPolicy{
+				MinLength:           8,
+				RequireUppercase:    true,
+				RequireLowercase:    true,
+				RequireNumbers:      true,
+				RequireSpecialChars: true,
+			},
+		},
+		Transfers: TransferSettings{
+			FeeBasisPoints: TRANSFER_FEE_BASIS_POINTS,
+			MinFee:         models.MustParseMoney(MIN_TRANSFER_FEE, ""),
+			MaxFee:         models.MustParseMoney(MAX_TRANSFER_FEE, ""),
+		},
+		Merchants: MerchantSettings{
+			FeeBasisPoints: MERCHANT_FEE_BASIS_POINTS,
+			FixedFee:       models.MustParseMoney(MERCHANT_FIXED_FEE, ""),
+		},
+		RateLimit: RateLimitSettings{
+			MaxAttempts:   5,
+			WindowSeconds: 15 * 60,
+		},
+		Comments: CommentSettings{
+			AutoApprovalEnabled:     true,
+			AutoApproveDelaySeconds: 60,
+		},
+		Backups: BackupSettings{
+			Enabled:         true,
+			MaxBackups:      7,
+			IntervalMinutes: 60,
+		},
+	}
+}
+
+// Validate checks that every setting is within its allowed range
+func (s SystemSettings) Validate() error {
+	var problems []string
+	check := func(ok bool, problem string) {
+		if !ok {
+			problems = append(problems, problem)
+		}
+	}
+
+	check(s.Security.SessionTimeout >= 5 && s.Security.SessionTimeout <= 1440, "security.sessionTimeout must be between 5 and 1440 minutes")
+	check(s.Security.PasswordPolicy.MinLength >= 8 && s.Security.PasswordPolicy.MinLength <= 128, "security.passwordPolicy.minLength must be between 8 and 128")
+	check(s.Transfers.FeeBasisPoints >= 0 && s.Transfers.FeeBasisPoints <= 1000, "transfers.feeBasisPoints must be between 0 and 1000")
+	check(!s.Transfers.MinFee.IsNegative(), "transfers.minFee cannot be negative")
+	check(s.Transfers.MaxFee.Amount >= s.Transfers.MinFee.Amount, "transfers.maxFee must not be below transfers.minFee")
+	check(s.Merchants.FeeBasisPoints >= 0 && s.Merchants.FeeBasisPoints <= 1000, "merchants.feeBasisPoints must be between 0 and 1000")
+	check(!s.Merchants.FixedFee.IsNegative(), "merchants.fixedFee cannot be negative")
+	check(s.RateLimit.MaxAttempts >= 1 && s.RateLimit.MaxAttempts <= 100, "rateLimit.maxAttempts must be between 1 and 100")
+	check(s.RateLimit.WindowSeconds >= 60 && s.RateLimit.WindowSeconds <= 86400, "rateLimit.windowSeconds must be between 60 and 86400")
+	check(s.Comments.AutoApproveDelaySeconds >= 0 && s.Comments.AutoApproveDelaySeconds <= 7*86400, "comments.autoApproveDelaySeconds must be between 0 and 604800")
+	check(s.Backups.MaxBackups >= 1 && s.Backups.MaxBackups <= 100, "backups.maxBackups must be between 1 and 100")
+	check(s.Backups.IntervalMinutes >= 5 && s.Backups.IntervalMinutes <= 10080, "backups.intervalMinutes must be between 5 and 10080")
+
+	if len(problems) > 0 {
+		return &SettingsValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// SettingsService stores versioned system settings and caches the current version in Redis
+type SettingsService struct {
+	db *gorm.DB
+}
+
+// NewSettingsService creates a new settings service
+func NewSettingsService() *SettingsService {
+	return &SettingsService{
+		db: config.GetDB(),
+	}
+}
+
+// CurrentSettings returns the current settings, falling back to the defaults
+// when they cannot be loaded. Services call this at runtime instead of keeping
+// their own package-level configuration.
+func CurrentSettings() SystemSettings {
+	settings, _, err := NewSettingsService().Current()
+	if err != nil {
+		log.Printf("Failed to load system settings, using defaults: %v", err)
+		return DefaultSystemSettings()
+	}
+	return *settings
+}
+
+// Current returns the current settings and their version (0 for the defaults)
+func (ss *SettingsService) Current() (*SystemSettings, int, error) {
+	ctx := context.Background()
+	redisClient := config.GetRedis()
+
+	if redisClient != nil {
+		if raw, err := redisClient.Get(ctx, settingsCacheKey).Bytes(); err == nil {
+			// Decode over the defaults like load does, for entries cached before a setting was added
+			cached := cachedSettings{Settings: DefaultSystemSettings()}
+			if err := json.Unmarshal(raw, &cached); err == nil {
+				return &cached.Settings, cached.Version, nil
+			}
+		}
+	}
+
+	settings, version, err := ss.load(ss.db)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if redisClient != nil {
+		if raw, err := json.Marshal(cachedSettings{Version: version, Settings: *settings}); err == nil {
+			redisClient.Set(ctx, settingsCacheKey, raw, settingsCacheTTL)
+		}
+	}
+	return settings, version, nil
+}
+
+// load reads the newest version from the database
+func (ss *SettingsService) load(tx *gorm.DB) (*SystemSettings, int, error) {
+	settings := DefaultSystemSettings()
+
+	var latest models.SettingsVersion
+	err := tx.Order("version DESC").First(&latest).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &settings, 0, nil
+	}
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to load settings: %w", err)
+	}
+
+	// Decode over the defaults so that settings added later get their default value
+	if err := json.Unmarshal([]byte(latest.Data), &settings); err != nil {
+		return nil, 0, fmt.Errorf("failed to decode settings version %d: %w", latest.Version, err)
+	}
+	return &settings, latest.Version, nil
+}
+
+// Update applies a partial settings document on top of the current version and
+// saves the result as a new version. expectedVersion guards against lost
+// updates; pass a negative value to skip the check. Nothing is saved and a nil
+// version is returned when the document does not change any setting.
+func (ss *SettingsService) Update(patch []byte, changedBy uuid.UUID, note string, expectedVersion int) (*models.SettingsVersion, error) {
+	current, version, err := ss.load(ss.db)
+	if err != nil {
+		return nil, err
+	}
+	if expectedVersion >= 0 && expectedVersion != version {
+		return nil, ErrSettingsConflict
+	}
+
+	updated := *current
+	decoder := json.NewDecoder(bytes.NewReader(patch))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&updated); err != nil {
+		return nil, &SettingsValidationError{Problems: []string{err.Error()}}
+	}
+
+	return ss.save(*current, updated, version, changedBy, note)
+}
+
+// Restore saves the settings of an older version as a new version
+func (ss *SettingsService) Restore(version int, changedBy uuid.UUID) (*models.SettingsVersion, error) {
+	var old models.SettingsVersion
+	if err := ss.db.Where("version = ?", version).First(&old).Error; err != nil {
+		return nil, ErrSettingsVersionNotFound
+	}
+
+	current, currentVersion, err := ss.load(ss.db)
+	if err != nil {
+		return nil, err
+	}
+	restored := DefaultSystemSettings()
+	if err := json.Unmarshal([]byte(old.Data), &restored); err != nil {
+		return nil, fmt.Errorf("failed to decode settings version %d: %w", version, err)
+	}
+
+	return ss.save(*current, restored, currentVersion, changedBy, fmt.Sprintf("Restored version %d", version))
+}
+
+// save validates and writes a new version, then invalidates the cache
+func (ss *SettingsService) save(current, updated SystemSettings, version int, changedBy uuid.UUID, note string) (*models.SettingsVersion, error) {
+	if err := updated.Validate(); err != nil {
+		return nil, err
+	}
+
+	changes := diffSettings(current, updated)
+	if len(changes) == 0 {
+		return nil, nil
+	}
+	data, err := json.Marshal(updated)
+	if err != nil {
+		return nil, err
+	}
+	changesJSON, err := json.Marshal(changes)
+	if err != nil {
+		return nil, err
+	}
+
+	saved := models.SettingsVersion{
+		Version:   version + 1,
+		Data:      string(data),
+		Changes:   changesJSON,
+		Note:      note,
+		ChangedBy: changedBy,
+	}
+	// The unique version index rejects a concurrent save of the same version
+	if err := ss.db.Create(&saved).Error; err != nil {
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlErrDuplicateEntry {
+			return nil, ErrSettingsConflict
+		}
+		return nil, fmt.Errorf("failed to save settings: %w", err)
+	}
+
+	ss.invalidateCache()
+	return &saved, nil
+}
+
+// History returns the most recent settings versions, newest first
+func (ss *SettingsService) History(limit int) ([]models.SettingsVersion, error) {
+	var versions []models.SettingsVersion
+	if err := ss.db.Preload("User").
+		Order("version DESC").
+		Limit(limit).
+		Find(&versions).Error; err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
+
+// Version returns the full settings of a stored version
+func (ss *SettingsService) Version(version int) (*models.SettingsVersion, *SystemSettings, error) {
+	var stored models.SettingsVersion
+	if err := ss.db.Preload("User").Where("version = ?", version).First(&stored).Error; err != nil {
+		return nil, nil, ErrSettingsVersionNotFound
+	}
+
+	settings := DefaultSystemSettings()
+	if err := json.Unmarshal([]byte(stored.Data), &settings); err != nil {
+		return nil, nil, fmt.Errorf("failed to decode settings version %d: %w", version, err)
+	}
+	return &stored, &settings, nil
+}
+
+// invalidateCache drops the cached settings so the next read loads the new version
+func (ss *SettingsService) invalidateCache() {
+	if redisClient := config.GetRedis(); redisClient != nil {
+		if err := redisClient.Del(context.Background(), settingsCacheKey).Err(); err != nil {
+			log.Printf("Failed to invalidate settings cache: %v", err)
+		}
+	}
+}
+
+// diffSettings lists the keys whose values differ between two settings documents
+func diffSettings(old, updated SystemSettings) []SettingChange {
+	oldValues := flattenSettings(old)
+	newValues := flattenSettings(updated)
+
+	changes := []SettingChange{}
+	for key, newValue := range newValues {
+		if oldValue := oldValues[key]; !reflect.DeepEqual(oldValue, newValue) {
+			changes = append(changes, SettingChange{Key: key, Old: oldValue, New: newValue})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Key < changes[j].Key })
+	return changes
+}
+
+// flattenSettings turns settings into dotted keys, e.g. "security.sessionTimeout"
+func flattenSettings(settings SystemSettings) map[string]interface{} {
+	raw, _ := json.Marshal(settings)
+	var tree map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	decoder.Decode(&tree)
+
+	flat := make(map[string]interface{})
+	var walk func(prefix string, value interface{})
+	walk = func(prefix string, value interface{}) {
+		if nested, ok := value.(map[string]interface{}); ok {
+			for key, child := range nested {
+				if prefix != "" {
+					key = prefix + "." + key
+				}
+				walk(key, child)
+			}
+			return
+		}
+		flat[prefix] = value
+	}
+	walk("", tree)
+	return flat
+}
@@ -0,0 +1,54 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestSettingsDefaultsAndVersions(t *testing.T) {
+	setupTestDB(t)
+	settings := NewSettingsService()
+
+	// Until an admin saves anything the defaults keep the old behaviour
+	if err := DefaultSystemSettings().Validate(); err != nil {
+		t.Fatalf("default settings are invalid: %v", err)
+	}
+	if delay := CurrentCommentConfig().AutoApproveDelay; delay != time.Minute {
+		t.Errorf("comment approval delay = %v, want 1m", delay)
+	}
+
+	admin := uuid.New()
+	version, err := settings.Update([]byte(`{"comments": {"autoApproveDelaySeconds": 600}}`), admin, "slower approval", 0)
+	if err != nil || version == nil || version.Version != 1 {
+		t.Fatalf("Update = %+v, %v, want version 1", version, err)
+	}
+	if delay := CurrentCommentConfig().AutoApproveDelay; delay != 10*time.Minute {
+		t.Errorf("comment approval delay = %v, want 10m", delay)
+	}
+
+	// A stale version is refused and an invalid value is not saved
+	if _, err := settings.Update([]byte(`{"comments": {"autoApproveDelaySeconds": 30}}`), admin, "", 0); !errors.Is(err, ErrSettingsConflict) {
+		t.Errorf("Update with a stale version = %v, want %v", err, ErrSettingsConflict)
+	}
+	var invalid *SettingsValidationError
+	if _, err := settings.Update([]byte(`{"rateLimit": {"maxAttempts": 0}}`), admin, "", 1); !errors.As(err, &invalid) {
+		t.Errorf("Update with an invalid value = %v, want a validation error", err)
+	}
+
+	if _, err := settings.Update([]byte(`{"rateLimit": {"maxAttempts": 10}}`), admin, "", 1); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if _, err := settings.Restore(1, admin); err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+	if attempts := CurrentSettings().RateLimit.MaxAttempts; attempts != 5 {
+		t.Errorf("max attempts after restore = %d, want 5", attempts)
+	}
+	history, err := settings.History(10)
+	if err != nil || len(history) != 3 {
+		t.Errorf("History = %d versions, %v, want 3", len(history), err)
+	}
+}
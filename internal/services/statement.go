@@ -0,0 +1,318 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"securewallet/internal/config"
+	"securewallet/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Statement formats
+const (
+	StatementFormatJSON = "json"
+	StatementFormatCSV  = "csv"
+	StatementFormatOFX  = "ofx"
+	StatementFormatPDF  = "pdf"
+)
+
+// statementDir holds the pre-generated monthly statement files
+const statementDir = "statements"
+
+// maxStatementPeriod bounds how much history one statement may cover
+const maxStatementPeriod = 366 * 24 * time.Hour
+
+// Statement errors
+var (
+	ErrInvalidStatementPeriod = errors.New("statement period must start before it ends and cover at most a year")
+	ErrInvalidStatementFormat = errors.New("format must be json, csv, ofx or pdf")
+)
+
+// WalletStatement lists every ledger movement of a wallet over a period with a
+// running balance, starting from the balance the wallet had when it began
+type WalletStatement struct {
+	WalletID       uuid.UUID       `json:"wallet_id"`
+	WalletName     string          `json:"wallet_name"`
+	Currency       string          `json:"currency"`
+	OwnerName      string          `json:"owner_name"`
+	PeriodStart    time.Time       `json:"period_start"`
+	PeriodEnd      time.Time       `json:"period_end"` // Exclusive
+	OpeningBalance models.Money    `json:"opening_balance"`
+	ClosingBalance models.Money    `json:"closing_balance"`
+	TotalIn        models.Money    `json:"total_in"`
+	TotalOut       models.Money    `json:"total_out"`
+	Lines          []StatementLine `json:"lines"`
+	GeneratedAt    time.Time       `json:"generated_at"`
+}
+
+// StatementLine is one transaction on a statement
+type StatementLine struct {
+	TransactionID uuid.UUID    `json:"transaction_id"`
+	Date          time.Time    `json:"date"`
+	Type          string       `json:"type"`
+	Description   string       `json:"description"`
+	Amount        models.Money `json:"amount"`  // Positive for money in, negative for money out
+	Balance       models.Money `json:"balance"` // Running balance after this transaction
+}
+
+// StatementFile is a statement rendered for download
+type StatementFile struct {
+	Name        string
+	ContentType string
+	Data        []byte
+}
+
+// StatementService builds wallet statements from the ledger and exports them
+type StatementService struct {
+	db *gorm.DB
+}
+
+// NewStatementService creates a new statement service
+func NewStatementService() *StatementService {
+	return &StatementService{db: config.GetDB()}
+}
+
+// ParseStatementPeriod reads a period from a month (2006-01) or from two dates
+// (2006-01-02) where both days are included
+func ParseStatementPeriod(month, from, to string) (time.Time, time.Time, error) {
+	if month != "" {
+		start, err := time.ParseInLocation("2006-01", month, time.Local)
+		if err != nil {
+			return time.Time{}, time.Time{}, ErrInvalidStatementPeriod
+		}
+		return start, start.AddDate(0, 1, 0), nil
+	}
+
+	start, err := time.ParseInLocation("2006-01-02", from, time.Local)
+	if err != nil {
+		return time.Time{}, time.Time{}, ErrInvalidStatementPeriod
+	}
+	end, err := time.ParseInLocation("2006-01-02", to, time.Local)
+	if err != nil {
+		return time.Time{}, time.Time{}, ErrInvalidStatementPeriod
+	}
+	return start, end.AddDate(0, 0, 1), nil
+}
+
+// Build returns the statement of one of the user's wallets, closed wallets included
+func (ss *StatementService) Build(userID uuid.UUID, walletID string, from, to time.Time) (*WalletStatement, error) {
+	wallet, err := ss.ownedWallet(userID, walletID)
+	if err != nil {
+		return nil, err
+	}
+	return ss.build(wallet, from, to)
+}
+
+// Export returns a statement file, serving the pre-generated copy when the
+// period is a month the cron job already stored
+func (ss *StatementService) Export(userID uuid.UUID, walletID string, from, to time.Time, format string) (*StatementFile, error) {
+	if format != StatementFormatCSV && format != StatementFormatOFX && format != StatementFormatPDF {
+		return nil, ErrInvalidStatementFormat
+	}
+	wallet, err := ss.ownedWallet(userID, walletID)
+	if err != nil {
+		return nil, err
+	}
+
+	var stored models.Statement
+	if err := ss.db.Where("wallet_id = ? AND period_start = ? AND period_end = ?", wallet.ID, from, to).
+		First(&stored).Error; err == nil {
+		if data, err := os.ReadFile(statementPath(wallet.ID, from, format)); err == nil {
+			return &StatementFile{
+				Name:        statementFileName(wallet.ID, from, to, format),
+				ContentType: statementContentTypes[format],
+				Data:        data,
+			}, nil
+		}
+	}
+
+	statement, err := ss.build(wallet, from, to)
+	if err != nil {
+		return nil, err
+	}
+	return RenderStatement(statement, format)
+}
+
+// List returns the stored monthly statements of one of the user's wallets
+func (ss *StatementService) List(userID uuid.UUID, walletID string) ([]models.Statement, error) {
+	wallet, err := ss.ownedWallet(userID, walletID)
+	if err != nil {
+		return nil, err
+	}
+
+	var statements []models.Statement
+	if err := ss.db.Where("wallet_id = ?", wallet.ID).Order("period_start DESC").Find(&statements).Error; err != nil {
+		return nil, err
+	}
+	return statements, nil
+}
+
+// GenerateMonthly stores the statements of the month before now for every wallet
+// that was open during it. Wallets that already have one are skipped, so the job
+// can be re-run safely. A wallet that fails is logged and does not stop the
+// others; the failures are returned together with the number generated.
+func (ss *StatementService) GenerateMonthly(now time.Time) (int, error) {
+	start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.Local).AddDate(0, -1, 0)
+	end := start.AddDate(0, 1, 0)
+
+	var wallets []models.Wallet
+	if err := ss.db.Where("created_at < ? AND (status <> ? OR closed_at >= ?)", end, models.WalletStatusClosed, start).
+		Where("id NOT IN (?)", ss.db.Model(&models.Statement{}).Select("wallet_id").Where("period_start = ?", start)).
+		Find(&wallets).Error; err != nil {
+		return 0, fmt.Errorf("failed to load wallets: %w", err)
+	}
+
+	generated := 0
+	var errs []error
+	for i := range wallets {
+		if err := ss.store(&wallets[i], start, end); err != nil {
+			err = fmt.Errorf("failed to generate statement for wallet %s: %w", wallets[i].ID, err)
+			log.Println(err)
+			errs = append(errs, err)
+			continue
+		}
+		generated++
+	}
+	return generated, errors.Join(errs...)
+}
+
+// store renders a monthly statement in every file format and records it
+func (ss *StatementService) store(wallet *models.Wallet, start, end time.Time) error {
+	statement, err := ss.build(wallet, start, end)
+	if err != nil {
+		return err
+	}
+
+	for _, format := range []string{StatementFormatCSV, StatementFormatOFX, StatementFormatPDF} {
+		file, err := RenderStatement(statement, format)
+		if err != nil {
+			return err
+		}
+		path := statementPath(wallet.ID, start, format)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return fmt.Errorf("failed to create statement directory: %w", err)
+		}
+		if err := os.WriteFile(path, file.Data, 0644); err != nil {
+			return fmt.Errorf("failed to write statement file: %w", err)
+		}
+	}
+
+	record := models.Statement{
+		WalletID:         wallet.ID,
+		UserID:           wallet.UserID,
+		PeriodStart:      start,
+		PeriodEnd:        end,
+		Currency:         wallet.Currency,
+		OpeningBalance:   statement.OpeningBalance,
+		ClosingBalance:   statement.ClosingBalance,
+		TotalIn:          statement.TotalIn,
+		TotalOut:         statement.TotalOut,
+		TransactionCount: len(statement.Lines),
+	}
+	return ss.db.Create(&record).Error
+}
+
+// build reads the wallet's ledger movements over [from, to)
+func (ss *StatementService) build(wallet *models.Wallet, from, to time.Time) (*WalletStatement, error) {
+	if !from.Before(to) || to.Sub(from) > maxStatementPeriod {
+		return nil, ErrInvalidStatementPeriod
+	}
+
+	var owner models.User
+	if err := ss.db.Unscoped().First(&owner, "id = ?", wallet.UserID).Error; err != nil {
+		return nil, fmt.Errorf("failed to load wallet owner: %w", err)
+	}
+
+	// Only ledger views count; they add up to the wallet's postings
+	ledger := ss.db.Model(&models.Transaction{}).
+		Where("wallet_id = ? AND journal_entry_id IS NOT NULL", wallet.ID)
+
+	opening := models.ZeroMoney(wallet.Currency)
+	if err := ledger.Session(&gorm.Session{}).
+		Where("created_at < ?", from).
+		Select("COALESCE(SUM(CASE WHEN direction = 'out' THEN -amount ELSE amount END), 0)").
+		Row().Scan(&opening); err != nil {
+		return nil, fmt.Errorf("failed to sum opening balance: %w", err)
+	}
+
+	var transactions []models.Transaction
+	if err := ledger.Session(&gorm.Session{}).
+		Where("created_at >= ? AND created_at < ?", from, to).
+		Order("created_at ASC, id ASC").
+		Find(&transactions).Error; err != nil {
+		return nil, fmt.Errorf("failed to load transactions: %w", err)
+	}
+
+	statement := &WalletStatement{
+		WalletID:       wallet.ID,
+		WalletName:     wallet.Name,
+		Currency:       wallet.Currency,
+		OwnerName:      owner.Name,
+		PeriodStart:    from,
+		PeriodEnd:      to,
+		OpeningBalance: opening,
+		TotalIn:        models.ZeroMoney(wallet.Currency),
+		TotalOut:       models.ZeroMoney(wallet.Currency),
+		Lines:          make([]StatementLine, 0, len(transactions)),
+		GeneratedAt:    time.Now(),
+	}
+
+	balance := opening
+	for _, transaction := range transactions {
+		amount := transaction.Amount
+		if transaction.Direction == "out" {
+			amount = amount.Neg()
+			statement.TotalOut, _ = statement.TotalOut.Add(transaction.Amount)
+		} else {
+			statement.TotalIn, _ = statement.TotalIn.Add(transaction.Amount)
+		}
+		balance, _ = balance.Add(amount)
+
+		statement.Lines = append(statement.Lines, StatementLine{
+			TransactionID: transaction.ID,
+			Date:          transaction.CreatedAt,
+			Type:          transaction.Type,
+			Description:   transaction.Description,
+			Amount:        amount,
+			Balance:       balance,
+		})
+	}
+	statement.ClosingBalance = balance
+
+	return statement, nil
+}
+
+// ownedWallet loads a wallet the user owns or is a member of without rejecting closed ones
+func (ss *StatementService) ownedWallet(userID uuid.UUID, walletID string) (*models.Wallet, error) {
+	id, err := uuid.Parse(walletID)
+	if err != nil {
+		return nil, ErrWalletNotFound
+	}
+
+	var wallet models.Wallet
+	if err := ss.db.Where("id = ? AND id IN (?)", id, memberWallets(ss.db, userID, models.WalletRoleViewer)).
+		First(&wallet).Error; err != nil {
+		return nil, ErrWalletNotFound
+	}
+	return &wallet, nil
+}
+
+// statementPath is where the cron job stores a monthly statement file
+func statementPath(walletID uuid.UUID, start time.Time, format string) string {
+	return filepath.Join(statementDir, walletID.String(), start.Format("2006-01")+"."+format)
+}
+
+// statementFileName names a statement download after its wallet and period
+func statementFileName(walletID uuid.UUID, from, to time.Time, format string) string {
+	last := to.AddDate(0, 0, -1)
+	return fmt.Sprintf("statement_%s_%s_%s.%s",
+		strings.SplitN(walletID.String(), "-", 2)[0], from.Format("20060102"), last.Format("20060102"), format)
+}
@@ -0,0 +1,177 @@
+package services
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// statementContentTypes maps each file format to its MIME type
+var statementContentTypes = map[string]string{
+	StatementFormatCSV: "text/csv; charset=utf-8",
+	StatementFormatOFX: "application/x-ofx",
+	StatementFormatPDF: "application/pdf",
+}
+
+// RenderStatement exports a statement as a CSV, OFX or PDF file
+func RenderStatement(statement *WalletStatement, format string) (*StatementFile, error) {
+	var data []byte
+	var err error
+	switch format {
+	case StatementFormatCSV:
+		data, err = renderStatementCSV(statement)
+	case StatementFormatOFX:
+		data, err = renderStatementOFX(statement)
+	case StatementFormatPDF:
+		data, err = renderStatementPDF(statement)
+	default:
+		return nil, ErrInvalidStatementFormat
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to render %s statement: %w", format, err)
+	}
+
+	return &StatementFile{
+		Name:        statementFileName(statement.WalletID, statement.PeriodStart, statement.PeriodEnd, format),
+		ContentType: statementContentTypes[format],
+		Data:        data,
+	}, nil
+}
+
+// renderStatementCSV writes one row per transaction between opening and closing balance rows
+func renderStatementCSV(statement *WalletStatement) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+
+	rows := [][]string{
+		{"date", "transaction_id", "type", "description", "money_in", "money_out", "balance", "currency"},
+		{statement.PeriodStart.Format(time.RFC3339), "", "", "Opening balance", "", "", statement.OpeningBalance.Format(), statement.Currency},
+	}
+	for _, line := range statement.Lines {
+		in, out := "", ""
+		if line.Amount.IsNegative() {
+			out = line.Amount.Abs().Format()
+		} else {
+			in = line.Amount.Format()
+		}
+		rows = append(rows, []string{
+			line.Date.Format(time.RFC3339),
+			line.TransactionID.String(),
+			line.Type,
+			line.Description,
+			in,
+			out,
+			line.Balance.Format(),
+			statement.Currency,
+		})
+	}
+	rows = append(rows, []string{
+		statement.PeriodEnd.Format(time.RFC3339), "", "", "Closing balance",
+		statement.TotalIn.Format(), statement.TotalOut.Format(), statement.ClosingBalance.Format(), statement.Currency,
+	})
+
+	if err := w.WriteAll(rows); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// ofxTime formats a time as an OFX datetime in UTC
+func ofxTime(t time.Time) string {
+	return t.UTC().Format("20060102150405.000") + "[0:GMT]"
+}
+
+// renderStatementOFX writes an OFX 2.2 bank statement response
+func renderStatementOFX(statement *WalletStatement) ([]byte, error) {
+	var buf bytes.Buffer
+	escape := func(value string) string {
+		var escaped bytes.Buffer
+		xml.EscapeText(&escaped, []byte(value))
+		return escaped.String()
+	}
+	tag := func(indent int, name, value string) {
+		fmt.Fprintf(&buf, "%s<%s>%s</%s>\n", strings.Repeat("  ", indent), name, escape(value), name)
+	}
+	open := func(indent int, name string) {
+		fmt.Fprintf(&buf, "%s<%s>\n", strings.Repeat("  ", indent), name)
+	}
+	closeTag := func(indent int, name string) {
+		fmt.Fprintf(&buf, "%s</%s>\n", strings.Repeat("  ", indent), name)
+	}
+
+	buf.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="no"?>` + "\n")
+	buf.WriteString(`<?OFX OFXHEADER="200" VERSION="220" SECURITY="NONE" OLDFILEUID="NONE" NEWFILEUID="NONE"?>` + "\n")
+	open(0, "OFX")
+
+	open(1, "SIGNONMSGSRSV1")
+	open(2, "SONRS")
+	open(3, "STATUS")
+	tag(4, "CODE", "0")
+	tag(4, "SEVERITY", "INFO")
+	closeTag(3, "STATUS")
+	tag(3, "DTSERVER", ofxTime(statement.GeneratedAt))
+	tag(3, "LANGUAGE", "ENG")
+	closeTag(2, "SONRS")
+	closeTag(1, "SIGNONMSGSRSV1")
+
+	open(1, "BANKMSGSRSV1")
+	open(2, "STMTTRNRS")
+	tag(3, "TRNUID", statement.WalletID.String())
+	open(3, "STATUS")
+	tag(4, "CODE", "0")
+	tag(4, "SEVERITY", "INFO")
+	closeTag(3, "STATUS")
+	open(3, "STMTRS")
+	tag(4, "CURDEF", statement.Currency)
+	open(4, "BANKACCTFROM")
+	tag(5, "BANKID", "SECUREWALLET")
+	tag(5, "ACCTID", statement.WalletID.String())
+	tag(5, "ACCTTYPE", "CHECKING")
+	closeTag(4, "BANKACCTFROM")
+
+	open(4, "BANKTRANLIST")
+	tag(5, "DTSTART", ofxTime(statement.PeriodStart))
+	tag(5, "DTEND", ofxTime(statement.PeriodEnd))
+	for _, line := range statement.Lines {
+		trnType := "CREDIT"
+		if line.Amount.IsNegative() {
+			trnType = "DEBIT"
+		}
+		name := line.Description
+		if name == "" {
+			name = line.Type
+		}
+		open(5, "STMTTRN")
+		tag(6, "TRNTYPE", trnType)
+		tag(6, "DTPOSTED", ofxTime(line.Date))
+		tag(6, "TRNAMT", line.Amount.Format())
+		tag(6, "FITID", line.TransactionID.String())
+		tag(6, "NAME", truncateRunes(name, 32))
+		tag(6, "MEMO", strings.TrimSpace(line.Type+" "+line.Description))
+		closeTag(5, "STMTTRN")
+	}
+	closeTag(4, "BANKTRANLIST")
+
+	open(4, "LEDGERBAL")
+	tag(5, "BALAMT", statement.ClosingBalance.Format())
+	tag(5, "DTASOF", ofxTime(statement.PeriodEnd))
+	closeTag(4, "LEDGERBAL")
+	closeTag(3, "STMTRS")
+	closeTag(2, "STMTTRNRS")
+	closeTag(1, "BANKMSGSRSV1")
+
+	closeTag(0, "OFX")
+	return buf.Bytes(), nil
+}
+
+// truncateRunes shortens a string to at most n characters
+func truncateRunes(value string, n int) string {
+	runes := []rune(value)
+	if len(runes) <= n {
+		return value
+	}
+	return string(runes[:n])
+}
@@ -0,0 +1,294 @@
+package services
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"strings"
+)
+
+// A4 page geometry in PDF points
+const (
+	pdfPageWidth  = 595
+	pdfPageHeight = 842
+	pdfMargin     = 40
+	pdfRowHeight  = 14
+)
+
+// Fonts every PDF reader provides, so nothing has to be embedded
+const (
+	pdfFontRegular = "F1" // Helvetica
+	pdfFontBold    = "F2" // Helvetica-Bold
+)
+
+// helveticaWidths holds the Helvetica glyph widths of the printable ASCII
+// characters, in thousandths of the font size
+var helveticaWidths = [95]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+// pdfPage collects the drawing operators of one page
+type pdfPage struct {
+	content bytes.Buffer
+}
+
+// text draws a string with its baseline starting at (x, y)
+func (p *pdfPage) text(font string, size float64, x, y float64, value string) {
+	if value == "" {
+		return
+	}
+	fmt.Fprintf(&p.content, "BT /%s %.1f Tf %.2f %.2f Td (%s) Tj ET\n", font, size, x, y, pdfEscape(value))
+}
+
+// textRight draws a string that ends at x
+func (p *pdfPage) textRight(font string, size float64, x, y float64, value string) {
+	p.text(font, size, x-pdfTextWidth(value, size), y, value)
+}
+
+// line draws a thin horizontal rule
+func (p *pdfPage) line(x1, x2, y float64) {
+	fmt.Fprintf(&p.content, "0.5 w %.2f %.2f m %.2f %.2f l S\n", x1, y, x2, y)
+}
+
+// shade fills a light grey rectangle behind later drawing
+func (p *pdfPage) shade(x, y, width, height float64) {
+	fmt.Fprintf(&p.content, "0.93 g %.2f %.2f %.2f %.2f re f 0 g\n", x, y, width, height)
+}
+
+// pdfDocument writes pages as a minimal PDF 1.4 file using the standard fonts
+type pdfDocument struct {
+	pages []*pdfPage
+}
+
+// addPage starts a new page
+func (d *pdfDocument) addPage() *pdfPage {
+	page := &pdfPage{}
+	d.pages = append(d.pages, page)
+	return page
+}
+
+// bytes serializes the document with a cross-reference table
+func (d *pdfDocument) bytes() ([]byte, error) {
+	var buf bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// Objects 1-4 are the catalog, page tree and fonts; each page then takes
+	// a page object followed by its content stream
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 5+2*i)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+
+	for i, page := range d.pages {
+		var stream bytes.Buffer
+		zw := zlib.NewWriter(&stream)
+		if _, err := zw.Write(page.content.Bytes()); err != nil {
+			return nil, err
+		}
+		if err := zw.Close(); err != nil {
+			return nil, err
+		}
+
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] "+
+			"/Resources << /Font << /%s 3 0 R /%s 4 0 R >> >> /Contents %d 0 R >>",
+			pdfPageWidth, pdfPageHeight, pdfFontRegular, pdfFontBold, 6+2*i))
+		object(fmt.Sprintf("<< /Length %d /Filter /FlateDecode >>\nstream\n%s\nendstream", stream.Len(), stream.Bytes()))
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	return buf.Bytes(), nil
+}
+
+// pdfEscape encodes a string for a PDF literal in WinAnsi, replacing characters
+// the standard fonts cannot show
+func pdfEscape(value string) string {
+	var b strings.Builder
+	for _, r := range value {
+		switch {
+		case r == '\\' || r == '(' || r == ')':
+			b.WriteByte('\\')
+			b.WriteByte(byte(r))
+		case r >= 32 && r < 127, r >= 160 && r < 256:
+			b.WriteByte(byte(r))
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
+
+// pdfTextWidth approximates the width of a string set in Helvetica
+func pdfTextWidth(value string, size float64) float64 {
+	total := 0
+	for _, r := range value {
+		if r >= 32 && r < 127 {
+			total += helveticaWidths[r-32]
+		} else {
+			total += 556
+		}
+	}
+	return float64(total) * size / 1000
+}
+
+// pdfFit shortens a string with an ellipsis until it fits the width
+func pdfFit(value string, size, width float64) string {
+	if pdfTextWidth(value, size) <= width {
+		return value
+	}
+	runes := []rune(value)
+	for len(runes) > 0 && pdfTextWidth(string(runes)+"...", size) > width {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "..."
+}
+
+// statementColumns are the x positions of the PDF table columns; amounts are right-aligned
+var statementColumns = struct {
+	date, description, in, out, balance float64
+}{pdfMargin, 105, 395, 475, pdfPageWidth - pdfMargin}
+
+// renderStatementPDF lays a statement out as a paginated A4 document
+func renderStatementPDF(statement *WalletStatement) ([]byte, error) {
+	type row struct {
+		date, description, in, out, balance string
+		bold                                bool
+	}
+
+	rows := []row{{
+		date:        statement.PeriodStart.Format("02 Jan 2006"),
+		description: "Opening balance",
+		balance:     statement.OpeningBalance.Format(),
+		bold:        true,
+	}}
+	for _, line := range statement.Lines {
+		r := row{
+			date:        line.Date.Format("02 Jan 2006"),
+			description: line.Description,
+			balance:     line.Balance.Format(),
+		}
+		if r.description == "" {
+			r.description = line.Type
+		}
+		if line.Amount.IsNegative() {
+			r.out = line.Amount.Abs().Format()
+		} else {
+			r.in = line.Amount.Format()
+		}
+		rows = append(rows, r)
+	}
+	rows = append(rows, row{
+		date:        statement.PeriodEnd.AddDate(0, 0, -1).Format("02 Jan 2006"),
+		description: "Closing balance",
+		in:          statement.TotalIn.Format(),
+		out:         statement.TotalOut.Format(),
+		balance:     statement.ClosingBalance.Format(),
+		bold:        true,
+	})
+
+	// The first page carries the account details and summary above the table
+	const firstTableTop, otherTableTop, tableBottom = 600, 770, 60
+	firstRows := (firstTableTop - tableBottom) / pdfRowHeight
+	otherRows := (otherTableTop - tableBottom) / pdfRowHeight
+
+	var pages [][]row
+	for remaining, capacity := rows, firstRows; len(remaining) > 0; capacity = otherRows {
+		n := capacity
+		if n > len(remaining) {
+			n = len(remaining)
+		}
+		pages = append(pages, remaining[:n])
+		remaining = remaining[n:]
+	}
+
+	period := fmt.Sprintf("%s to %s",
+		statement.PeriodStart.Format("02 Jan 2006"), statement.PeriodEnd.AddDate(0, 0, -1).Format("02 Jan 2006"))
+	walletName := statement.WalletName
+	if walletName == "" {
+		walletName = statement.Currency + " wallet"
+	}
+
+	doc := &pdfDocument{}
+	right := float64(pdfPageWidth - pdfMargin)
+	for i, pageRows := range pages {
+		page := doc.addPage()
+		top := float64(otherTableTop)
+
+		if i == 0 {
+			top = firstTableTop
+			page.text(pdfFontBold, 18, pdfMargin, 790, "Account statement")
+			page.text(pdfFontRegular, 10, pdfMargin, 765, "Account holder: "+statement.OwnerName)
+			page.text(pdfFontRegular, 10, pdfMargin, 751, fmt.Sprintf("Wallet: %s (%s)", walletName, statement.WalletID))
+			page.text(pdfFontRegular, 10, pdfMargin, 737, "Period: "+period)
+			page.text(pdfFontRegular, 10, pdfMargin, 723, "Currency: "+statement.Currency)
+
+			page.shade(pdfMargin, 630, right-pdfMargin, 80)
+			summary := [][2]string{
+				{"Opening balance", statement.OpeningBalance.String()},
+				{"Money in", statement.TotalIn.String()},
+				{"Money out", statement.TotalOut.String()},
+				{"Closing balance", statement.ClosingBalance.String()},
+			}
+			for j, item := range summary {
+				y := float64(692 - 16*j)
+				font := pdfFontRegular
+				if j == len(summary)-1 {
+					font = pdfFontBold
+				}
+				page.text(font, 10, pdfMargin+10, y, item[0])
+				page.textRight(font, 10, right-10, y, item[1])
+			}
+		} else {
+			page.text(pdfFontBold, 12, pdfMargin, 800, "Account statement")
+			page.textRight(pdfFontRegular, 9, right, 800, fmt.Sprintf("%s, %s", walletName, period))
+		}
+
+		// Table header
+		page.text(pdfFontBold, 9, statementColumns.date, top, "Date")
+		page.text(pdfFontBold, 9, statementColumns.description, top, "Description")
+		page.textRight(pdfFontBold, 9, statementColumns.in, top, "Money in")
+		page.textRight(pdfFontBold, 9, statementColumns.out, top, "Money out")
+		page.textRight(pdfFontBold, 9, statementColumns.balance, top, "Balance")
+		page.line(pdfMargin, right, top-5)
+
+		y := top - 5 - pdfRowHeight
+		for _, r := range pageRows {
+			font := pdfFontRegular
+			if r.bold {
+				font = pdfFontBold
+			}
+			descriptionWidth := statementColumns.in - pdfTextWidth("000000000.00", 9) - statementColumns.description
+			page.text(font, 9, statementColumns.date, y, r.date)
+			page.text(font, 9, statementColumns.description, y, pdfFit(r.description, 9, descriptionWidth))
+			page.textRight(font, 9, statementColumns.in, y, r.in)
+			page.textRight(font, 9, statementColumns.out, y, r.out)
+			page.textRight(font, 9, statementColumns.balance, y, r.balance)
+			y -= pdfRowHeight
+		}
+
+		page.line(pdfMargin, right, 45)
+		page.text(pdfFontRegular, 8, pdfMargin, 32, "Generated "+statement.GeneratedAt.Format("02 Jan 2006 15:04 MST"))
+		page.textRight(pdfFontRegular, 8, right, 32, fmt.Sprintf("Page %d of %d", i+1, len(pages)))
+	}
+
+	return doc.bytes()
+}
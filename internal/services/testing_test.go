@@ -0,0 +1,134 @@
+package services
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"securewallet/internal/config"
+	"securewallet/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// setupTestDB points config.DB at an empty database for one test. Tests run
+// against the MySQL database in TEST_MYSQL_DSN when it is set (its tables are
+// dropped first) and against a SQLite file otherwise. SQLite has no row locks,
+// so its transactions are serialised by taking the write lock when they begin.
+func setupTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	gormConfig := &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)}
+	var db *gorm.DB
+	var err error
+	if dsn := os.Getenv("TEST_MYSQL_DSN"); dsn != "" {
+		db, err = gorm.Open(mysql.Open(dsn), gormConfig)
+		if err == nil {
+			err = db.Migrator().DropTable(config.Models()...)
+		}
+	} else {
+		path := filepath.Join(t.TempDir(), "wallet.db")
+		db, err = gorm.Open(sqlite.Open("file:"+path+"?_txlock=immediate&_busy_timeout=30000&_journal_mode=WAL"), gormConfig)
+	}
+	if err != nil {
+		t.Fatalf("failed to open test database: %v", err)
+	}
+	if err := db.AutoMigrate(config.Models()...); err != nil {
+		t.Fatalf("failed to migrate test database: %v", err)
+	}
+
+	previous := config.DB
+	config.DB = db
+	t.Cleanup(func() {
+		config.DB = previous
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return db
+}
+
+// createTestWallet creates a user with one wallet in the given currency
+func createTestWallet(t *testing.T, db *gorm.DB, currency string) *models.Wallet {
+	t.Helper()
+
+	name := "user" + uuid.NewString()[:8]
+	user := models.User{
+		Username:     name,
+		Name:         name,
+		Email:        name + "@example.com",
+		PasswordHash: "x",
+		IsActive:     true,
+	}
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+	wallet := models.Wallet{
+		UserID:    user.ID,
+		Name:      "Main",
+		Type:      models.WalletTypeCurrent,
+		Balance:   models.ZeroMoney(currency),
+		Held:      models.ZeroMoney(currency),
+		Currency:  currency,
+		IsDefault: true,
+		Status:    models.WalletStatusActive,
+	}
+	if err := db.Create(&wallet).Error; err != nil {
+		t.Fatalf("failed to create wallet: %v", err)
+	}
+	return &wallet
+}
+
+// deposit funds a test wallet from external cash
+func deposit(t *testing.T, wallet *models.Wallet, amount string) {
+	t.Helper()
+
+	_, err := NewTransferService().Deposit(DepositInput{
+		WalletID:    wallet.ID,
+		Amount:      models.MustParseMoney(amount, wallet.Currency),
+		Description: "Test deposit",
+		InitiatedBy: wallet.UserID,
+	})
+	if err != nil {
+		t.Fatalf("failed to deposit %s into wallet %s: %v", amount, wallet.ID, err)
+	}
+}
+
+// walletBalance reads a wallet's stored balance
+func walletBalance(t *testing.T, db *gorm.DB, walletID uuid.UUID) models.Money {
+	t.Helper()
+
+	var wallet models.Wallet
+	if err := db.First(&wallet, "id = ?", walletID).Error; err != nil {
+		t.Fatalf("failed to load wallet %s: %v", walletID, err)
+	}
+	return wallet.Balance
+}
+
+// postingTotal sums every posting in a currency, which a balanced ledger keeps at zero
+func postingTotal(t *testing.T, db *gorm.DB, currency string) models.Money {
+	t.Helper()
+
+	total := models.ZeroMoney(currency)
+	if err := db.Model(&models.Posting{}).
+		Where("currency = ?", currency).
+		Select("COALESCE(SUM(amount), 0)").
+		Row().Scan(&total); err != nil {
+		t.Fatalf("failed to sum postings: %v", err)
+	}
+	return total
+}
+
+// mustMoney parses an amount for test expectations
+func mustMoney(amount, currency string) models.Money {
+	money, err := models.ParseMoney(amount, currency)
+	if err != nil {
+		panic(fmt.Sprintf("bad test amount %q: %v", amount, err))
+	}
+	return money
+}
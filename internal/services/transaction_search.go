@@ -0,0 +1,411 @@
+package services
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"securewallet/internal/config"
+	"securewallet/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Transaction search paging
+const (
+	defaultTransactionPageSize = 50
+	maxTransactionPageSize     = 200
+)
+
+// Transaction search errors
+var (
+	ErrInvalidCursor = errors.New("invalid cursor")
+	ErrInvalidFilter = errors.New("invalid transaction filter")
+)
+
+// TransactionFilter narrows a search over the user's transactions. Empty fields
+// do not filter.
+type TransactionFilter struct {
+	WalletID     string // One of the user's wallets; all of them when empty
+	Currency     string // Also the currency MinAmount and MaxAmount are given in; the wallet's when empty
+	From         string // Date (2006-01-02) or RFC 3339 time, inclusive
+	To           string // Date (2006-01-02, whole day included) or RFC 3339 time, exclusive
+	Type         string // DEPOSIT, TRANSFER, EXCHANGE, ...
+	Status       string
+	Direction    string // in, out
+	MinAmount    string
+	MaxAmount    string
+	Counterparty string // Email or username of the other side of a transfer
+	Query        string // Free text matched against the description
+	CategoryID   string // One of the user's categories
+	Tag          string
+	Cursor       string // next_cursor of the previous page
+	Limit        int
+}
+
+// TransactionSummary totals the in and out amounts of one currency
+type TransactionSummary struct {
+	Currency string       `json:"currency"`
+	In       models.Money `json:"in"`
+	Out      models.Money `json:"out"`
+	Net      models.Money `json:"net"`
+	Count    int64        `json:"count"`
+}
+
+// TransactionPage is one page of search results. The summary covers every
+// transaction matching the filter, not just this page.
+type TransactionPage struct {
+	Transactions []models.Transaction `json:"transactions"`
+	NextCursor   string               `json:"next_cursor,omitempty"`
+	Summary      []TransactionSummary `json:"summary"`
+}
+
+// Counterparty is the other side of a transfer as shown to a wallet owner
+type Counterparty struct {
+	UserID   uuid.UUID `json:"user_id"`
+	Username string    `json:"username"`
+	Name     string    `json:"name"`
+	WalletID uuid.UUID `json:"wallet_id"`
+}
+
+// TransactionDetail is a transaction with the journal entry and counterparty behind it
+type TransactionDetail struct {
+	models.Transaction
+	Entry        *models.JournalEntry `json:"journal_entry,omitempty"`
+	Counterparty *Counterparty        `json:"counterparty,omitempty"`
+	Notes        *TransactionNotes    `json:"notes"` // The user's own category, note, tags and attachments
+}
+
+// TransactionService searches and reads the transactions of a user's wallets
+type TransactionService struct {
+	db *gorm.DB
+}
+
+// NewTransactionService creates a new transaction service
+func NewTransactionService() *TransactionService {
+	return &TransactionService{db: config.GetDB()}
+}
+
+// Search returns a page of the user's transactions, newest first. Pages are keyed
+// on (created_at, id) so that rows posted while paging do not shift later pages.
+func (ts *TransactionService) Search(userID uuid.UUID, filter TransactionFilter) (*TransactionPage, error) {
+	query, err := ts.filtered(userID, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	summary, err := ts.summarize(query.Session(&gorm.Session{}))
+	if err != nil {
+		return nil, err
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = defaultTransactionPageSize
+	}
+	if limit > maxTransactionPageSize {
+		limit = maxTransactionPageSize
+	}
+
+	page := query.Session(&gorm.Session{})
+	if filter.Cursor != "" {
+		createdAt, id, err := decodeTransactionCursor(filter.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		page = page.Where("(created_at < ? OR (created_at = ? AND id < ?))", createdAt, createdAt, id)
+	}
+
+	// One extra row tells whether there is a next page
+	var transactions []models.Transaction
+	if err := page.Order("created_at DESC, id DESC").Limit(limit + 1).Find(&transactions).Error; err != nil {
+		return nil, fmt.Errorf("failed to search transactions: %w", err)
+	}
+
+	result := &TransactionPage{Transactions: transactions, Summary: summary}
+	if len(transactions) > limit {
+		result.Transactions = transactions[:limit]
+		result.NextCursor = encodeTransactionCursor(result.Transactions[limit-1])
+	}
+	return result, nil
+}
+
+// Get returns one of the user's transactions with its journal entry and, for
+// transfers, the user on the other side
+func (ts *TransactionService) Get(userID uuid.UUID, transactionID string) (*TransactionDetail, error) {
+	transaction, err := ts.UserTransaction(userID, transactionID)
+	if err != nil {
+		return nil, err
+	}
+
+	notes, err := loadTransactionNotes(ts.db, userID, transaction.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load annotations: %w", err)
+	}
+	detail := &TransactionDetail{Transaction: *transaction, Notes: notes}
+	if transaction.JournalEntryID == nil {
+		return detail, nil
+	}
+
+	var entry models.JournalEntry
+	if err := ts.db.First(&entry, "id = ?", *transaction.JournalEntryID).Error; err != nil {
+		return nil, fmt.Errorf("failed to load journal entry: %w", err)
+	}
+	detail.Entry = &entry
+
+	// The counterparty owns the other wallet the entry moved money on. Exchanges
+	// between the user's own wallets have none.
+	var other models.Transaction
+	err = ts.db.Where("journal_entry_id = ? AND wallet_id NOT IN (?)", entry.ID, ts.userWallets(userID)).
+		First(&other).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return detail, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load counterparty: %w", err)
+	}
+
+	var wallet models.Wallet
+	if err := ts.db.Unscoped().First(&wallet, "id = ?", other.WalletID).Error; err != nil {
+		return nil, fmt.Errorf("failed to load counterparty wallet: %w", err)
+	}
+	var counterparty models.User
+	if err := ts.db.Unscoped().First(&counterparty, "id = ?", wallet.UserID).Error; err != nil {
+		return nil, fmt.Errorf("failed to load counterparty: %w", err)
+	}
+	detail.Counterparty = &Counterparty{
+		UserID:   counterparty.ID,
+		Username: counterparty.Username,
+		Name:     counterparty.Name,
+		WalletID: wallet.ID,
+	}
+	return detail, nil
+}
+
+// UserTransaction loads a transaction from one of the user's wallets, closed ones included
+func (ts *TransactionService) UserTransaction(userID uuid.UUID, transactionID string) (*models.Transaction, error) {
+	id, err := uuid.Parse(transactionID)
+	if err != nil {
+		return nil, ErrTransactionNotFound
+	}
+
+	// Transactions of other users' wallets are reported as missing so that IDs cannot be probed
+	var transaction models.Transaction
+	if err := ts.db.Where("id = ? AND wallet_id IN (?)", id, ts.userWallets(userID)).
+		First(&transaction).Error; err != nil {
+		return nil, ErrTransactionNotFound
+	}
+	return &transaction, nil
+}
+
+// filtered builds the query for every transaction matching the filter
+func (ts *TransactionService) filtered(userID uuid.UUID, filter TransactionFilter) (*gorm.DB, error) {
+	query := ts.db.Model(&models.Transaction{})
+	currency := ""
+
+	if filter.WalletID != "" {
+		walletID, err := uuid.Parse(filter.WalletID)
+		if err != nil {
+			return nil, ErrWalletNotFound
+		}
+		// Closed wallets keep their history, so only access is checked
+		var wallet models.Wallet
+		if err := ts.db.Unscoped().Select("id", "currency").
+			Where("id = ? AND id IN (?)", walletID, ts.userWallets(userID)).First(&wallet).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, ErrWalletNotFound
+			}
+			return nil, err
+		}
+		query = query.Where("wallet_id = ?", walletID)
+		currency = wallet.Currency
+	} else {
+		query = query.Where("wallet_id IN (?)", ts.userWallets(userID))
+	}
+
+	if filter.From != "" {
+		from, _, err := parseFilterTime(filter.From)
+		if err != nil {
+			return nil, fmt.Errorf("%w: from: %v", ErrInvalidFilter, err)
+		}
+		query = query.Where("created_at >= ?", from)
+	}
+	if filter.To != "" {
+		to, dateOnly, err := parseFilterTime(filter.To)
+		if err != nil {
+			return nil, fmt.Errorf("%w: to: %v", ErrInvalidFilter, err)
+		}
+		if dateOnly {
+			to = to.AddDate(0, 0, 1)
+		}
+		query = query.Where("created_at < ?", to)
+	}
+
+	if filter.Type != "" {
+		query = query.Where("type = ?", strings.ToUpper(filter.Type))
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", strings.ToLower(filter.Status))
+	}
+	if filter.Direction != "" {
+		direction := strings.ToLower(filter.Direction)
+		if direction != "in" && direction != "out" {
+			return nil, fmt.Errorf("%w: direction must be in or out", ErrInvalidFilter)
+		}
+		query = query.Where("direction = ?", direction)
+	}
+
+	if filter.Currency != "" {
+		currency = strings.ToUpper(filter.Currency)
+		if !models.IsSupportedCurrency(currency) {
+			return nil, fmt.Errorf("%w: unsupported currency", ErrInvalidFilter)
+		}
+		query = query.Where("currency = ?", currency)
+	}
+	// Amounts are parsed exactly in the currency searched, to its minor units
+	if filter.MinAmount != "" {
+		minAmount, err := models.ParseMoney(filter.MinAmount, currency)
+		if err != nil {
+			return nil, fmt.Errorf("%w: min_amount: %v", ErrInvalidFilter, err)
+		}
+		query = query.Where("amount >= ?", minAmount)
+	}
+	if filter.MaxAmount != "" {
+		maxAmount, err := models.ParseMoney(filter.MaxAmount, currency)
+		if err != nil {
+			return nil, fmt.Errorf("%w: max_amount: %v", ErrInvalidFilter, err)
+		}
+		query = query.Where("amount <= ?", maxAmount)
+	}
+
+	if counterparty := strings.TrimSpace(filter.Counterparty); counterparty != "" {
+		// Entries that also moved money on one of the counterparty's wallets
+		column := "username"
+		if strings.Contains(counterparty, "@") {
+			column = "email"
+		}
+		counterpartyWallets := ts.db.Unscoped().Model(&models.Wallet{}).Select("id").
+			Where("user_id IN (?)", ts.db.Model(&models.User{}).Select("id").Where(column+" = ?", counterparty))
+		query = query.Where("journal_entry_id IN (?)",
+			ts.db.Model(&models.Transaction{}).Select("journal_entry_id").Where("wallet_id IN (?)", counterpartyWallets))
+	}
+
+	if text := strings.TrimSpace(filter.Query); text != "" {
+		query = query.Where("description LIKE ?", "%"+escapeLike(text)+"%")
+	}
+
+	if filter.CategoryID != "" {
+		categoryID, err := uuid.Parse(filter.CategoryID)
+		if err != nil {
+			return nil, ErrCategoryNotFound
+		}
+		query = query.Where("id IN (?)", ts.db.Model(&models.TransactionAnnotation{}).Select("transaction_id").
+			Where("user_id = ? AND category_id = ?", userID, categoryID))
+	}
+	if tag := NormalizeTag(filter.Tag); tag != "" {
+		query = query.Where("id IN (?)", ts.db.Model(&models.TransactionTag{}).Select("transaction_id").
+			Where("user_id = ? AND tag = ?", userID, tag))
+	}
+
+	return query, nil
+}
+
+// summarize totals the matching transactions per currency
+func (ts *TransactionService) summarize(query *gorm.DB) ([]TransactionSummary, error) {
+	rows, err := query.Select("currency, direction, SUM(amount), COUNT(*)").
+		Group("currency, direction").Order("currency").Rows()
+	if err != nil {
+		return nil, fmt.Errorf("failed to summarize transactions: %w", err)
+	}
+	defer rows.Close()
+
+	summary := []TransactionSummary{}
+	index := map[string]int{}
+	for rows.Next() {
+		var currency, direction string
+		var total []byte
+		var count int64
+		if err := rows.Scan(&currency, &direction, &total, &count); err != nil {
+			return nil, fmt.Errorf("failed to summarize transactions: %w", err)
+		}
+
+		i, seen := index[currency]
+		if !seen {
+			i = len(summary)
+			index[currency] = i
+			summary = append(summary, TransactionSummary{
+				Currency: currency,
+				In:       models.ZeroMoney(currency),
+				Out:      models.ZeroMoney(currency),
+				Net:      models.ZeroMoney(currency),
+			})
+		}
+
+		// Scanning into a zero amount binds the total to its currency
+		amount := models.ZeroMoney(currency)
+		if err := amount.Scan(total); err != nil {
+			return nil, fmt.Errorf("failed to summarize transactions: %w", err)
+		}
+		if direction == "out" {
+			summary[i].Out, _ = summary[i].Out.Add(amount)
+			summary[i].Net, _ = summary[i].Net.Sub(amount)
+		} else {
+			summary[i].In, _ = summary[i].In.Add(amount)
+			summary[i].Net, _ = summary[i].Net.Add(amount)
+		}
+		summary[i].Count += count
+	}
+	return summary, rows.Err()
+}
+
+// userWallets selects the IDs of every wallet the user owns or is a member of, closed ones included
+func (ts *TransactionService) userWallets(userID uuid.UUID) *gorm.DB {
+	return memberWallets(ts.db, userID, models.WalletRoleViewer)
+}
+
+// parseFilterTime accepts a date or an RFC 3339 time and reports which one it got
+func parseFilterTime(value string) (time.Time, bool, error) {
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return t, true, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, false, errors.New("expected YYYY-MM-DD or an RFC 3339 time")
+	}
+	return t, false, nil
+}
+
+// encodeTransactionCursor points after the given transaction
+func encodeTransactionCursor(transaction models.Transaction) string {
+	raw := transaction.CreatedAt.UTC().Format(time.RFC3339Nano) + "|" + transaction.ID.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeTransactionCursor reads the position a cursor points after
+func decodeTransactionCursor(cursor string) (time.Time, uuid.UUID, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, uuid.Nil, ErrInvalidCursor
+	}
+	createdAt, id, ok := strings.Cut(string(raw), "|")
+	if !ok {
+		return time.Time{}, uuid.Nil, ErrInvalidCursor
+	}
+	t, err := time.Parse(time.RFC3339Nano, createdAt)
+	if err != nil {
+		return time.Time{}, uuid.Nil, ErrInvalidCursor
+	}
+	parsedID, err := uuid.Parse(id)
+	if err != nil {
+		return time.Time{}, uuid.Nil, ErrInvalidCursor
+	}
+	return t, parsedID, nil
+}
+
+// escapeLike escapes the LIKE wildcards in user input
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
+}
@@ -0,0 +1,399 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"securewallet/internal/config"
+	"securewallet/internal/models"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Default transfer fee and amount constants (amounts are in the wallet currency).
+// The default fees can be changed at runtime through the system settings, and
+// are overridden by any fee rule matching the transaction.
+const (
+	TRANSFER_FEE_BASIS_POINTS = 100       // 1% transfer fee
+	MIN_TRANSFER_FEE          = "1.00"    // Minimum $1 fee
+	MAX_TRANSFER_FEE          = "50.00"   // Maximum $50 fee
+	MIN_TRANSFER_AMOUNT       = "1.00"    // Minimum transfer amount
+	MAX_TRANSFER_AMOUNT       = "1000.00" // Maximum transfer amount
+	MERCHANT_FEE_BASIS_POINTS = 250       // 2.5% merchant fee on checkout payments
+	MERCHANT_FIXED_FEE        = "0.30"    // Plus $0.30 per checkout payment
+)
+
+// MySQL error numbers that mean the transaction can simply be retried
+const (
+	mysqlErrLockWaitTimeout = 1205
+	mysqlErrDeadlock        = 1213
+)
+
+// maxTransferAttempts bounds how often a deadlocked transaction is retried
+const maxTransferAttempts = 5
+
+// Transfer errors
+var (
+	ErrWalletNotFound   = errors.New("wallet not found")
+	ErrSameWallet       = errors.New("cannot transfer to the same wallet")
+	ErrCurrencyMismatch = errors.New("wallets use different currencies")
+	ErrInvalidAmount    = errors.New("amount must be positive")
+)
+
+// InsufficientFundsError reports a wallet that cannot cover a debit
+type InsufficientFundsError struct {
+	Balance  models.Money
+	Required models.Money
+}
+
+func (e *InsufficientFundsError) Error() string {
+	return fmt.Sprintf("insufficient balance: %s available, %s required", e.Balance, e.Required)
+}
+
+// TransferService moves money between wallets with row locking so that
+// concurrent requests cannot overdraw a wallet
+type TransferService struct {
+	db     *gorm.DB
+	ledger *LedgerService
+	limits *LimitService
+	fees   *FeeService
+}
+
+// DepositInput describes a deposit into a wallet
+type DepositInput struct {
+	WalletID    uuid.UUID
+	Amount      models.Money
+	Description string
+	InitiatedBy uuid.UUID // Member making the deposit
+}
+
+// DepositResult is the outcome of a deposit
+type DepositResult struct {
+	Wallet      models.Wallet
+	Entry       *models.JournalEntry
+	Transaction *models.Transaction
+}
+
+// TransferInput describes a transfer between two wallets
+type TransferInput struct {
+	SenderWalletID    uuid.UUID
+	RecipientWalletID uuid.UUID
+	Amount            models.Money
+	Description       string
+	SenderMemo        string
+	RecipientMemo     string
+	InitiatedBy       uuid.UUID // Member paying from the sender wallet; checked against their role and spend limit
+
+	approved bool   // Set when executing a payment instruction; organisation wallets only pay out approved ones
+	feeType  string // Fee rules to price the transfer with, transfer by default. For checkout the recipient merchant pays the fee instead of the sender
+}
+
+// TransferResult is the outcome of a transfer
+type TransferResult struct {
+	SenderWallet      models.Wallet
+	RecipientWallet   models.Wallet
+	Fee               models.Money
+	FeeRuleID         *uuid.UUID // Nil when the default fee applied
+	Total             models.Money
+	Entry             *models.JournalEntry
+	SenderTransaction *models.Transaction
+	RoundUp           models.Money // Change swept into the sender's round-up pocket
+}
+
+// NewTransferService creates a new transfer service
+func NewTransferService() *TransferService {
+	return &TransferService{
+		db:     config.GetDB(),
+		ledger: NewLedgerService(),
+		limits: NewLimitService(),
+		fees:   NewFeeService(),
+	}
+}
+
+// CalculateTransferFee applies the default percentage fee, clamped to the minimum
+// and maximum fee. It prices transfers that no fee rule matches.
+func CalculateTransferFee(amount models.Money) models.Money {
+	settings := CurrentSettings().Transfers
+	fee := amount.Mul(settings.FeeBasisPoints, 10000, models.RoundHalfUp)
+	minFee, err := settings.MinFee.RoundTo(amount.Currency, models.RoundHalfUp)
+	if err != nil {
+		minFee = models.MustParseMoney(MIN_TRANSFER_FEE, amount.Currency)
+	}
+	maxFee, err := settings.MaxFee.RoundTo(amount.Currency, models.RoundHalfUp)
+	if err != nil {
+		maxFee = models.MustParseMoney(MAX_TRANSFER_FEE, amount.Currency)
+	}
+	return fee.Max(minFee).Min(maxFee)
+}
+
+// CalculateMerchantFee applies the default merchant percentage plus the fixed
+// fee, for checkout payments that no fee rule matches. The fee never exceeds the
+// amount it is taken from.
+func CalculateMerchantFee(amount models.Money) models.Money {
+	settings := CurrentSettings().Merchants
+	fee := amount.Mul(settings.FeeBasisPoints, 10000, models.RoundHalfUp)
+	fixedFee, err := settings.FixedFee.RoundTo(amount.Currency, models.RoundHalfUp)
+	if err != nil {
+		fixedFee = models.MustParseMoney(MERCHANT_FIXED_FEE, amount.Currency)
+	}
+	fee, _ = fee.Add(fixedFee)
+	return fee.Min(amount)
+}
+
+// Deposit credits a wallet from external cash
+func (ts *TransferService) Deposit(input DepositInput) (*DepositResult, error) {
+	if !input.Amount.IsPositive() {
+		return nil, ErrInvalidAmount
+	}
+
+	var result DepositResult
+	err := ts.withRetry(func(tx *gorm.DB) error {
+		wallets, err := ts.lockWallets(tx, input.WalletID)
+		if err != nil {
+			return err
+		}
+		wallet := wallets[input.WalletID]
+
+		walletAccount, err := ts.ledger.WalletAccount(tx, &wallet)
+		if err != nil {
+			return err
+		}
+		cashAccount, err := ts.ledger.SystemAccount(tx, SystemAccountExternalCash, wallet.Currency)
+		if err != nil {
+			return err
+		}
+
+		// Post the deposit: external cash -> wallet
+		entry, err := ts.ledger.PostEntry(tx, LedgerEntry{
+			Type:        "DEPOSIT",
+			Description: input.Description,
+			InitiatedBy: initiator(input.InitiatedBy),
+			Lines: []LedgerLine{
+				{AccountID: cashAccount.ID, Amount: input.Amount.Neg()},
+				{AccountID: walletAccount.ID, Amount: input.Amount, Memo: input.Description},
+			},
+		})
+		if err != nil {
+			return err
+		}
+
+		if err := tx.First(&result.Wallet, "id = ?", wallet.ID).Error; err != nil {
+			return err
+		}
+		result.Entry = entry
+		result.Transaction = ts.ledger.TransactionFor(entry, wallet.ID)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// Transfer moves an amount plus the transfer fee out of the sender wallet,
+// credits the recipient and books the fee as revenue
+func (ts *TransferService) Transfer(input TransferInput) (*TransferResult, error) {
+	var result *TransferResult
+	err := ts.withRetry(func(tx *gorm.DB) error {
+		var err error
+		result, err = ts.transfer(tx, input)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// transfer books a transfer inside an open transaction, so that callers can
+// combine it with their own updates (e.g. marking a payment request paid)
+func (ts *TransferService) transfer(tx *gorm.DB, input TransferInput) (*TransferResult, error) {
+	if !input.Amount.IsPositive() {
+		return nil, ErrInvalidAmount
+	}
+	if input.SenderWalletID == input.RecipientWalletID {
+		return nil, ErrSameWallet
+	}
+
+	wallets, err := ts.lockWallets(tx, input.SenderWalletID, input.RecipientWalletID)
+	if err != nil {
+		return nil, err
+	}
+	sender := wallets[input.SenderWalletID]
+	recipient := wallets[input.RecipientWalletID]
+	if sender.OrganizationID != nil && !input.approved {
+		return nil, ErrApprovalRequired
+	}
+
+	// Transfers do not convert between currencies
+	if sender.Currency != input.Amount.Currency || recipient.Currency != sender.Currency {
+		return nil, ErrCurrencyMismatch
+	}
+
+	// The sender pays the fee, except on checkout payments where the merchant does
+	feeType := input.feeType
+	if feeType == "" {
+		feeType = models.FeeTypeTransfer
+	}
+	checkout := feeType == models.FeeTypeCheckout
+	payerID := sender.UserID
+	if checkout {
+		payerID = recipient.UserID
+	}
+	quote, err := ts.fees.Quote(tx, payerID, feeType, input.Amount)
+	if err != nil {
+		return nil, err
+	}
+	fee, total := quote.Fee, quote.Total
+
+	// The sender row is locked, so this check cannot race with another debit.
+	// A credit line lets the sender go below zero down to its limit.
+	spendable, err := spendableBalance(tx, &sender)
+	if err != nil {
+		return nil, err
+	}
+	if spendable.Amount < total.Amount {
+		return nil, &InsufficientFundsError{Balance: spendable, Required: total}
+	}
+
+	// Limits are checked under the same locks so concurrent transfers cannot exceed them
+	if err := ts.limits.Check(tx, sender.UserID, input.Amount); err != nil {
+		return nil, err
+	}
+	if err := checkMemberSpend(tx, &sender, input.InitiatedBy, total); err != nil {
+		return nil, err
+	}
+
+	senderAccount, err := ts.ledger.WalletAccount(tx, &sender)
+	if err != nil {
+		return nil, err
+	}
+	recipientAccount, err := ts.ledger.WalletAccount(tx, &recipient)
+	if err != nil {
+		return nil, err
+	}
+	feeAccount, err := ts.ledger.SystemAccount(tx, SystemAccountFeeRevenue, sender.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	// Sender pays amount + fee, recipient receives amount, fee goes to revenue.
+	// Fee lines are left out when a rule or free quota waives the fee.
+	senderMemo := input.SenderMemo
+	if fee.IsPositive() && !checkout {
+		senderMemo += " + " + fee.String() + " fee"
+	}
+	ledgerEntry := LedgerEntry{
+		Type:        "TRANSFER",
+		Description: input.Description,
+		InitiatedBy: initiator(input.InitiatedBy),
+		FeeRuleID:   quote.RuleID,
+		Lines: []LedgerLine{
+			{AccountID: senderAccount.ID, Amount: input.Amount.Neg(), Memo: senderMemo},
+			{AccountID: recipientAccount.ID, Amount: input.Amount, Memo: input.RecipientMemo},
+		},
+	}
+	switch {
+	case checkout:
+		// Checkout payments: sender pays amount, the merchant fee comes out of what the merchant receives
+		ledgerEntry.Type = "CHECKOUT"
+		if fee.IsPositive() {
+			ledgerEntry.Lines = append(ledgerEntry.Lines,
+				LedgerLine{AccountID: recipientAccount.ID, Amount: fee.Neg(), Memo: "Merchant fee"},
+				LedgerLine{AccountID: feeAccount.ID, Amount: fee, Memo: "Merchant fee"},
+			)
+		}
+	case fee.IsPositive():
+		ledgerEntry.Lines = append(ledgerEntry.Lines,
+			LedgerLine{AccountID: senderAccount.ID, Amount: fee.Neg(), Memo: "Transfer fee"},
+			LedgerLine{AccountID: feeAccount.ID, Amount: fee, Memo: "Transfer fee"},
+		)
+	}
+	entry, err := ts.ledger.PostEntry(tx, ledgerEntry)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ts.fees.Record(tx, quote, payerID, entry.ID); err != nil {
+		return nil, err
+	}
+
+	if err := ts.limits.Record(tx, sender.UserID, input.Amount, entry.ID); err != nil {
+		return nil, err
+	}
+
+	roundUp, err := sweepRoundUp(tx, ts.ledger, sender.ID, input.Amount, input.InitiatedBy)
+	if err != nil {
+		return nil, err
+	}
+
+	result := TransferResult{Fee: fee, FeeRuleID: quote.RuleID, Total: total, Entry: entry, RoundUp: roundUp}
+	if err := tx.First(&result.SenderWallet, "id = ?", sender.ID).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.First(&result.RecipientWallet, "id = ?", recipient.ID).Error; err != nil {
+		return nil, err
+	}
+	result.SenderTransaction = ts.ledger.TransactionFor(entry, sender.ID)
+	return &result, nil
+}
+
+// lockWallets loads wallets with SELECT ... FOR UPDATE, always in ID order so
+// that two transfers touching the same pair of wallets cannot deadlock each other
+func (ts *TransferService) lockWallets(tx *gorm.DB, ids ...uuid.UUID) (map[uuid.UUID]models.Wallet, error) {
+	sorted := make([]string, 0, len(ids))
+	for _, id := range ids {
+		sorted = append(sorted, id.String())
+	}
+	sort.Strings(sorted)
+
+	wallets := make(map[uuid.UUID]models.Wallet, len(ids))
+	for _, id := range sorted {
+		var wallet models.Wallet
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&wallet, "id = ?", id).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrWalletNotFound
+		}
+		if err != nil {
+			return nil, err
+		}
+		if wallet.Status == models.WalletStatusClosed {
+			return nil, ErrWalletClosed
+		}
+		wallets[wallet.ID] = wallet
+	}
+
+	return wallets, nil
+}
+
+// withRetry runs fn in a database transaction, retrying when MySQL reports a
+// deadlock or lock wait timeout
+func (ts *TransferService) withRetry(fn func(tx *gorm.DB) error) error {
+	var err error
+	for attempt := 1; attempt <= maxTransferAttempts; attempt++ {
+		err = ts.db.Transaction(fn)
+		if !isRetryableLockError(err) {
+			return err
+		}
+		log.Printf("Transfer transaction hit a lock conflict (attempt %d/%d): %v", attempt, maxTransferAttempts, err)
+		time.Sleep(time.Duration(attempt*attempt) * 10 * time.Millisecond)
+	}
+	return err
+}
+
+// isRetryableLockError reports whether err is a MySQL deadlock or lock wait timeout
+func isRetryableLockError(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		return mysqlErr.Number == mysqlErrDeadlock || mysqlErr.Number == mysqlErrLockWaitTimeout
+	}
+	return false
+}
@@ -0,0 +1,213 @@
+package services
+
+import (
+	"errors"
+	"math/rand"
+	"sync"
+	"testing"
+
+	"securewallet/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// allowManyTransfers raises the global limits so that tests are bounded by balances only
+func allowManyTransfers(t *testing.T, db *gorm.DB, currency string) {
+	t.Helper()
+
+	count := 1000000
+	amount := mustMoney("1000000.00", currency)
+	if err := db.Create(&models.TransferLimit{
+		Scope:             models.LimitScopeGlobal,
+		Currency:          currency,
+		MaxPerTransaction: &amount,
+		DailyAmount:       &amount,
+		MonthlyAmount:     &amount,
+		DailyCount:        &count,
+		MonthlyCount:      &count,
+	}).Error; err != nil {
+		t.Fatalf("failed to create transfer limit: %v", err)
+	}
+}
+
+// feeRevenue returns the fees collected so far in a currency
+func feeRevenue(t *testing.T, db *gorm.DB, currency string) models.Money {
+	t.Helper()
+
+	ledger := NewLedgerService()
+	account, err := ledger.SystemAccount(db, SystemAccountFeeRevenue, currency)
+	if err != nil {
+		t.Fatalf("failed to load fee account: %v", err)
+	}
+	balance, err := ledger.AccountBalance(db, account)
+	if err != nil {
+		t.Fatalf("failed to load fee revenue: %v", err)
+	}
+	return balance
+}
+
+func TestConcurrentTransfersConserveMoney(t *testing.T) {
+	db := setupTestDB(t)
+	allowManyTransfers(t, db, "USD")
+
+	const walletCount, transferCount = 8, 400
+	wallets := make([]*models.Wallet, walletCount)
+	for i := range wallets {
+		wallets[i] = createTestWallet(t, db, "USD")
+		deposit(t, wallets[i], "100.00")
+	}
+	deposited := int64(walletCount * 10000)
+
+	// Random pairs in both directions, so that transfers lock the same wallets in opposite orders
+	random := rand.New(rand.NewSource(1))
+	inputs := make([]TransferInput, transferCount)
+	for i := range inputs {
+		from := random.Intn(walletCount)
+		to := (from + 1 + random.Intn(walletCount-1)) % walletCount
+		inputs[i] = TransferInput{
+			SenderWalletID:    wallets[from].ID,
+			RecipientWalletID: wallets[to].ID,
+			Amount:            models.NewMoney(int64(100+random.Intn(3000)), "USD"),
+			Description:       "Concurrent transfer",
+			InitiatedBy:       wallets[from].UserID,
+		}
+	}
+
+	var mu sync.Mutex
+	succeeded, declined := 0, 0
+	var wg sync.WaitGroup
+	for _, input := range inputs {
+		wg.Add(1)
+		go func(input TransferInput) {
+			defer wg.Done()
+			_, err := NewTransferService().Transfer(input)
+
+			mu.Lock()
+			defer mu.Unlock()
+			var insufficient *InsufficientFundsError
+			switch {
+			case err == nil:
+				succeeded++
+			case errors.As(err, &insufficient):
+				declined++
+			default:
+				t.Errorf("transfer failed: %v", err)
+			}
+		}(input)
+	}
+	wg.Wait()
+
+	if succeeded == 0 {
+		t.Fatal("no transfer succeeded")
+	}
+	t.Logf("%d transfers succeeded, %d declined for insufficient funds", succeeded, declined)
+
+	// Money only moved between the wallets and the fee account
+	total := feeRevenue(t, db, "USD").Amount
+	for _, wallet := range wallets {
+		balance := walletBalance(t, db, wallet.ID)
+		if balance.IsNegative() {
+			t.Errorf("wallet %s overdrawn: %s", wallet.ID, balance)
+		}
+		total += balance.Amount
+	}
+	if total != deposited {
+		t.Errorf("wallets and fees hold %s, want %s", models.NewMoney(total, "USD"), models.NewMoney(deposited, "USD"))
+	}
+
+	var entries int64
+	db.Model(&models.JournalEntry{}).Where("type = ?", "TRANSFER").Count(&entries)
+	if entries != int64(succeeded) {
+		t.Errorf("%d transfer entries posted for %d successful transfers", entries, succeeded)
+	}
+	if total := postingTotal(t, db, "USD"); !total.IsZero() {
+		t.Errorf("postings sum to %s, want zero", total)
+	}
+	discrepancies, err := NewLedgerService().ReconcileWallets()
+	if err != nil {
+		t.Fatalf("ReconcileWallets failed: %v", err)
+	}
+	if len(discrepancies) != 0 {
+		t.Errorf("wallets out of balance with the ledger: %+v", discrepancies)
+	}
+}
+
+func TestConcurrentTransfersCannotOverdraw(t *testing.T) {
+	db := setupTestDB(t)
+	allowManyTransfers(t, db, "USD")
+	sender := createTestWallet(t, db, "USD")
+	recipient := createTestWallet(t, db, "USD")
+	deposit(t, sender, "50.00")
+
+	// Each transfer costs 11.00 with the 1.00 minimum fee, so only four fit
+	const attempts = 200
+	var mu sync.Mutex
+	succeeded := 0
+	var wg sync.WaitGroup
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := NewTransferService().Transfer(TransferInput{
+				SenderWalletID:    sender.ID,
+				RecipientWalletID: recipient.ID,
+				Amount:            mustMoney("10.00", "USD"),
+				InitiatedBy:       sender.UserID,
+			})
+
+			mu.Lock()
+			defer mu.Unlock()
+			var insufficient *InsufficientFundsError
+			switch {
+			case err == nil:
+				succeeded++
+			case !errors.As(err, &insufficient):
+				t.Errorf("transfer failed: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if succeeded != 4 {
+		t.Errorf("%d transfers succeeded, want 4", succeeded)
+	}
+	if got := walletBalance(t, db, sender.ID); got.Amount != 600 {
+		t.Errorf("sender balance = %s, want 6.00", got)
+	}
+	if got := walletBalance(t, db, recipient.ID); got.Amount != 4000 {
+		t.Errorf("recipient balance = %s, want 40.00", got)
+	}
+	if got := feeRevenue(t, db, "USD"); got.Amount != 400 {
+		t.Errorf("fee revenue = %s, want 4.00", got)
+	}
+}
+
+func TestTransferRejectsInvalidInput(t *testing.T) {
+	db := setupTestDB(t)
+	allowManyTransfers(t, db, "USD")
+	sender := createTestWallet(t, db, "USD")
+	recipient := createTestWallet(t, db, "USD")
+	euros := createTestWallet(t, db, "EUR")
+	deposit(t, sender, "100.00")
+
+	tests := []struct {
+		name  string
+		input TransferInput
+		want  error
+	}{
+		{"zero amount", TransferInput{SenderWalletID: sender.ID, RecipientWalletID: recipient.ID, Amount: models.ZeroMoney("USD")}, ErrInvalidAmount},
+		{"negative amount", TransferInput{SenderWalletID: sender.ID, RecipientWalletID: recipient.ID, Amount: mustMoney("-5.00", "USD")}, ErrInvalidAmount},
+		{"same wallet", TransferInput{SenderWalletID: sender.ID, RecipientWalletID: sender.ID, Amount: mustMoney("5.00", "USD")}, ErrSameWallet},
+		{"other currency", TransferInput{SenderWalletID: sender.ID, RecipientWalletID: euros.ID, Amount: mustMoney("5.00", "USD")}, ErrCurrencyMismatch},
+	}
+	for _, tt := range tests {
+		tt.input.InitiatedBy = sender.UserID
+		if _, err := NewTransferService().Transfer(tt.input); !errors.Is(err, tt.want) {
+			t.Errorf("%s: Transfer error = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+
+	if got := walletBalance(t, db, sender.ID); got.Amount != 10000 {
+		t.Errorf("sender balance = %s, want 100.00", got)
+	}
+}
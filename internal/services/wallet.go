@@ -0,0 +1,323 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"securewallet/internal/config"
+	"securewallet/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Wallet errors
+var (
+	ErrWalletClosed        = errors.New("wallet is closed")
+	ErrWalletNotEmpty      = errors.New("wallet balance must be zero to close it")
+	ErrUnsupportedCurrency = errors.New("unsupported currency")
+	ErrNoRecipientWallet   = errors.New("recipient has no wallet in this currency")
+	ErrRecipientNotFound   = errors.New("recipient not found")
+	ErrWalletHasPayouts    = errors.New("wallet has withdrawals in progress or within their return window")
+	ErrWalletHasPockets    = errors.New("wallet still has money in pockets")
+	ErrInvalidWalletType   = errors.New("invalid wallet type")
+)
+
+// maxWalletsPerUser limits how many open wallets a user can hold
+const maxWalletsPerUser = 10
+
+// WalletService manages a user's wallets
+type WalletService struct {
+	db *gorm.DB
+}
+
+// NewWalletService creates a new wallet service
+func NewWalletService() *WalletService {
+	return &WalletService{
+		db: config.GetDB(),
+	}
+}
+
+// UserWallet returns an active wallet the user owns or is a member of. An empty
+// walletID selects the user's default wallet.
+func (ws *WalletService) UserWallet(userID uuid.UUID, walletID string) (*models.Wallet, error) {
+	return ws.MemberWallet(userID, walletID, models.WalletRoleViewer)
+}
+
+// MemberWallet returns an active wallet on which the user holds at least the
+// given role. An empty walletID selects the user's default wallet.
+func (ws *WalletService) MemberWallet(userID uuid.UUID, walletID, minRole string) (*models.Wallet, error) {
+	if walletID == "" {
+		return ws.DefaultWallet(userID)
+	}
+
+	id, err := uuid.Parse(walletID)
+	if err != nil {
+		return nil, ErrWalletNotFound
+	}
+
+	// Wallets the user has no access to are reported as missing so that IDs cannot be probed
+	var wallet models.Wallet
+	if err := ws.db.First(&wallet, "id = ?", id).Error; err != nil {
+		return nil, ErrWalletNotFound
+	}
+	role, err := walletRole(ws.db, &wallet, userID)
+	if err != nil {
+		return nil, err
+	}
+	if role == "" {
+		return nil, ErrWalletNotFound
+	}
+	if !hasWalletRole(role, minRole) {
+		return nil, ErrWalletPermission
+	}
+	if wallet.Status == models.WalletStatusClosed {
+		return nil, ErrWalletClosed
+	}
+
+	return &wallet, nil
+}
+
+// WalletRole returns the user's role on a wallet, or an empty string if they have no access
+func (ws *WalletService) WalletRole(userID uuid.UUID, wallet *models.Wallet) (string, error) {
+	return walletRole(ws.db, wallet, userID)
+}
+
+// DefaultWallet returns the user's default wallet, falling back to the oldest active
+// personal one
+func (ws *WalletService) DefaultWallet(userID uuid.UUID) (*models.Wallet, error) {
+	var wallet models.Wallet
+	err := ws.db.Where("user_id = ? AND organization_id IS NULL AND status = ?", userID, models.WalletStatusActive).
+		Order("is_default DESC, created_at ASC").
+		First(&wallet).Error
+	if err != nil {
+		return nil, ErrWalletNotFound
+	}
+	return &wallet, nil
+}
+
+// FindRecipient looks up the user to send money to or request money from by email or username
+func (ws *WalletService) FindRecipient(identifier string) (*models.User, error) {
+	identifier = strings.TrimSpace(identifier)
+	if identifier == "" {
+		return nil, ErrRecipientNotFound
+	}
+
+	var user models.User
+	query := ws.db.Where("username = ?", identifier)
+	if strings.Contains(identifier, "@") {
+		query = ws.db.Where("email = ?", identifier)
+	}
+	if err := query.First(&user).Error; err != nil {
+		return nil, ErrRecipientNotFound
+	}
+	return &user, nil
+}
+
+// RecipientWallet picks the personal wallet that receives a transfer in the given currency:
+// the default wallet when it matches, otherwise the oldest active wallet in that currency
+func (ws *WalletService) RecipientWallet(userID uuid.UUID, currency string) (*models.Wallet, error) {
+	var wallet models.Wallet
+	err := ws.db.Where("user_id = ? AND organization_id IS NULL AND currency = ? AND status = ?", userID, currency, models.WalletStatusActive).
+		Order("is_default DESC, created_at ASC").
+		First(&wallet).Error
+	if err != nil {
+		return nil, ErrNoRecipientWallet
+	}
+	return &wallet, nil
+}
+
+// ListWallets returns the user's own wallets followed by the wallets shared with
+// them, optionally including closed ones
+func (ws *WalletService) ListWallets(userID uuid.UUID, includeClosed bool) ([]models.Wallet, error) {
+	query := ws.db.Where("id IN (?)", memberWallets(ws.db, userID, models.WalletRoleViewer))
+	if !includeClosed {
+		query = query.Where("status = ?", models.WalletStatusActive)
+	}
+
+	var wallets []models.Wallet
+	order := clause.Expr{SQL: "user_id = ? DESC, is_default DESC, created_at ASC", Vars: []interface{}{userID}, WithoutParentheses: true}
+	if err := query.Order(clause.OrderBy{Expression: order}).Find(&wallets).Error; err != nil {
+		return nil, err
+	}
+	return wallets, nil
+}
+
+// CreateWallet opens a new wallet. The user's first wallet becomes the default.
+func (ws *WalletService) CreateWallet(userID uuid.UUID, name, currency, walletType string, makeDefault bool) (*models.Wallet, error) {
+	currency = strings.ToUpper(currency)
+	if !models.IsSupportedCurrency(currency) {
+		return nil, ErrUnsupportedCurrency
+	}
+	if walletType == "" {
+		walletType = models.WalletTypeCurrent
+	}
+	if walletType != models.WalletTypeCurrent && walletType != models.WalletTypeSavings {
+		return nil, ErrInvalidWalletType
+	}
+
+	var wallet models.Wallet
+	err := ws.db.Transaction(func(tx *gorm.DB) error {
+		var openWallets int64
+		if err := tx.Model(&models.Wallet{}).
+			Where("user_id = ? AND organization_id IS NULL AND status = ?", userID, models.WalletStatusActive).
+			Count(&openWallets).Error; err != nil {
+			return err
+		}
+		if openWallets >= maxWalletsPerUser {
+			return fmt.Errorf("cannot hold more than %d open wallets", maxWalletsPerUser)
+		}
+
+		if name == "" {
+			name = currency + " wallet"
+		}
+		wallet = models.Wallet{
+			UserID:    userID,
+			Name:      name,
+			Type:      walletType,
+			Balance:   models.ZeroMoney(currency),
+			Currency:  currency,
+			IsDefault: makeDefault || openWallets == 0,
+			Status:    models.WalletStatusActive,
+		}
+
+		if wallet.IsDefault {
+			if err := clearDefaultWallet(tx, userID); err != nil {
+				return err
+			}
+		}
+		return tx.Create(&wallet).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &wallet, nil
+}
+
+// UpdateWallet renames a wallet and/or makes it the default. Only owners can
+// rename a shared wallet and only its account holder can make it their default.
+func (ws *WalletService) UpdateWallet(userID uuid.UUID, walletID string, name *string, makeDefault bool) (*models.Wallet, error) {
+	wallet, err := ws.MemberWallet(userID, walletID, models.WalletRoleOwner)
+	if err != nil {
+		return nil, err
+	}
+	if makeDefault && (wallet.UserID != userID || wallet.OrganizationID != nil) {
+		return nil, ErrWalletPermission
+	}
+
+	err = ws.db.Transaction(func(tx *gorm.DB) error {
+		updates := map[string]interface{}{}
+		if name != nil {
+			updates["name"] = *name
+		}
+		if makeDefault && !wallet.IsDefault {
+			if err := clearDefaultWallet(tx, userID); err != nil {
+				return err
+			}
+			updates["is_default"] = true
+		}
+		if len(updates) == 0 {
+			return nil
+		}
+		return tx.Model(wallet).Updates(updates).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ws.db.First(wallet, "id = ?", wallet.ID).Error; err != nil {
+		return nil, err
+	}
+	return wallet, nil
+}
+
+// CloseWallet closes an empty wallet whose pockets are empty too. If it was
+// the default, the oldest remaining wallet takes over.
+func (ws *WalletService) CloseWallet(userID uuid.UUID, walletID string) (*models.Wallet, error) {
+	wallet, err := ws.MemberWallet(userID, walletID, models.WalletRoleOwner)
+	if err != nil {
+		return nil, err
+	}
+
+	err = ws.db.Transaction(func(tx *gorm.DB) error {
+		// Lock the row so that no transfer can credit the wallet while it closes
+		var locked models.Wallet
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&locked, "id = ?", wallet.ID).Error; err != nil {
+			return err
+		}
+		if !locked.Balance.IsZero() {
+			return ErrWalletNotEmpty
+		}
+
+		// Failed and returned withdrawals are credited back, so the wallet must stay
+		// open until they finish and settled ones are past the return window
+		var inFlight int64
+		if err := tx.Model(&models.Payout{}).
+			Where("wallet_id = ? AND (status IN ? OR (status = ? AND settled_at >= ?))", locked.ID,
+				[]string{models.PayoutStatusPending, models.PayoutStatusProcessing},
+				models.PayoutStatusSettled, time.Now().Add(-payoutReturnWindow)).
+			Count(&inFlight).Error; err != nil {
+			return err
+		}
+		if inFlight > 0 {
+			return ErrWalletHasPayouts
+		}
+
+		// Money set aside in pockets has to be moved out first; empty pockets close with the wallet
+		var pocketMoney int64
+		if err := tx.Model(&models.Pocket{}).
+			Where("wallet_id = ? AND balance <> 0", locked.ID).
+			Count(&pocketMoney).Error; err != nil {
+			return err
+		}
+		if pocketMoney > 0 {
+			return ErrWalletHasPockets
+		}
+		if err := tx.Where("wallet_id = ?", locked.ID).Delete(&models.Pocket{}).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		if err := tx.Model(&locked).Updates(map[string]interface{}{
+			"status":     models.WalletStatusClosed,
+			"is_default": false,
+			"closed_at":  &now,
+		}).Error; err != nil {
+			return err
+		}
+
+		if !locked.IsDefault {
+			return nil
+		}
+		var next models.Wallet
+		err := tx.Where("user_id = ? AND organization_id IS NULL AND status = ?", locked.UserID, models.WalletStatusActive).
+			Order("created_at ASC").
+			First(&next).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		return tx.Model(&next).Update("is_default", true).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ws.db.First(wallet, "id = ?", wallet.ID).Error; err != nil {
+		return nil, err
+	}
+	return wallet, nil
+}
+
+// clearDefaultWallet unsets the default flag on all of the user's wallets
+func clearDefaultWallet(tx *gorm.DB, userID uuid.UUID) error {
+	return tx.Model(&models.Wallet{}).
+		Where("user_id = ? AND is_default = ?", userID, true).
+		Update("is_default", false).Error
+}
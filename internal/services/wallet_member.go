@@ -0,0 +1,512 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"securewallet/internal/config"
+	"securewallet/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Wallet membership limits
+const (
+	maxMembersPerWallet = 10
+	walletInviteTTL     = 7 * 24 * time.Hour
+)
+
+// walletRoleRanks orders the member roles; a higher role can do everything a lower one can
+var walletRoleRanks = map[string]int{
+	models.WalletRoleViewer:  1,
+	models.WalletRoleSpender: 2,
+	models.WalletRoleOwner:   3,
+}
+
+// memberSpendExcludedTypes keep money inside the wallet, so they do not count towards a spend limit
+var memberSpendExcludedTypes = []string{"POCKET_TRANSFER", "ROUND_UP"}
+
+// Wallet member errors
+var (
+	ErrWalletPermission  = errors.New("your role on this wallet does not allow this")
+	ErrInvalidMemberRole = errors.New("role must be owner, spender or viewer and the spend limit cannot be negative")
+	ErrMemberNotFound    = errors.New("wallet member not found")
+	ErrAlreadyMember     = errors.New("user already has access to this wallet")
+	ErrTooManyMembers    = errors.New("wallet already has the maximum number of members")
+	ErrInviteNotFound    = errors.New("wallet invite not found")
+	ErrInviteExists      = errors.New("this email already has a pending invite to the wallet")
+	ErrInviteExpired     = errors.New("wallet invite has expired")
+	ErrWalletHolder      = errors.New("the account holder of a wallet cannot be changed or removed")
+)
+
+// MemberSpendLimitError reports a member who would go over their monthly spend limit
+type MemberSpendLimitError struct {
+	Limit    models.Money
+	Spent    models.Money
+	Required models.Money
+}
+
+func (e *MemberSpendLimitError) Error() string {
+	return fmt.Sprintf("monthly spend limit on this wallet exceeded: %s of %s used, %s required", e.Spent, e.Limit, e.Required)
+}
+
+// WalletMemberInfo describes someone with access to a wallet
+type WalletMemberInfo struct {
+	MemberID       *uuid.UUID   `json:"member_id,omitempty"` // Empty for the account holder
+	UserID         uuid.UUID    `json:"user_id"`
+	Username       string       `json:"username"`
+	Name           string       `json:"name"`
+	Role           string       `json:"role"`
+	Holder         bool         `json:"holder"`
+	SpendLimit     models.Money `json:"spend_limit"`
+	SpentThisMonth models.Money `json:"spent_this_month"`
+	JoinedAt       time.Time    `json:"joined_at"`
+}
+
+// WalletMemberService manages who can see and spend from a joint or shared wallet
+type WalletMemberService struct {
+	db      *gorm.DB
+	wallets *WalletService
+}
+
+// NewWalletMemberService creates a new wallet member service
+func NewWalletMemberService() *WalletMemberService {
+	return &WalletMemberService{
+		db:      config.GetDB(),
+		wallets: NewWalletService(),
+	}
+}
+
+// Members lists the account holder and every member of a wallet the user can see
+func (ms *WalletMemberService) Members(userID uuid.UUID, walletID string) ([]WalletMemberInfo, error) {
+	wallet, err := ms.wallets.UserWallet(userID, walletID)
+	if err != nil {
+		return nil, err
+	}
+	if wallet.OrganizationID != nil {
+		return nil, ErrOrganizationWallet
+	}
+
+	var holder models.User
+	if err := ms.db.First(&holder, "id = ?", wallet.UserID).Error; err != nil {
+		return nil, err
+	}
+	var members []models.WalletMember
+	if err := ms.db.Preload("User").Where("wallet_id = ?", wallet.ID).Order("created_at ASC").Find(&members).Error; err != nil {
+		return nil, err
+	}
+
+	since := monthStart(time.Now())
+	holderSpent, err := memberSpent(ms.db, wallet, holder.ID, since)
+	if err != nil {
+		return nil, err
+	}
+	infos := []WalletMemberInfo{{
+		UserID:         holder.ID,
+		Username:       holder.Username,
+		Name:           holder.Name,
+		Role:           models.WalletRoleOwner,
+		Holder:         true,
+		SpendLimit:     models.ZeroMoney(wallet.Currency),
+		SpentThisMonth: holderSpent,
+		JoinedAt:       wallet.CreatedAt,
+	}}
+	for i := range members {
+		member := &members[i]
+		spent, err := memberSpent(ms.db, wallet, member.UserID, since)
+		if err != nil {
+			return nil, err
+		}
+		infos = append(infos, WalletMemberInfo{
+			MemberID:       &member.ID,
+			UserID:         member.UserID,
+			Username:       member.User.Username,
+			Name:           member.User.Name,
+			Role:           member.Role,
+			SpendLimit:     member.SpendLimit,
+			SpentThisMonth: spent,
+			JoinedAt:       member.CreatedAt,
+		})
+	}
+	return infos, nil
+}
+
+// Invite asks someone to join a wallet by email. Only owners can invite.
+func (ms *WalletMemberService) Invite(userID uuid.UUID, walletID, email, role string, spendLimit models.Money) (*models.WalletInvite, error) {
+	wallet, err := ms.wallets.MemberWallet(userID, walletID, models.WalletRoleOwner)
+	if err != nil {
+		return nil, err
+	}
+	if wallet.OrganizationID != nil {
+		return nil, ErrOrganizationWallet
+	}
+	limit, err := validateMemberRole(wallet, role, spendLimit)
+	if err != nil {
+		return nil, err
+	}
+	email = strings.ToLower(strings.TrimSpace(email))
+	if email == "" {
+		return nil, ErrRecipientNotFound
+	}
+
+	// Someone who already has access cannot be invited again
+	var invitee models.User
+	err = ms.db.Where("email = ?", email).First(&invitee).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if err == nil {
+		role, err := walletRole(ms.db, wallet, invitee.ID)
+		if err != nil {
+			return nil, err
+		}
+		if role != "" {
+			return nil, ErrAlreadyMember
+		}
+	}
+
+	var pending int64
+	if err := ms.db.Model(&models.WalletInvite{}).
+		Where("wallet_id = ? AND email = ? AND status = ? AND expires_at > ?", wallet.ID, email, models.WalletInviteStatusPending, time.Now()).
+		Count(&pending).Error; err != nil {
+		return nil, err
+	}
+	if pending > 0 {
+		return nil, ErrInviteExists
+	}
+	if err := ms.checkMemberCount(ms.db, wallet.ID); err != nil {
+		return nil, err
+	}
+
+	invite := models.WalletInvite{
+		WalletID:   wallet.ID,
+		Email:      email,
+		Role:       role,
+		SpendLimit: limit,
+		Currency:   wallet.Currency,
+		InvitedBy:  userID,
+		Status:     models.WalletInviteStatusPending,
+		ExpiresAt:  time.Now().Add(walletInviteTTL),
+	}
+	if err := ms.db.Create(&invite).Error; err != nil {
+		return nil, fmt.Errorf("failed to create invite: %w", err)
+	}
+	return &invite, nil
+}
+
+// Invites lists the pending invites of a wallet. Only owners can see them.
+func (ms *WalletMemberService) Invites(userID uuid.UUID, walletID string) ([]models.WalletInvite, error) {
+	wallet, err := ms.wallets.MemberWallet(userID, walletID, models.WalletRoleOwner)
+	if err != nil {
+		return nil, err
+	}
+
+	invites := []models.WalletInvite{}
+	if err := ms.db.Where("wallet_id = ? AND status = ? AND expires_at > ?", wallet.ID, models.WalletInviteStatusPending, time.Now()).
+		Order("created_at DESC").Find(&invites).Error; err != nil {
+		return nil, err
+	}
+	return invites, nil
+}
+
+// RevokeInvite withdraws a pending invite
+func (ms *WalletMemberService) RevokeInvite(userID uuid.UUID, walletID, inviteID string) error {
+	wallet, err := ms.wallets.MemberWallet(userID, walletID, models.WalletRoleOwner)
+	if err != nil {
+		return err
+	}
+	id, err := uuid.Parse(inviteID)
+	if err != nil {
+		return ErrInviteNotFound
+	}
+
+	result := ms.db.Model(&models.WalletInvite{}).
+		Where("id = ? AND wallet_id = ? AND status = ?", id, wallet.ID, models.WalletInviteStatusPending).
+		Updates(map[string]interface{}{"status": models.WalletInviteStatusRevoked, "responded_at": time.Now()})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrInviteNotFound
+	}
+	return nil
+}
+
+// PendingInvites lists the open invites sent to the user's email address
+func (ms *WalletMemberService) PendingInvites(user *models.User) ([]models.WalletInvite, error) {
+	invites := []models.WalletInvite{}
+	if err := ms.db.Preload("Wallet").
+		Where("email = ? AND status = ? AND expires_at > ?", strings.ToLower(user.Email), models.WalletInviteStatusPending, time.Now()).
+		Order("created_at DESC").Find(&invites).Error; err != nil {
+		return nil, err
+	}
+	return invites, nil
+}
+
+// AcceptInvite makes the user a member of the wallet they were invited to
+func (ms *WalletMemberService) AcceptInvite(user *models.User, inviteID string) (*models.WalletMember, error) {
+	var member models.WalletMember
+	err := ms.db.Transaction(func(tx *gorm.DB) error {
+		invite, wallet, err := ms.pendingInvite(tx, user, inviteID)
+		if err != nil {
+			return err
+		}
+		role, err := walletRole(tx, wallet, user.ID)
+		if err != nil {
+			return err
+		}
+		if role != "" {
+			return ErrAlreadyMember
+		}
+		if err := ms.checkMemberCount(tx, wallet.ID); err != nil {
+			return err
+		}
+
+		invitedBy := invite.InvitedBy
+		member = models.WalletMember{
+			WalletID:   wallet.ID,
+			UserID:     user.ID,
+			Role:       invite.Role,
+			SpendLimit: invite.SpendLimit,
+			Currency:   invite.Currency,
+			InvitedBy:  &invitedBy,
+		}
+		if err := tx.Create(&member).Error; err != nil {
+			return fmt.Errorf("failed to add member: %w", err)
+		}
+		return tx.Model(invite).Updates(map[string]interface{}{
+			"status":       models.WalletInviteStatusAccepted,
+			"responded_at": time.Now(),
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+// DeclineInvite turns down an invite
+func (ms *WalletMemberService) DeclineInvite(user *models.User, inviteID string) error {
+	return ms.db.Transaction(func(tx *gorm.DB) error {
+		invite, _, err := ms.pendingInvite(tx, user, inviteID)
+		if err != nil {
+			return err
+		}
+		return tx.Model(invite).Updates(map[string]interface{}{
+			"status":       models.WalletInviteStatusDeclined,
+			"responded_at": time.Now(),
+		}).Error
+	})
+}
+
+// UpdateMember changes a member's role and spend limit. Only owners can do this.
+func (ms *WalletMemberService) UpdateMember(userID uuid.UUID, walletID, memberID, role string, spendLimit models.Money) (*models.WalletMember, error) {
+	wallet, err := ms.wallets.MemberWallet(userID, walletID, models.WalletRoleOwner)
+	if err != nil {
+		return nil, err
+	}
+	member, err := ms.member(wallet, memberID)
+	if err != nil {
+		return nil, err
+	}
+	limit, err := validateMemberRole(wallet, role, spendLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ms.db.Model(member).Updates(map[string]interface{}{"role": role, "spend_limit": limit}).Error; err != nil {
+		return nil, fmt.Errorf("failed to update member: %w", err)
+	}
+	member.Role = role
+	member.SpendLimit = limit
+	return member, nil
+}
+
+// RemoveMember takes a member off a wallet. Owners can remove anyone but the
+// account holder; every member can remove themselves.
+func (ms *WalletMemberService) RemoveMember(userID uuid.UUID, walletID, memberID string) error {
+	wallet, err := ms.wallets.UserWallet(userID, walletID)
+	if err != nil {
+		return err
+	}
+	member, err := ms.member(wallet, memberID)
+	if err != nil {
+		return err
+	}
+	if member.UserID != userID {
+		role, err := walletRole(ms.db, wallet, userID)
+		if err != nil {
+			return err
+		}
+		if role != models.WalletRoleOwner {
+			return ErrWalletPermission
+		}
+	}
+	return ms.db.Delete(member).Error
+}
+
+// member loads a member row of a wallet
+func (ms *WalletMemberService) member(wallet *models.Wallet, memberID string) (*models.WalletMember, error) {
+	id, err := uuid.Parse(memberID)
+	if err != nil {
+		return nil, ErrMemberNotFound
+	}
+
+	var member models.WalletMember
+	if err := ms.db.Where("id = ? AND wallet_id = ?", id, wallet.ID).First(&member).Error; err != nil {
+		if wallet.UserID.String() == memberID {
+			return nil, ErrWalletHolder
+		}
+		return nil, ErrMemberNotFound
+	}
+	return &member, nil
+}
+
+// pendingInvite loads an open invite addressed to the user together with its wallet
+func (ms *WalletMemberService) pendingInvite(tx *gorm.DB, user *models.User, inviteID string) (*models.WalletInvite, *models.Wallet, error) {
+	id, err := uuid.Parse(inviteID)
+	if err != nil {
+		return nil, nil, ErrInviteNotFound
+	}
+
+	var invite models.WalletInvite
+	if err := tx.Where("id = ? AND email = ? AND status = ?", id, strings.ToLower(user.Email), models.WalletInviteStatusPending).
+		First(&invite).Error; err != nil {
+		return nil, nil, ErrInviteNotFound
+	}
+	if !time.Now().Before(invite.ExpiresAt) {
+		return nil, nil, ErrInviteExpired
+	}
+
+	var wallet models.Wallet
+	if err := tx.First(&wallet, "id = ?", invite.WalletID).Error; err != nil {
+		return nil, nil, ErrWalletNotFound
+	}
+	if wallet.Status == models.WalletStatusClosed {
+		return nil, nil, ErrWalletClosed
+	}
+	return &invite, &wallet, nil
+}
+
+// checkMemberCount rejects a new member or invite once the wallet is full
+func (ms *WalletMemberService) checkMemberCount(tx *gorm.DB, walletID uuid.UUID) error {
+	var members int64
+	if err := tx.Model(&models.WalletMember{}).Where("wallet_id = ?", walletID).Count(&members).Error; err != nil {
+		return err
+	}
+	if members >= maxMembersPerWallet {
+		return ErrTooManyMembers
+	}
+	return nil
+}
+
+// validateMemberRole checks a role and returns the spend limit in the wallet currency
+func validateMemberRole(wallet *models.Wallet, role string, spendLimit models.Money) (models.Money, error) {
+	if _, ok := walletRoleRanks[role]; !ok {
+		return models.Money{}, ErrInvalidMemberRole
+	}
+	limit, err := spendLimit.In(wallet.Currency)
+	if err != nil || limit.IsNegative() {
+		return models.Money{}, ErrInvalidMemberRole
+	}
+	return limit, nil
+}
+
+// walletRole returns the user's role on a wallet, or an empty string if they have no access
+func walletRole(db *gorm.DB, wallet *models.Wallet, userID uuid.UUID) (string, error) {
+	if wallet.OrganizationID != nil {
+		return organizationWalletRole(db, *wallet.OrganizationID, userID)
+	}
+	if wallet.UserID == userID {
+		return models.WalletRoleOwner, nil
+	}
+
+	var member models.WalletMember
+	err := db.Select("role").Where("wallet_id = ? AND user_id = ?", wallet.ID, userID).First(&member).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return member.Role, nil
+}
+
+// hasWalletRole reports whether a role includes everything the minimum role may do
+func hasWalletRole(role, minRole string) bool {
+	return role != "" && walletRoleRanks[role] >= walletRoleRanks[minRole]
+}
+
+// memberWallets selects the IDs of every wallet, closed ones included, on which
+// the user holds at least the given role, organisation wallets included
+func memberWallets(db *gorm.DB, userID uuid.UUID, minRole string) *gorm.DB {
+	var roles []string
+	for role := range walletRoleRanks {
+		if hasWalletRole(role, minRole) {
+			roles = append(roles, role)
+		}
+	}
+	var orgRoles []string
+	for orgRole, role := range orgWalletRoles {
+		if hasWalletRole(role, minRole) {
+			orgRoles = append(orgRoles, orgRole)
+		}
+	}
+	return db.Unscoped().Model(&models.Wallet{}).Select("id").
+		Where("(user_id = ? AND organization_id IS NULL) OR id IN (?) OR organization_id IN (?)", userID,
+			db.Model(&models.WalletMember{}).Select("wallet_id").Where("user_id = ? AND role IN ?", userID, roles),
+			db.Model(&models.OrganizationMember{}).Select("organization_id").Where("user_id = ? AND role IN ?", userID, orgRoles))
+}
+
+// memberSpent sums what a user has paid out of a wallet since the given time
+func memberSpent(db *gorm.DB, wallet *models.Wallet, userID uuid.UUID, since time.Time) (models.Money, error) {
+	spent := models.ZeroMoney(wallet.Currency)
+	if err := db.Model(&models.Transaction{}).
+		Where("wallet_id = ? AND initiated_by = ? AND direction = ? AND created_at >= ?", wallet.ID, userID, "out", since).
+		Where("type NOT IN ?", memberSpendExcludedTypes).
+		Select("COALESCE(SUM(amount), 0)").
+		Row().Scan(&spent); err != nil {
+		return models.Money{}, fmt.Errorf("failed to sum member spending: %w", err)
+	}
+	return spent, nil
+}
+
+// checkMemberSpend makes sure that a member may pay an amount out of a wallet
+// and stays within their monthly spend limit. The wallet row must be locked so
+// that concurrent payments by the same member cannot both pass. Payments made by
+// the account holder or by the system are not limited, and payments out of
+// organisation wallets are controlled by their approvals instead.
+func checkMemberSpend(tx *gorm.DB, wallet *models.Wallet, userID uuid.UUID, amount models.Money) error {
+	if userID == uuid.Nil || wallet.OrganizationID != nil || userID == wallet.UserID {
+		return nil
+	}
+
+	var member models.WalletMember
+	if err := tx.Where("wallet_id = ? AND user_id = ?", wallet.ID, userID).First(&member).Error; err != nil {
+		return ErrWalletPermission
+	}
+	if !hasWalletRole(member.Role, models.WalletRoleSpender) {
+		return ErrWalletPermission
+	}
+	if !member.SpendLimit.IsPositive() {
+		return nil
+	}
+
+	spent, err := memberSpent(tx, wallet, userID, monthStart(time.Now()))
+	if err != nil {
+		return err
+	}
+	after, _ := spent.Add(amount)
+	if after.Amount > member.SpendLimit.Amount {
+		return &MemberSpendLimitError{Limit: member.SpendLimit, Spent: spent, Required: amount}
+	}
+	return nil
+}
+
+// monthStart returns midnight on the first day of the month
+func monthStart(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+}
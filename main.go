@@ -27,7 +27,8 @@ import (
 // @BasePath /api
 func main() {
 	// Parse command line flags
-	cronJob := flag.String("cron", "", "Execute a specific cron job (comment-approval, backup, log-cleanup, security-monitor)")
+	cronJob := flag.String("cron", "", "Execute a specific cron job (comment-approval, backup, log-cleanup, security-monitor, payout-processing, scheduled-transfers, monthly-statements, insight-rollups, bulk-payouts, hold-expiry, merchant-webhooks, interest-accrual, credit-line-interest)")
+	interestRecompute := flag.String("interest-recompute", "", "Accrue and capitalise interest again for a date range (YYYY-MM-DD:YYYY-MM-DD)")
 	flag.Parse()
 
 	// Load environment variables
@@ -41,6 +42,12 @@ func main() {
 		return
 	}
 
+	// Handle interest recompute
+	if *interestRecompute != "" {
+		executeInterestRecompute(*interestRecompute)
+		return
+	}
+
 	// Initialize Redis
 	if err := config.InitRedis(); err != nil {
 		log.Fatal("Failed to initialize Redis:", err)
@@ -133,7 +140,7 @@ func main() {
 		}
 
 		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization")
+		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, Idempotency-Key")
 		c.Header("Access-Control-Allow-Credentials", "true")
 		c.Header("Access-Control-Max-Age", "86400") // 24 hours
 
@@ -154,6 +161,19 @@ func main() {
 		routes.SetupAuthRoutes(api)
 		routes.SetupUserRoutes(api)
 		routes.SetupWalletRoutes(api)
+		routes.SetupPocketRoutes(api)
+		routes.SetupHoldRoutes(api)
+		routes.SetupWalletMemberRoutes(api)
+		routes.SetupOrganizationRoutes(api)
+		routes.SetupExternalAccountRoutes(api)
+		routes.SetupScheduledTransferRoutes(api)
+		routes.SetupBulkPayoutRoutes(api)
+		routes.SetupPaymentRequestRoutes(api)
+		routes.SetupDisputeRoutes(api)
+		routes.SetupMerchantRoutes(api)
+		routes.SetupCheckoutRoutes(api)
+		routes.SetupCategoryRoutes(api)
+		routes.SetupInsightRoutes(api)
 		routes.SetupTransactionRoutes(api)
 		routes.SetupAdminRoutes(api)
 		routes.SetupSupportRoutes(api)
@@ -251,3 +271,41 @@ func executeCronJob(jobName string) {
 
 	log.Printf("Cron job %s completed successfully", jobName)
 }
+
+// executeInterestRecompute accrues and capitalises interest again for a date range
+func executeInterestRecompute(dateRange string) {
+	from, to, found := strings.Cut(dateRange, ":")
+	if !found {
+		log.Fatal("Interest recompute range must be YYYY-MM-DD:YYYY-MM-DD")
+	}
+	start, err := time.ParseInLocation("2006-01-02", from, time.Local)
+	if err != nil {
+		log.Fatal("Invalid recompute start date:", err)
+	}
+	end, err := time.ParseInLocation("2006-01-02", to, time.Local)
+	if err != nil {
+		log.Fatal("Invalid recompute end date:", err)
+	}
+
+	log.Printf("Recomputing interest from %s to %s", from, to)
+
+	// Initialize Redis
+	if err := config.InitRedis(); err != nil {
+		log.Fatal("Failed to initialize Redis:", err)
+	}
+
+	// Initialize database connection
+	if err := config.InitDB(); err != nil {
+		log.Fatal("Failed to initialize database:", err)
+	}
+
+	// Initialize services
+	services.InitServices()
+
+	result, err := services.NewInterestService().Recompute(start, end)
+	if err != nil {
+		log.Fatal("Failed to recompute interest:", err)
+	}
+
+	log.Printf("Recomputed %d days: %d accruals, %d interest entries posted", result.Days, result.Accruals, result.Postings)
+}